@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	cmpinstall "github.com/posener/complete/cmd/install"
 	"github.com/urfave/cli/v2"
@@ -11,6 +12,7 @@ import (
 	"github.com/peak/s5cmd/log/stat"
 	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
 )
 
 const (
@@ -24,6 +26,10 @@ var app = &cli.App{
 	Name:  appName,
 	Usage: "Blazing fast S3 and local filesystem execution tool",
 	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "config",
+			Usage: "path of the config file which sets the defaults of the global flags (default: ~/.s5cmd.yaml)",
+		},
 		&cli.BoolFlag{
 			Name:  "json",
 			Usage: "enable JSON formatted output",
@@ -31,7 +37,11 @@ var app = &cli.App{
 		&cli.IntFlag{
 			Name:  "numworkers",
 			Value: defaultWorkerCount,
-			Usage: "number of workers execute operation on each object",
+			Usage: "number of workers execute operation on each object, i.e. the number of objects operated on at once",
+		},
+		&cli.IntFlag{
+			Name:  "max-inflight",
+			Usage: "maximum number of operations which are running or waiting for a worker, listings pause until it drops (0 means no limit)",
 		},
 		&cli.IntFlag{
 			Name:    "retry-count",
@@ -43,14 +53,51 @@ var app = &cli.App{
 			Name:  "endpoint-url",
 			Usage: "override default S3 host for custom services",
 		},
+		&cli.BoolFlag{
+			Name:  "use-accelerate-endpoint",
+			Usage: "use S3 Transfer Acceleration endpoints, acceleration must be enabled on the buckets",
+		},
 		&cli.BoolFlag{
 			Name:  "no-verify-ssl",
 			Usage: "disable SSL certificate verification",
 		},
+		&cli.StringSliceFlag{
+			Name:  "insecure-hosts",
+			Usage: "disable SSL certificate verification only for given comma separated hosts, e.g. minio.local",
+		},
+		&cli.BoolFlag{
+			Name:  "no-sign-request",
+			Usage: "do not sign requests: credentials will not be loaded, e.g. to access public buckets",
+		},
+		&cli.IntFlag{
+			Name:  "max-requests-per-second",
+			Usage: "limit the number of requests sent to each bucket in a second to avoid throttling (0 means no limit)",
+		},
+		&cli.IntFlag{
+			Name:  "list-concurrency",
+			Value: 1,
+			Usage: "number of sub-prefixes listed concurrently for wildcard operations, objects are not listed in order if it's more than 1",
+		},
+		&cli.DurationFlag{
+			Name:  "http-timeout",
+			Usage: "time limit for each HTTP request made to the S3 host, e.g. 30s (0 means no limit)",
+		},
+		&cli.StringFlag{
+			Name:  "proxy-url",
+			Usage: "send requests through the given HTTP proxy instead of the one in environment variables",
+		},
+		&cli.StringFlag{
+			Name:  "ca-bundle",
+			Usage: "path to a PEM file of certificates to trust, in addition to the system ones",
+		},
+		&cli.BoolFlag{
+			Name:  "no-follow-redirects",
+			Usage: "do not follow HTTP redirects returned by the S3 host",
+		},
 		&cli.StringFlag{
 			Name:  "log",
 			Value: "info",
-			Usage: "log level: (debug, info, error)",
+			Usage: "log level: (debug, info, warning, error)",
 		},
 		&cli.BoolFlag{
 			Name:  "install-completion",
@@ -64,8 +111,24 @@ var app = &cli.App{
 			Name:  "stat",
 			Usage: "collect statistics of program execution and display it at the end",
 		},
+		&cli.BoolFlag{
+			Name:  "summary",
+			Usage: "print the failed objects with their last errors at the end",
+		},
+		&cli.BoolFlag{
+			Name:  "continue-on-error",
+			Usage: "run the remaining operations when an operation fails (default)",
+		},
+		&cli.BoolFlag{
+			Name:  "stop-on-first-error",
+			Usage: "cancel the remaining operations as soon as an operation fails",
+		},
 	},
 	Before: func(c *cli.Context) error {
+		// the config file is loaded before the other flags are read, since it
+		// sets their defaults.
+		configErr := loadConfig(c)
+
 		retryCount := c.Int("retry-count")
 		workerCount := c.Int("numworkers")
 		printJSON := c.Bool("json")
@@ -73,7 +136,12 @@ var app = &cli.App{
 		isStat := c.Bool("stat")
 
 		log.Init(logLevel, printJSON)
-		parallel.Init(workerCount)
+		parallel.Init(workerCount, c.Int("max-inflight"))
+
+		if configErr != nil {
+			printError(givenCommand(c), c.Command.Name, configErr)
+			return configErr
+		}
 
 		if retryCount < 0 {
 			err := fmt.Errorf("retry count cannot be a negative value")
@@ -81,10 +149,62 @@ var app = &cli.App{
 			return err
 		}
 
+		if c.Int("max-requests-per-second") < 0 {
+			err := fmt.Errorf("max requests per second cannot be a negative value")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		if c.Bool("use-accelerate-endpoint") && c.String("endpoint-url") != "" {
+			err := fmt.Errorf("--use-accelerate-endpoint can not be used with --endpoint-url")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		if len(c.StringSlice("insecure-hosts")) > 0 && c.Bool("no-verify-ssl") {
+			err := fmt.Errorf("--insecure-hosts can not be used with --no-verify-ssl")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		if c.Int("max-inflight") < 0 {
+			err := fmt.Errorf("max inflight cannot be a negative value")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		if c.Int("list-concurrency") < 1 {
+			err := fmt.Errorf("list concurrency must be a positive value")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		if c.Bool("continue-on-error") && c.Bool("stop-on-first-error") {
+			err := fmt.Errorf("--continue-on-error can not be used with --stop-on-first-error")
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		// the context of the commands is replaced, so that the operations
+		// in-flight are canceled on the first error, along with draining the
+		// ones which are not started yet.
+		if c.Bool("stop-on-first-error") {
+			ctx, cancel := context.WithCancel(c.Context)
+			c.Context = ctx
+			stopOnError = func() {
+				parallel.Drain()
+				cancel()
+			}
+		}
+
 		if isStat {
 			stat.InitStat()
 		}
 
+		if c.Bool("summary") {
+			stat.InitFailures()
+		}
+
 		return storage.Init(NewStorageOpts(c))
 	},
 	Action: func(c *cli.Context) error {
@@ -101,6 +221,16 @@ var app = &cli.App{
 	After: func(c *cli.Context) error {
 		if c.Bool("stat") {
 			log.Info(stat.Statistics())
+
+			if transfers := stat.Transfers(); transfers.Objects > 0 {
+				log.Info(transfers)
+			}
+		}
+
+		if c.Bool("summary") {
+			if failures := stat.Failures(); len(failures) > 0 {
+				log.Error(failures)
+			}
 		}
 
 		parallel.Close()
@@ -116,7 +246,35 @@ func NewStorageOpts(c *cli.Context) storage.Options {
 		Endpoint:    c.String("endpoint-url"),
 		NoVerifySSL: c.Bool("no-verify-ssl"),
 		DryRun:      c.Bool("dry-run"),
+
+		InsecureHosts: strings.Join(c.StringSlice("insecure-hosts"), ","),
+
+		NoSignRequest:        c.Bool("no-sign-request"),
+		UseAccelerate:        c.Bool("use-accelerate-endpoint"),
+		MaxRequestsPerSecond: c.Int("max-requests-per-second"),
+		ListConcurrency:      c.Int("list-concurrency"),
+
+		HTTPTimeout:       c.Duration("http-timeout"),
+		ProxyURL:          c.String("proxy-url"),
+		CABundle:          c.String("ca-bundle"),
+		NoFollowRedirects: c.Bool("no-follow-redirects"),
+	}
+}
+
+// maxDepthFromFlags returns the value of --max-depth flag, or -1 if it is not
+// given, which means there is no limit.
+func maxDepthFromFlags(c *cli.Context) int {
+	if !c.IsSet("max-depth") {
+		return -1
 	}
+	return c.Int("max-depth")
+}
+
+// exceedsMaxDepth reports whether the object is deeper than the given depth
+// limit, relative to the base of the listing. A negative limit means there is
+// no limit.
+func exceedsMaxDepth(u *url.URL, maxDepth int) bool {
+	return maxDepth >= 0 && u.Depth() > maxDepth
 }
 
 // Main is the entrypoint function to run given commands.
@@ -129,7 +287,17 @@ func Main(ctx context.Context, args []string) error {
 		makeBucketCommand,
 		sizeCommand,
 		catCommand,
+		existsCommand,
+		waitCommand,
+		listMultipartCommand,
+		checksumCommand,
+		concatCommand,
+		setMetaCommand,
+		makePublicCommand,
+		makePrivateCommand,
+		syncCommand,
 		runCommand,
+		debugConfigCommand,
 		versionCommand,
 	}
 
@@ -5,14 +5,23 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
+	errorpkg "github.com/peak/s5cmd/error"
 	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage"
 	"github.com/peak/s5cmd/storage/url"
 )
 
+// defaultFollowInterval is the default interval of checking the object for
+// appended bytes with --follow.
+const defaultFollowInterval = 5 * time.Second
+
 var catHelpTemplate = `Name:
 	{{.HelpName}} - {{.Usage}}
 
@@ -25,6 +34,18 @@ Options:
 Examples:
 	1. Print a remote object's content to stdout
 		 > s5cmd {{.HelpName}} s3://bucket/prefix/object
+
+	2. Print a remote object's content to stdout, only if it's not changed since it's last seen
+		 > s5cmd {{.HelpName}} --if-match 5d41402abc4b2a76b9719d911017c592 s3://bucket/prefix/object
+
+	3. Print the first kilobyte of a remote object
+		 > s5cmd {{.HelpName}} --length 1024 s3://bucket/prefix/object
+
+	4. Print the last 8 bytes of a remote object, e.g. the footer of a parquet file
+		 > s5cmd {{.HelpName}} --range bytes=-8 s3://bucket/prefix/data.parquet
+
+	5. Print a remote log object, and then the bytes appended to it as it grows, checking it every 10 seconds
+		 > s5cmd {{.HelpName}} --follow --interval 10s s3://bucket/logs/app.log
 `
 
 var catCommand = &cli.Command{
@@ -32,6 +53,38 @@ var catCommand = &cli.Command{
 	HelpName:           "cat",
 	Usage:              "print remote object's contents to stdout",
 	CustomHelpTemplate: catHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "if-match",
+			Usage: "only print the object if its ETag matches the given one",
+		},
+		&cli.StringFlag{
+			Name:  "if-none-match",
+			Usage: "only print the object if its ETag doesn't match the given one",
+		},
+		&cli.Int64Flag{
+			Name:  "offset",
+			Usage: "print the object starting from given byte offset",
+		},
+		&cli.Int64Flag{
+			Name:  "length",
+			Usage: "print at most given number of bytes of the object",
+		},
+		&cli.StringFlag{
+			Name:  "range",
+			Usage: "print given HTTP byte range of the object, e.g. 'bytes=0-1023', 'bytes=1024-' or 'bytes=-1024' for the last 1024 bytes",
+		},
+		&cli.BoolFlag{
+			Name:    "follow",
+			Aliases: []string{"f"},
+			Usage:   "keep printing the bytes appended to the object as it grows, until interrupted",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Value: defaultFollowInterval,
+			Usage: "check the object for appended bytes at given interval, only for --follow",
+		},
+	},
 	Before: func(c *cli.Context) error {
 		err := validateCatCommand(c)
 		if err != nil {
@@ -54,6 +107,13 @@ var catCommand = &cli.Command{
 			src:         src,
 			op:          op,
 			fullCommand: fullCommand,
+			// flags
+			ifMatch:     c.String("if-match"),
+			ifNoneMatch: c.String("if-none-match"),
+			byteRange:   byteRangeFromFlags(c),
+			follow:      c.Bool("follow"),
+			interval:    c.Duration("interval"),
+			offset:      c.Int64("offset"),
 
 			storageOpts: NewStorageOpts(c),
 		}.Run(c.Context)
@@ -66,18 +126,40 @@ type Cat struct {
 	op          string
 	fullCommand string
 
+	// flags
+	ifMatch     string
+	ifNoneMatch string
+	byteRange   string
+	follow      bool
+	interval    time.Duration
+	offset      int64
+
 	storageOpts storage.Options
 }
 
 // Run prints content of given source to standard output.
 func (c Cat) Run(ctx context.Context) error {
-	client, err := storage.NewRemoteClient(c.src, c.storageOpts)
+	client, err := storage.NewRemoteClient(ctx, c.src, c.storageOpts)
 	if err != nil {
 		printError(c.fullCommand, c.op, err)
 		return err
 	}
 
-	rc, err := client.Read(ctx, c.src)
+	if c.follow {
+		err := c.doFollow(ctx, client)
+		if err != nil && !errorpkg.IsCancelation(err) {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+		return nil
+	}
+
+	metadata := storage.NewMetadata().
+		SetIfMatch(c.ifMatch).
+		SetIfNoneMatch(c.ifNoneMatch).
+		SetRange(c.byteRange)
+
+	rc, err := client.Read(ctx, c.src, metadata)
 	if err != nil {
 		printError(c.fullCommand, c.op, err)
 		return err
@@ -93,6 +175,75 @@ func (c Cat) Run(ctx context.Context) error {
 	return nil
 }
 
+// doFollow prints the object starting from the offset, and then the bytes
+// appended to it at each interval, until the context is canceled. Objects are
+// assumed to grow by being re-uploaded with more data. If the object shrinks,
+// or it's changed without growing, it's printed from the start again.
+func (c Cat) doFollow(ctx context.Context, client *storage.S3) error {
+	var (
+		printed = c.offset
+		etag    string
+	)
+
+	for {
+		obj, err := client.Stat(ctx, c.src)
+		switch {
+		case err == storage.ErrGivenObjectNotFound:
+			// the object is being replaced, it's printed from the start once
+			// it's back.
+			printed, etag = 0, ""
+		case err != nil:
+			return err
+		case obj.Size < printed || (obj.Size == printed && etag != "" && obj.Etag != etag):
+			printed, etag = 0, obj.Etag
+			fallthrough
+		case obj.Size > printed:
+			n, err := c.printRange(ctx, client, obj, printed)
+			printed += n
+			if err == storage.ErrPreconditionFailed {
+				// the object is changed after it's checked, the rest is
+				// printed at the next interval.
+				break
+			}
+			if err != nil {
+				return err
+			}
+			etag = obj.Etag
+		}
+
+		// first interrupt stops following once the bytes being printed are
+		// done.
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-parallel.Draining():
+			return nil
+		case <-time.After(c.interval):
+		}
+	}
+}
+
+// printRange prints the bytes of the object starting from the given offset.
+// The object is only read if it's not changed since it's checked. It returns
+// the number of printed bytes.
+func (c Cat) printRange(ctx context.Context, client *storage.S3, obj *storage.Object, offset int64) (int64, error) {
+	if obj.Size == 0 {
+		return 0, nil
+	}
+
+	metadata := storage.NewMetadata().
+		SetIfMatch(obj.Etag).
+		SetRange(fmt.Sprintf("bytes=%d-%d", offset, obj.Size-1))
+
+	rc, err := client.Read(ctx, c.src, metadata)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(os.Stdout, rc)
+}
+
 func validateCatCommand(c *cli.Context) error {
 	if c.Args().Len() != 1 {
 		return fmt.Errorf("expected only one argument")
@@ -115,5 +266,79 @@ func validateCatCommand(c *cli.Context) error {
 	if src.HasGlob() {
 		return fmt.Errorf("remote source %q can not contain glob characters", src)
 	}
+
+	if c.String("if-match") != "" && c.String("if-none-match") != "" {
+		return fmt.Errorf("--if-match can not be used with --if-none-match")
+	}
+
+	if c.Bool("follow") {
+		if c.String("range") != "" || c.IsSet("length") {
+			return fmt.Errorf("--follow can not be used with --range or --length")
+		}
+		if c.String("if-match") != "" || c.String("if-none-match") != "" {
+			return fmt.Errorf("--follow can not be used with --if-match or --if-none-match")
+		}
+		if c.Duration("interval") <= 0 {
+			return fmt.Errorf("--interval must be a positive duration")
+		}
+	} else if c.IsSet("interval") {
+		return fmt.Errorf("--interval can only be used with --follow")
+	}
+
+	return validateByteRange(c)
+}
+
+// byteRangeRegex matches a single HTTP byte range, e.g. bytes=0-1023,
+// bytes=1024- or bytes=-1024.
+var byteRangeRegex = regexp.MustCompile(`^bytes=(?:(\d+)-(\d*)|-(\d+))$`)
+
+// byteRangeFromFlags returns the HTTP byte range of --range, or the one of
+// --offset and --length. It's empty if none of them is given.
+func byteRangeFromFlags(c *cli.Context) string {
+	if r := c.String("range"); r != "" {
+		return r
+	}
+
+	offset, length := c.Int64("offset"), c.Int64("length")
+	switch {
+	case c.IsSet("length"):
+		return fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)
+	case c.IsSet("offset"):
+		return fmt.Sprintf("bytes=%d-", offset)
+	default:
+		return ""
+	}
+}
+
+// validateByteRange validates --offset, --length and --range flags. Ranges
+// past the end of the object are rejected by S3.
+func validateByteRange(c *cli.Context) error {
+	if r := c.String("range"); r != "" {
+		if c.IsSet("offset") || c.IsSet("length") {
+			return fmt.Errorf("--range can not be used with --offset or --length")
+		}
+
+		match := byteRangeRegex.FindStringSubmatch(r)
+		if match == nil {
+			return fmt.Errorf("invalid --range %q: must be a single byte range, e.g. bytes=0-1023, bytes=1024- or bytes=-1024", r)
+		}
+
+		if match[2] != "" {
+			start, _ := strconv.ParseInt(match[1], 10, 64)
+			end, _ := strconv.ParseInt(match[2], 10, 64)
+			if end < start {
+				return fmt.Errorf("invalid --range %q: end of the range is before its start", r)
+			}
+		}
+		return nil
+	}
+
+	if c.Int64("offset") < 0 {
+		return fmt.Errorf("--offset can not be negative")
+	}
+
+	if c.IsSet("length") && c.Int64("length") <= 0 {
+		return fmt.Errorf("--length must be a positive value")
+	}
 	return nil
 }
@@ -0,0 +1,246 @@
+package command
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+var checksumHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] argument
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Print MD5 checksum of an S3 object
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Print MD5 checksums of all objects that match a wildcard
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/*.gz
+
+	3. Print MD5 checksums of all objects that match a wildcard, downloading the ones uploaded in multiple parts
+		 > s5cmd {{.HelpName}} --compute s3://bucket/prefix/*.gz
+
+Checksums are read from the ETags of the objects, without downloading them.
+ETags of the objects uploaded in multiple parts, or encrypted with SSE-KMS, are
+not MD5 checksums of the content. Such objects are downloaded to compute their
+checksums if --compute is given.
+`
+
+var checksumCommand = &cli.Command{
+	Name:               "checksum",
+	HelpName:           "checksum",
+	Usage:              "print MD5 checksums of objects",
+	CustomHelpTemplate: checksumHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "compute",
+			Usage: "download the objects whose checksums are not known to compute them",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateChecksumCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		return Checksum{
+			src:         c.Args().First(),
+			op:          c.Command.Name,
+			fullCommand: givenCommand(c),
+			// flags
+			compute: c.Bool("compute"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// Checksum holds checksum operation flags and states.
+type Checksum struct {
+	src         string
+	op          string
+	fullCommand string
+
+	// flags
+	compute bool
+
+	storageOpts storage.Options
+}
+
+// Run prints checksums of the given source objects.
+func (cs Checksum) Run(ctx context.Context) error {
+	srcurl, err := url.New(cs.src)
+	if err != nil {
+		printError(cs.fullCommand, cs.op, err)
+		return err
+	}
+
+	client, err := storage.NewRemoteClient(ctx, srcurl, cs.storageOpts)
+	if err != nil {
+		printError(cs.fullCommand, cs.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, srcurl)
+	if err != nil {
+		printError(cs.fullCommand, cs.op, err)
+		return err
+	}
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(cs.fullCommand, cs.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	for object := range objch {
+		if parallel.IsDraining() {
+			break
+		}
+
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			printError(cs.fullCommand, cs.op, err)
+			continue
+		}
+
+		srcurl := object.URL
+		task := func() error {
+			err := cs.doChecksum(ctx, client, srcurl)
+			if err != nil {
+				return &errorpkg.Error{
+					Op:  cs.op,
+					Src: srcurl,
+					Err: err,
+				}
+			}
+			return nil
+		}
+
+		parallel.Run(task, waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	return merror
+}
+
+// doChecksum prints the checksum of the object. The object is stat'ed, since
+// listings don't tell whether the objects are encrypted.
+func (cs Checksum) doChecksum(ctx context.Context, client *storage.S3, srcurl *url.URL) error {
+	obj, err := client.Stat(ctx, srcurl)
+	if err != nil {
+		return err
+	}
+
+	msg := ChecksumMessage{
+		Source:    srcurl,
+		Algorithm: "md5",
+	}
+
+	switch {
+	case isMD5ETag(obj):
+		msg.Checksum = obj.Etag
+	case cs.compute:
+		msg.Checksum, err = computeChecksum(ctx, client, srcurl)
+		if err != nil {
+			return err
+		}
+		msg.Computed = true
+	default:
+		return fmt.Errorf("checksum is not stored, use --compute to compute it")
+	}
+
+	log.Info(msg)
+	return nil
+}
+
+// computeChecksum reads the object and returns the MD5 digest of its content
+// in hex.
+func computeChecksum(ctx context.Context, client *storage.S3, srcurl *url.URL) (string, error) {
+	rc, err := client.Read(ctx, srcurl, storage.NewMetadata())
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ChecksumMessage is the structure for logging the checksum of an object.
+type ChecksumMessage struct {
+	Source    *url.URL `json:"source"`
+	Checksum  string   `json:"checksum"`
+	Algorithm string   `json:"algorithm"`
+	Computed  bool     `json:"computed"`
+}
+
+// String returns the string representation of ChecksumMessage, which is the
+// same as the output of md5sum.
+func (m ChecksumMessage) String() string {
+	return fmt.Sprintf("%v  %v", m.Checksum, m.Source)
+}
+
+// JSON returns the JSON representation of ChecksumMessage.
+func (m ChecksumMessage) JSON() string {
+	return strutil.JSON(m)
+}
+
+func validateChecksumCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected only one argument")
+	}
+
+	src, err := url.New(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if !src.HasGlob() && (src.IsBucket() || src.IsPrefix()) {
+		return fmt.Errorf("remote source must be an object or contain a wildcard")
+	}
+	return nil
+}
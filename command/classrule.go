@@ -0,0 +1,150 @@
+package command
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// sizeUnits are the multipliers of the size units accepted in storage class
+// rules. Units are powers of 1024, as in the humanized sizes printed by ls.
+var sizeUnits = map[string]int64{
+	"":   1,
+	"B":  1,
+	"K":  1 << 10,
+	"KB": 1 << 10,
+	"M":  1 << 20,
+	"MB": 1 << 20,
+	"G":  1 << 30,
+	"GB": 1 << 30,
+	"T":  1 << 40,
+	"TB": 1 << 40,
+}
+
+// storageClassRule selects a storage class for the files whose sizes satisfy
+// the comparison.
+//
+// Example:
+//
+//	rule: >1GB:STANDARD_IA
+//	size: 2147483648
+//	output: STANDARD_IA
+type storageClassRule struct {
+	op    string
+	size  int64
+	class storage.StorageClass
+}
+
+// storageClassRules selects the storage class of the uploaded files by their
+// sizes. Rules are evaluated in order and the first matching one is used.
+type storageClassRules []storageClassRule
+
+// parseStorageClassRules parses the given rules, each in '<op><size>:<class>'
+// format. Supported comparison operators are '<', '<=', '>' and '>='.
+func parseStorageClassRules(exprs []string) (storageClassRules, error) {
+	var rules storageClassRules
+	for _, expr := range exprs {
+		rule, err := parseStorageClassRule(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func parseStorageClassRule(expr string) (storageClassRule, error) {
+	invalid := func(reason string) (storageClassRule, error) {
+		return storageClassRule{}, fmt.Errorf("invalid storage class rule %q: %v", expr, reason)
+	}
+
+	i := strings.LastIndexByte(expr, ':')
+	if i == -1 {
+		return invalid("must be in '>1GB:STANDARD_IA' format")
+	}
+
+	cond, class := strings.TrimSpace(expr[:i]), strings.TrimSpace(expr[i+1:])
+	if class == "" {
+		return invalid("empty storage class")
+	}
+
+	var op string
+	for _, candidate := range []string{"<=", ">=", "<", ">"} {
+		if strings.HasPrefix(cond, candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return invalid("size must start with one of '<', '<=', '>' or '>='")
+	}
+
+	size, err := parseSize(strings.TrimSpace(strings.TrimPrefix(cond, op)))
+	if err != nil {
+		return invalid(err.Error())
+	}
+
+	return storageClassRule{
+		op:    op,
+		size:  size,
+		class: storage.StorageClass(strings.ToUpper(class)),
+	}, nil
+}
+
+// parseSize parses a size such as '512MB' or '1GB' in bytes.
+func parseSize(s string) (int64, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i == -1 {
+		i = len(s)
+	}
+
+	if i == 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	multiplier, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid size unit %q", s[i:])
+	}
+
+	n, err := strconv.ParseInt(s[:i], 10, 64)
+	if err != nil || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// match reports whether the file with given size satisfies the rule.
+func (r storageClassRule) match(size int64) bool {
+	switch r.op {
+	case "<":
+		return size < r.size
+	case "<=":
+		return size <= r.size
+	case ">":
+		return size > r.size
+	default:
+		return size >= r.size
+	}
+}
+
+// storageClass returns the storage class of the first rule satisfied by the
+// given size. If no rule is satisfied, fallback is returned.
+func (r storageClassRules) storageClass(size int64, fallback storage.StorageClass) storage.StorageClass {
+	for _, rule := range r {
+		if rule.match(size) {
+			return rule.class
+		}
+	}
+	return fallback
+}
+
+// belowMinimumSize reports whether a file with given size is stored in the
+// standard storage class instead of the given infrequent access class, since
+// it's smaller than the minimum size.
+func belowMinimumSize(class storage.StorageClass, size, minSize int64) bool {
+	return minSize > 0 && class.IsInfrequentAccess() && size < minSize
+}
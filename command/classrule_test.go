@@ -0,0 +1,146 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestStorageClassRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		rules    []string
+		size     int64
+		fallback storage.StorageClass
+		want     storage.StorageClass
+		wantErr  bool
+	}{
+		{
+			name:  "larger_than_threshold",
+			rules: []string{">1GB:STANDARD_IA"},
+			size:  1<<30 + 1,
+			want:  "STANDARD_IA",
+		},
+		{
+			name:     "equal_to_threshold",
+			rules:    []string{">1GB:STANDARD_IA"},
+			size:     1 << 30,
+			fallback: "STANDARD",
+			want:     "STANDARD",
+		},
+		{
+			name:  "greater_or_equal",
+			rules: []string{">=1G:standard_ia"},
+			size:  1 << 30,
+			want:  "STANDARD_IA",
+		},
+		{
+			name:  "smaller_than_threshold",
+			rules: []string{"<128KB:ONEZONE_IA"},
+			size:  1024,
+			want:  "ONEZONE_IA",
+		},
+		{
+			name:  "first_matching_rule_wins",
+			rules: []string{">1TB:DEEP_ARCHIVE", ">1GB:GLACIER", ">512MB:STANDARD_IA"},
+			size:  2 << 30,
+			want:  "GLACIER",
+		},
+		{
+			name:     "no_rules",
+			size:     2 << 30,
+			fallback: "REDUCED_REDUNDANCY",
+			want:     "REDUCED_REDUNDANCY",
+		},
+		{
+			name:  "size_in_bytes",
+			rules: []string{"<= 100 : STANDARD_IA"},
+			size:  100,
+			want:  "STANDARD_IA",
+		},
+		{
+			name:    "error_if_missing_class",
+			rules:   []string{">1GB"},
+			wantErr: true,
+		},
+		{
+			name:    "error_if_empty_class",
+			rules:   []string{">1GB:"},
+			wantErr: true,
+		},
+		{
+			name:    "error_if_missing_operator",
+			rules:   []string{"1GB:STANDARD_IA"},
+			wantErr: true,
+		},
+		{
+			name:    "error_if_unknown_unit",
+			rules:   []string{">1PB:STANDARD_IA"},
+			wantErr: true,
+		},
+		{
+			name:    "error_if_missing_size",
+			rules:   []string{">GB:STANDARD_IA"},
+			wantErr: true,
+		},
+		{
+			name:    "error_if_size_overflows",
+			rules:   []string{">9223372036854775807TB:STANDARD_IA"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			rules, err := parseStorageClassRules(tc.rules)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for rules %q", tc.rules)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := rules.storageClass(tc.size, tc.fallback); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBelowMinimumSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		class   storage.StorageClass
+		size    int64
+		minSize int64
+		want    bool
+	}{
+		{name: "small_infrequent_access", class: "STANDARD_IA", size: 1024, minSize: 128 << 10, want: true},
+		{name: "small_one_zone_infrequent_access", class: "ONEZONE_IA", size: 1024, minSize: 128 << 10, want: true},
+		{name: "large_infrequent_access", class: "STANDARD_IA", size: 128 << 10, minSize: 128 << 10, want: false},
+		{name: "small_standard", class: "STANDARD", size: 1024, minSize: 128 << 10, want: false},
+		{name: "small_glacier", class: "GLACIER", size: 1024, minSize: 128 << 10, want: false},
+		{name: "no_minimum_size", class: "STANDARD_IA", size: 1024, minSize: 0, want: false},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := belowMinimumSize(tc.class, tc.size, tc.minSize); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
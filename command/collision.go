@@ -0,0 +1,145 @@
+package command
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// Strategies to resolve the source objects which would be downloaded to the
+// same local file.
+const (
+	onCollisionError     = "error"
+	onCollisionRename    = "rename"
+	onCollisionSkip      = "skip"
+	onCollisionOverwrite = "overwrite"
+)
+
+var onCollisionValues = []string{
+	onCollisionError,
+	onCollisionRename,
+	onCollisionSkip,
+	onCollisionOverwrite,
+}
+
+func isValidOnCollision(s string) bool {
+	for _, v := range onCollisionValues {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// collisionsPossible reports whether different source objects can be written
+// to the same destination. Keys of the objects are unique, so it's only
+// possible if the names are changed, i.e. the directory structure is flattened
+// or the names are transformed, or the objects are matched by different source
+// arguments.
+func (c Copy) collisionsPossible(srcurl, dsturl *url.URL, isBatch, multipleSources bool) bool {
+	if !srcurl.IsRemote() || dsturl.IsRemote() {
+		return false
+	}
+	return multipleSources || (isBatch && (c.flatten || c.transform != nil))
+}
+
+// pendingObject is a source object which is transferred once the collisions
+// are resolved.
+type pendingObject struct {
+	object  *storage.Object
+	isBatch bool
+}
+
+// sortBySize sorts the objects by their sizes in increasing order. Objects
+// with the same size are kept in the order they are listed.
+func sortBySize(objects []pendingObject) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].object.Size < objects[j].object.Size
+	})
+}
+
+// resolveCollisions returns the objects to be downloaded after applying the
+// collision strategy to the objects with the same destination names:
+//
+//	error: an error is returned and nothing is downloaded
+//	rename: all objects are downloaded, except the first one, a number is
+//	        appended to the names of the objects, i.e. 'file-1.txt'
+//	skip: only the first object is downloaded
+//	overwrite: only the last object is downloaded, as if they were downloaded
+//	           in order
+//
+// Objects are ordered by their keys.
+func (c Copy) resolveCollisions(objects []pendingObject, dsturl *url.URL) ([]pendingObject, error) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		return objects[i].object.URL.String() < objects[j].object.URL.String()
+	})
+
+	var names []string
+	byName := map[string][]pendingObject{}
+	for _, p := range objects {
+		name := c.objectName(p.object.URL, p.isBatch)
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], p)
+	}
+
+	var resolved []pendingObject
+	for _, name := range names {
+		group := byName[name]
+		if len(group) == 1 {
+			resolved = append(resolved, group[0])
+			continue
+		}
+
+		switch c.onCollision {
+		case onCollisionRename:
+			resolved = append(resolved, group[0])
+			for i, p := range group[1:] {
+				newName := uniqueName(name, i+1, byName)
+				byName[newName] = []pendingObject{p}
+				c.renames[p.object.URL.String()] = newName
+				resolved = append(resolved, p)
+			}
+		case onCollisionSkip:
+			resolved = append(resolved, group[0])
+			for _, p := range group[1:] {
+				err := fmt.Errorf("object %q has the same destination", group[0].object.URL)
+				printDebug(c.op, p.object.URL, dsturl.Join(name), err)
+			}
+		case onCollisionOverwrite:
+			last := group[len(group)-1]
+			resolved = append(resolved, last)
+			for _, p := range group[:len(group)-1] {
+				err := fmt.Errorf("object %q has the same destination", last.object.URL)
+				printDebug(c.op, p.object.URL, dsturl.Join(name), err)
+			}
+		default:
+			return nil, fmt.Errorf(
+				"objects %q and %q would be downloaded to the same file %q, use --on-collision to resolve",
+				group[0].object.URL, group[1].object.URL, name,
+			)
+		}
+	}
+
+	return resolved, nil
+}
+
+// uniqueName appends the smallest number starting from n to the name, before
+// its extension, which doesn't collide with any of the given names.
+func uniqueName(name string, n int, names map[string][]pendingObject) string {
+	dir, base := path.Split(name)
+	ext := path.Ext(base)
+	base = strings.TrimSuffix(base, ext)
+
+	for ; ; n++ {
+		candidate := fmt.Sprintf("%v%v-%d%v", dir, base, n, ext)
+		if _, ok := names[candidate]; !ok {
+			return candidate
+		}
+	}
+}
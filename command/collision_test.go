@@ -0,0 +1,137 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestResolveCollisions(t *testing.T) {
+	// dropped objects are reported with debug messages.
+	log.Init("error", false)
+	t.Parallel()
+
+	keys := []string{
+		"s3://bucket/b/file.txt",
+		"s3://bucket/a/file.txt",
+		"s3://bucket/a/other.txt",
+		"s3://bucket/c/file.txt",
+		"s3://bucket/file-1.txt",
+	}
+
+	tests := []struct {
+		onCollision string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			onCollision: onCollisionError,
+			wantErr:     true,
+		},
+		{
+			onCollision: onCollisionRename,
+			want: map[string]string{
+				"s3://bucket/a/file.txt":  "file.txt",
+				"s3://bucket/a/other.txt": "other.txt",
+				"s3://bucket/b/file.txt":  "file-2.txt",
+				"s3://bucket/c/file.txt":  "file-3.txt",
+				"s3://bucket/file-1.txt":  "file-1.txt",
+			},
+		},
+		{
+			onCollision: onCollisionSkip,
+			want: map[string]string{
+				"s3://bucket/a/file.txt":  "file.txt",
+				"s3://bucket/a/other.txt": "other.txt",
+				"s3://bucket/file-1.txt":  "file-1.txt",
+			},
+		},
+		{
+			onCollision: onCollisionOverwrite,
+			want: map[string]string{
+				"s3://bucket/a/other.txt": "other.txt",
+				"s3://bucket/c/file.txt":  "file.txt",
+				"s3://bucket/file-1.txt":  "file-1.txt",
+			},
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.onCollision, func(t *testing.T) {
+			t.Parallel()
+
+			var objects []pendingObject
+			for _, key := range keys {
+				u, err := url.New(key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				objects = append(objects, pendingObject{object: &storage.Object{URL: u}, isBatch: true})
+			}
+
+			dsturl, err := url.New("dir/")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := Copy{
+				flatten:     true,
+				onCollision: tc.onCollision,
+				renames:     map[string]string{},
+			}
+
+			resolved, err := c.resolveCollisions(objects, dsturl)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := map[string]string{}
+			for _, p := range resolved {
+				got[p.object.URL.String()] = c.objectName(p.object.URL, true)
+			}
+
+			if len(got) != len(tc.want) {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+			for key, name := range tc.want {
+				if got[key] != name {
+					t.Errorf("%v: got %q, want %q", key, got[key], name)
+				}
+			}
+		})
+	}
+}
+
+func TestSortBySize(t *testing.T) {
+	t.Parallel()
+
+	objects := []pendingObject{
+		{object: &storage.Object{URL: &url.URL{Path: "large"}, Size: 1 << 30}},
+		{object: &storage.Object{URL: &url.URL{Path: "small"}, Size: 10}},
+		{object: &storage.Object{URL: &url.URL{Path: "medium"}, Size: 1 << 20}},
+		{object: &storage.Object{URL: &url.URL{Path: "other-small"}, Size: 10}},
+	}
+
+	sortBySize(objects)
+
+	var got []string
+	for _, p := range objects {
+		got = append(got, p.object.URL.Path)
+	}
+
+	// objects with the same size are kept in the listing order.
+	want := []string{"small", "other-small", "medium", "large"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// gzipEncoding is the Content-Encoding of the objects compressed with gzip.
+const gzipEncoding = "gzip"
+
+// compressReader is an io.ReadCloser which compresses the content of the
+// underlying reader on the fly.
+type compressReader struct {
+	*io.PipeReader
+	done chan struct{}
+}
+
+// newCompressReader returns a reader which reads gzip compressed content of
+// r. Compressed size is not known beforehand, the returned reader is not
+// seekable.
+func newCompressReader(r io.Reader) *compressReader {
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		gw := gzip.NewWriter(pw)
+		_, err := io.Copy(gw, r)
+		if cerr := gw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+
+	return &compressReader{
+		PipeReader: pr,
+		done:       done,
+	}
+}
+
+// Close closes the reader and waits until the underlying reader is no longer
+// in use.
+func (c *compressReader) Close() error {
+	err := c.PipeReader.Close()
+	<-c.done
+	return err
+}
+
+// decompress writes gzip decompressed content of src to dst and returns the
+// number of bytes written.
+func decompress(dst io.Writer, src io.Reader) (int64, error) {
+	zr, err := gzip.NewReader(src)
+	if err != nil {
+		return 0, err
+	}
+	defer zr.Close()
+
+	return io.Copy(dst, zr)
+}
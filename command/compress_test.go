@@ -0,0 +1,49 @@
+package command
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompressDecompress(t *testing.T) {
+	t.Parallel()
+
+	content := strings.Repeat("this is a text-heavy file. ", 1024)
+
+	r := newCompressReader(strings.NewReader(content))
+	compressed, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+
+	assert.True(t, len(compressed) < len(content))
+
+	var buf bytes.Buffer
+	n, err := decompress(&buf, bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), n)
+	assert.Equal(t, content, buf.String())
+}
+
+func TestCompressReaderCloseBeforeEOF(t *testing.T) {
+	t.Parallel()
+
+	r := newCompressReader(strings.NewReader(strings.Repeat("a", 1024*1024)))
+
+	// read a small part and close the reader, as a failed upload would do.
+	// Close must not block.
+	_, err := r.Read(make([]byte, 8))
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+}
+
+func TestDecompressInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	_, err := decompress(&buf, strings.NewReader("not compressed"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,256 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+const (
+	// minConcatPartSize is the minimum size of the source objects, except
+	// the last one, since each of them is copied as a part.
+	minConcatPartSize = 5 * megabytes
+	// maxConcatPartSize is the maximum size of a part copied from another
+	// object.
+	maxConcatPartSize = 5 * 1024 * megabytes
+	// maxConcatParts is the maximum number of parts of a multipart upload.
+	maxConcatParts = 10000
+)
+
+var concatHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source destination
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Concatenate the parts of a sharded output into a single object
+		 > s5cmd {{.HelpName}} "s3://bucket/output/part-*" s3://bucket/output.csv
+
+	2. Concatenate log files into a single object with a content type
+		 > s5cmd {{.HelpName}} --content-type text/plain "s3://bucket/logs/2020/03/*.log" s3://bucket/logs/2020-03.log
+
+Objects are concatenated in the lexical order of their keys, without
+downloading them. Each source object is copied as a part of a multipart
+upload, so all source objects except the last one must be at least 5MB, and
+none of them can be larger than 5GB.
+`
+
+var concatCommand = &cli.Command{
+	Name:               "concat",
+	HelpName:           "concat",
+	Usage:              "concatenate objects into a single object on the server side",
+	CustomHelpTemplate: concatHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "content-type",
+			Usage: "set Content-Type of the concatenated object",
+		},
+		&cli.StringFlag{
+			Name:  "storage-class",
+			Usage: "set storage class of the concatenated object",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateConcatCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		return Concat{
+			src:         c.Args().Get(0),
+			dst:         c.Args().Get(1),
+			op:          c.Command.Name,
+			fullCommand: givenCommand(c),
+			// flags
+			contentType:  c.String("content-type"),
+			storageClass: c.String("storage-class"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// Concat holds concat operation flags and states.
+type Concat struct {
+	src         string
+	dst         string
+	op          string
+	fullCommand string
+
+	// flags
+	contentType  string
+	storageClass string
+
+	storageOpts storage.Options
+}
+
+// Run concatenates the source objects into the destination object.
+func (c Concat) Run(ctx context.Context) error {
+	srcurl, err := url.New(c.src)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	dsturl, err := url.New(c.dst)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	client, err := storage.NewRemoteClient(ctx, srcurl, c.storageOpts)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	objects, err := c.sourceObjects(ctx, client, srcurl, dsturl)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	sources := make([]*url.URL, 0, len(objects))
+	var size int64
+	for _, object := range objects {
+		sources = append(sources, object.URL)
+		size += object.Size
+	}
+
+	metadata := storage.NewMetadata().
+		SetContentType(c.contentType).
+		SetStorageClass(c.storageClass)
+
+	err = client.Concat(ctx, sources, dsturl, metadata)
+	if err != nil {
+		err = &errorpkg.Error{
+			Op:  c.op,
+			Src: srcurl,
+			Dst: dsturl,
+			Err: err,
+		}
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size: size,
+		},
+	}
+	log.Info(msg)
+
+	return nil
+}
+
+// sourceObjects returns the objects matching the source, sorted by their
+// keys. Sizes of the objects are validated, since they are copied as parts.
+func (c Concat) sourceObjects(
+	ctx context.Context,
+	client *storage.S3,
+	srcurl, dsturl *url.URL,
+) ([]*storage.Object, error) {
+	objch, err := expandSource(ctx, client, false, srcurl)
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []*storage.Object
+	for object := range objch {
+		if object.Type.IsDir() {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			return nil, err
+		}
+
+		if object.URL.String() == dsturl.String() {
+			return nil, fmt.Errorf("destination %v matches the source", dsturl)
+		}
+
+		objects = append(objects, object)
+	}
+
+	// objects are not in order if they are listed concurrently.
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].URL.Path < objects[j].URL.Path
+	})
+
+	if err := validateConcatParts(objects); err != nil {
+		return nil, err
+	}
+	return objects, nil
+}
+
+// validateConcatParts validates the number and the sizes of the objects to
+// be copied as the parts of a multipart upload.
+func validateConcatParts(objects []*storage.Object) error {
+	if len(objects) > maxConcatParts {
+		return fmt.Errorf("can not concatenate more than %v objects, got %v", maxConcatParts, len(objects))
+	}
+
+	for i, object := range objects {
+		if object.Size > maxConcatPartSize {
+			return fmt.Errorf("%v is larger than 5GB, the maximum size of a part", object.URL)
+		}
+
+		isLast := i == len(objects)-1
+		if !isLast && object.Size < minConcatPartSize {
+			return fmt.Errorf("%v is smaller than 5MB, only the last object can be smaller than the minimum size of a part", object.URL)
+		}
+	}
+	return nil
+}
+
+func validateConcatCommand(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return fmt.Errorf("expected source and destination arguments")
+	}
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	dst, err := url.New(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() || !dst.IsRemote() {
+		return fmt.Errorf("source and destination must be remote")
+	}
+
+	if !src.HasGlob() {
+		return fmt.Errorf("source must contain a wildcard")
+	}
+
+	if dst.IsBucket() || dst.IsPrefix() {
+		return fmt.Errorf("destination must be an object")
+	}
+
+	if dst.HasGlob() {
+		return fmt.Errorf("destination %q can not contain glob characters", dst)
+	}
+	return nil
+}
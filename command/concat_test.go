@@ -0,0 +1,68 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestValidateConcatParts(t *testing.T) {
+	t.Parallel()
+
+	newObjects := func(sizes ...int64) []*storage.Object {
+		var objects []*storage.Object
+		for i, size := range sizes {
+			u, _ := url.New(fmt.Sprintf("s3://bucket/part-%v", i))
+			objects = append(objects, &storage.Object{URL: u, Size: size})
+		}
+		return objects
+	}
+
+	testcases := []struct {
+		name    string
+		objects []*storage.Object
+
+		expectedErr string
+	}{
+		{
+			name:    "single small object",
+			objects: newObjects(1),
+		},
+		{
+			name:    "small last object",
+			objects: newObjects(minConcatPartSize, minConcatPartSize, 1),
+		},
+		{
+			name:        "small object in the middle",
+			objects:     newObjects(minConcatPartSize, minConcatPartSize-1, minConcatPartSize),
+			expectedErr: "s3://bucket/part-1 is smaller than 5MB",
+		},
+		{
+			name:        "large object",
+			objects:     newObjects(minConcatPartSize, maxConcatPartSize+1),
+			expectedErr: "s3://bucket/part-1 is larger than 5GB",
+		},
+		{
+			name:        "too many objects",
+			objects:     make([]*storage.Object, maxConcatParts+1),
+			expectedErr: "can not concatenate more than 10000 objects",
+		},
+	}
+
+	for _, tc := range testcases {
+		err := validateConcatParts(tc.objects)
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+			t.Errorf("%v: expected error %q, got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultConfigFile is the name of the config file which is read from the
+// home directory if --config is not given.
+const defaultConfigFile = ".s5cmd.yaml"
+
+// nonConfigurableFlags are the global flags which can't be given in the
+// config file.
+var nonConfigurableFlags = map[string]bool{
+	"config":             true,
+	"install-completion": true,
+}
+
+// loadConfig sets the global flags which are not given on the command line
+// from the config file. The config file is a YAML document which maps the
+// names of the global flags to their values, e.g.
+//
+//	endpoint-url: http://localhost:9000
+//	numworkers: 64
+//
+// The default config file is read only if it exists, whereas the one given
+// with --config must exist.
+func loadConfig(c *cli.Context) error {
+	path := c.String("config")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, defaultConfigFile)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read config file: %v", err)
+	}
+
+	var values map[string]interface{}
+	if err := yaml.UnmarshalStrict(data, &values); err != nil {
+		return fmt.Errorf("invalid config file %q: %v", path, err)
+	}
+
+	configurable := map[string]bool{}
+	for _, flag := range c.App.Flags {
+		name := flag.Names()[0]
+		if !nonConfigurableFlags[name] {
+			configurable[name] = true
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !configurable[key] {
+			return fmt.Errorf("invalid config file %q: unknown key %q", path, key)
+		}
+
+		// flags given on the command line take precedence over the config
+		// file.
+		if c.IsSet(key) {
+			continue
+		}
+
+		value := values[key]
+		switch value.(type) {
+		case bool, int, float64, string:
+		default:
+			return fmt.Errorf("invalid config file %q: value of %q must be a string, number or boolean", path, key)
+		}
+
+		if err := c.Set(key, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid config file %q: invalid value %q for %q", path, fmt.Sprint(value), key)
+		}
+	}
+	return nil
+}
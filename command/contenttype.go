@@ -0,0 +1,58 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// contentTypeMap maps the extensions of the uploaded files to their content
+// types. It's consulted before the system MIME types, e.g. for the extensions
+// which are not known to the system, such as ".wasm" or ".webmanifest".
+type contentTypeMap map[string]string
+
+// contentTypeMapFromFlags reads the --content-type-map file, which is a JSON
+// object of extensions to content types, e.g. {".wasm": "application/wasm"}.
+// Extensions are matched case-insensitively, with or without the leading dot.
+func contentTypeMapFromFlags(c *cli.Context) (contentTypeMap, error) {
+	path := c.String("content-type-map")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --content-type-map %q: %v", path, err)
+	}
+
+	var fromFile map[string]string
+	if err := json.Unmarshal(data, &fromFile); err != nil {
+		return nil, fmt.Errorf("invalid --content-type-map %q: must be a JSON object of string values: %v", path, err)
+	}
+
+	m := contentTypeMap{}
+	for ext, contentType := range fromFile {
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if ext == "" || strings.ContainsAny(ext, `./\`) {
+			return nil, fmt.Errorf("invalid --content-type-map %q: %q is not a file extension", path, ext)
+		}
+
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || !strings.Contains(mediaType, "/") {
+			return nil, fmt.Errorf("invalid --content-type-map %q: %q is not a valid content type for %q", path, contentType, ext)
+		}
+		m["."+ext] = contentType
+	}
+	return m, nil
+}
+
+// lookup returns the content type of the file of given name, or an empty
+// string if its extension is not in the map.
+func (m contentTypeMap) lookup(name string) string {
+	return m[strings.ToLower(filepath.Ext(name))]
+}
@@ -0,0 +1,107 @@
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func TestContentTypeMapFromFlags(t *testing.T) {
+	t.Parallel()
+
+	writeFile := func(content string) string {
+		f, err := ioutil.TempFile("", "s5cmd-types-")
+		assert.NoError(t, err)
+		_, err = f.WriteString(content)
+		assert.NoError(t, err)
+		f.Close()
+		return f.Name()
+	}
+
+	valid := writeFile(`{".wasm": "application/wasm", "WebManifest": "application/manifest+json; charset=utf-8"}`)
+	defer os.Remove(valid)
+
+	nonString := writeFile(`{".wasm": 42}`)
+	defer os.Remove(nonString)
+
+	invalidType := writeFile(`{".wasm": "wasm"}`)
+	defer os.Remove(invalidType)
+
+	invalidExtension := writeFile(`{"tar.gz": "application/gzip"}`)
+	defer os.Remove(invalidExtension)
+
+	testcases := []struct {
+		name string
+		file string
+
+		expected contentTypeMap
+		wantErr  bool
+	}{
+		{
+			name: "not given",
+		},
+		{
+			name: "valid",
+			file: valid,
+			expected: contentTypeMap{
+				".wasm":        "application/wasm",
+				".webmanifest": "application/manifest+json; charset=utf-8",
+			},
+		},
+		{
+			name:    "missing file",
+			file:    valid + "-missing",
+			wantErr: true,
+		},
+		{
+			name:    "non-string value",
+			file:    nonString,
+			wantErr: true,
+		},
+		{
+			name:    "invalid content type",
+			file:    invalidType,
+			wantErr: true,
+		},
+		{
+			name:    "extension with dot",
+			file:    invalidExtension,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		// subtests are not parallel, the files are removed once the test
+		// returns.
+		t.Run(tc.name, func(t *testing.T) {
+			set := flag.NewFlagSet("cp", 0)
+			set.String("content-type-map", tc.file, "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			got, err := contentTypeMapFromFlags(ctx)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestContentTypeMapLookup(t *testing.T) {
+	t.Parallel()
+
+	m := contentTypeMap{".wasm": "application/wasm"}
+
+	assert.Equal(t, "application/wasm", m.lookup("site/app.wasm"))
+	assert.Equal(t, "application/wasm", m.lookup("site/APP.WASM"))
+	assert.Equal(t, "", m.lookup("site/index.html"), "unknown extensions fall back to detection")
+	assert.Equal(t, "", contentTypeMap(nil).lookup("site/app.wasm"))
+}
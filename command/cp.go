@@ -2,14 +2,20 @@ package command
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"mime"
 	"net/http"
+	urlpkg "net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/urfave/cli/v2"
@@ -23,16 +29,49 @@ import (
 )
 
 const (
+	// stdinSource is the source argument to read the data from the standard
+	// input.
+	stdinSource = "-"
+
+	// stdoutTarget is the destination argument to write the object to the
+	// standard output.
+	stdoutTarget = "-"
+
+	// bucketOwnerFullControl is the canned ACL which gives both the object
+	// owner and the bucket owner full control over the object.
+	bucketOwnerFullControl = "bucket-owner-full-control"
+
+	// storageClassStandard is the default storage class of S3 objects.
+	storageClassStandard = storage.StorageClass("STANDARD")
+
 	defaultCopyConcurrency = 5
 	defaultPartSize        = 50 // MiB
 	megabytes              = 1024 * 1024
+
+	// moveConcurrency and movePartSize are the defaults of the S3 transfer
+	// manager, which mv uses instead of --concurrency and --part-size.
+	moveConcurrency = 5
+	movePartSize    = 5 // MiB
+
+	// maxSinglePartSize is the maximum size of the objects uploaded in a
+	// single part, in MiB.
+	maxSinglePartSize = 5 * 1024
+
+	// minPartSize and maxPartSize are the limits of the part sizes of
+	// multipart uploads, in MiB. S3 rejects smaller parts, except the last
+	// one, and larger parts.
+	minPartSize = 5
+	maxPartSize = 5 * 1024
+
+	// maxUploadParts is the maximum number of parts of a multipart upload.
+	maxUploadParts = 10000
 )
 
 var copyHelpTemplate = `Name:
 	{{.HelpName}} - {{.Usage}}
 
 Usage:
-	{{.HelpName}} [options] source destination
+	{{.HelpName}} [options] source [source] destination
 
 Options:
 	{{range .VisibleFlags}}{{.}}
@@ -73,6 +112,165 @@ Examples:
 
 	12. Perform KMS-SSE of the object(s) at the destination using customer managed Customer Master Key (CMK) key id
 		> s5cmd {{.HelpName}} -sse aws:kms -sse-kms-key-id <your-kms-key-id> s3://bucket/object s3://target-bucket/prefix/object
+
+	13. Upload data read from standard input to an S3 object
+		> somecmd | s5cmd {{.HelpName}} - s3://bucket/object
+
+	14. Upload a file to another account's S3 bucket and give the bucket owner full control over the object
+		> s5cmd {{.HelpName}} --bucket-owner-full-control myfile.gz s3://bucket/
+
+	15. Upload a file to S3 bucket compressed with gzip
+		> s5cmd {{.HelpName}} --compress myfile.csv s3://bucket/
+
+	16. Download gzip compressed S3 objects and decompress them
+		> s5cmd {{.HelpName}} --decompress s3://bucket/*.csv target-directory/
+
+	17. Report the number and the total size of the objects to be downloaded, without downloading them
+		> s5cmd {{.HelpName}} --estimate s3://bucket/* target-directory/
+
+	18. Upload a directory to S3 bucket, replacing the leading "raw/" of the keys with "processed/"
+		> s5cmd {{.HelpName}} --transform 's/^raw/processed/' dir/ s3://bucket/
+
+	19. Download all S3 objects under a prefix, skipping the ones in subdirectories
+		> s5cmd {{.HelpName}} --max-depth 0 s3://bucket/prefix/* target-directory/
+
+	20. Download all S3 objects under a prefix, recording the transferred and failed objects
+		> s5cmd {{.HelpName}} --manifest done.csv --error-manifest failed.csv s3://bucket/prefix/* target-directory/
+
+	21. Resume an interrupted download, skipping the objects recorded in the manifest and appending new ones to it
+		> s5cmd {{.HelpName}} --resume-from done.csv s3://bucket/prefix/* target-directory/
+
+	22. Copy S3 objects to another bucket, preserving their storage classes
+		> s5cmd {{.HelpName}} --keep-storage-class s3://bucket/prefix/* s3://target-bucket/prefix/
+
+	23. Download an S3 object only if it's not changed since it's last seen
+		> s5cmd {{.HelpName}} --if-match 5d41402abc4b2a76b9719d911017c592 s3://bucket/object.gz .
+
+	24. Upload a directory to S3 bucket, appending the current date to the names of the files
+		> s5cmd {{.HelpName}} --key-template '{dir}{name}-{date}{ext}' dir/ s3://bucket/
+
+	25. Download the parquet files of daily partitions, skipping the temporary ones
+		> s5cmd {{.HelpName}} --include-regex '^\d{4}/\d{2}/data-.*\.parquet$' --exclude-regex '\.tmp' s3://bucket/* target-directory/
+
+	26. Upload a directory to S3 bucket, storing the files larger than 1 GB in STANDARD_IA and the smaller ones in STANDARD
+		> s5cmd {{.HelpName}} --class-rule '>1GB:STANDARD_IA' dir/ s3://bucket/
+
+	27. Copy S3 objects to another bucket, overwriting the existing ones regardless of the other override flags
+		> s5cmd {{.HelpName}} -n -u --force s3://bucket/prefix/* s3://target-bucket/prefix/
+
+	28. Download S3 objects without their directories, appending a number to the names of the files with the same name
+		> s5cmd {{.HelpName}} --flatten --on-collision rename s3://bucket/prefix/* target-directory/
+
+	29. Upload a file to S3 bucket with public read access and print its URL
+		> s5cmd {{.HelpName}} --acl public-read --print-url image.png s3://bucket/
+
+	30. Upload a file to a bucket with object lock enabled, retaining it in compliance mode until the given date
+		> s5cmd {{.HelpName}} --object-lock-mode COMPLIANCE --object-lock-retain-until 2030-01-01 report.pdf s3://bucket/
+
+	31. Copy S3 objects to another bucket along with the grants of the objects
+		> s5cmd {{.HelpName}} --copy-acl s3://bucket/prefix/* s3://target-bucket/prefix/
+
+	32. Download objects under multiple prefixes to a directory
+		> s5cmd {{.HelpName}} s3://bucket/logs/* s3://bucket/backups/* target-directory/
+
+	33. Download only the S3 objects which don't exist in the target directory, e.g. to resume an interrupted download
+		> s5cmd {{.HelpName}} --only-missing s3://bucket/prefix/* target-directory/
+
+	34. Upload files to S3 bucket with STANDARD_IA storage class, except the ones smaller than 128KB
+		> s5cmd {{.HelpName}} --storage-class STANDARD_IA --min-ia-size 128KB dir/ s3://bucket/
+
+	35. Upload the files changed since the last run, marked by the modification time of a local file
+		> s5cmd {{.HelpName}} --newer-than-file .lastrun dir/ s3://bucket/
+
+	36. Upload files to S3 bucket only if they don't exist, failing if any of them exists
+		> s5cmd {{.HelpName}} -n --fail-on-skip dir/ s3://bucket/
+
+	37. Upload files to an S3 compatible service which doesn't support multipart uploads well
+		> s5cmd {{.HelpName}} --disable-multipart dir/ s3://bucket/
+
+	38. Upload a file with user-defined metadata loaded from a JSON file, overriding one of them
+		> s5cmd {{.HelpName}} --metadata-from-file meta.json --meta owner=data-team report.pdf s3://bucket/
+
+	39. Download S3 objects along with the empty directories, which are zero-byte objects whose keys end with '/'
+		> s5cmd {{.HelpName}} --create-empty-dirs s3://bucket/prefix/* target-directory/
+
+	40. Copy S3 objects with a new content type, keeping their cache control and user-defined metadata
+		> s5cmd {{.HelpName}} --metadata-directive REPLACE --content-type text/plain --preserve cache-control,metadata s3://bucket/prefix/* s3://target-bucket/prefix/
+
+	41. Download S3 objects, skipping the ones which are larger than 5GB
+		> s5cmd {{.HelpName}} --max-object-size 5GB s3://bucket/prefix/* target-directory/
+
+	42. Restore a previous version of an S3 object in a versioned bucket as its current version
+		> s5cmd {{.HelpName}} --source-version-id 3HL4kqtJlcpXroDTDmJ.rmSpXd3dIbrHY s3://bucket/object s3://bucket/object
+
+	43. Upload files in a directory, starting with the smallest ones so that they are not queued behind the large ones
+		> s5cmd {{.HelpName}} --prioritize-small dir/ s3://bucket/
+
+	44. Upload a large file, resuming its multipart upload if an earlier run was interrupted
+		> s5cmd {{.HelpName}} --resume-uploads large.iso s3://bucket/
+
+	45. Download S3 objects without overwriting the local files which are edited after the objects are uploaded
+		> s5cmd {{.HelpName}} --no-overwrite-newer s3://bucket/prefix/* target-directory/
+
+	46. Download S3 objects whose keys have no extension, naming the files by the content types of the objects, e.g. 'report.pdf'
+		> s5cmd {{.HelpName}} --add-extension s3://bucket/reports/* target-directory/
+
+	47. Upload a file, granting read access to an account and full control to another one
+		> s5cmd {{.HelpName}} --grant-read id=79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be --grant-full-control emailAddress=owner@example.com myfile.gz s3://bucket/
+
+	48. Copy objects from DigitalOcean Spaces to AWS S3, streaming them through s5cmd
+		> s5cmd {{.HelpName}} --source-endpoint https://nyc3.digitaloceanspaces.com --source-profile spaces 's3://bucket/*' s3://target-bucket/
+
+	49. Upload a static site, setting the content types of the extensions which are not known to the system from a file
+		> s5cmd {{.HelpName}} --content-type-map types.json 'site/*' s3://bucket/
+
+	50. Copy the objects listed in an S3 Inventory report of the bucket, instead of listing the bucket
+		> s5cmd {{.HelpName}} --from-inventory s3://bucket/inventory/manifest.json 's3://bucket/logs/*' s3://target/logs/
+
+	51. Download objects and run a command for each downloaded file
+		> s5cmd {{.HelpName}} --on-success 'ingest --size {size} {path}' 's3://bucket/logs/*' logs/
+
+	52. Resume an interrupted download, downloading the empty files left by it again
+		> s5cmd {{.HelpName}} --only-missing --replace-empty 's3://bucket/logs/*' logs/
+
+	53. Download an S3 object to the given path, failing if it's an existing directory instead of downloading into it
+		> s5cmd {{.HelpName}} --trailing-slash-as-dir s3://bucket/prefix/object.gz backup.gz
+
+	54. Download S3 objects only if they fit in the disk, keeping 10GB of it available
+		> s5cmd {{.HelpName}} --check-space --space-margin 10GB s3://bucket/prefix/* target-directory/
+
+	55. Download S3 objects, downloading the corrupted ones again up to 3 times
+		> s5cmd {{.HelpName}} --retry-on-checksum-mismatch 3 s3://bucket/prefix/* target-directory/
+
+	56. Upload the local files listed in a file, e.g. the output of find
+		> s5cmd {{.HelpName}} --source-file-list paths.txt s3://bucket/prefix/
+
+	57. Download S3 objects only if they are newer than the local files by more than a second
+		> s5cmd {{.HelpName}} -u --modify-window 1s s3://bucket/prefix/* target-directory/
+
+	58. Upload only the files whose keys don't exist in the bucket, listing the bucket once instead of checking each key
+		> s5cmd {{.HelpName}} --only-new-keys dir/ s3://bucket/prefix/
+
+	59. Upload large files in parts of at least 1 GiB to make fewer requests, keeping the part size of downloads
+		> s5cmd {{.HelpName}} --min-part-size 1024 --storage-class DEEP_ARCHIVE large-file.tar s3://bucket/
+
+	60. Download an S3 object to standard output
+		> s5cmd {{.HelpName}} s3://bucket/prefix/object.gz - | gunzip
+
+	61. Download an S3 object before the waiting transfers of the other commands, e.g. in a file given to run
+		> s5cmd {{.HelpName}} --priority high s3://bucket/urgent.txt .
+
+	62. Upload a file as a redirect to another page of a static website bucket
+		> s5cmd {{.HelpName}} --website-redirect /new-page.html old-page.html s3://bucket/
+
+	63. Download S3 objects to temporary files which are renamed once they are complete, so that partial files are never seen
+		> s5cmd {{.HelpName}} --atomic s3://bucket/prefix/* target-directory/
+
+	64. Upload a directory to S3 bucket, skipping the files which match the regular expressions in a file
+		> s5cmd {{.HelpName}} --exclude-from backup-excludes.txt dir/ s3://bucket/backup/
+
+	65. Download S3 objects, skipping the folder placeholders, which are zero-byte objects whose keys end with '/'
+		> s5cmd {{.HelpName}} --skip-placeholders s3://bucket/prefix/* target-directory/
 `
 
 var copyCommandFlags = []cli.Flag{
@@ -91,6 +289,49 @@ var copyCommandFlags = []cli.Flag{
 		Aliases: []string{"u"},
 		Usage:   "only overwrite destination if source modtime is newer",
 	},
+	&cli.BoolFlag{
+		Name:  "no-overwrite-newer",
+		Usage: "do not overwrite the local files which are newer than the downloaded objects, to keep local edits",
+	},
+	modifyWindowFlag,
+	&cli.BoolFlag{
+		Name:  "fail-on-skip",
+		Usage: "fail if the destination is not overwritten because of --no-clobber, --if-size-differ, --if-source-newer or --no-overwrite-newer",
+	},
+	&cli.BoolFlag{
+		Name:  "only-missing",
+		Usage: "only download the objects which don't exist locally, regardless of their size or modification time",
+	},
+	&cli.BoolFlag{
+		Name:  "only-new-keys",
+		Usage: "only upload the files whose keys don't exist at the destination, by listing the destination once instead of checking each key; with --if-size-differ, the files whose sizes differ are uploaded too",
+	},
+	&cli.BoolFlag{
+		Name:    "replace-empty",
+		Aliases: []string{"overwrite-if-size-zero"},
+		Usage:   "treat the existing empty local files as missing and download them again, e.g. the ones left by an interrupted download",
+	},
+	&cli.BoolFlag{
+		Name:  "trailing-slash-as-dir",
+		Usage: "treat the local target as a directory only if it ends with a slash, the same as the remote targets, otherwise as the path of the file even if it's an existing directory",
+	},
+	&cli.BoolFlag{
+		Name:  "add-extension",
+		Usage: "append the extension of the content type of the objects to the names of the downloaded files which have no extension",
+	},
+	&cli.BoolFlag{
+		Name:  "create-empty-dirs",
+		Usage: "create local directories for the empty directory placeholders, i.e. zero-byte objects whose keys end with '/', on download",
+	},
+	&cli.BoolFlag{
+		Name:  "skip-placeholders",
+		Usage: "skip the folder placeholders, i.e. zero-byte objects whose keys end with '/', on download",
+	},
+	&cli.BoolFlag{
+		Name:    "force",
+		Aliases: []string{"overwrite"},
+		Usage:   "always overwrite destination, takes precedence over --no-clobber, --if-size-differ, --if-source-newer and --no-overwrite-newer",
+	},
 	&cli.BoolFlag{
 		Name:    "flatten",
 		Aliases: []string{"f"},
@@ -104,17 +345,46 @@ var copyCommandFlags = []cli.Flag{
 		Name:  "storage-class",
 		Usage: "set storage class for target ('STANDARD','REDUCED_REDUNDANCY','GLACIER','STANDARD_IA','ONEZONE_IA','INTELLIGENT_TIERING','DEEP_ARCHIVE')",
 	},
+	&cli.StringSliceFlag{
+		Name:  "class-rule",
+		Usage: "set storage class of the uploaded files by their sizes, e.g. '>1GB:STANDARD_IA', can be given multiple times and the first matching rule is used",
+	},
+	&cli.StringFlag{
+		Name:  "min-ia-size",
+		Usage: "upload the files smaller than given size, e.g. 128KB, with STANDARD storage class instead of an infrequent access class, which bills them as if they were larger",
+	},
+	&cli.StringFlag{
+		Name:  "max-object-size",
+		Usage: "skip the objects larger than given size, e.g. 5GB",
+	},
+	&cli.BoolFlag{
+		Name:  "keep-storage-class",
+		Usage: "preserve storage class of the source objects on S3 to S3 copy",
+	},
 	&cli.IntFlag{
 		Name:    "concurrency",
-		Aliases: []string{"c"},
+		Aliases: []string{"c", "threads-per-file"},
 		Value:   defaultCopyConcurrency,
-		Usage:   "number of concurrent parts transferred between host and remote server",
+		Usage:   "number of concurrent parts transferred between host and remote server for each file, unlike --numworkers which is the number of files transferred at once",
 	},
 	&cli.IntFlag{
 		Name:    "part-size",
 		Aliases: []string{"p"},
 		Value:   defaultPartSize,
-		Usage:   "size of each part transferred between host and remote server, in MiB",
+		Usage:   "size of each part transferred between host and remote server, in MiB, between 5 and 5120",
+	},
+	priorityFlag,
+	&cli.IntFlag{
+		Name:  "min-part-size",
+		Usage: "minimum size of the parts of multipart uploads, in MiB, between 5 and 5120, e.g. to upload large files in fewer parts without changing the part size of downloads",
+	},
+	&cli.BoolFlag{
+		Name:  "disable-multipart",
+		Usage: "upload files in a single part regardless of their size, for the services which don't support multipart uploads well",
+	},
+	&cli.IntFlag{
+		Name:  "multipart-threshold",
+		Usage: "upload the files larger than given size in multiple parts and the others in a single part, in MiB",
 	},
 	&cli.StringFlag{
 		Name:  "sse",
@@ -128,6 +398,230 @@ var copyCommandFlags = []cli.Flag{
 		Name:  "acl",
 		Usage: "set acl for target: defines granted accesses and their types on different accounts/groups",
 	},
+	&cli.BoolFlag{
+		Name:  "bucket-owner-full-control",
+		Usage: "give the bucket owner full control over the target, shortcut for '--acl bucket-owner-full-control'",
+	},
+	&cli.BoolFlag{
+		Name:  "acl-public",
+		Usage: "make the target publicly readable, shortcut for '--acl public-read'",
+	},
+	&cli.StringSliceFlag{
+		Name:  "grant-read",
+		Usage: "grant read access to given grantees, e.g. id=<canonical-user-id>, emailAddress=<email> or uri=<group-uri>",
+	},
+	&cli.StringSliceFlag{
+		Name:  "grant-read-acp",
+		Usage: "grant permission to read the acl of the target to given grantees",
+	},
+	&cli.StringSliceFlag{
+		Name:  "grant-write-acp",
+		Usage: "grant permission to write the acl of the target to given grantees",
+	},
+	&cli.StringSliceFlag{
+		Name:  "grant-full-control",
+		Usage: "grant read, read-acp and write-acp permissions on the target to given grantees",
+	},
+	&cli.StringFlag{
+		Name:  "object-lock-mode",
+		Usage: "set object lock retention mode of the uploaded objects: (GOVERNANCE, COMPLIANCE)",
+	},
+	&cli.StringFlag{
+		Name:  "object-lock-retain-until",
+		Usage: "set the date until the uploaded objects are locked, e.g. 2030-01-01 or 2030-01-01T00:00:00Z",
+	},
+	&cli.StringFlag{
+		Name:  "legal-hold",
+		Usage: "set legal hold status of the uploaded objects: (on, off)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "meta",
+		Usage: "set user-defined metadata of the uploaded objects, e.g. 'key=value', can be given multiple times and overrides the ones in --metadata-from-file",
+	},
+	&cli.StringFlag{
+		Name:  "metadata-from-file",
+		Usage: "set user-defined metadata of the uploaded objects from a JSON file of string key/value pairs",
+	},
+	&cli.StringFlag{
+		Name:  "content-type-map",
+		Usage: "set content type of the uploaded files by their extensions from a JSON file, e.g. {\".wasm\": \"application/wasm\"}",
+	},
+	&cli.StringFlag{
+		Name:  "metadata-directive",
+		Usage: "copy the metadata of the S3 objects from the source, or replace it: (COPY, REPLACE)",
+	},
+	&cli.StringSliceFlag{
+		Name:  "preserve",
+		Usage: "headers of the source objects to keep when the metadata is replaced, e.g. 'cache-control,content-encoding': (" + strings.Join(preservableHeaders, ", ") + ")",
+	},
+	&cli.StringFlag{
+		Name:  "content-type",
+		Usage: "set Content-Type of the S3 objects copied with '--metadata-directive REPLACE'",
+	},
+	&cli.StringFlag{
+		Name:  "cache-control",
+		Usage: "set Cache-Control of the S3 objects copied with '--metadata-directive REPLACE'",
+	},
+	&cli.StringFlag{
+		Name:  "website-redirect",
+		Usage: "redirect the requests of the uploaded objects to given path or URL, if the bucket is configured as a website, e.g. '/new-page.html'",
+	},
+	&cli.StringFlag{
+		Name:  "source-version-id",
+		Usage: "copy the given version of the source S3 object instead of its current version, e.g. to restore it",
+	},
+	&cli.BoolFlag{
+		Name:    "copy-acl",
+		Aliases: []string{"preserve-acl"},
+		Usage:   "copy access control lists of the source objects, including the explicit grants, to the target objects, requires two extra requests per object",
+	},
+	&cli.BoolFlag{
+		Name:  "print-url",
+		Usage: "print the URL of the uploaded objects, e.g. to share the ones uploaded with '--acl public-read'",
+	},
+	&cli.BoolFlag{
+		Name:  "content-md5",
+		Usage: "send MD5 digest of the file with single-part uploads to let S3 reject corrupted objects",
+	},
+	&cli.BoolFlag{
+		Name:  "compress",
+		Usage: "compress the content with gzip on upload and set Content-Encoding of the object to gzip",
+	},
+	&cli.BoolFlag{
+		Name:  "resume-uploads",
+		Usage: "resume the interrupted multipart uploads of the files, uploading only the parts which are missing or differ",
+	},
+	&cli.BoolFlag{
+		Name:  "decompress",
+		Usage: "decompress the objects with gzip Content-Encoding on download",
+	},
+	&cli.BoolFlag{
+		Name:  "estimate",
+		Usage: "only list the source objects and report how many objects and bytes would be affected",
+	},
+	&cli.StringFlag{
+		Name:  "transform",
+		Usage: "rename destination keys using a sed-like expression, e.g. 's/^raw/processed/'",
+	},
+	&cli.StringFlag{
+		Name:  "key-template",
+		Usage: "generate keys of the uploaded files from a template with {dir}, {name}, {ext}, {basename}, {date} and {date:layout} tokens",
+	},
+	&cli.StringFlag{
+		Name:  "on-collision",
+		Value: onCollisionError,
+		Usage: "what to do if objects would be downloaded to the same file with --flatten or --transform: (error, rename, skip, overwrite)",
+	},
+	&cli.StringFlag{
+		Name:  "include-regex",
+		Usage: "only copy the source objects whose keys relative to the source match given regular expression",
+	},
+	&cli.StringFlag{
+		Name:  "exclude-regex",
+		Usage: "skip the source objects whose keys relative to the source match given regular expression",
+	},
+	&cli.StringFlag{
+		Name:  "include-from",
+		Usage: "only copy the source objects whose keys relative to the source match any of the regular expressions in given file, one per line",
+	},
+	&cli.StringFlag{
+		Name:  "exclude-from",
+		Usage: "skip the source objects whose keys relative to the source match any of the regular expressions in given file, one per line",
+	},
+	&cli.BoolFlag{
+		Name:  "prioritize-small",
+		Usage: "transfer the smaller objects first, objects are listed before transferring any of them which requires more memory",
+	},
+	&cli.BoolFlag{
+		Name:  "check-space",
+		Usage: "fail before downloading if the objects don't fit in the available space of the target filesystem, objects are listed before transferring any of them which requires more memory",
+	},
+	&cli.StringFlag{
+		Name:  "space-margin",
+		Usage: "keep given size, e.g. 1GB, available on the target filesystem after the download, only for --check-space",
+	},
+	&cli.IntFlag{
+		Name:  "max-depth",
+		Usage: "skip the source objects which are more than given number of directory levels deep",
+	},
+	&cli.StringFlag{
+		Name:  "newer-than-file",
+		Usage: "only copy the source objects modified after given local file",
+	},
+	&cli.StringFlag{
+		Name:  "older-than-file",
+		Usage: "only copy the source objects modified before given local file",
+	},
+	&cli.StringFlag{
+		Name:  "manifest",
+		Usage: "append a CSV row with source, destination, size, etag and timestamp to given file for each transferred object",
+	},
+	&cli.StringFlag{
+		Name:  "if-match",
+		Usage: "only copy the source object if its ETag matches the given one",
+	},
+	&cli.StringFlag{
+		Name:  "if-none-match",
+		Usage: "only copy the source object if its ETag doesn't match the given one",
+	},
+	&cli.StringFlag{
+		Name:  "resume-from",
+		Usage: "skip the source objects recorded in given manifest file, and append the transferred ones to it unless --manifest is given",
+	},
+	&cli.IntFlag{
+		Name:  "retry-on-checksum-mismatch",
+		Usage: "verify size and checksum of the downloaded files, and download them again up to given times if they don't match the objects",
+	},
+	&cli.BoolFlag{
+		Name:  "atomic",
+		Usage: "download to a temporary file next to the destination and rename it once the download is complete, so that partial files are never seen",
+	},
+	&cli.BoolFlag{
+		Name:  "verify-before-delete",
+		Usage: "compare size and checksum of the destination with the source before deleting the source, only for mv",
+	},
+	&cli.BoolFlag{
+		Name:  "remove-empty-dirs",
+		Usage: "remove the source directories which are emptied by moving the files in them, only for mv",
+	},
+	&cli.StringFlag{
+		Name:  "error-manifest",
+		Usage: "append a CSV row with source, destination, error and timestamp to given file for each failed object",
+	},
+	&cli.StringFlag{
+		Name:  "source-endpoint",
+		Usage: "override the S3 host of the source objects, e.g. to copy objects between different S3 compatible services",
+	},
+	&cli.StringFlag{
+		Name:  "source-region",
+		Usage: "set the region of the source objects",
+	},
+	&cli.StringFlag{
+		Name:  "source-profile",
+		Usage: "use the credentials of given AWS profile to access the source objects",
+	},
+	&cli.StringFlag{
+		Name:  "dest-endpoint",
+		Usage: "override the S3 host of the target objects, e.g. to copy objects between different S3 compatible services",
+	},
+	&cli.StringFlag{
+		Name:  "dest-region",
+		Usage: "set the region of the target objects",
+	},
+	&cli.StringFlag{
+		Name:  "dest-profile",
+		Usage: "use the credentials of given AWS profile to access the target objects",
+	},
+	fromInventoryFlag,
+	sourceFileListFlag,
+	&cli.StringFlag{
+		Name:  "on-success",
+		Usage: "run given command after each object is transferred, with {src}, {dst}, {key}, {path} and {size} placeholders, e.g. 'ingest {path}'",
+	},
+	&cli.StringFlag{
+		Name:  "on-error",
+		Usage: "run given command after each object fails to be transferred, with {src}, {dst}, {key}, {path} and {error} placeholders",
+	},
 }
 
 var copyCommand = &cli.Command{
@@ -146,33 +640,18 @@ var copyCommand = &cli.Command{
 	Action: func(c *cli.Context) (err error) {
 		defer stat.Collect(c.Command.FullName(), &err)()
 
-		return Copy{
-			src:          c.Args().Get(0),
-			dst:          c.Args().Get(1),
-			op:           c.Command.Name,
-			fullCommand:  givenCommand(c),
-			deleteSource: false, // don't delete source
-			// flags
-			noClobber:        c.Bool("no-clobber"),
-			ifSizeDiffer:     c.Bool("if-size-differ"),
-			ifSourceNewer:    c.Bool("if-source-newer"),
-			flatten:          c.Bool("flatten"),
-			followSymlinks:   !c.Bool("no-follow-symlinks"),
-			storageClass:     storage.StorageClass(c.String("storage-class")),
-			concurrency:      c.Int("concurrency"),
-			partSize:         c.Int64("part-size") * megabytes,
-			encryptionMethod: c.String("sse"),
-			encryptionKeyID:  c.String("sse-kms-key-id"),
-			acl:              c.String("acl"),
-
-			storageOpts: NewStorageOpts(c),
-		}.Run(c.Context)
+		copyCommand, err := NewCopy(c, false) // don't delete source
+		if err != nil {
+			return err
+		}
+
+		return copyCommand.Run(c.Context)
 	},
 }
 
 // Copy holds copy operation flags and states.
 type Copy struct {
-	src         string
+	src         []string
 	dst         string
 	op          string
 	fullCommand string
@@ -180,511 +659,2500 @@ type Copy struct {
 	deleteSource bool
 
 	// flags
+	verify           bool
+	checksumRetries  int
+	atomic           bool
 	noClobber        bool
 	ifSizeDiffer     bool
 	ifSourceNewer    bool
+	noOverwriteNewer bool
+	modifyWindow     time.Duration
+	addExtension     bool
+	failOnSkip       bool
+	onlyMissing      bool
+	onlyNewKeys      bool
+	replaceEmpty     bool
+	createEmptyDirs  bool
+	skipPlaceholders bool
+	force            bool
 	flatten          bool
 	followSymlinks   bool
 	storageClass     storage.StorageClass
+	keepStorageClass bool
+	classRules       storageClassRules
+	minIASize        int64
+	maxObjectSize    int64
 	encryptionMethod string
 	encryptionKeyID  string
 	acl              string
+	objectLock       objectLock
+	userMetadata     userMetadata
+	contentTypes     contentTypeMap
+	grants           grants
+	directive        metadataDirective
+	sourceVersionID  string
+	websiteRedirect  string
+	prioritizeSmall  bool
+	checkSpace       bool
+	spaceMargin      int64
+	printURL         bool
+	copyACL          bool
+	contentMD5       bool
+	compress         bool
+	resumeUploads    bool
+	decompress       bool
+	estimate         bool
+	transform        *transform
+	keyTemplate      *keyTemplate
+	filter           *keyFilter
+	modTimeFilter    *modTimeFilter
+	maxDepth         int
+	ifMatch          string
+	ifNoneMatch      string
+
+	manifestPath      string
+	errorManifestPath string
+	resumeFrom        string
+	manifest          *manifest
+	errorManifest     *manifest
+	hooks             *hooks
+
+	// onCollision is the strategy to resolve the objects which would be
+	// downloaded to the same file. renames are the names of the objects
+	// renamed to resolve collisions, by their source URLs.
+	onCollision string
+	renames     map[string]string
+
+	// remoteKeys are the existing objects under the destination by their
+	// keys, which are listed once if --only-new-keys is given.
+	remoteKeys map[string]*storage.Object
+
+	// aclLimiter bounds the number of the access control lists copied at
+	// once with --copy-acl by --concurrency. Copying an ACL takes two
+	// requests in addition to the copy of the object, they are bounded as
+	// the parts of a file are, to avoid throttling.
+	aclLimiter chan struct{}
+
+	// removeEmptyDirs is set if the source directories which are emptied by
+	// the move are removed. emptyDirs are the directories of the moved files.
+	removeEmptyDirs bool
+	emptyDirs       *emptyDirs
 
 	// s3 options
-	concurrency int
-	partSize    int64
+	concurrency        int
+	partSize           int64
+	partSizeFloor      int64
+	priority           parallel.Priority
+	disableMultipart   bool
+	multipartThreshold int64
+
+	// srcEndpoint and dstEndpoint override the global storage options of the
+	// source and the destination clients.
+	srcEndpoint endpoint
+	dstEndpoint endpoint
+
+	// inventory is the manifest of the S3 Inventory report which the source
+	// objects are read from, instead of listing the source bucket.
+	inventory *url.URL
+
+	// fileList is the list of the local files given with --source-file-list,
+	// which are uploaded instead of walking the source directory.
+	fileList *fileList
+
 	storageOpts storage.Options
 }
 
-const fdlimitWarning = `
-WARNING: s5cmd is hitting the max open file limit allowed by your OS. Either
-increase the open file limit or try to decrease the number of workers with
-'-numworkers' parameter.
-`
+// NewCopy creates a Copy from the given cli context. It is shared by the cp
+// and mv commands.
+func NewCopy(c *cli.Context, deleteSource bool) (Copy, error) {
+	var tr *transform
+	if expr := c.String("transform"); expr != "" {
+		var err error
+		tr, err = parseTransform(expr)
+		if err != nil {
+			return Copy{}, err
+		}
+	}
 
-// Run starts copying given source objects to destination.
-func (c Copy) Run(ctx context.Context) error {
-	srcurl, err := url.New(c.src)
+	var kt *keyTemplate
+	if tmpl := c.String("key-template"); tmpl != "" {
+		var err error
+		kt, err = parseKeyTemplate(tmpl)
+		if err != nil {
+			return Copy{}, err
+		}
+	}
+
+	classRules, err := parseStorageClassRules(c.StringSlice("class-rule"))
 	if err != nil {
-		printError(c.fullCommand, c.op, err)
-		return err
+		return Copy{}, err
 	}
 
-	dsturl, err := url.New(c.dst)
+	minIASize, err := minIASizeFromFlags(c)
 	if err != nil {
-		printError(c.fullCommand, c.op, err)
-		return err
+		return Copy{}, err
 	}
 
-	client, err := storage.NewClient(srcurl, c.storageOpts)
+	maxObjectSize, err := maxObjectSizeFromFlags(c)
 	if err != nil {
-		printError(c.fullCommand, c.op, err)
-		return err
+		return Copy{}, err
 	}
 
-	objch, err := expandSource(ctx, client, c.followSymlinks, srcurl)
+	modifyWindow, err := modifyWindowFromFlags(c)
 	if err != nil {
-		printError(c.fullCommand, c.op, err)
-		return err
+		return Copy{}, err
 	}
 
-	waiter := parallel.NewWaiter()
+	priority, err := priorityFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
 
-	var (
-		merror    error
-		errDoneCh = make(chan bool)
-	)
+	spaceMargin, err := spaceMarginFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
 
-	go func() {
-		defer close(errDoneCh)
-		for err := range waiter.Err() {
-			if strings.Contains(err.Error(), "too many open files") {
-				fmt.Println(strings.TrimSpace(fdlimitWarning))
-				fmt.Printf("ERROR %v\n", err)
+	filter, err := keyFilterFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
 
-				os.Exit(1)
-			}
-			printError(c.fullCommand, c.op, err)
-			merror = multierror.Append(merror, err)
-		}
-	}()
+	modTimeFilter, err := parseModTimeFilter(c.String("newer-than-file"), c.String("older-than-file"))
+	if err != nil {
+		return Copy{}, err
+	}
 
-	isBatch := srcurl.HasGlob()
-	if !isBatch && !srcurl.IsRemote() {
-		obj, _ := client.Stat(ctx, srcurl)
-		isBatch = obj != nil && obj.Type.IsDir()
+	lock, err := objectLockFromFlags(c)
+	if err != nil {
+		return Copy{}, err
 	}
 
-	for object := range objch {
-		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
-			continue
-		}
+	meta, err := userMetadataFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
 
-		if err := object.Err; err != nil {
+	grants, err := grantsFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	contentTypes, err := contentTypeMapFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	inventory, err := inventoryFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	fileList, err := fileListFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	hooks, err := hooksFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	directive, err := metadataDirectiveFromFlags(c)
+	if err != nil {
+		return Copy{}, err
+	}
+
+	// a resumed run extends the record it's resumed from, unless a different
+	// one is asked for.
+	manifestPath := c.String("manifest")
+	if manifestPath == "" {
+		manifestPath = c.String("resume-from")
+	}
+
+	// mv doesn't follow symbolic links, and it transfers the objects with the
+	// defaults of the S3 transfer manager.
+	followSymlinks := !c.Bool("no-follow-symlinks")
+	concurrency := c.Int("concurrency")
+	partSize := c.Int64("part-size") * megabytes
+	if deleteSource {
+		followSymlinks = false
+		concurrency = moveConcurrency
+		partSize = movePartSize * megabytes
+	}
+
+	// the last argument is the destination, the rest are the sources.
+	args := c.Args().Slice()
+	sources := args[:len(args)-1]
+	if fileList != nil {
+		sources = fileList.sources()
+	}
+
+	return Copy{
+		src:          sources,
+		dst:          args[len(args)-1],
+		op:           c.Command.Name,
+		fullCommand:  givenCommand(c),
+		deleteSource: deleteSource,
+		// flags
+		verify:           c.Bool("verify-before-delete"),
+		checksumRetries:  c.Int("retry-on-checksum-mismatch"),
+		atomic:           c.Bool("atomic"),
+		removeEmptyDirs:  c.Bool("remove-empty-dirs"),
+		noClobber:        c.Bool("no-clobber"),
+		ifSizeDiffer:     c.Bool("if-size-differ"),
+		ifSourceNewer:    c.Bool("if-source-newer"),
+		noOverwriteNewer: c.Bool("no-overwrite-newer"),
+		modifyWindow:     modifyWindow,
+		addExtension:     c.Bool("add-extension"),
+		failOnSkip:       c.Bool("fail-on-skip"),
+		onlyMissing:      c.Bool("only-missing"),
+		onlyNewKeys:      c.Bool("only-new-keys"),
+		replaceEmpty:     c.Bool("replace-empty"),
+		createEmptyDirs:  c.Bool("create-empty-dirs"),
+		skipPlaceholders: c.Bool("skip-placeholders"),
+		force:            c.Bool("force"),
+		flatten:          c.Bool("flatten"),
+		followSymlinks:   followSymlinks,
+		storageClass:     storage.StorageClass(c.String("storage-class")),
+		keepStorageClass: c.Bool("keep-storage-class"),
+		classRules:       classRules,
+		minIASize:        minIASize,
+		maxObjectSize:    maxObjectSize,
+		concurrency:      concurrency,
+		partSize:         partSize,
+		partSizeFloor:    c.Int64("min-part-size") * megabytes,
+		priority:         priority,
+		encryptionMethod: c.String("sse"),
+		encryptionKeyID:  c.String("sse-kms-key-id"),
+		acl:              aclFromFlags(c),
+		objectLock:       lock,
+		userMetadata:     meta,
+		contentTypes:     contentTypes,
+		grants:           grants,
+		directive:        directive,
+		sourceVersionID:  c.String("source-version-id"),
+		websiteRedirect:  c.String("website-redirect"),
+		prioritizeSmall:  c.Bool("prioritize-small"),
+		checkSpace:       c.Bool("check-space"),
+		spaceMargin:      spaceMargin,
+		printURL:         c.Bool("print-url"),
+		copyACL:          c.Bool("copy-acl"),
+		contentMD5:       c.Bool("content-md5"),
+		compress:         c.Bool("compress"),
+		resumeUploads:    c.Bool("resume-uploads"),
+		decompress:       c.Bool("decompress"),
+		estimate:         c.Bool("estimate"),
+		transform:        tr,
+		keyTemplate:      kt,
+		filter:           filter,
+		modTimeFilter:    modTimeFilter,
+		maxDepth:         maxDepthFromFlags(c),
+		ifMatch:          c.String("if-match"),
+		ifNoneMatch:      c.String("if-none-match"),
+
+		manifestPath:      manifestPath,
+		errorManifestPath: c.String("error-manifest"),
+		hooks:             hooks,
+		resumeFrom:        c.String("resume-from"),
+		onCollision:       c.String("on-collision"),
+
+		disableMultipart:   c.Bool("disable-multipart"),
+		multipartThreshold: c.Int64("multipart-threshold") * megabytes,
+
+		srcEndpoint: endpointFromFlags(c, "source"),
+		dstEndpoint: endpointFromFlags(c, "dest"),
+		inventory:   inventory,
+		fileList:    fileList,
+
+		storageOpts: NewStorageOpts(c),
+	}, nil
+}
+
+// srcStorageOpts returns the storage options of the source client.
+func (c Copy) srcStorageOpts() storage.Options {
+	return c.srcEndpoint.storageOpts(c.storageOpts)
+}
+
+// dstStorageOpts returns the storage options of the destination client.
+func (c Copy) dstStorageOpts() storage.Options {
+	return c.dstEndpoint.storageOpts(c.storageOpts)
+}
+
+// crossEndpoint reports whether the S3 objects are copied between different
+// endpoints, in which case they can't be copied on the server side.
+func (c Copy) crossEndpoint() bool {
+	return c.srcStorageOpts() != c.dstStorageOpts()
+}
+
+const fdlimitWarning = `
+WARNING: s5cmd is hitting the max open file limit allowed by your OS. Either
+increase the open file limit or try to decrease the number of workers with
+'-numworkers' parameter.
+`
+
+// Run starts copying given source objects to destination.
+func (c Copy) Run(ctx context.Context) error {
+	srcurls, err := newURLs(c.src...)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	dsturl, err := url.New(c.dst)
+	if err != nil {
+		printError(c.fullCommand, c.op, err)
+		return err
+	}
+
+	// sources are read before the manifests are opened, since a resumed run
+	// can append to the manifest it's resumed from.
+	var completed map[string]struct{}
+	if c.resumeFrom != "" {
+		completed, err = readManifestSources(c.resumeFrom)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+	}
+
+	// manifests are a record of completed transfers and hooks are run for
+	// them, nothing is transferred on dry-run.
+	if !c.storageOpts.DryRun && !c.estimate {
+		c.manifest, err = openManifest(c.manifestPath, manifestHeader)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+		defer c.manifest.Close()
+
+		c.errorManifest, err = openManifest(c.errorManifestPath, errorManifestHeader)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+		defer c.errorManifest.Close()
+	} else {
+		c.hooks = nil
+	}
+
+	if c.src[0] == stdinSource {
+		err := c.doUploadStdin(ctx, srcurls[0], dsturl)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+		}
+		return err
+	}
+
+	if c.dst == stdoutTarget {
+		err := c.doDownloadStdout(ctx, srcurls[0], dsturl)
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+		}
+		return err
+	}
+
+	// objects of all sources are transferred to the same destination, thus
+	// the objects which are matched by more than one source are transferred
+	// once, and the collisions are detected across the sources.
+	var (
+		seen             map[string]struct{}
+		pending          []pendingObject
+		expandErr        error
+		detectCollisions bool
+	)
+	if len(srcurls) > 1 {
+		seen = map[string]struct{}{}
+	}
+	c.renames = map[string]string{}
+	if c.removeEmptyDirs && !c.storageOpts.DryRun && !c.estimate {
+		c.emptyDirs = newEmptyDirs()
+	}
+
+	sources := make([]copySource, 0, len(srcurls))
+	for _, srcurl := range srcurls {
+		client, err := storage.NewClient(ctx, srcurl, c.srcStorageOpts())
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+		client = withInventory(client, c.inventory)
+		client = withFileList(client, c.fileList)
+
+		isBatch := srcurl.HasGlob()
+		if !isBatch && !srcurl.IsRemote() {
+			obj, _ := client.Stat(ctx, srcurl)
+			isBatch = obj != nil && obj.Type.IsDir()
+		}
+
+		if !c.estimate && c.collisionsPossible(srcurl, dsturl, isBatch, len(srcurls) > 1) {
+			detectCollisions = true
+		}
+
+		if c.emptyDirs != nil && isBatch {
+			c.emptyDirs.addSource(srcurl)
+		}
+		sources = append(sources, copySource{url: srcurl, client: client, isBatch: isBatch})
+	}
+
+	if c.onlyNewKeys {
+		c.remoteKeys, err = listRemoteKeys(ctx, dsturl, c.dstStorageOpts())
+		if err != nil {
+			printError(c.fullCommand, c.op, err)
+			return err
+		}
+	}
+
+	if c.copyACL {
+		c.aclLimiter = make(chan struct{}, c.concurrency)
+	}
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			if strings.Contains(err.Error(), "too many open files") {
+				fmt.Println(strings.TrimSpace(fdlimitWarning))
+				fmt.Printf("ERROR %v\n", err)
+
+				os.Exit(1)
+			}
+			printError(c.fullCommand, c.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	estimate := EstimateMessage{Operation: c.op}
+
+	// interrupted is set if the objects are not all dispatched because of an
+	// interrupt.
+	var interrupted bool
+
+	for _, source := range sources {
+		if parallel.IsDraining() {
+			interrupted = true
+			break
+		}
+
+		isBatch := source.isBatch
+		listctx, cancelList := context.WithCancel(ctx)
+		objch, err := expandSource(listctx, source.client, c.followSymlinks, source.url)
+		if err != nil {
+			cancelList()
 			printError(c.fullCommand, c.op, err)
+			expandErr = multierror.Append(expandErr, err)
 			continue
 		}
 
-		if object.StorageClass.IsGlacier() {
-			err := fmt.Errorf("object '%v' is on Glacier storage", object)
+		for object := range objch {
+			if parallel.IsDraining() {
+				interrupted = true
+				discardObjects(cancelList, objch)
+				break
+			}
+
+			if errorpkg.IsCancelation(object.Err) {
+				continue
+			}
+
+			// placeholders are skipped before anything else, the parent
+			// directories of the downloaded files are created anyway.
+			if c.skipPlaceholders && isPlaceholder(object) {
+				continue
+			}
+
+			// directories are not transferred, they are created along with
+			// the files in them. Placeholders of the empty ones are created
+			// only if asked for.
+			if object.Type.IsDir() && !c.createsEmptyDir(object, dsturl, isBatch) {
+				continue
+			}
+
+			if err := object.Err; err != nil {
+				printError(c.fullCommand, c.op, err)
+				continue
+			}
+
+			if isBatch && exceedsMaxDepth(object.URL, c.maxDepth) {
+				continue
+			}
+
+			if !c.filter.match(object.URL) {
+				continue
+			}
+
+			if object.Type.IsDir() {
+				if !c.estimate {
+					parallel.RunWithPriority(c.prepareMkdirTask(ctx, object, dsturl), waiter, c.priority)
+				}
+				continue
+			}
+
+			if isBatch && !c.modTimeFilter.match(object) {
+				continue
+			}
+
+			// skip the objects which are transferred by a previous run.
+			if _, ok := completed[object.URL.String()]; ok {
+				continue
+			}
+
+			if seen != nil {
+				if _, ok := seen[object.URL.String()]; ok {
+					continue
+				}
+				seen[object.URL.String()] = struct{}{}
+			}
+
+			if object.StorageClass.IsGlacier() {
+				err := fmt.Errorf("object '%v' is on Glacier storage", object)
+				printError(c.fullCommand, c.op, err)
+				continue
+			}
+
+			// destinations of the colliding objects are known once the
+			// collisions are resolved.
+			if !detectCollisions && c.existsLocally(ctx, object, dsturl, isBatch) {
+				continue
+			}
+
+			if c.existsRemotely(object, dsturl, isBatch) {
+				continue
+			}
+
+			if c.estimate {
+				estimate.addObject(object)
+				continue
+			}
+
+			// destinations of all objects must be known to detect collisions,
+			// and sizes of all objects must be known to transfer the small
+			// ones first or to check the available space. The objects are
+			// transferred once the listing is complete.
+			if detectCollisions || c.prioritizeSmall || c.checkSpace {
+				pending = append(pending, pendingObject{object: object, isBatch: isBatch})
+				continue
+			}
+
+			parallel.RunWithPriority(c.prepareTask(ctx, object, dsturl, isBatch), waiter, c.priority)
+		}
+		cancelList()
+	}
+
+	var collisionErr error
+	if detectCollisions {
+		pending, err = c.resolveCollisions(pending, dsturl)
+		if err != nil {
 			printError(c.fullCommand, c.op, err)
+			collisionErr = err
+		}
+		pending = c.skipExistingLocally(ctx, pending, dsturl)
+	}
+
+	if c.prioritizeSmall {
+		sortBySize(pending)
+	}
+
+	var spaceErr error
+	if c.checkSpace && !parallel.IsDraining() {
+		if spaceErr = c.ensureSpace(pending, dsturl); spaceErr != nil {
+			printError(c.fullCommand, c.op, spaceErr)
+			pending = nil
+		}
+	}
+
+	for _, p := range pending {
+		if parallel.IsDraining() {
+			interrupted = true
+			break
+		}
+		parallel.RunWithPriority(c.prepareTask(ctx, p.object, dsturl, p.isBatch), waiter, c.priority)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	if c.emptyDirs != nil {
+		c.emptyDirs.remove()
+	}
+
+	// estimates of the partial listings are not printed, they would be
+	// taken as the totals.
+	if c.estimate && !interrupted {
+		log.Info(estimate)
+	}
+
+	if expandErr != nil {
+		merror = multierror.Append(merror, expandErr)
+	}
+	if collisionErr != nil {
+		merror = multierror.Append(merror, collisionErr)
+	}
+	if spaceErr != nil {
+		merror = multierror.Append(merror, spaceErr)
+	}
+	if interrupted || waiter.Interrupted() {
+		merror = appendInterrupted(merror, c.fullCommand, c.op)
+	}
+	return merror
+}
+
+// skipExistingLocally returns the pending objects which are not skipped by
+// existsLocally. It's called once the collisions are resolved, since the
+// destinations of the colliding objects aren't known before.
+func (c Copy) skipExistingLocally(ctx context.Context, pending []pendingObject, dsturl *url.URL) []pendingObject {
+	var missing []pendingObject
+	for _, p := range pending {
+		if c.existsLocally(ctx, p.object, dsturl, p.isBatch) {
 			continue
 		}
+		missing = append(missing, p)
+	}
+	return missing
+}
+
+// ensureSpace checks that the pending objects fit in the available space of
+// the filesystem of the local destination, leaving --space-margin available.
+// The files skipped by --only-missing are already removed from the pending
+// objects. Sizes of the existing files which would be overwritten are not
+// subtracted, so the check errs on the safe side.
+func (c Copy) ensureSpace(pending []pendingObject, dsturl *url.URL) error {
+	var total int64
+	for _, p := range pending {
+		total += p.object.Size
+	}
+
+	available, err := storage.NewLocalClient(c.storageOpts).AvailableSpace(dsturl.Absolute())
+	if err != nil {
+		return fmt.Errorf("check available space of %q: %v", dsturl, err)
+	}
+
+	if required := uint64(total) + uint64(c.spaceMargin); required > available {
+		return fmt.Errorf(
+			"not enough space at %q: %v bytes are required, including %v bytes of --space-margin, but %v bytes are available",
+			dsturl, required, c.spaceMargin, available,
+		)
+	}
+	return nil
+}
+
+// copySource is a source argument of the copy operation.
+type copySource struct {
+	url    *url.URL
+	client storage.Storage
+
+	// isBatch reports whether the source is expanded to multiple objects,
+	// i.e. it has a wildcard or it's a local directory.
+	isBatch bool
+}
+
+// prepareTask returns the task to transfer the source object to the
+// destination, depending on the source and destination types.
+func (c Copy) prepareTask(
+	ctx context.Context,
+	srcobj *storage.Object,
+	dsturl *url.URL,
+	isBatch bool,
+) parallel.Task {
+	srcurl := srcobj.URL
+	switch {
+	case srcurl.Type == dsturl.Type: // local->local or remote->remote
+		return c.prepareCopyTask(ctx, srcobj, dsturl, isBatch)
+	case srcurl.IsRemote(): // remote->local
+		return c.prepareDownloadTask(ctx, srcobj, dsturl, isBatch)
+	case dsturl.IsRemote(): // local->remote
+		return c.prepareUploadTask(ctx, srcobj, dsturl, isBatch)
+	default:
+		panic("unexpected src-dst pair")
+	}
+}
+
+// createsEmptyDir reports whether a local directory is created for the given
+// directory object, which is only done for batch downloads with
+// --create-empty-dirs.
+func (c Copy) createsEmptyDir(object *storage.Object, dsturl *url.URL, isBatch bool) bool {
+	return c.createEmptyDirs && isBatch && object.URL.IsRemote() && !dsturl.IsRemote()
+}
+
+// isPlaceholder reports whether the object is a folder placeholder, i.e. a
+// zero-byte S3 object whose key ends with '/'.
+func isPlaceholder(object *storage.Object) bool {
+	return object.URL.IsRemote() && strings.HasSuffix(object.URL.Path, "/") && object.Size == 0
+}
+
+// prepareMkdirTask creates the local directory of an empty directory
+// placeholder. The placeholder is deleted if the source is to be deleted.
+func (c Copy) prepareMkdirTask(ctx context.Context, srcobj *storage.Object, dsturl *url.URL) func() error {
+	return func() error {
+		srcurl := srcobj.URL
+		// the placeholder of the directory which the wildcard is in has no
+		// relative path, its directory is the destination itself.
+		if srcurl.Relative() == srcurl.Absolute() {
+			return nil
+		}
 
-		srcurl := object.URL
-		var task parallel.Task
+		objname, err := c.localObjectName(srcurl, true)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+
+		dsturl := dsturl.Join(objname)
+		err = c.doMkdir(ctx, srcurl, dsturl)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+		return nil
+	}
+}
+
+func (c Copy) doMkdir(ctx context.Context, srcurl, dsturl *url.URL) error {
+	dstClient := storage.NewLocalClient(c.storageOpts)
+	if err := dstClient.MkdirAll(dsturl.Absolute()); err != nil {
+		return err
+	}
 
-		switch {
-		case srcurl.Type == dsturl.Type: // local->local or remote->remote
-			task = c.prepareCopyTask(ctx, srcurl, dsturl, isBatch)
-		case srcurl.IsRemote(): // remote->local
-			task = c.prepareDownloadTask(ctx, srcurl, dsturl, isBatch)
-		case dsturl.IsRemote(): // local->remote
-			task = c.prepareUploadTask(ctx, srcurl, dsturl, isBatch)
-		default:
-			panic("unexpected src-dst pair")
+	if c.deleteSource {
+		srcClient, err := storage.NewRemoteClient(ctx, srcurl, c.srcStorageOpts())
+		if err != nil {
+			return err
+		}
+		if err := srcClient.Delete(ctx, srcurl); err != nil {
+			return err
 		}
+	}
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+	}
+	log.Info(msg)
+	return nil
+}
+
+func (c Copy) prepareCopyTask(
+	ctx context.Context,
+	srcobj *storage.Object,
+	dsturl *url.URL,
+	isBatch bool,
+) func() error {
+	return func() error {
+		srcurl := srcobj.URL
+		dsturl = prepareRemoteDestination(dsturl, c.objectName(srcurl, isBatch))
+		err := c.doCopy(ctx, srcobj, dsturl)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+		return nil
+	}
+}
+
+func (c Copy) prepareDownloadTask(
+	ctx context.Context,
+	srcobj *storage.Object,
+	dsturl *url.URL,
+	isBatch bool,
+) func() error {
+	return func() error {
+		srcurl := srcobj.URL
+		objname, err := c.localObjectName(srcurl, isBatch)
+		if err == nil && c.addExtension {
+			objname, err = c.nameWithExtension(ctx, srcobj, objname)
+		}
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+
+		dsturl, err := prepareLocalDestination(ctx, dsturl, objname, c.flatten, isBatch, c.storageOpts)
+		if err != nil {
+			c.recordError(ctx, srcurl, nil, err)
+			return err
+		}
+
+		err = c.doDownload(ctx, srcobj, dsturl)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+		return nil
+	}
+}
+
+// nameWithExtension returns the name of the file the object is downloaded to,
+// with the extension of the content type of the object if the name has none.
+// Content types are not listed, they are fetched with a HEAD request.
+func (c Copy) nameWithExtension(ctx context.Context, srcobj *storage.Object, objname string) (string, error) {
+	metadata := srcobj.Metadata
+	if metadata == nil {
+		client, err := storage.NewRemoteClient(ctx, srcobj.URL, c.srcStorageOpts())
+		if err != nil {
+			return "", err
+		}
+
+		obj, err := client.Stat(ctx, srcobj.URL)
+		if err != nil {
+			return "", err
+		}
+		metadata = obj.Metadata
+	}
+	return withExtension(objname, metadata.ContentType()), nil
+}
+
+func (c Copy) prepareUploadTask(
+	ctx context.Context,
+	srcobj *storage.Object,
+	dsturl *url.URL,
+	isBatch bool,
+) func() error {
+	return func() error {
+		srcurl := srcobj.URL
+		objname, err := c.keyTemplate.apply(filepath.ToSlash(c.objectName(srcurl, isBatch)), time.Now())
+		if err != nil {
+			c.recordError(ctx, srcurl, nil, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Err: err,
+			}
+		}
+
+		dsturl = prepareRemoteDestination(dsturl, objname)
+		err = c.doUpload(ctx, srcobj, dsturl)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+		return nil
+	}
+}
+
+// doDownload is used to fetch a remote object and save as a local object.
+func (c Copy) doDownload(ctx context.Context, srcobj *storage.Object, dsturl *url.URL) error {
+	srcurl := srcobj.URL
+	srcClient, err := storage.NewRemoteClient(ctx, srcurl, c.srcStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	dstClient := storage.NewLocalClient(c.storageOpts)
+
+	err = c.checkObjectSize(ctx, srcClient, srcobj)
+	if err == nil {
+		err = c.shouldOverride(ctx, srcurl, dsturl)
+	}
+	if err != nil {
+		// FIXME(ig): rename
+		if errorpkg.IsWarning(err) {
+			return c.skip(srcurl, dsturl, err)
+		}
+		return err
+	}
+
+	// with --atomic, the object is downloaded to a temporary file which is
+	// renamed once it's complete. The existing file is left as is if the
+	// download fails.
+	downloadurl := dsturl
+	if c.atomic && !c.storageOpts.DryRun {
+		downloadurl, err = atomicDownloadURL(dsturl)
+		if err != nil {
+			return err
+		}
+	}
+
+	size, retries, err := c.downloadVerified(ctx, srcClient, dstClient, srcurl, downloadurl)
+	if err == nil && downloadurl != dsturl {
+		err = os.Rename(downloadurl.Absolute(), dsturl.Absolute())
+	}
+	if err != nil {
+		// the file is closed at this point, it's safe to remove it.
+		_ = dstClient.Delete(ctx, downloadurl)
+		return err
+	}
+	stat.AddDownload(size)
+
+	if c.deleteSource {
+		if err := c.verifyBeforeDelete(ctx, srcClient, dstClient, srcurl, dsturl); err != nil {
+			return err
+		}
+		_ = srcClient.Delete(ctx, srcurl)
+	}
+
+	if err := c.recordTransfer(ctx, srcurl, dsturl, size, srcobj.Etag); err != nil {
+		return err
+	}
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size: size,
+		},
+		Retries: retries,
+	}
+	log.Info(msg)
+
+	return nil
+}
+
+func (c Copy) doUpload(ctx context.Context, srcobj *storage.Object, dsturl *url.URL) error {
+	srcurl := srcobj.URL
+	srcClient := storage.NewLocalClient(c.storageOpts)
+
+	err := c.checkObjectSize(ctx, srcClient, srcobj)
+	if err == nil {
+		err = c.shouldOverride(ctx, srcurl, dsturl)
+	}
+	if err != nil {
+		if errorpkg.IsWarning(err) {
+			return c.skip(srcurl, dsturl, err)
+		}
+		return err
+	}
+
+	dstClient, err := storage.NewRemoteClient(ctx, dsturl, c.dstStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	size, err := c.upload(ctx, srcClient, dstClient, srcurl, dsturl)
+	if err != nil {
+		return err
+	}
+	stat.AddUpload(size)
+
+	if c.deleteSource {
+		if err := c.verifyBeforeDelete(ctx, srcClient, dstClient, srcurl, dsturl); err != nil {
+			return err
+		}
+		if err := srcClient.Delete(ctx, srcurl); err != nil {
+			return err
+		}
+		if c.emptyDirs != nil {
+			c.emptyDirs.addFile(srcurl.Absolute())
+		}
+	}
+
+	if err := c.recordTransfer(ctx, srcurl, dsturl, size, ""); err != nil {
+		return err
+	}
+
+	objectURL := c.objectURL(dstClient, srcurl, dsturl)
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size:         size,
+			StorageClass: c.uploadStorageClass(size),
+		},
+		URL: objectURL,
+	}
+	log.Info(msg)
+
+	return nil
+}
+
+// uploadStorageClass returns the storage class of an uploaded file with given
+// size. Files smaller than --min-ia-size are stored in the standard storage
+// class instead of the infrequent access classes.
+func (c Copy) uploadStorageClass(size int64) storage.StorageClass {
+	class := c.classRules.storageClass(size, c.storageClass)
+	if belowMinimumSize(class, size, c.minIASize) {
+		return storageClassStandard
+	}
+	return class
+}
+
+// doUploadStdin uploads the data read from standard input to the remote
+// destination. Size of the input is not known beforehand, so the data is
+// uploaded in parts of the configured part size.
+func (c Copy) doUploadStdin(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
+	dstClient, err := storage.NewRemoteClient(ctx, dsturl, c.dstStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	metadata := storage.NewMetadata().
+		SetStorageClass(string(c.storageClass)).
+		SetSSE(c.encryptionMethod).
+		SetSSEKeyID(c.encryptionKeyID).
+		SetACL(c.acl).
+		SetWebsiteRedirectLocation(c.websiteRedirect)
+	c.objectLock.setMetadata(metadata)
+	c.userMetadata.setMetadata(metadata)
+	c.grants.setMetadata(metadata)
+
+	reader := &countingReader{r: os.Stdin}
+
+	var body io.Reader = reader
+	if c.compress {
+		metadata.SetContentEncoding(gzipEncoding)
+		cr := newCompressReader(reader)
+		defer cr.Close()
+		body = cr
+	}
+
+	err = dstClient.Put(ctx, body, dsturl, metadata, c.concurrency, c.minUploadPartSize())
+	if err != nil {
+		c.recordError(ctx, srcurl, dsturl, err)
+		return &errorpkg.Error{
+			Op:  c.op,
+			Src: srcurl,
+			Dst: dsturl,
+			Err: err,
+		}
+	}
+	stat.AddUpload(reader.n)
+
+	if err := c.recordTransfer(ctx, srcurl, dsturl, reader.n, ""); err != nil {
+		return err
+	}
+
+	objectURL := c.objectURL(dstClient, srcurl, dsturl)
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size:         reader.n,
+			StorageClass: c.storageClass,
+		},
+		URL: objectURL,
+	}
+	log.Info(msg)
+
+	return nil
+}
+
+// doDownloadStdout writes the remote object to standard output. The object is
+// read with a single request to write it in order, and the messages are
+// printed to standard error to keep the output clean.
+func (c Copy) doDownloadStdout(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
+	srcClient, err := storage.NewRemoteClient(ctx, srcurl, c.srcStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	var size int64
+	if !c.storageOpts.DryRun {
+		size, err = readTo(ctx, srcClient, srcurl, c.preconditions(), os.Stdout)
+		if err != nil {
+			c.recordError(ctx, srcurl, dsturl, err)
+			return &errorpkg.Error{
+				Op:  c.op,
+				Src: srcurl,
+				Dst: dsturl,
+				Err: err,
+			}
+		}
+	}
+	stat.AddDownload(size)
+
+	if err := c.recordTransfer(ctx, srcurl, dsturl, size, ""); err != nil {
+		return err
+	}
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			Size: size,
+		},
+	}
+	log.InfoToStderr(msg)
+
+	return nil
+}
+
+// readTo reads the remote object into the given writer.
+func readTo(ctx context.Context, client *storage.S3, srcurl *url.URL, metadata storage.Metadata, w io.Writer) (int64, error) {
+	rc, err := client.Read(ctx, srcurl, metadata)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(w, rc)
+}
+
+// objectURL returns the URL of the uploaded object if --print-url is given,
+// otherwise an empty string. The object is already uploaded, so a failure to
+// build the URL is printed as a warning rather than failing the upload.
+func (c Copy) objectURL(client *storage.S3, srcurl, dsturl *url.URL) string {
+	if !c.printURL {
+		return ""
+	}
+
+	objectURL, err := client.ObjectURL(dsturl)
+	if err != nil {
+		log.Warning(log.WarningMessage{
+			Operation: c.op,
+			Command:   fmt.Sprintf("%v %v %v", c.op, srcurl, dsturl),
+			Warning:   fmt.Sprintf("object is uploaded, but its URL is not printed: %v", cleanupError(err)),
+		})
+		return ""
+	}
+	return objectURL
+}
+
+// download writes the remote object to a local file and returns the number
+// of bytes written. The file is closed before returning, once the transfer is
+// either complete or aborted.
+func (c Copy) download(
+	ctx context.Context,
+	srcClient *storage.S3,
+	dstClient *storage.Filesystem,
+	srcurl *url.URL,
+	dsturl *url.URL,
+) (int64, error) {
+	file, err := dstClient.Create(dsturl.Absolute())
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if c.decompress && !c.storageOpts.DryRun {
+		obj, err := srcClient.Stat(ctx, srcurl)
+		if err != nil {
+			return 0, err
+		}
+
+		if obj.Metadata.ContentEncoding() == gzipEncoding {
+			return c.downloadDecompressed(ctx, srcClient, srcurl, file)
+		}
+	}
+
+	return srcClient.Get(ctx, srcurl, file, c.preconditions(), c.concurrency, c.partSize)
+}
+
+// downloadVerified downloads the remote object, and compares the file with
+// the object if --retry-on-checksum-mismatch is given. The object is
+// downloaded again up to the given times while they don't match, since
+// corruptions in transit are often fixed by a retry. It returns the number of
+// the retries along with the size of the file.
+func (c Copy) downloadVerified(
+	ctx context.Context,
+	srcClient *storage.S3,
+	dstClient *storage.Filesystem,
+	srcurl *url.URL,
+	dsturl *url.URL,
+) (int64, int, error) {
+	for retries := 0; ; retries++ {
+		size, err := c.download(ctx, srcClient, dstClient, srcurl, dsturl)
+		if err != nil || c.checksumRetries == 0 || c.storageOpts.DryRun {
+			return size, retries, err
+		}
+
+		mismatch, err := compareWithSource(ctx, srcClient, dstClient, srcurl, dsturl, c.decompress)
+		if err != nil {
+			return 0, retries, fmt.Errorf("verification failed: %v", err)
+		}
+		if mismatch == "" {
+			return size, retries, nil
+		}
+
+		if retries == c.checksumRetries {
+			return 0, retries, fmt.Errorf("verification failed after %d retries: %v", retries, mismatch)
+		}
+		printDebug(c.op, srcurl, dsturl, fmt.Errorf("verification failed, downloading again: %v", mismatch))
+	}
+}
+
+// atomicDownloadURL creates a temporary file in the directory of the
+// destination and returns its url. Renaming it to the destination is atomic
+// since they are on the same filesystem. The file is created with the same
+// permissions as the destination would be.
+func atomicDownloadURL(dsturl *url.URL) (*url.URL, error) {
+	dir, name := filepath.Split(dsturl.Absolute())
+	suffix := time.Now().UnixNano()
+	for {
+		path := filepath.Join(dir, fmt.Sprintf(".%v.s5cmd-%v", name, suffix))
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) {
+			suffix++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := file.Close(); err != nil {
+			_ = os.Remove(path)
+			return nil, err
+		}
+
+		tmpurl := dsturl.Clone()
+		tmpurl.Path = path
+		return tmpurl, nil
+	}
+}
+
+// downloadDecompressed downloads the gzip compressed remote object to a
+// temporary file next to the destination, and writes the decompressed content
+// to the destination file. Multipart downloads write the parts out of order,
+// so the object can't be decompressed while it's being downloaded.
+func (c Copy) downloadDecompressed(
+	ctx context.Context,
+	srcClient *storage.S3,
+	srcurl *url.URL,
+	file *os.File,
+) (int64, error) {
+	tmpfile, err := ioutil.TempFile(filepath.Dir(file.Name()), ".s5cmd-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpfile.Name())
+	defer tmpfile.Close()
+
+	_, err = srcClient.Get(ctx, srcurl, tmpfile, c.preconditions(), c.concurrency, c.partSize)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tmpfile.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	return decompress(file, tmpfile)
+}
+
+// upload reads the local file and writes it to the remote destination. It
+// returns the size of the file. The file is closed before returning, once the
+// transfer is either complete or aborted.
+func (c Copy) upload(
+	ctx context.Context,
+	srcClient *storage.Filesystem,
+	dstClient *storage.S3,
+	srcurl *url.URL,
+	dsturl *url.URL,
+) (int64, error) {
+	file, err := srcClient.Open(srcurl.Absolute())
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	// AWS rejects larger single part uploads only after the whole file is
+	// sent.
+	if c.uploadsInSinglePart(info.Size()) && info.Size() > maxSinglePartSize*megabytes && storage.IsAmazonEndpoint(c.dstStorageOpts()) {
+		return 0, fmt.Errorf("file is larger than %v MiB, the maximum size of single part uploads to S3, it can't be uploaded with --disable-multipart", maxSinglePartSize)
+	}
+
+	if class := c.classRules.storageClass(info.Size(), c.storageClass); belowMinimumSize(class, info.Size(), c.minIASize) {
+		err := fmt.Errorf("file is smaller than --min-ia-size, stored in %v storage class instead of %v", storageClassStandard, class)
+		printDebug(c.op, srcurl, dsturl, err)
+	}
+
+	contentType := c.contentTypes.lookup(file.Name())
+	if contentType == "" {
+		contentType = guessContentType(file)
+	}
+
+	metadata := storage.NewMetadata().
+		SetContentType(contentType).
+		SetStorageClass(string(c.uploadStorageClass(info.Size()))).
+		SetSSE(c.encryptionMethod).
+		SetSSEKeyID(c.encryptionKeyID).
+		SetACL(c.acl).
+		SetWebsiteRedirectLocation(c.websiteRedirect)
+	c.objectLock.setMetadata(metadata)
+	c.userMetadata.setMetadata(metadata)
+	c.grants.setMetadata(metadata)
+
+	if c.contentMD5 {
+		digest, err := computeContentMD5(file, c.singlePartLimit())
+		if err != nil {
+			return 0, err
+		}
+		metadata.SetContentMD5(digest)
+	}
+
+	var reader io.Reader = file
+	if c.compress {
+		metadata.SetContentEncoding(gzipEncoding)
+		cr := newCompressReader(file)
+		defer cr.Close()
+		reader = cr
+	}
+
+	// the file is never compressed here, --compress can't be used with the
+	// single part options.
+	partSize := c.uploadPartSize(info.Size())
+	if partSize != c.minUploadPartSize() && !c.uploadsInSinglePart(info.Size()) {
+		err := fmt.Errorf("file needs more than %v parts with --part-size, uploaded in parts of %v MiB instead", maxUploadParts, partSize/megabytes)
+		printDebug(c.op, srcurl, dsturl, err)
+	}
+
+	switch {
+	case c.uploadsInSinglePart(info.Size()):
+		err = dstClient.PutSinglePart(ctx, file, dsturl, metadata)
+	case c.resumeUploads && info.Size() > partSize:
+		err = dstClient.PutResumable(ctx, file, info.Size(), dsturl, metadata, c.concurrency, partSize)
+	default:
+		err = dstClient.Put(ctx, reader, dsturl, metadata, c.concurrency, partSize)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// uploadPartSize returns the part size of the multipart upload of a file of
+// given size. It's minUploadPartSize, unless the file would be uploaded in
+// more than maxUploadParts parts. Then it's increased to the smallest size, in
+// MiB, which fits the file in maxUploadParts parts. The part size only depends
+// on the file size, so that resumed uploads have the same parts on each run.
+func (c Copy) uploadPartSize(size int64) int64 {
+	if partSize := c.minUploadPartSize(); size <= partSize*maxUploadParts {
+		return partSize
+	}
+
+	partSize := (size + maxUploadParts - 1) / maxUploadParts
+	return (partSize + megabytes - 1) / megabytes * megabytes
+}
+
+// minUploadPartSize returns the smallest part size of multipart uploads,
+// which is --part-size, or --min-part-size if it's larger.
+func (c Copy) minUploadPartSize() int64 {
+	if c.partSizeFloor > c.partSize {
+		return c.partSizeFloor
+	}
+	return c.partSize
+}
+
+// singlePartLimit returns the size of the largest file which is uploaded in a
+// single part.
+func (c Copy) singlePartLimit() int64 {
+	switch {
+	case c.disableMultipart:
+		return math.MaxInt64
+	case c.multipartThreshold > 0:
+		return c.multipartThreshold
+	default:
+		return c.minUploadPartSize()
+	}
+}
+
+// uploadsInSinglePart reports whether a file of given size is uploaded with a
+// single PutObject request, instead of letting the uploader decide.
+func (c Copy) uploadsInSinglePart(size int64) bool {
+	if !c.disableMultipart && c.multipartThreshold == 0 {
+		return false
+	}
+	return size <= c.singlePartLimit()
+}
+
+func (c Copy) doCopy(ctx context.Context, srcobj *storage.Object, dsturl *url.URL) error {
+	srcurl := srcobj.URL
+	srcClient, err := storage.NewRemoteClient(ctx, srcurl, c.srcStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := storage.NewRemoteClient(ctx, dsturl, c.dstStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	// single object arguments are not listed, only their URLs are known.
+	if srcobj.ModTime == nil && (c.keepStorageClass || c.manifest != nil) {
+		srcobj, err = srcClient.Stat(ctx, srcurl)
+		if err != nil {
+			return err
+		}
+	}
+
+	// headers of the objects are not listed, they are fetched to be
+	// preserved. Objects copied between endpoints are uploaded anew, their
+	// headers are carried forward unless the metadata is replaced.
+	if (c.directive.needsSource() || c.crossEndpoint()) && srcobj.Metadata == nil {
+		srcobj, err = srcClient.Stat(ctx, srcurl)
+		if err != nil {
+			return err
+		}
+	}
+
+	storageClass := c.storageClass
+	if c.keepStorageClass {
+		storageClass = srcobj.StorageClass
+	}
+
+	metadata := storage.NewMetadata().
+		SetStorageClass(string(storageClass)).
+		SetSSE(c.encryptionMethod).
+		SetSSEKeyID(c.encryptionKeyID).
+		SetACL(c.acl).
+		SetIfMatch(c.ifMatch).
+		SetIfNoneMatch(c.ifNoneMatch).
+		SetSourceVersionID(c.sourceVersionID)
+	c.grants.setMetadata(metadata)
+	c.directive.setMetadata(metadata, srcobj.Metadata)
+
+	err = c.checkObjectSize(ctx, srcClient, srcobj)
+	if err == nil {
+		err = c.shouldOverride(ctx, srcurl, dsturl)
+	}
+	if err != nil {
+		if errorpkg.IsWarning(err) {
+			return c.skip(srcurl, dsturl, err)
+		}
+		return err
+	}
+
+	if c.crossEndpoint() {
+		err = c.streamCopy(ctx, srcClient, dstClient, srcobj, dsturl, metadata)
+	} else {
+		err = srcClient.Copy(ctx, srcurl, dsturl, metadata)
+	}
+	if err != nil {
+		return err
+	}
+	stat.AddCopy(srcobj.Size)
+
+	// the source of mv is kept if its ACL is not copied, so that it can be
+	// moved again.
+	if c.copyACL {
+		if err := c.copyObjectACL(ctx, srcClient, srcurl, dsturl); err != nil {
+			return &aclError{Err: err}
+		}
+	}
+
+	if c.deleteSource {
+		if err := c.verifyBeforeDelete(ctx, srcClient, dstClient, srcurl, dsturl); err != nil {
+			return err
+		}
+		if err := srcClient.Delete(ctx, srcurl); err != nil {
+			return err
+		}
+	}
+
+	if err := c.recordTransfer(ctx, srcurl, dsturl, srcobj.Size, srcobj.Etag); err != nil {
+		return err
+	}
+
+	msg := log.InfoMessage{
+		Operation:   c.op,
+		Source:      srcurl,
+		Destination: dsturl,
+		Object: &storage.Object{
+			URL:          dsturl,
+			StorageClass: storageClass,
+		},
+	}
+	log.Info(msg)
+
+	return nil
+}
+
+// streamCopy copies an S3 object to a different endpoint, which can't be done
+// on the server side, by streaming its content from the source to the
+// destination without storing it locally. Headers of the source object are
+// carried forward, unless the metadata is replaced.
+func (c Copy) streamCopy(
+	ctx context.Context,
+	srcClient *storage.S3,
+	dstClient *storage.S3,
+	srcobj *storage.Object,
+	dsturl *url.URL,
+	metadata storage.Metadata,
+) error {
+	if c.storageOpts.DryRun {
+		return nil
+	}
+
+	if !c.directive.replaces() {
+		source := srcobj.Metadata
+		metadata.SetContentType(source.ContentType()).
+			SetContentEncoding(source.ContentEncoding()).
+			SetCacheControl(source.CacheControl()).
+			SetContentDisposition(source.ContentDisposition()).
+			SetContentLanguage(source.ContentLanguage())
+		for key, value := range source.UserDefined() {
+			metadata.SetUserDefined(key, value)
+		}
+	}
+
+	body, err := srcClient.Read(ctx, srcobj.URL, metadata)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	return dstClient.Put(ctx, body, dsturl, metadata, c.concurrency, c.minUploadPartSize())
+}
+
+// skip handles the objects which are not copied since the destination is not
+// overridden. They are only logged in debug level, unless --fail-on-skip is
+// given.
+func (c Copy) skip(srcurl, dsturl *url.URL, err error) error {
+	if c.failOnSkip {
+		return err
+	}
+
+	// objects are expected to be skipped if the destination exists, but
+	// oversize ones are reported in the summary of the failed objects.
+	if err == errorpkg.ErrObjectTooLarge {
+		stat.AddFailure(srcurl.String(), err.Error())
+	}
+
+	printDebug(c.op, srcurl, dsturl, err)
+	return nil
+}
+
+// recordTransfer records a transferred object in the manifest, and runs the
+// --on-success command for it.
+func (c Copy) recordTransfer(ctx context.Context, srcurl, dsturl *url.URL, size int64, etag string) error {
+	if err := c.manifest.writeTransfer(srcurl, dsturl, size, etag); err != nil {
+		return err
+	}
+	return c.hooks.runSuccess(ctx, srcurl, dsturl, size)
+}
+
+// recordError records an object which couldn't be transferred in the error
+// manifest, and runs the --on-error command for it. Failures of the command
+// are printed, since the object has already failed.
+func (c Copy) recordError(ctx context.Context, srcurl, dsturl *url.URL, err error) {
+	_ = c.errorManifest.writeError(srcurl, dsturl, err)
+	if err := c.hooks.runError(ctx, srcurl, dsturl, err); err != nil {
+		printError(c.fullCommand, c.op, err)
+	}
+}
+
+// aclError is returned if an object is copied but its access control list is
+// not, to report it apart from the objects which are not copied at all.
+type aclError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *aclError) Error() string {
+	return fmt.Sprintf("object is copied, but its ACL is not: %v", e.Err)
+}
+
+// Unwrap unwraps the error.
+func (e *aclError) Unwrap() error {
+	return e.Err
+}
+
+// copyObjectACL copies the access control list of the source object to the
+// destination object, waiting for the other ACLs being copied if there are
+// --concurrency of them.
+func (c Copy) copyObjectACL(ctx context.Context, client *storage.S3, srcurl, dsturl *url.URL) error {
+	select {
+	case c.aclLimiter <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-c.aclLimiter }()
+
+	return client.CopyACL(ctx, srcurl, dsturl)
+}
+
+// checkObjectSize returns ErrObjectTooLarge if the object is larger than
+// --max-object-size. Sizes of the listed objects are used as is, except the
+// files, which are checked again as they might have changed since.
+func (c Copy) checkObjectSize(ctx context.Context, client storage.Storage, srcobj *storage.Object) error {
+	if c.maxObjectSize <= 0 {
+		return nil
+	}
+
+	// single object arguments are not listed, only their URLs are known.
+	if srcobj.ModTime == nil || !srcobj.URL.IsRemote() {
+		obj, err := client.Stat(ctx, srcobj.URL)
+		if err != nil {
+			return err
+		}
+		srcobj = obj
+	}
+
+	if srcobj.Size > c.maxObjectSize {
+		return errorpkg.ErrObjectTooLarge
+	}
+	return nil
+}
+
+// existsLocally reports whether the object is skipped since it would be
+// downloaded to an existing file and --only-missing is given. Files are
+// checked before the downloads are queued, neither the sizes nor the
+// modification times are compared.
+func (c Copy) existsLocally(ctx context.Context, srcobj *storage.Object, dsturl *url.URL, isBatch bool) bool {
+	if !c.onlyMissing || !srcobj.URL.IsRemote() || dsturl.IsRemote() {
+		return false
+	}
+
+	client := storage.NewLocalClient(c.storageOpts)
+	dsturl, err := localDestination(ctx, client, dsturl, c.objectName(srcobj.URL, isBatch), isBatch)
+	if err != nil {
+		return false
+	}
+
+	obj, err := client.Stat(ctx, dsturl)
+	if err != nil || obj.Type.IsDir() || c.isReplacedEmpty(srcobj, obj) {
+		return false
+	}
+
+	printDebug(c.op, srcobj.URL, dsturl, errorpkg.ErrObjectExists)
+	return true
+}
+
+// existsRemotely reports whether the file is skipped since it would be
+// uploaded to an existing key and --only-new-keys is given. Keys are looked up
+// in the listing of the destination. With --if-size-differ, the files whose
+// sizes differ from the existing objects are uploaded.
+func (c Copy) existsRemotely(srcobj *storage.Object, dsturl *url.URL, isBatch bool) bool {
+	if c.remoteKeys == nil || srcobj.URL.IsRemote() || !dsturl.IsRemote() {
+		return false
+	}
+
+	objname, err := c.keyTemplate.apply(filepath.ToSlash(c.objectName(srcobj.URL, isBatch)), time.Now())
+	if err != nil {
+		return false
+	}
+
+	dsturl = prepareRemoteDestination(dsturl, objname)
+	dstobj, ok := c.remoteKeys[dsturl.Path]
+	if !ok {
+		return false
+	}
+
+	if !c.ifSizeDiffer {
+		printDebug(c.op, srcobj.URL, dsturl, errorpkg.ErrObjectExists)
+		return true
+	}
+
+	if dstobj.Size != srcobj.Size {
+		return false
+	}
+
+	printDebug(c.op, srcobj.URL, dsturl, errorpkg.ErrObjectSizesMatch)
+	return true
+}
+
+// listRemoteKeys returns the objects under the given destination by their
+// keys. The destination is listed as a prefix, so the keys of the objects
+// uploaded into it are found regardless of its trailing slash.
+func listRemoteKeys(ctx context.Context, dsturl *url.URL, opts storage.Options) (map[string]*storage.Object, error) {
+	client, err := storage.NewRemoteClient(ctx, dsturl, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	listurl, err := dsturl.Recursive()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]*storage.Object{}
+	for object := range client.List(ctx, listurl, false) {
+		if object.Err == storage.ErrNoObjectFound {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			return nil, err
+		}
+
+		if object.Type.IsDir() {
+			continue
+		}
+		keys[object.URL.Path] = object
+	}
+	return keys, nil
+}
+
+// isReplacedEmpty reports whether the existing empty local file is treated as
+// missing because of --replace-empty, since it's likely left by an interrupted
+// download. Files of empty objects are not downloaded again.
+func (c Copy) isReplacedEmpty(srcobj, dstobj *storage.Object) bool {
+	return c.replaceEmpty && !dstobj.URL.IsRemote() && dstobj.Size == 0 && srcobj.Size > 0
+}
+
+// shouldOverride function checks if the destination should be overridden if
+// the source-destination pair and given copy flags conform to the
+// override criteria. For example; "cp -n -s <src> <dst>" should not override
+// the <dst> if <src> and <dst> filenames are the same, except if the size
+// differs. If force is set, the destination is always overridden regardless
+// of the other flags.
+func (c Copy) shouldOverride(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
+	if c.force {
+		return nil
+	}
+
+	// if not asked to override, ignore.
+	if !c.noClobber && !c.ifSizeDiffer && !c.ifSourceNewer && !c.noOverwriteNewer {
+		return nil
+	}
+
+	srcClient, err := storage.NewClient(ctx, srcurl, c.srcStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	srcObj, err := getObject(ctx, srcurl, srcClient)
+	if err != nil {
+		return err
+	}
+
+	dstClient, err := storage.NewClient(ctx, dsturl, c.dstStorageOpts())
+	if err != nil {
+		return err
+	}
+
+	dstObj, err := getObject(ctx, dsturl, dstClient)
+	if err != nil {
+		return err
+	}
+
+	// if destination not exists, no conditions apply.
+	if dstObj == nil || c.isReplacedEmpty(srcObj, dstObj) {
+		return nil
+	}
+
+	var stickyErr error
+	if c.noClobber {
+		stickyErr = errorpkg.ErrObjectExists
+	}
+
+	if c.ifSizeDiffer {
+		if srcObj.Size == dstObj.Size {
+			stickyErr = errorpkg.ErrObjectSizesMatch
+		} else {
+			stickyErr = nil
+		}
+	}
+
+	if c.ifSourceNewer {
+		srcMod, dstMod := srcObj.ModTime, dstObj.ModTime
+
+		if !isNewer(*srcMod, *dstMod, c.modifyWindow) {
+			stickyErr = errorpkg.ErrObjectIsNewer
+		} else {
+			stickyErr = nil
+		}
+	}
+
+	// local edits are kept regardless of the other conditions.
+	if c.noOverwriteNewer && isNewer(*dstObj.ModTime, *srcObj.ModTime, c.modifyWindow) {
+		stickyErr = errorpkg.ErrObjectIsNewer
+	}
+
+	return stickyErr
+}
+
+// preconditions returns the ETag preconditions of the source object.
+func (c Copy) preconditions() storage.Metadata {
+	return storage.NewMetadata().
+		SetIfMatch(c.ifMatch).
+		SetIfNoneMatch(c.ifNoneMatch)
+}
+
+// objectName returns the name of the source object to be used at the
+// destination. Directory structure of the source is preserved for batch
+// operations unless flatten is set. If a transform expression is given, it is
+// applied to the resulting name. Names of the objects which are renamed to
+// resolve collisions are returned as is.
+func (c Copy) objectName(srcurl *url.URL, isBatch bool) string {
+	if name, ok := c.renames[srcurl.String()]; ok {
+		return name
+	}
+
+	objname := srcurl.Base()
+	if isBatch && !c.flatten {
+		objname = srcurl.Relative()
+	}
+	return c.transform.apply(objname)
+}
+
+// localObjectName returns the name of the object at the local destination.
+// Transformed names which would be written outside of the destination
+// directory are rejected.
+func (c Copy) localObjectName(srcurl *url.URL, isBatch bool) (string, error) {
+	objname := c.objectName(srcurl, isBatch)
+	if c.transform != nil && isOutsideDir(objname) {
+		return "", fmt.Errorf("transformed name %q is outside of the destination directory", objname)
+	}
+	return objname, nil
+}
+
+// prepareRemoteDestination will return a new destination URL for
+// remote->remote and local->remote copy operations.
+func prepareRemoteDestination(dsturl *url.URL, objname string) *url.URL {
+	if dsturl.IsPrefix() || dsturl.IsBucket() {
+		dsturl = dsturl.Join(objname)
+	}
+	return dsturl
+}
+
+// prepareDownloadDestination will return a new destination URL for
+// remote->local copy operations.
+func prepareLocalDestination(
+	ctx context.Context,
+	dsturl *url.URL,
+	objname string,
+	flatten bool,
+	isBatch bool,
+	storageOpts storage.Options,
+) (*url.URL, error) {
+	client := storage.NewLocalClient(storageOpts)
+
+	if isBatch {
+		err := client.MkdirAll(dsturl.Absolute())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	obj, err := client.Stat(ctx, dsturl)
+	if err != nil && err != storage.ErrGivenObjectNotFound {
+		return nil, err
+	}
+
+	if isBatch && !flatten {
+		dsturl = dsturl.Join(objname)
+		err := client.MkdirAll(dsturl.Dir())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err == storage.ErrGivenObjectNotFound {
+		err := client.MkdirAll(dsturl.Dir())
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(dsturl.Absolute(), "/") {
+			dsturl = dsturl.Join(objname)
+		}
+	} else {
+		if obj.Type.IsDir() {
+			dsturl = obj.URL.Join(objname)
+		}
+	}
+
+	return dsturl, nil
+}
+
+// localDestination returns the file the object would be downloaded to by
+// prepareLocalDestination, without creating any directories.
+func localDestination(
+	ctx context.Context,
+	client *storage.Filesystem,
+	dsturl *url.URL,
+	objname string,
+	isBatch bool,
+) (*url.URL, error) {
+	// directory of the batch operations is always created.
+	if isBatch {
+		return dsturl.Join(objname), nil
+	}
+
+	obj, err := client.Stat(ctx, dsturl)
+	if err == storage.ErrGivenObjectNotFound {
+		if strings.HasSuffix(dsturl.Absolute(), "/") {
+			return dsturl.Join(objname), nil
+		}
+		return dsturl, nil
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		parallel.Run(task, waiter)
+	if obj.Type.IsDir() {
+		return obj.URL.Join(objname), nil
 	}
+	return dsturl, nil
+}
 
-	waiter.Wait()
-	<-errDoneCh
+// getObject checks if the object from given url exists. If no object is
+// found, error and returning object would be nil.
+func getObject(ctx context.Context, url *url.URL, client storage.Storage) (*storage.Object, error) {
+	obj, err := client.Stat(ctx, url)
+	if err == storage.ErrGivenObjectNotFound {
+		return nil, nil
+	}
 
-	return merror
+	return obj, err
 }
 
-func (c Copy) prepareCopyTask(
-	ctx context.Context,
-	srcurl *url.URL,
-	dsturl *url.URL,
-	isBatch bool,
-) func() error {
-	return func() error {
-		dsturl = prepareRemoteDestination(srcurl, dsturl, c.flatten, isBatch)
-		err := c.doCopy(ctx, srcurl, dsturl)
-		if err != nil {
-			return &errorpkg.Error{
-				Op:  c.op,
-				Src: srcurl,
-				Dst: dsturl,
-				Err: err,
-			}
+func validateCopyCommand(c *cli.Context) error {
+	if c.String("source-file-list") != "" {
+		if c.Args().Len() != 1 {
+			return fmt.Errorf("expected only destination argument with --source-file-list")
 		}
-		return nil
+	} else if c.Args().Len() < 2 {
+		return fmt.Errorf("expected source and destination arguments")
 	}
-}
 
-func (c Copy) prepareDownloadTask(
-	ctx context.Context,
-	srcurl *url.URL,
-	dsturl *url.URL,
-	isBatch bool,
-) func() error {
-	return func() error {
-		dsturl, err := prepareLocalDestination(ctx, srcurl, dsturl, c.flatten, isBatch, c.storageOpts)
-		if err != nil {
+	if expr := c.String("transform"); expr != "" {
+		if _, err := parseTransform(expr); err != nil {
 			return err
 		}
+	}
 
-		err = c.doDownload(ctx, srcurl, dsturl)
-		if err != nil {
-			return &errorpkg.Error{
-				Op:  c.op,
-				Src: srcurl,
-				Dst: dsturl,
-				Err: err,
-			}
+	if tmpl := c.String("key-template"); tmpl != "" {
+		if _, err := parseKeyTemplate(tmpl); err != nil {
+			return err
 		}
-		return nil
 	}
-}
 
-func (c Copy) prepareUploadTask(
-	ctx context.Context,
-	srcurl *url.URL,
-	dsturl *url.URL,
-	isBatch bool,
-) func() error {
-	return func() error {
-		dsturl = prepareRemoteDestination(srcurl, dsturl, c.flatten, isBatch)
-		err := c.doUpload(ctx, srcurl, dsturl)
-		if err != nil {
-			return &errorpkg.Error{
-				Op:  c.op,
-				Src: srcurl,
-				Dst: dsturl,
-				Err: err,
-			}
-		}
-		return nil
+	if _, err := parseStorageClassRules(c.StringSlice("class-rule")); err != nil {
+		return err
 	}
-}
 
-// doDownload is used to fetch a remote object and save as a local object.
-func (c Copy) doDownload(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
-	srcClient, err := storage.NewRemoteClient(srcurl, c.storageOpts)
-	if err != nil {
+	if _, err := keyFilterFromFlags(c); err != nil {
 		return err
 	}
 
-	dstClient := storage.NewLocalClient(c.storageOpts)
+	if _, err := parseModTimeFilter(c.String("newer-than-file"), c.String("older-than-file")); err != nil {
+		return err
+	}
 
-	err = c.shouldOverride(ctx, srcurl, dsturl)
-	if err != nil {
-		// FIXME(ig): rename
-		if errorpkg.IsWarning(err) {
-			printDebug(c.op, srcurl, dsturl, err)
-			return nil
-		}
+	if _, err := minIASizeFromFlags(c); err != nil {
 		return err
 	}
 
-	file, err := dstClient.Create(dsturl.Absolute())
-	if err != nil {
+	if _, err := maxObjectSizeFromFlags(c); err != nil {
 		return err
 	}
 
-	defer file.Close()
+	if _, err := spaceMarginFromFlags(c); err != nil {
+		return err
+	}
+
+	if _, err := modifyWindowFromFlags(c); err != nil {
+		return err
+	}
+
+	if _, err := priorityFromFlags(c); err != nil {
+		return err
+	}
+
+	if err := validateWebsiteRedirect(c.String("website-redirect")); err != nil {
+		return err
+	}
+
+	if c.IsSet("modify-window") && !c.Bool("if-source-newer") && !c.Bool("no-overwrite-newer") {
+		return fmt.Errorf("--modify-window can only be used with --if-source-newer or --no-overwrite-newer")
+	}
+
+	if c.IsSet("space-margin") && !c.Bool("check-space") {
+		return fmt.Errorf("--space-margin can only be used with --check-space")
+	}
 
-	size, err := srcClient.Get(ctx, srcurl, file, c.concurrency, c.partSize)
+	lock, err := objectLockFromFlags(c)
 	if err != nil {
-		_ = dstClient.Delete(ctx, dsturl)
 		return err
 	}
 
-	if c.deleteSource {
-		_ = srcClient.Delete(ctx, srcurl)
+	meta, err := userMetadataFromFlags(c)
+	if err != nil {
+		return err
 	}
 
-	msg := log.InfoMessage{
-		Operation:   c.op,
-		Source:      srcurl,
-		Destination: dsturl,
-		Object: &storage.Object{
-			Size: size,
-		},
+	grants, err := grantsFromFlags(c)
+	if err != nil {
+		return err
 	}
-	log.Info(msg)
 
-	return nil
-}
+	if _, err := contentTypeMapFromFlags(c); err != nil {
+		return err
+	}
 
-func (c Copy) doUpload(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
-	srcClient := storage.NewLocalClient(c.storageOpts)
+	if _, err := inventoryFromFlags(c); err != nil {
+		return err
+	}
 
-	file, err := srcClient.Open(srcurl.Absolute())
+	list, err := fileListFromFlags(c)
 	if err != nil {
 		return err
 	}
 
-	defer file.Close()
+	if _, err := hooksFromFlags(c); err != nil {
+		return err
+	}
+
+	// S3 rejects the requests which have both a canned acl and grants.
+	if grants.isSet() && aclFromFlags(c) != "" {
+		return fmt.Errorf("grant options can not be used with --acl or --bucket-owner-full-control")
+	}
 
-	err = c.shouldOverride(ctx, srcurl, dsturl)
+	directive, err := metadataDirectiveFromFlags(c)
 	if err != nil {
-		if errorpkg.IsWarning(err) {
-			printDebug(c.op, srcurl, dsturl, err)
-			return nil
+		return err
+	}
+
+	if c.Bool("bucket-owner-full-control") {
+		if acl := c.String("acl"); acl != "" && acl != bucketOwnerFullControl {
+			return fmt.Errorf("--bucket-owner-full-control can not be used with --acl %v", acl)
 		}
+		if c.Bool("acl-public") {
+			return fmt.Errorf("--bucket-owner-full-control can not be used with --acl-public")
+		}
+	}
+
+	if c.Bool("acl-public") {
+		if acl := c.String("acl"); acl != "" && acl != aclPublicRead {
+			return fmt.Errorf("--acl-public can not be used with --acl %v", acl)
+		}
+	}
+
+	if c.Bool("compress") && c.Bool("content-md5") {
+		return fmt.Errorf("--content-md5 can not be used with --compress")
+	}
+
+	// parts of the compressed data are not known beforehand to be verified.
+	if c.Bool("compress") && c.Bool("resume-uploads") {
+		return fmt.Errorf("--resume-uploads can not be used with --compress")
+	}
+
+	if err := validateResumeUploadFlags(c); err != nil {
 		return err
 	}
 
-	dstClient, err := storage.NewRemoteClient(dsturl, c.storageOpts)
-	if err != nil {
+	if err := validateSinglePartFlags(c); err != nil {
 		return err
 	}
 
-	metadata := storage.NewMetadata().
-		SetContentType(guessContentType(file)).
-		SetStorageClass(string(c.storageClass)).
-		SetSSE(c.encryptionMethod).
-		SetSSEKeyID(c.encryptionKeyID).
-		SetACL(c.acl)
+	if c.Int("concurrency") < 1 {
+		return fmt.Errorf("--concurrency must be a positive value")
+	}
+
+	if partSize := c.Int("min-part-size"); c.IsSet("min-part-size") && (partSize < minPartSize || partSize > maxPartSize) {
+		return fmt.Errorf("--min-part-size must be between %v and %v MiB, the limits of the part sizes of multipart uploads", minPartSize, maxPartSize)
+	}
+
+	if c.Int("max-depth") < 0 {
+		return fmt.Errorf("--max-depth can not be negative")
+	}
+
+	if !isValidOnCollision(c.String("on-collision")) {
+		return fmt.Errorf("--on-collision must be one of %v", strings.Join(onCollisionValues, ", "))
+	}
+
+	if c.Int("retry-on-checksum-mismatch") < 0 {
+		return fmt.Errorf("--retry-on-checksum-mismatch can not be negative")
+	}
+
+	if c.Bool("verify-before-delete") && c.Command.Name != "mv" {
+		return fmt.Errorf("--verify-before-delete can only be used with mv")
+	}
+
+	if c.Bool("remove-empty-dirs") && c.Command.Name != "mv" {
+		return fmt.Errorf("--remove-empty-dirs can only be used with mv")
+	}
+
+	if c.Bool("only-missing") && c.Bool("force") {
+		return fmt.Errorf("--only-missing can not be used with --force")
+	}
+
+	if c.Bool("only-new-keys") && c.Bool("force") {
+		return fmt.Errorf("--only-new-keys can not be used with --force")
+	}
 
-	err = dstClient.Put(ctx, file, dsturl, metadata, c.concurrency, c.partSize)
+	if c.Bool("fail-on-skip") && c.Bool("only-missing") {
+		return fmt.Errorf("--fail-on-skip can not be used with --only-missing")
+	}
+
+	// names of the files are known once the objects are fetched, they can't
+	// be checked before the objects are queued.
+	if c.Bool("add-extension") && c.Bool("only-missing") {
+		return fmt.Errorf("--add-extension can not be used with --only-missing")
+	}
+
+	if c.Bool("create-empty-dirs") && c.Bool("flatten") {
+		return fmt.Errorf("--create-empty-dirs can not be used with --flatten")
+	}
+
+	if c.Bool("skip-placeholders") && c.Bool("create-empty-dirs") {
+		return fmt.Errorf("--skip-placeholders can not be used with --create-empty-dirs")
+	}
+
+	if c.Bool("keep-storage-class") && c.String("storage-class") != "" {
+		return fmt.Errorf("--keep-storage-class can not be used with --storage-class")
+	}
+
+	args := c.Args().Slice()
+	sources, dst := args[:len(args)-1], args[len(args)-1]
+
+	dsturl, err := url.New(dst)
 	if err != nil {
 		return err
 	}
 
-	obj, _ := srcClient.Stat(ctx, srcurl)
-	size := obj.Size
+	if list != nil {
+		if !dsturl.IsRemote() {
+			return fmt.Errorf("--source-file-list can only be used for uploads")
+		}
+		sources = list.sources()
+	}
 
-	if c.deleteSource {
-		// close the file before deleting
-		file.Close()
-		if err := srcClient.Delete(ctx, srcurl); err != nil {
+	// wildcard destination doesn't mean anything
+	if dsturl.HasGlob() {
+		return fmt.Errorf("target %q can not contain glob characters", dst)
+	}
+
+	// 'cp s3://bucket/object -': write to stdout
+	if dst == stdoutTarget {
+		if err := validateStdoutDownload(c.Command.Name, sources); err != nil {
 			return err
 		}
 	}
 
-	msg := log.InfoMessage{
-		Operation:   c.op,
-		Source:      srcurl,
-		Destination: dsturl,
-		Object: &storage.Object{
-			Size:         size,
-			StorageClass: c.storageClass,
-		},
+	if len(sources) > 1 {
+		if err := validateMultipleSources(c.Context, sources, dsturl, NewStorageOpts(c)); err != nil {
+			return err
+		}
 	}
-	log.Info(msg)
 
+	for _, src := range sources {
+		if err := validateCopySource(c, src, dsturl, lock, meta, directive, grants, len(sources) > 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateMultipleSources validates the sources of a copy operation with more
+// than one source. Objects of all sources are copied into the target, thus it
+// must be a bucket, a prefix or a directory.
+func validateMultipleSources(ctx context.Context, sources []string, dsturl *url.URL, storageOpts storage.Options) error {
+	for _, src := range sources {
+		if src == stdinSource {
+			return fmt.Errorf("reading from standard input can not be used with multiple sources")
+		}
+	}
+
+	if err := sourcesHaveSameType(sources...); err != nil {
+		return err
+	}
+
+	if dsturl.IsRemote() {
+		if !dsturl.IsBucket() && !dsturl.IsPrefix() {
+			return fmt.Errorf("target %q must be a bucket or a prefix", dsturl)
+		}
+		return nil
+	}
+
+	if strings.HasSuffix(dsturl.Absolute(), "/") {
+		return nil
+	}
+
+	obj, err := storage.NewLocalClient(storageOpts).Stat(ctx, dsturl)
+	if err != nil && err != storage.ErrGivenObjectNotFound {
+		return err
+	}
+	if obj == nil || !obj.Type.IsDir() {
+		return fmt.Errorf("target %q must be a directory", dsturl)
+	}
+	return nil
+}
+
+// singlePartFlag returns the name of the given flag which makes the files
+// uploaded in a single part, or an empty string if none is given.
+func singlePartFlag(c *cli.Context) string {
+	switch {
+	case c.Bool("disable-multipart"):
+		return "--disable-multipart"
+	case c.IsSet("multipart-threshold"):
+		return "--multipart-threshold"
+	default:
+		return ""
+	}
+}
+
+// validateSinglePartFlags validates --disable-multipart and
+// --multipart-threshold flags.
+func validateSinglePartFlags(c *cli.Context) error {
+	flag := singlePartFlag(c)
+	if flag == "" {
+		return nil
+	}
+
+	if c.Bool("disable-multipart") && c.IsSet("multipart-threshold") {
+		return fmt.Errorf("--disable-multipart can not be used with --multipart-threshold")
+	}
+
+	// size of the compressed data is not known beforehand.
+	if c.Bool("compress") {
+		return fmt.Errorf("%v can not be used with --compress", flag)
+	}
+
+	if c.IsSet("multipart-threshold") {
+		threshold := c.Int("multipart-threshold")
+		if threshold < c.Int("part-size") {
+			return fmt.Errorf("--multipart-threshold can not be less than --part-size")
+		}
+		if threshold > maxSinglePartSize {
+			return fmt.Errorf("--multipart-threshold can not be more than %v MiB, the maximum size of single part uploads", maxSinglePartSize)
+		}
+	}
+	return nil
+}
+
+// resumeUploadConflicts are the flags which set the metadata of the uploads,
+// other than the storage class. Metadata of the multipart uploads in progress
+// is not listed by S3, so it can't be compared with the flags to decide
+// whether an upload can be resumed.
+var resumeUploadConflicts = []string{
+	"sse",
+	"sse-kms-key-id",
+	"acl",
+	"bucket-owner-full-control",
+	"acl-public",
+	"grant-read",
+	"grant-read-acp",
+	"grant-write-acp",
+	"grant-full-control",
+	"object-lock-mode",
+	"object-lock-retain-until",
+	"legal-hold",
+	"meta",
+	"metadata-from-file",
+	"content-type-map",
+	"website-redirect",
+}
+
+// validateResumeUploadFlags validates the flags given with --resume-uploads.
+func validateResumeUploadFlags(c *cli.Context) error {
+	if !c.Bool("resume-uploads") {
+		return nil
+	}
+
+	for _, flag := range resumeUploadConflicts {
+		if c.IsSet(flag) {
+			return fmt.Errorf("--resume-uploads can not be used with --%v, metadata of the upload which is resumed can't be checked", flag)
+		}
+	}
 	return nil
 }
 
-func (c Copy) doCopy(ctx context.Context, srcurl, dsturl *url.URL) error {
-	srcClient, err := storage.NewClient(srcurl, c.storageOpts)
-	if err != nil {
-		return err
+// validateCopySource validates a source of a copy operation against the given
+// target and flags.
+func validateCopySource(
+	c *cli.Context,
+	src string,
+	dsturl *url.URL,
+	lock objectLock,
+	meta userMetadata,
+	directive metadataDirective,
+	grants grants,
+	multipleSources bool,
+) error {
+	ctx := c.Context
+
+	srcurl, err := url.New(src)
+	if err != nil {
+		return err
+	}
+
+	if c.String("key-template") != "" && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--key-template can only be used for uploading files")
+	}
+
+	// keys are only generated under a bucket or a prefix, a full object key
+	// would be used as is.
+	if c.String("key-template") != "" && !dsturl.IsBucket() && !dsturl.IsPrefix() {
+		return fmt.Errorf("--key-template can only be used with a bucket or a prefix destination, e.g. s3://bucket/prefix/")
+	}
+
+	if len(c.StringSlice("class-rule")) > 0 && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--class-rule can only be used for uploading files")
+	}
+
+	if c.String("min-ia-size") != "" && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--min-ia-size can only be used for uploading files")
 	}
 
-	metadata := storage.NewMetadata().
-		SetStorageClass(string(c.storageClass)).
-		SetSSE(c.encryptionMethod).
-		SetSSEKeyID(c.encryptionKeyID).
-		SetACL(c.acl)
+	if c.Bool("remove-empty-dirs") && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--remove-empty-dirs can only be used for uploading files")
+	}
 
-	err = c.shouldOverride(ctx, srcurl, dsturl)
-	if err != nil {
-		if errorpkg.IsWarning(err) {
-			printDebug(c.op, srcurl, dsturl, err)
-			return nil
-		}
-		return err
+	if flag := singlePartFlag(c); flag != "" && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("%v can only be used for uploading files", flag)
 	}
 
-	err = srcClient.Copy(ctx, srcurl, dsturl, metadata)
-	if err != nil {
-		return err
+	if c.Bool("resume-uploads") && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--resume-uploads can only be used for uploading files")
 	}
 
-	if c.deleteSource {
-		if err := srcClient.Delete(ctx, srcurl); err != nil {
-			return err
-		}
+	// the limits only apply to the parts of multipart uploads. mv doesn't use
+	// --part-size.
+	if partSize := c.Int("part-size"); c.Command.Name != "mv" && !srcurl.IsRemote() && dsturl.IsRemote() && (partSize < minPartSize || partSize > maxPartSize) {
+		return fmt.Errorf("--part-size must be between %v and %v MiB, the limits of the part sizes of multipart uploads", minPartSize, maxPartSize)
 	}
 
-	msg := log.InfoMessage{
-		Operation:   c.op,
-		Source:      srcurl,
-		Destination: dsturl,
-		Object: &storage.Object{
-			URL:          dsturl,
-			StorageClass: c.storageClass,
-		},
+	if c.String("content-type-map") != "" && (src == stdinSource || srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--content-type-map can only be used for uploading files")
 	}
-	log.Info(msg)
 
-	return nil
-}
+	if c.String("from-inventory") != "" && (src == stdinSource || !srcurl.IsRemote()) {
+		return fmt.Errorf("--from-inventory can only be used with remote sources")
+	}
 
-// shouldOverride function checks if the destination should be overridden if
-// the source-destination pair and given copy flags conform to the
-// override criteria. For example; "cp -n -s <src> <dst>" should not override
-// the <dst> if <src> and <dst> filenames are the same, except if the size
-// differs.
-func (c Copy) shouldOverride(ctx context.Context, srcurl *url.URL, dsturl *url.URL) error {
-	// if not asked to override, ignore.
-	if !c.noClobber && !c.ifSizeDiffer && !c.ifSourceNewer {
-		return nil
+	if lock.isSet() && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("object lock options can only be used for uploads")
 	}
 
-	srcClient, err := storage.NewClient(srcurl, c.storageOpts)
-	if err != nil {
-		return err
+	if meta.isSet() && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--meta and --metadata-from-file can only be used for uploads")
 	}
 
-	srcObj, err := getObject(ctx, srcurl, srcClient)
-	if err != nil {
-		return err
+	if grants.isSet() && !dsturl.IsRemote() {
+		return fmt.Errorf("grant options can only be used for uploads and copying S3 objects")
 	}
 
-	dstClient, err := storage.NewClient(dsturl, c.storageOpts)
-	if err != nil {
-		return err
+	if c.Bool("print-url") && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--print-url can only be used for uploads")
 	}
 
-	dstObj, err := getObject(ctx, dsturl, dstClient)
-	if err != nil {
-		return err
+	if c.Bool("only-missing") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--only-missing can only be used for downloads")
 	}
 
-	// if destination not exists, no conditions apply.
-	if dstObj == nil {
-		return nil
+	if c.Bool("replace-empty") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--replace-empty can only be used for downloads")
 	}
 
-	var stickyErr error
-	if c.noClobber {
-		stickyErr = errorpkg.ErrObjectExists
+	if c.Bool("no-overwrite-newer") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--no-overwrite-newer can only be used for downloads")
 	}
 
-	if c.ifSizeDiffer {
-		if srcObj.Size == dstObj.Size {
-			stickyErr = errorpkg.ErrObjectSizesMatch
-		} else {
-			stickyErr = nil
-		}
+	if c.Bool("atomic") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--atomic can only be used for downloads")
 	}
 
-	if c.ifSourceNewer {
-		srcMod, dstMod := srcObj.ModTime, dstObj.ModTime
+	if c.Int("retry-on-checksum-mismatch") > 0 && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--retry-on-checksum-mismatch can only be used for downloads")
+	}
 
-		if !srcMod.After(*dstMod) {
-			stickyErr = errorpkg.ErrObjectIsNewer
-		} else {
-			stickyErr = nil
-		}
+	if c.String("website-redirect") != "" && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--website-redirect can only be used for uploads")
 	}
 
-	return stickyErr
-}
+	if c.IsSet("min-part-size") && !dsturl.IsRemote() {
+		return fmt.Errorf("--min-part-size can only be used for uploads")
+	}
 
-// prepareRemoteDestination will return a new destination URL for
-// remote->remote and local->remote copy operations.
-func prepareRemoteDestination(
-	srcurl *url.URL,
-	dsturl *url.URL,
-	flatten bool,
-	isBatch bool,
-) *url.URL {
-	objname := srcurl.Base()
-	if isBatch && !flatten {
-		objname = srcurl.Relative()
+	if c.Bool("only-new-keys") && (srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--only-new-keys can only be used for uploads")
 	}
 
-	if dsturl.IsPrefix() || dsturl.IsBucket() {
-		dsturl = dsturl.Join(objname)
+	if c.Bool("check-space") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--check-space can only be used for downloads")
 	}
-	return dsturl
-}
 
-// prepareDownloadDestination will return a new destination URL for
-// remote->local copy operations.
-func prepareLocalDestination(
-	ctx context.Context,
-	srcurl *url.URL,
-	dsturl *url.URL,
-	flatten bool,
-	isBatch bool,
-	storageOpts storage.Options,
-) (*url.URL, error) {
-	objname := srcurl.Base()
-	if isBatch && !flatten {
-		objname = srcurl.Relative()
+	if c.Bool("create-empty-dirs") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--create-empty-dirs can only be used for downloads")
 	}
 
-	client := storage.NewLocalClient(storageOpts)
+	if c.Bool("skip-placeholders") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--skip-placeholders can only be used for downloads")
+	}
 
-	if isBatch {
-		err := client.MkdirAll(dsturl.Absolute())
-		if err != nil {
-			return nil, err
-		}
+	if c.Bool("add-extension") && (!srcurl.IsRemote() || dsturl.IsRemote()) {
+		return fmt.Errorf("--add-extension can only be used for downloads")
 	}
 
-	obj, err := client.Stat(ctx, dsturl)
-	if err != nil && err != storage.ErrGivenObjectNotFound {
-		return nil, err
+	if directive.isSet() && (!srcurl.IsRemote() || !dsturl.IsRemote()) {
+		return fmt.Errorf("--metadata-directive can only be used for copying S3 objects")
 	}
 
-	if isBatch && !flatten {
-		dsturl = dsturl.Join(objname)
-		err := client.MkdirAll(dsturl.Dir())
-		if err != nil {
-			return nil, err
+	if versionID := c.String("source-version-id"); versionID != "" {
+		if err := validateSourceVersionID(c, versionID, srcurl, dsturl, multipleSources); err != nil {
+			return err
 		}
 	}
 
-	if err == storage.ErrGivenObjectNotFound {
-		err := client.MkdirAll(dsturl.Dir())
-		if err != nil {
-			return nil, err
+	if c.Bool("copy-acl") {
+		if !srcurl.IsRemote() || !dsturl.IsRemote() {
+			return fmt.Errorf("--copy-acl can only be used for copying S3 objects")
 		}
-		if strings.HasSuffix(dsturl.Absolute(), "/") {
-			dsturl = dsturl.Join(objname)
+		if aclFromFlags(c) != "" {
+			return fmt.Errorf("--copy-acl can not be used with --acl or --bucket-owner-full-control")
 		}
-	} else {
-		if obj.Type.IsDir() {
-			dsturl = obj.URL.Join(objname)
+		if grants.isSet() {
+			return fmt.Errorf("--copy-acl can not be used with grant options")
 		}
 	}
 
-	return dsturl, nil
-}
-
-// getObject checks if the object from given url exists. If no object is
-// found, error and returning object would be nil.
-func getObject(ctx context.Context, url *url.URL, client storage.Storage) (*storage.Object, error) {
-	obj, err := client.Stat(ctx, url)
-	if err == storage.ErrGivenObjectNotFound {
-		return nil, nil
+	srcEndpoint, dstEndpoint := endpointFromFlags(c, "source"), endpointFromFlags(c, "dest")
+	if srcEndpoint.isSet() && !srcurl.IsRemote() {
+		return fmt.Errorf("source endpoint options can only be used with remote sources")
 	}
-
-	return obj, err
-}
-
-func validateCopyCommand(c *cli.Context) error {
-	if c.Args().Len() != 2 {
-		return fmt.Errorf("expected source and destination arguments")
+	if dstEndpoint.isSet() && !dsturl.IsRemote() {
+		return fmt.Errorf("dest endpoint options can only be used with remote targets")
 	}
 
-	ctx := c.Context
-	src := c.Args().Get(0)
-	dst := c.Args().Get(1)
-
-	srcurl, err := url.New(src)
-	if err != nil {
-		return err
+	// objects copied between different endpoints are streamed from the
+	// latest version of the source to the target.
+	opts := NewStorageOpts(c)
+	if srcurl.IsRemote() && dsturl.IsRemote() && srcEndpoint.storageOpts(opts) != dstEndpoint.storageOpts(opts) {
+		if c.Bool("copy-acl") {
+			return fmt.Errorf("--copy-acl can not be used with different source and dest endpoints")
+		}
+		if c.String("source-version-id") != "" {
+			return fmt.Errorf("--source-version-id can not be used with different source and dest endpoints")
+		}
 	}
 
-	dsturl, err := url.New(dst)
-	if err != nil {
-		return err
+	if c.Bool("trailing-slash-as-dir") && !dsturl.IsRemote() {
+		isBatch := multipleSources || srcurl.HasGlob()
+		if err := validateTrailingSlash(c.Context, dsturl, isBatch, NewStorageOpts(c)); err != nil {
+			return err
+		}
 	}
 
-	// wildcard destination doesn't mean anything
-	if dsturl.HasGlob() {
-		return fmt.Errorf("target %q can not contain glob characters", dst)
+	// 'cp - s3://bucket/object': upload from stdin
+	if src == stdinSource {
+		return validateStdinUpload(c.Command.Name, dsturl)
 	}
 
 	// we don't operate on S3 prefixes for copy and delete operations.
@@ -698,6 +3166,20 @@ func validateCopyCommand(c *cli.Context) error {
 		return fmt.Errorf("target %q must be a bucket or a prefix", dsturl)
 	}
 
+	if c.String("if-match") != "" || c.String("if-none-match") != "" {
+		if c.String("if-match") != "" && c.String("if-none-match") != "" {
+			return fmt.Errorf("--if-match can not be used with --if-none-match")
+		}
+
+		if multipleSources || !srcurl.IsRemote() || srcurl.HasGlob() {
+			return fmt.Errorf("--if-match and --if-none-match can only be used with a single remote source object")
+		}
+	}
+
+	if c.Bool("keep-storage-class") && !(srcurl.IsRemote() && dsturl.IsRemote()) {
+		return fmt.Errorf("--keep-storage-class can only be used for S3 to S3 copy")
+	}
+
 	switch {
 	case srcurl.Type == dsturl.Type:
 		return validateCopy(srcurl, dsturl)
@@ -708,6 +3190,62 @@ func validateCopyCommand(c *cli.Context) error {
 	}
 }
 
+func validateStdinUpload(op string, dsturl *url.URL) error {
+	if op != "cp" {
+		return fmt.Errorf("reading from standard input is only supported by cp command")
+	}
+
+	if !dsturl.IsRemote() {
+		return fmt.Errorf("target %q must be a remote object when reading from standard input", dsturl)
+	}
+
+	if dsturl.IsBucket() || dsturl.IsPrefix() {
+		return fmt.Errorf("target %q must be an object key when reading from standard input", dsturl)
+	}
+
+	return nil
+}
+
+// validateWebsiteRedirect checks that the redirect location is either an
+// absolute path in the bucket or a URL, as S3 requires.
+func validateWebsiteRedirect(location string) error {
+	if location == "" {
+		return nil
+	}
+
+	if strings.HasPrefix(location, "/") {
+		return nil
+	}
+
+	if u, err := urlpkg.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" {
+		return nil
+	}
+
+	return fmt.Errorf("--website-redirect must be a path starting with '/' or an http or https URL, got %q", location)
+}
+
+func validateStdoutDownload(op string, sources []string) error {
+	if op != "cp" {
+		return fmt.Errorf("writing to standard output is only supported by cp command")
+	}
+
+	if len(sources) > 1 {
+		return fmt.Errorf("writing to standard output can not be used with multiple sources")
+	}
+
+	srcurl, err := url.New(sources[0])
+	if err != nil {
+		return err
+	}
+
+	// objects would be interleaved on the output.
+	if !srcurl.IsRemote() || srcurl.HasGlob() || srcurl.IsBucket() || srcurl.IsPrefix() {
+		return fmt.Errorf("source %q must be a single remote object when writing to standard output", srcurl)
+	}
+
+	return nil
+}
+
 func validateCopy(srcurl, dsturl *url.URL) error {
 	if srcurl.IsRemote() || dsturl.IsRemote() {
 		return nil
@@ -738,6 +3276,34 @@ func validateUpload(ctx context.Context, srcurl, dsturl *url.URL, storageOpts st
 	return nil
 }
 
+// validateTrailingSlash checks the local target of --trailing-slash-as-dir.
+// Targets which end with a slash are directories, the same as the prefixes of
+// the remote targets, and the others are the paths of the files. "." and ".."
+// are always directories.
+func validateTrailingSlash(ctx context.Context, dsturl *url.URL, isBatch bool, storageOpts storage.Options) error {
+	path := dsturl.Absolute()
+	if base := filepath.Base(path); strings.HasSuffix(path, "/") || base == "." || base == ".." {
+		return nil
+	}
+
+	if isBatch {
+		return fmt.Errorf("target %q must end with a slash to copy multiple objects into it", dsturl)
+	}
+
+	obj, err := storage.NewLocalClient(storageOpts).Stat(ctx, dsturl)
+	if err == storage.ErrGivenObjectNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if obj.Type.IsDir() {
+		return fmt.Errorf("target %q is a directory, add a trailing slash to copy into it", dsturl)
+	}
+	return nil
+}
+
 // guessContentType gets content type of the file.
 func guessContentType(file *os.File) string {
 	contentType := mime.TypeByExtension(filepath.Ext(file.Name()))
@@ -755,6 +3321,131 @@ func guessContentType(file *os.File) string {
 	return contentType
 }
 
+// computeContentMD5 returns the base64 encoded MD5 digest of the file if the
+// file is small enough to be uploaded in a single part. Multipart uploads
+// can't make use of the digest, so an empty string is returned for them.
+func computeContentMD5(file *os.File, partSize int64) (string, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	if info.Size() > partSize {
+		return "", nil
+	}
+
+	defer file.Seek(0, io.SeekStart)
+
+	h := md5.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// minIASizeFromFlags returns the size given with --min-ia-size in bytes, or
+// zero if it's not given.
+func minIASizeFromFlags(c *cli.Context) (int64, error) {
+	expr := c.String("min-ia-size")
+	if expr == "" {
+		return 0, nil
+	}
+
+	size, err := parseSize(strings.TrimSpace(expr))
+	if err != nil {
+		return 0, fmt.Errorf("invalid --min-ia-size %q: %v", expr, err)
+	}
+	return size, nil
+}
+
+// maxVersionIDLength is the maximum length of the version IDs of S3 objects.
+const maxVersionIDLength = 1024
+
+// versionIDRegex matches the version IDs of S3 objects. Version IDs are
+// opaque, but they are URL-safe strings, possibly base64 encoded, and "null"
+// for the objects which are put before versioning is enabled.
+var versionIDRegex = regexp.MustCompile(`^[A-Za-z0-9._+/=-]+$`)
+
+// validateSourceVersionID validates --source-version-id. A version can only
+// be given for a single S3 object, and the source is not deleted since it
+// would only add a delete marker on the current version.
+func validateSourceVersionID(c *cli.Context, versionID string, srcurl, dsturl *url.URL, multipleSources bool) error {
+	if len(versionID) > maxVersionIDLength || !versionIDRegex.MatchString(versionID) {
+		return fmt.Errorf("invalid --source-version-id %q", versionID)
+	}
+
+	if c.Command.Name == "mv" {
+		return fmt.Errorf("--source-version-id can not be used with mv")
+	}
+
+	if !srcurl.IsRemote() || !dsturl.IsRemote() {
+		return fmt.Errorf("--source-version-id can only be used for copying S3 objects")
+	}
+
+	if multipleSources || srcurl.HasGlob() {
+		return fmt.Errorf("--source-version-id can only be used with a single source object")
+	}
+	return nil
+}
+
+// maxObjectSizeFromFlags returns the size given with --max-object-size in
+// bytes, or 0 if it's not given, which means there is no limit.
+func maxObjectSizeFromFlags(c *cli.Context) (int64, error) {
+	expr := c.String("max-object-size")
+	if expr == "" {
+		return 0, nil
+	}
+
+	size, err := parseSize(strings.TrimSpace(expr))
+	if err != nil {
+		return 0, fmt.Errorf("invalid --max-object-size %q: %v", expr, err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("invalid --max-object-size %q: must be a positive size", expr)
+	}
+	return size, nil
+}
+
+// spaceMarginFromFlags returns the size given with --space-margin in bytes, or
+// 0 if it's not given.
+func spaceMarginFromFlags(c *cli.Context) (int64, error) {
+	expr := c.String("space-margin")
+	if expr == "" {
+		return 0, nil
+	}
+
+	size, err := parseSize(strings.TrimSpace(expr))
+	if err != nil {
+		return 0, fmt.Errorf("invalid --space-margin %q: %v", expr, err)
+	}
+	return size, nil
+}
+
+// aclFromFlags returns the canned ACL to set on the target.
+func aclFromFlags(c *cli.Context) string {
+	switch {
+	case c.Bool("bucket-owner-full-control"):
+		return bucketOwnerFullControl
+	case c.Bool("acl-public"):
+		return aclPublicRead
+	default:
+		return c.String("acl")
+	}
+}
+
+// countingReader is an io.Reader which counts the number of bytes read from
+// the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func givenCommand(c *cli.Context) string {
 	return fmt.Sprintf("%v %v", c.Command.FullName(), strings.Join(c.Args().Slice(), " "))
 }
@@ -1,12 +1,26 @@
 package command
 
 import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"flag"
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
 )
 
 func TestGuessContentType(t *testing.T) {
@@ -62,3 +76,477 @@ func TestGuessContentType(t *testing.T) {
 		os.Remove(f.Name())
 	}
 }
+
+func TestComputeContentMD5(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		content  string
+		partSize int64
+
+		expected string
+	}{
+		{
+			name:     "empty file",
+			partSize: 10,
+			expected: "1B2M2Y8AsgTpgAmY7PhCfg==",
+		},
+		{
+			name:     "single-part upload",
+			content:  "this is a test file",
+			partSize: 100,
+			expected: "pYkKzjCj6E2RGBlsFhruwg==",
+		},
+		{
+			name:     "multipart upload",
+			content:  "this is a test file",
+			partSize: 10,
+			expected: "",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		f, err := ioutil.TempFile("", "")
+		if err != nil {
+			t.Error(err)
+		}
+
+		f.WriteString(tc.content)
+		f.Seek(0, io.SeekStart)
+
+		got, err := computeContentMD5(f, tc.partSize)
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, got, tc.name)
+
+		// file offset must be restored for the upload
+		offset, _ := f.Seek(0, io.SeekCurrent)
+		assert.Equal(t, int64(0), offset, tc.name)
+
+		f.Close()
+		os.Remove(f.Name())
+	}
+}
+
+// cp --content-md5 --part-size 5 --min-part-size 10 file s3://bucket/
+func TestComputeContentMD5WithMinPartSize(t *testing.T) {
+	t.Parallel()
+
+	c := Copy{
+		contentMD5:    true,
+		partSize:      5 * megabytes,
+		partSizeFloor: 10 * megabytes,
+	}
+
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	// the file is larger than --part-size, but it's smaller than
+	// --min-part-size, so it's uploaded in a single part.
+	content := strings.Repeat("x", 7*megabytes)
+	f.WriteString(content)
+	f.Seek(0, io.SeekStart)
+
+	got, err := computeContentMD5(f, c.singlePartLimit())
+	assert.NoError(t, err)
+
+	digest := md5.Sum([]byte(content))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(digest[:]), got)
+}
+
+func TestUploadsInSinglePart(t *testing.T) {
+	t.Parallel()
+
+	const partSize = 50 * megabytes
+
+	testcases := []struct {
+		name               string
+		disableMultipart   bool
+		multipartThreshold int64
+		size               int64
+
+		expected bool
+	}{
+		{
+			name:     "no flags",
+			size:     1,
+			expected: false,
+		},
+		{
+			name:             "disable multipart",
+			disableMultipart: true,
+			size:             10 * 1024 * megabytes,
+			expected:         true,
+		},
+		{
+			name:               "smaller than threshold",
+			multipartThreshold: 100 * megabytes,
+			size:               100 * megabytes,
+			expected:           true,
+		},
+		{
+			name:               "larger than threshold",
+			multipartThreshold: 100 * megabytes,
+			size:               100*megabytes + 1,
+			expected:           false,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := Copy{
+			partSize:           partSize,
+			disableMultipart:   tc.disableMultipart,
+			multipartThreshold: tc.multipartThreshold,
+		}
+		assert.Equal(t, tc.expected, c.uploadsInSinglePart(tc.size), tc.name)
+	}
+}
+
+func TestUploadPartSize(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name          string
+		partSize      int64
+		partSizeFloor int64
+		size          int64
+
+		expected int64
+	}{
+		{
+			name:     "fits in maximum number of parts",
+			partSize: 5 * megabytes,
+			size:     5 * megabytes * maxUploadParts,
+			expected: 5 * megabytes,
+		},
+		{
+			name:     "one byte more than maximum number of parts",
+			partSize: 5 * megabytes,
+			size:     5*megabytes*maxUploadParts + 1,
+			expected: 6 * megabytes,
+		},
+		{
+			name:     "rounded up to megabytes",
+			partSize: 50 * megabytes,
+			size:     1024 * 1024 * megabytes,
+			expected: 105 * megabytes,
+		},
+		{
+			name:          "minimum part size",
+			partSize:      50 * megabytes,
+			partSizeFloor: 1024 * megabytes,
+			size:          100 * 1024 * megabytes,
+			expected:      1024 * megabytes,
+		},
+		{
+			name:          "minimum part size less than part size",
+			partSize:      50 * megabytes,
+			partSizeFloor: 5 * megabytes,
+			size:          100 * 1024 * megabytes,
+			expected:      50 * megabytes,
+		},
+		{
+			name:          "minimum part size increased to fit maximum number of parts",
+			partSize:      50 * megabytes,
+			partSizeFloor: 100 * megabytes,
+			size:          2 * 1024 * 1024 * megabytes,
+			expected:      210 * megabytes,
+		},
+	}
+
+	for _, tc := range testcases {
+		c := Copy{partSize: tc.partSize, partSizeFloor: tc.partSizeFloor}
+		got := c.uploadPartSize(tc.size)
+		assert.Equal(t, tc.expected, got, tc.name)
+		assert.True(t, (tc.size+got-1)/got <= maxUploadParts, tc.name)
+	}
+}
+
+func TestUploadRejectsLargeSinglePartUploadsToAWS(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-single-part-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// the file is sparse, it's not written to the disk.
+	path := filepath.Join(dir, "large.iso")
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Truncate(maxSinglePartSize*megabytes+1))
+	assert.NoError(t, f.Close())
+
+	srcurl, err := url.New(path)
+	assert.NoError(t, err)
+
+	dsturl, err := url.New("s3://bucket/large.iso")
+	assert.NoError(t, err)
+
+	// the file is rejected before any request is sent.
+	c := Copy{disableMultipart: true}
+	_, err = c.upload(context.Background(), storage.NewLocalClient(storage.Options{}), nil, srcurl, dsturl)
+	assert.EqualError(t, err, "file is larger than 5120 MiB, the maximum size of single part uploads to S3, it can't be uploaded with --disable-multipart")
+}
+
+func TestCreateEmptyDirs(t *testing.T) {
+	// created directories are reported with info messages.
+	log.Init("error", false)
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-empty-dirs-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	srcurl, err := url.New("s3://bucket/prefix/*")
+	assert.NoError(t, err)
+
+	dsturl, err := url.New(dir)
+	assert.NoError(t, err)
+
+	// relative paths of the listed objects are set by matching them.
+	assert.True(t, srcurl.Match("prefix/a/empty/"))
+	placeholder := srcurl.Clone()
+	placeholder.Path = "prefix/a/empty/"
+	object := &storage.Object{URL: placeholder}
+
+	c := Copy{createEmptyDirs: true}
+
+	assert.True(t, c.createsEmptyDir(object, dsturl, true))
+	assert.False(t, c.createsEmptyDir(object, dsturl, false), "not a batch download")
+	assert.False(t, c.createsEmptyDir(object, srcurl, true), "not a download")
+	assert.False(t, Copy{}.createsEmptyDir(object, dsturl, true), "not asked for")
+
+	err = c.prepareMkdirTask(context.Background(), object, dsturl)()
+	assert.NoError(t, err)
+
+	info, err := os.Stat(filepath.Join(dir, "a", "empty"))
+	assert.NoError(t, err)
+	assert.True(t, info.IsDir())
+
+	// placeholder of the directory which the wildcard is in.
+	assert.True(t, srcurl.Match("prefix/"))
+	parent := srcurl.Clone()
+	parent.Path = "prefix/"
+
+	err = c.prepareMkdirTask(context.Background(), &storage.Object{URL: parent}, dsturl)()
+	assert.NoError(t, err)
+
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestIsPlaceholder(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		url      string
+		size     int64
+		expected bool
+	}{
+		{name: "placeholder", url: "s3://bucket/prefix/empty/", expected: true},
+		{name: "object with a trailing slash and data", url: "s3://bucket/prefix/data/", size: 5},
+		{name: "empty object", url: "s3://bucket/prefix/empty.txt"},
+		{name: "local directory", url: "dir/"},
+	}
+
+	for _, tc := range testcases {
+		u, err := url.New(tc.url)
+		assert.NoError(t, err)
+
+		object := &storage.Object{URL: u, Size: tc.size}
+		assert.Equal(t, tc.expected, isPlaceholder(object), tc.name)
+	}
+}
+
+func TestACLFromFlags(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name                   string
+		acl                    string
+		bucketOwnerFullControl bool
+		aclPublic              bool
+
+		expected string
+	}{
+		{
+			name:     "no acl",
+			expected: "",
+		},
+		{
+			name:     "acl",
+			acl:      "public-read",
+			expected: "public-read",
+		},
+		{
+			name:                   "bucket-owner-full-control",
+			bucketOwnerFullControl: true,
+			expected:               "bucket-owner-full-control",
+		},
+		{
+			name:      "acl-public",
+			aclPublic: true,
+			expected:  "public-read",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("cp", 0)
+			set.String("acl", tc.acl, "")
+			set.Bool("bucket-owner-full-control", tc.bucketOwnerFullControl, "")
+			set.Bool("acl-public", tc.aclPublic, "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			assert.Equal(t, tc.expected, aclFromFlags(ctx))
+		})
+	}
+}
+
+func TestShouldOverrideWithForce(t *testing.T) {
+	t.Parallel()
+
+	srcurl, err := url.New("s3://bucket/object")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dsturl, err := url.New("object")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// force takes precedence over all the other flags, the objects are not
+	// even looked up.
+	c := Copy{
+		noClobber:        true,
+		ifSizeDiffer:     true,
+		ifSourceNewer:    true,
+		noOverwriteNewer: true,
+		force:            true,
+	}
+
+	assert.NoError(t, c.shouldOverride(context.Background(), srcurl, dsturl))
+}
+
+func TestIsReplacedEmpty(t *testing.T) {
+	t.Parallel()
+
+	newObject := func(rawurl string, size int64) *storage.Object {
+		u, err := url.New(rawurl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &storage.Object{URL: u, Size: size}
+	}
+
+	testcases := []struct {
+		name         string
+		replaceEmpty bool
+		src          *storage.Object
+		dst          *storage.Object
+		expected     bool
+	}{
+		{
+			name:         "empty file",
+			replaceEmpty: true,
+			src:          newObject("s3://bucket/object", 10),
+			dst:          newObject("object", 0),
+			expected:     true,
+		},
+		{
+			name:     "empty file without replace empty",
+			src:      newObject("s3://bucket/object", 10),
+			dst:      newObject("object", 0),
+			expected: false,
+		},
+		{
+			name:         "non-empty file",
+			replaceEmpty: true,
+			src:          newObject("s3://bucket/object", 10),
+			dst:          newObject("object", 5),
+			expected:     false,
+		},
+		{
+			name:         "empty file of empty object",
+			replaceEmpty: true,
+			src:          newObject("s3://bucket/object", 0),
+			dst:          newObject("object", 0),
+			expected:     false,
+		},
+		{
+			name:         "empty remote object",
+			replaceEmpty: true,
+			src:          newObject("object", 10),
+			dst:          newObject("s3://bucket/object", 0),
+			expected:     false,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := Copy{replaceEmpty: tc.replaceEmpty}
+			assert.Equal(t, tc.expected, c.isReplacedEmpty(tc.src, tc.dst))
+		})
+	}
+}
+
+func TestAtomicDownloadURL(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-atomic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dsturl, err := url.New(filepath.Join(dir, "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := atomicDownloadURL(dsturl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := atomicDownloadURL(dsturl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// temporary files are created next to the destination, each with a
+	// different name.
+	assert.Equal(t, dir, filepath.Dir(first.Absolute()))
+	assert.Equal(t, dir, filepath.Dir(second.Absolute()))
+	assert.NotEqual(t, first.Absolute(), second.Absolute())
+
+	_, err = os.Stat(dsturl.Absolute())
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestACLError(t *testing.T) {
+	t.Parallel()
+
+	awsErr := awserr.New("AccessDenied", "Access Denied", nil)
+	err := error(&aclError{Err: awsErr})
+
+	// objects whose ACLs are not copied are reported apart from the failed
+	// copies, with the category of the underlying error.
+	assert.Equal(t, "object is copied, but its ACL is not: AccessDenied: Access Denied", err.Error())
+	assert.True(t, errors.Is(err, awsErr))
+	assert.Equal(t, errorpkg.CategoryAccessDenied, errorpkg.Classify(err))
+}
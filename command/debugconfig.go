@@ -0,0 +1,174 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+var debugConfigHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] [bucket]
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Print the region, the endpoint and the credentials provider used by the commands
+		 > s5cmd {{.HelpName}}
+
+	2. Print the configuration of the clients created with the given profile and region
+		 > s5cmd {{.HelpName}} --profile backup --region eu-west-1
+
+	3. Print the configuration of the clients of a custom endpoint in JSON
+		 > s5cmd --json --endpoint-url https://storage.googleapis.com {{.HelpName}}
+
+	4. Print the configuration of the clients of a bucket, with the region detected from its location
+		 > s5cmd {{.HelpName}} s3://bucket
+
+The configuration is resolved as the other commands do, from the flags, the
+environment variables and the shared config files, in that order of
+precedence. Secrets are never printed, only the name of the provider the
+credentials are retrieved from. If the region is not given, the other
+commands use the region of the buckets on AWS S3. It's only detected if a
+bucket is given, which requires a GetBucketLocation request, otherwise no S3
+requests are made.
+`
+
+var debugConfigCommand = &cli.Command{
+	Name:               "debug-config",
+	HelpName:           "debug-config",
+	Usage:              "print the resolved configuration of the S3 clients",
+	CustomHelpTemplate: debugConfigHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "region",
+			Usage: "region of the clients, instead of the one in the environment variables or the profile",
+		},
+		&cli.StringFlag{
+			Name:  "profile",
+			Usage: "profile of the shared config files, instead of the one in the environment variables",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateDebugConfigCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		opts := endpoint{
+			region:  c.String("region"),
+			profile: c.String("profile"),
+		}.storageOpts(NewStorageOpts(c))
+
+		var bucket *url.URL
+		if c.Args().Present() {
+			bucket, err = url.New(c.Args().First())
+			if err != nil {
+				printError(givenCommand(c), c.Command.Name, err)
+				return err
+			}
+		}
+
+		cfg, err := storage.ResolveConfig(c.Context, bucket, opts)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
+		log.Info(newConfigMessage(cfg))
+		return nil
+	},
+}
+
+func validateDebugConfigCommand(c *cli.Context) error {
+	if c.Args().Len() > 1 {
+		return fmt.Errorf("expected at most one bucket argument")
+	}
+	if !c.Args().Present() {
+		return nil
+	}
+
+	bucket, err := url.New(c.Args().First())
+	if err != nil {
+		return err
+	}
+	if !bucket.IsRemote() || !bucket.IsBucket() {
+		return fmt.Errorf("argument must be a bucket, e.g. s3://bucket")
+	}
+	return nil
+}
+
+// ConfigMessage is the structure for logging the resolved configuration of
+// the S3 clients.
+type ConfigMessage struct {
+	Region           string `json:"region"`
+	RegionSource     string `json:"region_source"`
+	Endpoint         string `json:"endpoint"`
+	Profile          string `json:"profile"`
+	ProfileSource    string `json:"profile_source"`
+	SharedConfig     bool   `json:"shared_config"`
+	PathStyle        bool   `json:"path_style"`
+	Accelerate       bool   `json:"accelerate"`
+	MaxRetries       int    `json:"max_retries"`
+	Credentials      string `json:"credentials,omitempty"`
+	CredentialsError string `json:"credentials_error,omitempty"`
+}
+
+func newConfigMessage(cfg *storage.Config) ConfigMessage {
+	msg := ConfigMessage{
+		Region:        cfg.Region,
+		RegionSource:  cfg.RegionSource,
+		Endpoint:      cfg.Endpoint,
+		Profile:       cfg.Profile,
+		ProfileSource: cfg.ProfileSource,
+		SharedConfig:  cfg.SharedConfig,
+		PathStyle:     cfg.PathStyle,
+		Accelerate:    cfg.Accelerate,
+		MaxRetries:    cfg.MaxRetries,
+		Credentials:   cfg.Credentials,
+	}
+	if cfg.CredentialsErr != nil {
+		msg.CredentialsError = cleanupError(cfg.CredentialsErr)
+	}
+	return msg
+}
+
+// String returns the string representation of ConfigMessage, one setting per
+// line.
+func (m ConfigMessage) String() string {
+	credentials := m.Credentials
+	if m.CredentialsError != "" {
+		credentials = fmt.Sprintf("none (%v)", m.CredentialsError)
+	}
+
+	lines := []string{
+		fmt.Sprintf("region: %v (%v)", m.Region, m.RegionSource),
+		fmt.Sprintf("endpoint: %v", m.Endpoint),
+		fmt.Sprintf("profile: %v (%v)", m.Profile, m.ProfileSource),
+		fmt.Sprintf("shared-config: %v", m.SharedConfig),
+		fmt.Sprintf("credentials: %v", credentials),
+		fmt.Sprintf("path-style: %v", m.PathStyle),
+		fmt.Sprintf("accelerate: %v", m.Accelerate),
+		fmt.Sprintf("max-retries: %v", m.MaxRetries),
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON returns the JSON representation of ConfigMessage.
+func (m ConfigMessage) JSON() string {
+	return strutil.JSON(m)
+}
@@ -10,6 +10,7 @@ import (
 	errorpkg "github.com/peak/s5cmd/error"
 	"github.com/peak/s5cmd/log"
 	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage"
 	"github.com/peak/s5cmd/storage/url"
 	"github.com/peak/s5cmd/strutil"
@@ -19,7 +20,7 @@ var sizeHelpTemplate = `Name:
 	{{.HelpName}} - {{.Usage}}
 
 Usage:
-	{{.HelpName}} [options] argument
+	{{.HelpName}} [options] argument [argument]
 
 Options:
 	{{range .VisibleFlags}}{{.}}
@@ -30,6 +31,15 @@ Examples:
 
 	2. Show disk usage of all objects that match a wildcard, grouped by storage class
 		 > s5cmd {{.HelpName}} --group s3://bucket/prefix/obj*.gz
+
+	3. Show disk usage of the objects under multiple prefixes, and their total usage
+		 > s5cmd {{.HelpName}} s3://bucket/logs/* s3://bucket/backups/*
+
+	4. Count the objects under a prefix, without their sizes
+		 > s5cmd {{.HelpName}} --count-only s3://bucket/logs/*
+
+	5. Show disk usage of the objects under a prefix from an S3 Inventory report of the bucket
+		 > s5cmd {{.HelpName}} --from-inventory s3://bucket/inventory/manifest.json s3://bucket/logs/*
 `
 
 var sizeCommand = &cli.Command{
@@ -48,6 +58,12 @@ var sizeCommand = &cli.Command{
 			Aliases: []string{"H"},
 			Usage:   "human-readable output for object sizes",
 		},
+		&cli.BoolFlag{
+			Name:  "count-only",
+			Usage: "only print the number of objects, without their sizes",
+		},
+		fromInventoryFlag,
+		progressFlag,
 	},
 	Before: func(c *cli.Context) error {
 		err := validateDUCommand(c)
@@ -59,13 +75,22 @@ var sizeCommand = &cli.Command{
 	Action: func(c *cli.Context) (err error) {
 		defer stat.Collect(c.Command.FullName(), &err)()
 
+		inventory, err := inventoryFromFlags(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
 		return Size{
-			src:         c.Args().First(),
+			src:         c.Args().Slice(),
 			op:          c.Command.Name,
 			fullCommand: givenCommand(c),
 			// flags
 			groupByClass: c.Bool("group"),
 			humanize:     c.Bool("humanize"),
+			countOnly:    c.Bool("count-only"),
+			showProgress: progressFromFlags(c),
+			inventory:    inventory,
 
 			storageOpts: NewStorageOpts(c),
 		}.Run(c.Context)
@@ -74,75 +99,178 @@ var sizeCommand = &cli.Command{
 
 // Size holds disk usage (du) operation flags and states.
 type Size struct {
-	src         string
+	src         []string
 	op          string
 	fullCommand string
 
 	// flags
 	groupByClass bool
 	humanize     bool
+	countOnly    bool
+	showProgress bool
+	inventory    *url.URL
 
 	storageOpts storage.Options
 }
 
-// Run calculates disk usage of given source.
+// Run calculates disk usage of given sources. If more than one source is
+// given, the total usage is printed after the usage of each source. Objects
+// matched by more than one source are counted once in the total.
 func (sz Size) Run(ctx context.Context) error {
-	srcurl, err := url.New(sz.src)
-	if err != nil {
-		return err
-	}
-
-	client, err := storage.NewClient(srcurl, sz.storageOpts)
+	srcurls, err := newURLs(sz.src...)
 	if err != nil {
 		printError(sz.fullCommand, sz.op, err)
 		return err
 	}
 
-	storageTotal := map[string]sizeAndCount{}
-	total := sizeAndCount{}
+	clients := make([]storage.Storage, 0, len(srcurls))
+	for _, srcurl := range srcurls {
+		client, err := storage.NewClient(ctx, srcurl, sz.storageOpts)
+		if err != nil {
+			printError(sz.fullCommand, sz.op, err)
+			return err
+		}
+		clients = append(clients, withInventory(client, sz.inventory))
+	}
 
 	var merror error
 
-	for object := range client.List(ctx, srcurl, false) {
-		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
-			continue
+	var seen map[string]struct{}
+	if len(srcurls) > 1 {
+		seen = map[string]struct{}{}
+	}
+
+	usages := make([]sizeUsage, 0, len(srcurls))
+	total := newSizeUsage(sizeTotalSource)
+
+	progress := startListProgress(sz.showProgress)
+
+	var interrupted bool
+	for i, srcurl := range srcurls {
+		if parallel.IsDraining() {
+			interrupted = true
+			break
 		}
 
-		if err := object.Err; err != nil {
-			merror = multierror.Append(merror, err)
-			printError(sz.fullCommand, sz.op, err)
-			continue
+		client := clients[i]
+		usage := newSizeUsage(srcurl.String())
+
+		listctx, cancelList := context.WithCancel(ctx)
+		objch := client.List(listctx, srcurl, false)
+		for object := range objch {
+			if parallel.IsDraining() {
+				interrupted = true
+				discardObjects(cancelList, objch)
+				break
+			}
+
+			progress.Add()
+
+			if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+				continue
+			}
+
+			if err := object.Err; err != nil {
+				merror = multierror.Append(merror, err)
+				printError(sz.fullCommand, sz.op, err)
+				continue
+			}
+
+			usage.addObject(object)
+
+			if seen != nil {
+				key := object.URL.String()
+				if _, ok := seen[key]; ok {
+					continue
+				}
+				seen[key] = struct{}{}
+			}
+			total.addObject(object)
 		}
-		storageClass := string(object.StorageClass)
-		s := storageTotal[storageClass]
-		s.addObject(object)
-		storageTotal[storageClass] = s
+		cancelList()
+
+		usages = append(usages, usage)
+	}
+
+	progress.Stop()
+
+	// usages of the partial listings are not printed, they would be taken
+	// as the totals.
+	if interrupted {
+		return appendInterrupted(merror, sz.fullCommand, sz.op)
+	}
+
+	if len(srcurls) > 1 {
+		usages = append(usages, total)
+	}
 
-		total.addObject(object)
+	for _, usage := range usages {
+		sz.printUsage(usage)
 	}
 
 	if !sz.groupByClass {
-		msg := SizeMessage{
-			Source:        srcurl.String(),
-			Count:         total.count,
-			Size:          total.size,
-			showHumanized: sz.humanize,
-		}
-		log.Info(msg)
 		return nil
 	}
+	return merror
+}
+
+// sizeTotalSource is the source of the total usage of multiple sources.
+const sizeTotalSource = "total"
+
+// sizeUsage is the disk usage of a source, by storage class.
+type sizeUsage struct {
+	source  string
+	total   sizeAndCount
+	byClass map[string]sizeAndCount
+}
+
+func newSizeUsage(source string) sizeUsage {
+	return sizeUsage{
+		source:  source,
+		byClass: map[string]sizeAndCount{},
+	}
+}
+
+func (u *sizeUsage) addObject(obj *storage.Object) {
+	storageClass := string(obj.StorageClass)
+	s := u.byClass[storageClass]
+	s.addObject(obj)
+	u.byClass[storageClass] = s
+
+	u.total.addObject(obj)
+}
+
+// printUsage prints the usage of a source, either in total or by storage
+// class.
+func (sz Size) printUsage(usage sizeUsage) {
+	if !sz.groupByClass {
+		log.Info(sz.usageMessage(usage.source, "", usage.total))
+		return
+	}
+
+	for k, v := range usage.byClass {
+		log.Info(sz.usageMessage(usage.source, k, v))
+	}
+}
 
-	for k, v := range storageTotal {
-		msg := SizeMessage{
-			Source:        srcurl.String(),
-			StorageClass:  k,
-			Count:         v.count,
-			Size:          v.size,
-			showHumanized: sz.humanize,
+// usageMessage returns the message of the usage of a source, which only has
+// the number of objects if --count-only is given.
+func (sz Size) usageMessage(source, storageClass string, usage sizeAndCount) log.Message {
+	if sz.countOnly {
+		return CountMessage{
+			Source:       source,
+			StorageClass: storageClass,
+			Count:        usage.count,
 		}
-		log.Info(msg)
 	}
-	return merror
+
+	return SizeMessage{
+		Source:        source,
+		StorageClass:  storageClass,
+		Count:         usage.count,
+		Size:          usage.size,
+		showHumanized: sz.humanize,
+	}
 }
 
 // SizeMessage is the structure for logging disk usage.
@@ -183,6 +311,27 @@ func (s SizeMessage) JSON() string {
 	return strutil.JSON(s)
 }
 
+// CountMessage is the structure for logging the number of objects.
+type CountMessage struct {
+	Source       string `json:"source"`
+	StorageClass string `json:"storage_class,omitempty"`
+	Count        int64  `json:"count"`
+}
+
+// String returns the string representation of CountMessage.
+func (c CountMessage) String() string {
+	var storageCls string
+	if c.StorageClass != "" {
+		storageCls = fmt.Sprintf(" [%s]", c.StorageClass)
+	}
+	return fmt.Sprintf("%d objects: %s%s", c.Count, c.Source, storageCls)
+}
+
+// JSON returns the JSON representation of CountMessage.
+func (c CountMessage) JSON() string {
+	return strutil.JSON(c)
+}
+
 type sizeAndCount struct {
 	size  int64
 	count int64
@@ -194,8 +343,12 @@ func (s *sizeAndCount) addObject(obj *storage.Object) {
 }
 
 func validateDUCommand(c *cli.Context) error {
-	if c.Args().Len() != 1 {
-		return fmt.Errorf("expected only 1 argument")
+	if !c.Args().Present() {
+		return fmt.Errorf("expected at least 1 argument")
+	}
+
+	if c.Bool("count-only") && c.Bool("humanize") {
+		return fmt.Errorf("--count-only can not be used with --humanize")
 	}
-	return nil
+	return validateInventorySources(c, c.Args().Slice())
 }
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// emptyDirs records the directories of the moved files, so that the ones
+// which are emptied by the move are removed once all files are moved.
+type emptyDirs struct {
+	mu sync.Mutex
+	// roots are the directories of the sources. They are never removed, nor
+	// any directory above them.
+	roots []string
+	dirs  map[string]struct{}
+}
+
+func newEmptyDirs() *emptyDirs {
+	return &emptyDirs{dirs: map[string]struct{}{}}
+}
+
+// addSource adds the directory of a local source which is expanded to
+// multiple files, i.e. "dir" for both "dir/" and "dir/*.txt".
+func (e *emptyDirs) addSource(srcurl *url.URL) {
+	root := srcurl.Absolute()
+	if srcurl.HasGlob() {
+		root = filepath.Dir(filepath.FromSlash(srcurl.Prefix))
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.roots = append(e.roots, root)
+}
+
+// addFile records the directory of a moved file.
+func (e *emptyDirs) addFile(path string) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.dirs[dir] = struct{}{}
+}
+
+// remove removes the recorded directories which are empty, deepest first,
+// along with their parents which are emptied as a result, up to the root of
+// their source. Directories which were empty before the move are never
+// removed, since they don't contain any moved file or a removed directory.
+// Directories which can't be removed, e.g. because they are not empty, are
+// left as is.
+func (e *emptyDirs) remove() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	dirs := make([]string, 0, len(e.dirs))
+	for dir := range e.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+
+	for _, dir := range dirs {
+		root := e.rootOf(dir)
+		if root == "" {
+			continue
+		}
+
+		for dir != root {
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+}
+
+// rootOf returns the deepest root which contains the given directory, or an
+// empty string if there is no such root.
+func (e *emptyDirs) rootOf(dir string) string {
+	var found string
+	for _, root := range e.roots {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(root) > len(found) {
+			found = root
+		}
+	}
+	return found
+}
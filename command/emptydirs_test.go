@@ -0,0 +1,66 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestEmptyDirsRemove(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-emptydirs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	root := filepath.Join(dir, "root")
+	for _, d := range []string{"a/b", "c", "empty"} {
+		if err := os.MkdirAll(filepath.Join(root, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "c", "kept.txt"), []byte("kept"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	srcurl, err := url.New(root + "/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// files in a/b, c and root are moved, the one in c is not.
+	e := newEmptyDirs()
+	e.addSource(srcurl)
+	e.addFile(filepath.Join(root, "a", "b", "moved.txt"))
+	e.addFile(filepath.Join(root, "c", "moved.txt"))
+	e.addFile(filepath.Join(root, "moved.txt"))
+	e.remove()
+
+	_, err = os.Stat(filepath.Join(root, "a"))
+	assert.True(t, os.IsNotExist(err))
+	assert.DirExists(t, filepath.Join(root, "c"))
+	assert.DirExists(t, filepath.Join(root, "empty"))
+	assert.DirExists(t, root)
+}
+
+func TestEmptyDirsRootOf(t *testing.T) {
+	t.Parallel()
+
+	root := filepath.FromSlash("/data/root")
+	nested := filepath.FromSlash("/data/root/nested")
+
+	e := newEmptyDirs()
+	e.roots = []string{root, nested}
+
+	assert.Equal(t, root, e.rootOf(filepath.FromSlash("/data/root/a")))
+	assert.Equal(t, nested, e.rootOf(filepath.FromSlash("/data/root/nested/a/b")))
+	assert.Equal(t, "", e.rootOf(filepath.FromSlash("/data/rootless/a")))
+	assert.Equal(t, "", e.rootOf(filepath.FromSlash("/data")))
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"context"
+	"strings"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// emptyPrefixes collects the directory placeholders of a recursive listing,
+// i.e. zero-byte objects whose keys end with '/', along with the prefixes
+// which have objects under them. Placeholders whose prefixes have no objects
+// are known once the listing is complete.
+type emptyPrefixes struct {
+	placeholders []*storage.Object
+	nonEmpty     map[string]struct{}
+}
+
+func newEmptyPrefixes() *emptyPrefixes {
+	return &emptyPrefixes{nonEmpty: map[string]struct{}{}}
+}
+
+// add records the listed object. All prefixes of an object which is not a
+// placeholder are marked as non-empty.
+func (e *emptyPrefixes) add(object *storage.Object) {
+	key := object.URL.Path
+	if strings.HasSuffix(key, "/") {
+		e.placeholders = append(e.placeholders, object)
+		return
+	}
+
+	for i := strings.LastIndex(key, "/"); i >= 0; i = strings.LastIndex(key[:i], "/") {
+		e.nonEmpty[key[:i+1]] = struct{}{}
+	}
+}
+
+// empty returns the placeholders which have no objects under them, in the
+// order they are listed.
+func (e *emptyPrefixes) empty() []*storage.Object {
+	var objects []*storage.Object
+	for _, object := range e.placeholders {
+		if _, ok := e.nonEmpty[object.URL.Path]; !ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// isEmptyPrefix reports whether the given prefix has no objects under it,
+// other than the directory placeholders. The prefix is listed until the first
+// object is found.
+func isEmptyPrefix(ctx context.Context, client storage.Storage, prefix *url.URL) (bool, error) {
+	listurl, err := url.New(prefix.String())
+	if err != nil {
+		return false, err
+	}
+
+	listurl, err = listurl.Recursive()
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objch := client.List(ctx, listurl, false)
+
+	// the listing is stopped once an object is found. The rest of the
+	// objects are consumed to let the listing finish.
+	defer func() {
+		cancel()
+		for range objch {
+		}
+	}()
+
+	for object := range objch {
+		if object.Err == storage.ErrNoObjectFound || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			return false, err
+		}
+
+		if !object.Type.IsDir() {
+			return false, nil
+		}
+	}
+	return true, ctx.Err()
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestEmptyPrefixes(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{
+		"a/",
+		"a/b/",
+		"a/b/file.txt",
+		"a/c/",
+		"a/c/d/",
+		"e/",
+		"e/f/g/file.txt",
+		"h/",
+	}
+
+	e := newEmptyPrefixes()
+	for _, key := range keys {
+		u, err := url.New("s3://bucket/" + key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.add(&storage.Object{URL: u})
+	}
+
+	var got []string
+	for _, object := range e.empty() {
+		got = append(got, object.URL.Path)
+	}
+
+	assert.Equal(t, []string{"a/c/", "a/c/d/", "h/"}, got)
+}
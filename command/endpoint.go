@@ -0,0 +1,48 @@
+package command
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// endpoint holds the options of the S3 client of the source or the
+// destination, which override the global ones. It's used to transfer objects
+// between different S3 compatible services.
+type endpoint struct {
+	url     string
+	region  string
+	profile string
+}
+
+// endpointFromFlags returns the endpoint options given with the flags of the
+// given prefix, i.e. "source" or "dest".
+func endpointFromFlags(c *cli.Context, prefix string) endpoint {
+	return endpoint{
+		url:     c.String(prefix + "-endpoint"),
+		region:  c.String(prefix + "-region"),
+		profile: c.String(prefix + "-profile"),
+	}
+}
+
+// isSet reports whether any of the endpoint options is given.
+func (e endpoint) isSet() bool {
+	return e.url != "" || e.region != "" || e.profile != ""
+}
+
+// storageOpts returns the global storage options overridden with the given
+// endpoint options.
+func (e endpoint) storageOpts(opts storage.Options) storage.Options {
+	if e.url != "" {
+		opts.Endpoint = e.url
+		// transfer acceleration is only available for AWS S3.
+		opts.UseAccelerate = false
+	}
+	if e.region != "" {
+		opts.Region = e.region
+	}
+	if e.profile != "" {
+		opts.Profile = e.profile
+	}
+	return opts
+}
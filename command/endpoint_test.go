@@ -0,0 +1,77 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestEndpointStorageOpts(t *testing.T) {
+	t.Parallel()
+
+	global := storage.Options{
+		Endpoint:      "http://localhost:9000",
+		Region:        "us-east-1",
+		UseAccelerate: true,
+		MaxRetries:    3,
+	}
+
+	testcases := []struct {
+		name     string
+		endpoint endpoint
+		expected storage.Options
+	}{
+		{
+			name:     "not set",
+			expected: global,
+		},
+		{
+			name:     "endpoint",
+			endpoint: endpoint{url: "https://nyc3.digitaloceanspaces.com"},
+			expected: storage.Options{
+				Endpoint:   "https://nyc3.digitaloceanspaces.com",
+				Region:     "us-east-1",
+				MaxRetries: 3,
+			},
+		},
+		{
+			name:     "region and profile",
+			endpoint: endpoint{region: "eu-west-1", profile: "spaces"},
+			expected: storage.Options{
+				Endpoint:      "http://localhost:9000",
+				Region:        "eu-west-1",
+				Profile:       "spaces",
+				UseAccelerate: true,
+				MaxRetries:    3,
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.endpoint.isSet(), tc.expected != global)
+			assert.Equal(t, tc.expected, tc.endpoint.storageOpts(global))
+		})
+	}
+}
+
+func TestCopyCrossEndpoint(t *testing.T) {
+	t.Parallel()
+
+	c := Copy{}
+	assert.False(t, c.crossEndpoint(), "no endpoint options")
+
+	c = Copy{
+		srcEndpoint: endpoint{url: "http://localhost:9000"},
+		dstEndpoint: endpoint{url: "http://localhost:9000"},
+	}
+	assert.False(t, c.crossEndpoint(), "same endpoint")
+
+	c = Copy{dstEndpoint: endpoint{profile: "other"}}
+	assert.True(t, c.crossEndpoint(), "different profile")
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -8,9 +9,44 @@ import (
 
 	errorpkg "github.com/peak/s5cmd/error"
 	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage/url"
 )
 
+// ExitError is returned by the commands which report their results with the
+// exit status, i.e. exists.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap unwraps the error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit status of the program for the error returned from
+// Main. Errors which are all in the same category have the exit status of the
+// category, others have 1. Commands which fail after an interrupt have the
+// exit status of the Interrupted category, since the operations aborted by the
+// second interrupt only fail with cancelation errors.
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if parallel.IsInterrupted() {
+		return errorpkg.CategoryInterrupted.ExitCode()
+	}
+	return errorpkg.Classify(err).ExitCode()
+}
+
 func printDebug(op string, src, dst *url.URL, err error) {
 	msg := log.DebugMessage{
 		Command:   fmt.Sprintf("%v %v %v", op, src, dst),
@@ -20,6 +56,10 @@ func printDebug(op string, src, dst *url.URL, err error) {
 	log.Debug(msg)
 }
 
+// stopOnError is called after an error is printed. It cancels the remaining
+// operations if --stop-on-first-error is given, and does nothing otherwise.
+var stopOnError = func() {}
+
 // printError is the helper function to log error messages.
 func printError(command, op string, err error) {
 	// dont print cancelation errors
@@ -27,16 +67,20 @@ func printError(command, op string, err error) {
 		return
 	}
 
+	defer stopOnError()
+
 	// check if we have our own error type
 	{
 		cerr, ok := err.(*errorpkg.Error)
 		if ok {
 			msg := log.ErrorMessage{
 				Err:       cleanupError(cerr.Err),
+				Category:  string(errorpkg.Classify(cerr.Err)),
 				Command:   cerr.FullCommand(),
 				Operation: cerr.Op,
 			}
 			log.Error(msg)
+			addFailure(cerr)
 			return
 		}
 	}
@@ -50,15 +94,18 @@ func printError(command, op string, err error) {
 				if ok {
 					msg := log.ErrorMessage{
 						Err:       cleanupError(customErr.Err),
+						Category:  string(errorpkg.Classify(customErr.Err)),
 						Command:   customErr.FullCommand(),
 						Operation: customErr.Op,
 					}
 					log.Error(msg)
+					addFailure(customErr)
 					continue
 				}
 
 				msg := log.ErrorMessage{
 					Err:       cleanupError(err),
+					Category:  string(errorpkg.Classify(err)),
 					Command:   command,
 					Operation: op,
 				}
@@ -72,12 +119,38 @@ func printError(command, op string, err error) {
 	// we don't know the exact error type. log the error as is.
 	msg := log.ErrorMessage{
 		Err:       cleanupError(err),
+		Category:  string(errorpkg.Classify(err)),
 		Command:   command,
 		Operation: op,
 	}
 	log.Error(msg)
 }
 
+// appendInterrupted prints ErrInterrupted and appends it to the errors of
+// the command, to report that the command is stopped by an interrupt before
+// processing all of its objects. Draining on --stop-on-first-error is not
+// reported, the error which stopped the command is already.
+func appendInterrupted(merror error, command, op string) error {
+	if !parallel.IsInterrupted() {
+		return merror
+	}
+	printError(command, op, errorpkg.ErrInterrupted)
+	return multierror.Append(merror, errorpkg.ErrInterrupted)
+}
+
+// addFailure records the error of the object it occurred at, to be printed in
+// the summary of the failed objects.
+func addFailure(err *errorpkg.Error) {
+	object := err.Src
+	if object == nil {
+		object = err.Dst
+	}
+	if object == nil {
+		return
+	}
+	stat.AddFailure(object.String(), cleanupError(err.Err))
+}
+
 // cleanupError converts multiline messages into
 // a single line.
 func cleanupError(err error) string {
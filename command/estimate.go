@@ -0,0 +1,36 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/strutil"
+)
+
+// EstimateMessage is the structure for logging the number and the total size
+// of the objects an operation would affect.
+type EstimateMessage struct {
+	Operation string `json:"operation"`
+	Count     int64  `json:"count"`
+	Size      int64  `json:"size"`
+}
+
+// String returns the string representation of EstimateMessage.
+func (e EstimateMessage) String() string {
+	return fmt.Sprintf(
+		"%v would affect %d objects totaling %d bytes",
+		e.Operation,
+		e.Count,
+		e.Size,
+	)
+}
+
+// JSON returns the JSON representation of EstimateMessage.
+func (e EstimateMessage) JSON() string {
+	return strutil.JSON(e)
+}
+
+func (e *EstimateMessage) addObject(obj *storage.Object) {
+	e.Size += obj.Size
+	e.Count++
+}
@@ -0,0 +1,218 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+// Exit codes of the exists command. They are part of the interface of the
+// command and must not be changed.
+const (
+	existsExitCodeNotFound = 1
+	existsExitCodeError    = 2
+)
+
+var existsHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] argument
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Check if an object exists
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.gz
+
+	2. Check if any object matches a wildcard, and print the first matching one
+		 > s5cmd {{.HelpName}} -v 's3://bucket/prefix/*.gz'
+
+Exit status is 0 if the object exists, 1 if it doesn't exist and 2 if an error
+occurs. If the argument contains wildcards, the object exists if at least one
+object matches.
+`
+
+var existsCommand = &cli.Command{
+	Name:               "exists",
+	HelpName:           "exists",
+	Usage:              "check if an object exists",
+	CustomHelpTemplate: existsHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "verbose",
+			Aliases: []string{"v"},
+			Usage:   "print whether the object exists",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateExistsCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return &ExitError{Code: existsExitCodeError, Err: err}
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		src, err := url.New(c.Args().Get(0))
+		op := c.Command.Name
+		fullCommand := givenCommand(c)
+		if err != nil {
+			printError(fullCommand, op, err)
+			return &ExitError{Code: existsExitCodeError, Err: err}
+		}
+
+		return Exists{
+			src:         src,
+			op:          op,
+			fullCommand: fullCommand,
+			// flags
+			verbose: c.Bool("verbose"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// Exists holds exists operation flags and states.
+type Exists struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	verbose bool
+
+	storageOpts storage.Options
+}
+
+// Run checks if the given object exists, or at least one object matches the
+// given wildcard. An ExitError is returned if it doesn't exist, or the check
+// fails.
+func (e Exists) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, e.src, e.storageOpts)
+	if err != nil {
+		printError(e.fullCommand, e.op, err)
+		return &ExitError{Code: existsExitCodeError, Err: err}
+	}
+
+	object, err := e.find(ctx, client)
+	if err != nil {
+		printError(e.fullCommand, e.op, err)
+		return &ExitError{Code: existsExitCodeError, Err: err}
+	}
+
+	if e.verbose {
+		msg := ExistsMessage{
+			Source: e.src.String(),
+			Exists: object != nil,
+		}
+		if object != nil {
+			msg.Object = object.URL.String()
+		}
+		log.Info(msg)
+	}
+
+	if object == nil {
+		return &ExitError{
+			Code: existsExitCodeNotFound,
+			Err:  fmt.Errorf("%v does not exist", e.src),
+		}
+	}
+	return nil
+}
+
+// find returns the given object, or the first object matching the wildcard.
+// A nil object is returned if no object is found.
+func (e Exists) find(ctx context.Context, client *storage.S3) (*storage.Object, error) {
+	if !e.src.HasGlob() {
+		object, err := client.Stat(ctx, e.src)
+		if err == storage.ErrGivenObjectNotFound {
+			return nil, nil
+		}
+		return object, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objch := client.List(ctx, e.src, false)
+
+	// the listing is stopped once an object is found. The rest of the
+	// objects are consumed to let the listing finish.
+	defer func() {
+		cancel()
+		for range objch {
+		}
+	}()
+
+	for object := range objch {
+		if object.Err == storage.ErrNoObjectFound || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			return nil, err
+		}
+
+		if object.Type.IsDir() {
+			continue
+		}
+		return object, nil
+	}
+	return nil, ctx.Err()
+}
+
+// ExistsMessage is the structure for logging the result of exists command.
+type ExistsMessage struct {
+	Source string `json:"source"`
+	Exists bool   `json:"exists"`
+	Object string `json:"object,omitempty"`
+}
+
+// String returns the string representation of ExistsMessage.
+func (m ExistsMessage) String() string {
+	if !m.Exists {
+		return fmt.Sprintf("%v does not exist", m.Source)
+	}
+	if m.Object != m.Source {
+		return fmt.Sprintf("%v exists: %v", m.Source, m.Object)
+	}
+	return fmt.Sprintf("%v exists", m.Source)
+}
+
+// JSON returns the JSON representation of ExistsMessage.
+func (m ExistsMessage) JSON() string {
+	return strutil.JSON(m)
+}
+
+func validateExistsCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected only one argument")
+	}
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if !src.HasGlob() && (src.IsBucket() || src.IsPrefix()) {
+		return fmt.Errorf("remote source must be an object or contain a wildcard")
+	}
+	return nil
+}
@@ -46,6 +46,7 @@ func expandSource(
 // channel by walking and expanding the given source urls. If the url has a
 // glob, it creates a goroutine to list storage items and sends them to object
 // channel, otherwise it creates storage object from the original source.
+// Objects matched by more than one source are sent only once.
 func expandSources(
 	ctx context.Context,
 	client storage.Storage,
@@ -57,8 +58,30 @@ func expandSources(
 	go func() {
 		defer close(ch)
 
-		var wg sync.WaitGroup
-		var objFound bool
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			objFound bool
+			seen     = map[string]struct{}{}
+		)
+
+		// isFirst reports whether the object is not sent by another source.
+		isFirst := func(object *storage.Object) bool {
+			mu.Lock()
+			defer mu.Unlock()
+
+			objFound = true
+			if object.Err != nil || len(srcurls) == 1 {
+				return true
+			}
+
+			key := object.URL.String()
+			if _, ok := seen[key]; ok {
+				return false
+			}
+			seen[key] = struct{}{}
+			return true
+		}
 
 		for _, origSrc := range srcurls {
 			wg.Add(1)
@@ -75,8 +98,10 @@ func expandSources(
 					if object.Err == storage.ErrNoObjectFound {
 						continue
 					}
+					if !isFirst(object) {
+						continue
+					}
 					ch <- object
-					objFound = true
 				}
 			}(origSrc)
 		}
@@ -89,3 +114,11 @@ func expandSources(
 
 	return ch
 }
+
+// discardObjects cancels the listing of the given objects and consumes the
+// ones which are being sent, so that the goroutine sending them can exit.
+func discardObjects(cancel context.CancelFunc, objch <-chan *storage.Object) {
+	cancel()
+	for range objch {
+	}
+}
@@ -109,6 +109,40 @@ func TestExpandSources(t *testing.T) {
 				"s3://bucket/file2.txt",
 			},
 		},
+		{
+			name: "merge_overlapping_source_urls",
+			src: map[string][]*storage.Object{
+				"s3://bucket/*.txt": {
+					{
+						URL: &url.URL{
+							Scheme: "s3",
+							Bucket: "bucket",
+							Path:   "file1.txt",
+						},
+					},
+					{
+						URL: &url.URL{
+							Scheme: "s3",
+							Bucket: "bucket",
+							Path:   "file2.txt",
+						},
+					},
+				},
+				"s3://bucket/file1*": {
+					{
+						URL: &url.URL{
+							Scheme: "s3",
+							Bucket: "bucket",
+							Path:   "file1.txt",
+						},
+					},
+				},
+			},
+			wantObjects: []string{
+				"s3://bucket/file1.txt",
+				"s3://bucket/file2.txt",
+			},
+		},
 		{
 			// if multiple source has no item.
 			// it will return single storage.ErrNoObjectFound error.
@@ -0,0 +1,52 @@
+package command
+
+import (
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// preferredExtensions are the extensions used for the common content types
+// which have more than one known extension, e.g. ".jpg" instead of ".jfif".
+var preferredExtensions = map[string]string{
+	"application/gzip": ".gz",
+	"application/json": ".json",
+	"application/pdf":  ".pdf",
+	"application/xml":  ".xml",
+	"application/zip":  ".zip",
+	"image/jpeg":       ".jpg",
+	"image/png":        ".png",
+	"text/csv":         ".csv",
+	"text/html":        ".html",
+	"text/plain":       ".txt",
+}
+
+// withExtension appends the extension of the given content type to the file
+// name, unless it already has an extension. Names are not changed for the
+// generic binary content types, which are the defaults of the objects
+// uploaded without a content type, and the ones without a known extension.
+func withExtension(name, contentType string) string {
+	if filepath.Ext(name) != "" {
+		return name
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return name
+	}
+
+	switch mediaType = strings.ToLower(mediaType); mediaType {
+	case "application/octet-stream", "binary/octet-stream":
+		return name
+	}
+
+	if ext, ok := preferredExtensions[mediaType]; ok {
+		return name + ext
+	}
+
+	exts, err := mime.ExtensionsByType(mediaType)
+	if err != nil || len(exts) == 0 {
+		return name
+	}
+	return name + exts[0]
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestWithExtension(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		filename    string
+		contentType string
+		expected    string
+	}{
+		{
+			name:        "preferred extension",
+			filename:    "a1b2c3",
+			contentType: "image/jpeg",
+			expected:    "a1b2c3.jpg",
+		},
+		{
+			name:        "content type with parameters",
+			filename:    "a1b2c3",
+			contentType: "text/plain; charset=utf-8",
+			expected:    "a1b2c3.txt",
+		},
+		{
+			name:        "content type in upper case",
+			filename:    "a1b2c3",
+			contentType: "Application/PDF",
+			expected:    "a1b2c3.pdf",
+		},
+		{
+			name:        "nested file",
+			filename:    "reports/2020/a1b2c3",
+			contentType: "application/json",
+			expected:    "reports/2020/a1b2c3.json",
+		},
+		{
+			name:        "name has an extension",
+			filename:    "photo.jpeg",
+			contentType: "image/jpeg",
+			expected:    "photo.jpeg",
+		},
+		{
+			name:        "name has a different extension",
+			filename:    "data.bin",
+			contentType: "text/plain",
+			expected:    "data.bin",
+		},
+		{
+			name:        "generic binary content type",
+			filename:    "a1b2c3",
+			contentType: "binary/octet-stream",
+			expected:    "a1b2c3",
+		},
+		{
+			name:        "unknown content type",
+			filename:    "a1b2c3",
+			contentType: "application/x-s5cmd-unknown",
+			expected:    "a1b2c3",
+		},
+		{
+			name:        "no content type",
+			filename:    "a1b2c3",
+			contentType: "",
+			expected:    "a1b2c3",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, withExtension(tc.filename, tc.contentType))
+		})
+	}
+}
+
+func TestNameWithExtension(t *testing.T) {
+	t.Parallel()
+
+	srcurl, err := url.New("s3://bucket/reports/a1b2c3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// metadata of the object is known, it's not fetched.
+	srcobj := &storage.Object{
+		URL:      srcurl,
+		Metadata: storage.NewMetadata().SetContentType("application/pdf"),
+	}
+
+	name, err := Copy{}.nameWithExtension(context.Background(), srcobj, "reports/a1b2c3")
+	assert.NoError(t, err)
+	assert.Equal(t, "reports/a1b2c3.pdf", name)
+}
@@ -0,0 +1,166 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+var sourceFileListFlag = &cli.StringFlag{
+	Name:  "source-file-list",
+	Usage: "upload the local files whose paths are listed in given file, one per line, instead of the source arguments, e.g. the output of find",
+}
+
+// fileList is the list of the local files given with --source-file-list.
+// Files are uploaded with their paths relative to the base, which is the
+// closest existing directory which contains all of them.
+type fileList struct {
+	base  string
+	paths []string
+}
+
+// fileListFromFlags reads the paths given with --source-file-list, or returns
+// nil if it's not given. Empty lines are ignored.
+func fileListFromFlags(c *cli.Context) (*fileList, error) {
+	path := c.String("source-file-list")
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --source-file-list %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		paths = append(paths, filepath.Clean(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid --source-file-list %q: %v", path, err)
+	}
+
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("invalid --source-file-list %q: no paths are listed", path)
+	}
+
+	return newFileList(paths), nil
+}
+
+// newFileList returns the list of the given paths along with their base.
+// Relative paths are made absolute if they are mixed with the absolute ones,
+// so that they have a common base.
+func newFileList(paths []string) *fileList {
+	var absolute int
+	for _, path := range paths {
+		if filepath.IsAbs(path) {
+			absolute++
+		}
+	}
+	if absolute > 0 && absolute < len(paths) {
+		for i, path := range paths {
+			if abs, err := filepath.Abs(path); err == nil {
+				paths[i] = abs
+			}
+		}
+	}
+
+	base := filepath.Dir(paths[0])
+	for _, path := range paths[1:] {
+		for !isInDir(path, base) {
+			base = filepath.Dir(base)
+		}
+	}
+
+	// missing files are reported when they are uploaded, the base must exist
+	// to be listed.
+	for {
+		if _, err := os.Stat(base); err == nil || filepath.Dir(base) == base {
+			break
+		}
+		base = filepath.Dir(base)
+	}
+
+	return &fileList{base: base, paths: paths}
+}
+
+// isInDir reports whether the path is under the given directory.
+func isInDir(path, dir string) bool {
+	if dir == "." {
+		return !filepath.IsAbs(path) && path != ".." && !strings.HasPrefix(path, ".."+string(filepath.Separator))
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// sources returns the source arguments of the list, which is the base
+// directory of the files.
+func (l *fileList) sources() []string {
+	if strings.HasSuffix(l.base, string(filepath.Separator)) {
+		return []string{l.base}
+	}
+	return []string{l.base + string(filepath.Separator)}
+}
+
+// fileListClient is a storage client which lists the files given with
+// --source-file-list instead of walking the base directory.
+type fileListClient struct {
+	*storage.Filesystem
+	list *fileList
+}
+
+// List sends the listed files relative to the base directory. Files which
+// can't be found are sent without their attributes, so that they fail as the
+// other objects do once they are uploaded, without aborting the rest.
+func (c fileListClient) List(ctx context.Context, src *url.URL, followSymlinks bool) <-chan *storage.Object {
+	ch := make(chan *storage.Object)
+	go func() {
+		defer close(ch)
+
+		for _, path := range c.list.paths {
+			fileurl, err := url.New(path)
+			if err != nil {
+				ch <- &storage.Object{Err: err}
+				continue
+			}
+			fileurl.SetRelative(src.Absolute())
+
+			obj, err := c.Stat(ctx, fileurl)
+			if err != nil {
+				obj = &storage.Object{URL: fileurl}
+			} else if !storage.ShouldProcessUrl(fileurl, followSymlinks) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case ch <- obj:
+			}
+		}
+	}()
+	return ch
+}
+
+// withFileList returns a client which lists the files of the given list, or
+// the client itself if there is no list.
+func withFileList(client storage.Storage, list *fileList) storage.Storage {
+	fsclient, ok := client.(*storage.Filesystem)
+	if list == nil || !ok {
+		return client
+	}
+	return fileListClient{Filesystem: fsclient, list: list}
+}
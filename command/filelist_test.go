@@ -0,0 +1,67 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFileList(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-filelist-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0755))
+
+	testcases := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{
+			name:     "single file",
+			paths:    []string{filepath.Join(dir, "a", "b", "file.txt")},
+			expected: filepath.Join(dir, "a", "b"),
+		},
+		{
+			name: "files in different directories",
+			paths: []string{
+				filepath.Join(dir, "a", "b", "file.txt"),
+				filepath.Join(dir, "a", "file.txt"),
+			},
+			expected: filepath.Join(dir, "a"),
+		},
+		{
+			name: "directories with the same prefix",
+			paths: []string{
+				filepath.Join(dir, "a", "b", "file.txt"),
+				filepath.Join(dir, "ab", "file.txt"),
+			},
+			expected: dir,
+		},
+		{
+			name:     "missing directory",
+			paths:    []string{filepath.Join(dir, "a", "missing", "file.txt")},
+			expected: filepath.Join(dir, "a"),
+		},
+		{
+			name:     "relative paths",
+			paths:    []string{filepath.Join("a", "file.txt"), "file.txt"},
+			expected: ".",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		// subtests are not parallel, the directory is removed once the test
+		// returns.
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, newFileList(tc.paths).base)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// keyFilter selects the source objects by matching their keys, relative to
+// the source, against regular expressions. It is applied in addition to the
+// wildcard of the source, so an object is only selected if it matches both.
+type keyFilter struct {
+	includes []*regexp.Regexp
+	excludes []*regexp.Regexp
+}
+
+// keyFilterFromFlags returns the filter of the expressions given with
+// --include-regex and --exclude-regex, along with the ones read from the
+// files given with --include-from and --exclude-from. A nil filter is
+// returned if none of them is given, which selects all objects.
+func keyFilterFromFlags(c *cli.Context) (*keyFilter, error) {
+	f, err := parseKeyFilter(c.String("include-regex"), c.String("exclude-regex"))
+	if err != nil {
+		return nil, err
+	}
+
+	includes, err := readPatterns(c.String("include-from"), "--include-from")
+	if err != nil {
+		return nil, err
+	}
+
+	excludes, err := readPatterns(c.String("exclude-from"), "--exclude-from")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(includes) == 0 && len(excludes) == 0 {
+		return f, nil
+	}
+
+	if f == nil {
+		f = &keyFilter{}
+	}
+	f.includes = append(f.includes, includes...)
+	f.excludes = append(f.excludes, excludes...)
+	return f, nil
+}
+
+// readPatterns compiles the regular expressions in the given file, one per
+// line. Blank lines and the lines starting with '#' are ignored. The name of
+// the flag the file is given with is used in the errors.
+func readPatterns(path, flag string) ([]*regexp.Regexp, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v %q: %v", flag, path, err)
+	}
+	defer file.Close()
+
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(file)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v %q: line %d: %v", flag, path, lineno, err)
+		}
+		patterns = append(patterns, re)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid %v %q: %v", flag, path, err)
+	}
+
+	return patterns, nil
+}
+
+// parseKeyFilter compiles the given include and exclude expressions. Empty
+// expressions are ignored. A nil filter is returned if both are empty, which
+// selects all objects.
+func parseKeyFilter(include, exclude string) (*keyFilter, error) {
+	if include == "" && exclude == "" {
+		return nil, nil
+	}
+
+	var f keyFilter
+	if include != "" {
+		re, err := regexp.Compile(include)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --include-regex %q: %v", include, err)
+		}
+		f.includes = append(f.includes, re)
+	}
+
+	if exclude != "" {
+		re, err := regexp.Compile(exclude)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --exclude-regex %q: %v", exclude, err)
+		}
+		f.excludes = append(f.excludes, re)
+	}
+
+	return &f, nil
+}
+
+// match reports whether the object with given url is selected. Objects must
+// match at least one of the include expressions, if there is any, and must
+// not match any of the exclude expressions. Excludes take precedence over
+// includes.
+func (f *keyFilter) match(u *url.URL) bool {
+	if f == nil {
+		return true
+	}
+
+	key := filepath.ToSlash(u.Relative())
+	for _, re := range f.excludes {
+		if re.MatchString(key) {
+			return false
+		}
+	}
+
+	if len(f.includes) == 0 {
+		return true
+	}
+	for _, re := range f.includes {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// modTimeFilter selects the source objects by comparing their modification
+// times against the modification times of reference files.
+type modTimeFilter struct {
+	newerThan *time.Time
+	olderThan *time.Time
+}
+
+// parseModTimeFilter reads the modification times of the given reference
+// files. Empty paths are ignored. A nil filter is returned if both are empty,
+// which selects all objects.
+func parseModTimeFilter(newerThanFile, olderThanFile string) (*modTimeFilter, error) {
+	if newerThanFile == "" && olderThanFile == "" {
+		return nil, nil
+	}
+
+	var f modTimeFilter
+	if newerThanFile != "" {
+		st, err := os.Stat(newerThanFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --newer-than-file %q: %v", newerThanFile, err)
+		}
+		mod := st.ModTime()
+		f.newerThan = &mod
+	}
+
+	if olderThanFile != "" {
+		st, err := os.Stat(olderThanFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --older-than-file %q: %v", olderThanFile, err)
+		}
+		mod := st.ModTime()
+		f.olderThan = &mod
+	}
+
+	return &f, nil
+}
+
+// match reports whether the object is selected. Objects must be modified
+// after the newer-than reference and before the older-than reference. Objects
+// with unknown modification times are selected.
+func (f *modTimeFilter) match(obj *storage.Object) bool {
+	if f == nil || obj.ModTime == nil {
+		return true
+	}
+
+	if f.newerThan != nil && !obj.ModTime.After(*f.newerThan) {
+		return false
+	}
+	if f.olderThan != nil && !obj.ModTime.Before(*f.olderThan) {
+		return false
+	}
+	return true
+}
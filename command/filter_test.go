@@ -0,0 +1,277 @@
+package command
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestKeyFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		src     string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "no_filter",
+			src:  "2020/08/data-1.parquet",
+			want: true,
+		},
+		{
+			name:    "include_matches",
+			include: `^\d{4}/\d{2}/data-.*\.parquet$`,
+			src:     "2020/08/data-1.parquet",
+			want:    true,
+		},
+		{
+			name:    "include_does_not_match",
+			include: `^\d{4}/\d{2}/data-.*\.parquet$`,
+			src:     "2020/08/data-1.csv",
+			want:    false,
+		},
+		{
+			name:    "exclude_matches",
+			exclude: `\.tmp$`,
+			src:     "2020/08/data-1.tmp",
+			want:    false,
+		},
+		{
+			name:    "both_must_be_satisfied",
+			include: `^2020/`,
+			exclude: `/08/`,
+			src:     "2020/08/data-1.parquet",
+			want:    false,
+		},
+		{
+			name:    "invalid_include",
+			include: `(`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid_exclude",
+			exclude: `[a-`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := parseKeyFilter(tc.include, tc.exclude)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for include %q and exclude %q", tc.include, tc.exclude)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			src, err := url.New(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := f.match(src); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReadPatterns(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "patterns.txt")
+	content := "# temporary files\r\n\\.tmp$\n\n   \n  # logs\n^logs/\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	patterns, err := readPatterns(path, "--exclude-from")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, re := range patterns {
+		got = append(got, re.String())
+	}
+	want := []string{`\.tmp$`, `^logs/`}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got = %q, want %q", got, want)
+	}
+
+	invalid := filepath.Join(dir, "invalid.txt")
+	if err := ioutil.WriteFile(invalid, []byte("^data/\n(\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readPatterns(invalid, "--include-from"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error for line 2, got %v", err)
+	}
+
+	if _, err := readPatterns(filepath.Join(dir, "missing.txt"), "--include-from"); err == nil {
+		t.Errorf("expected error for missing file")
+	}
+}
+
+func TestKeyFilterWithMultiplePatterns(t *testing.T) {
+	t.Parallel()
+
+	f, err := parseKeyFilter(`^2020/`, `\.tmp$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.includes = append(f.includes, regexp.MustCompile(`^2021/`))
+	f.excludes = append(f.excludes, regexp.MustCompile(`/08/`))
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		// any of the includes selects the object.
+		{src: "2020/07/data-1.parquet", want: true},
+		{src: "2021/07/data-1.parquet", want: true},
+		{src: "2022/07/data-1.parquet", want: false},
+		// excludes take precedence over includes.
+		{src: "2021/07/data-1.tmp", want: false},
+		{src: "2020/08/data-1.parquet", want: false},
+	}
+	for _, tc := range tests {
+		src, err := url.New(tc.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := f.match(src); got != tc.want {
+			t.Errorf("%v: got = %v, want %v", tc.src, got, tc.want)
+		}
+	}
+}
+
+func TestModTimeFilter(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-filter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ref := time.Date(2020, 8, 1, 12, 0, 0, 0, time.UTC)
+
+	newerThanFile := filepath.Join(dir, "newer")
+	olderThanFile := filepath.Join(dir, "older")
+	for path, mod := range map[string]time.Time{
+		newerThanFile: ref,
+		olderThanFile: ref.Add(time.Hour),
+	} {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	timePtr := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name          string
+		newerThanFile string
+		olderThanFile string
+		modTime       *time.Time
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name:    "no_filter",
+			modTime: timePtr(ref),
+			want:    true,
+		},
+		{
+			name:          "newer",
+			newerThanFile: newerThanFile,
+			modTime:       timePtr(ref.Add(time.Second)),
+			want:          true,
+		},
+		{
+			name:          "same_time_is_not_newer",
+			newerThanFile: newerThanFile,
+			modTime:       timePtr(ref),
+			want:          false,
+		},
+		{
+			name:          "older",
+			olderThanFile: olderThanFile,
+			modTime:       timePtr(ref),
+			want:          true,
+		},
+		{
+			name:          "not_older",
+			olderThanFile: olderThanFile,
+			modTime:       timePtr(ref.Add(2 * time.Hour)),
+			want:          false,
+		},
+		{
+			name:          "between",
+			newerThanFile: newerThanFile,
+			olderThanFile: olderThanFile,
+			modTime:       timePtr(ref.Add(time.Minute)),
+			want:          true,
+		},
+		{
+			name:          "unknown_mod_time",
+			newerThanFile: newerThanFile,
+			want:          true,
+		},
+		{
+			name:          "missing_reference_file",
+			newerThanFile: filepath.Join(dir, "missing"),
+			wantErr:       true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		// subtests are not parallel, the reference files are removed once the
+		// test returns.
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parseModTimeFilter(tc.newerThanFile, tc.olderThanFile)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for newer-than %q and older-than %q", tc.newerThanFile, tc.olderThanFile)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := f.match(&storage.Object{ModTime: tc.modTime}); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
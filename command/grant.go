@@ -0,0 +1,110 @@
+package command
+
+import (
+	"fmt"
+	urlpkg "net/url"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// grants holds the explicit grants of the uploaded and copied objects, in the
+// format of the grant headers, e.g. `id="79a59df900b949e5", uri="http://acs.amazonaws.com/groups/global/AllUsers"`.
+type grants struct {
+	read        string
+	readACP     string
+	writeACP    string
+	fullControl string
+}
+
+// grantsFromFlags parses the grant flags. Each flag can be given multiple
+// times, or with comma separated grantees.
+func grantsFromFlags(c *cli.Context) (grants, error) {
+	var (
+		g   grants
+		err error
+	)
+
+	if g.read, err = parseGrantees(c, "grant-read"); err != nil {
+		return grants{}, err
+	}
+	if g.readACP, err = parseGrantees(c, "grant-read-acp"); err != nil {
+		return grants{}, err
+	}
+	if g.writeACP, err = parseGrantees(c, "grant-write-acp"); err != nil {
+		return grants{}, err
+	}
+	if g.fullControl, err = parseGrantees(c, "grant-full-control"); err != nil {
+		return grants{}, err
+	}
+	return g, nil
+}
+
+// parseGrantees parses the grantees given to a grant flag, which are in
+// id=..., emailAddress=... or uri=... format, and returns them in the format
+// of the grant header.
+func parseGrantees(c *cli.Context, flag string) (string, error) {
+	var grantees []string
+	for _, grantee := range c.StringSlice(flag) {
+		grantee = strings.TrimSpace(grantee)
+
+		invalid := func(reason string) error {
+			return fmt.Errorf("invalid --%v %q: %v", flag, grantee, reason)
+		}
+
+		i := strings.Index(grantee, "=")
+		if i < 0 {
+			return "", invalid("must be in id=..., emailAddress=... or uri=... format")
+		}
+
+		key := strings.ToLower(grantee[:i])
+		value := strings.Trim(grantee[i+1:], `"`)
+		if value == "" || strings.Contains(value, `"`) {
+			return "", invalid("grantee can not be empty or contain quotes")
+		}
+
+		switch key {
+		case "id":
+		case "emailaddress":
+			key = "emailAddress"
+			if !strings.Contains(value, "@") {
+				return "", invalid("must be an email address")
+			}
+		case "uri":
+			u, err := urlpkg.Parse(value)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return "", invalid("must be a URI of a predefined group, e.g. http://acs.amazonaws.com/groups/global/AllUsers")
+			}
+		default:
+			return "", invalid("grantee type must be one of id, emailAddress, uri")
+		}
+
+		grantees = append(grantees, fmt.Sprintf("%v=%q", key, value))
+	}
+	return strings.Join(grantees, ", "), nil
+}
+
+// isSet reports whether any grant is given.
+func (g grants) isSet() bool {
+	return g.read != "" || g.readACP != "" || g.writeACP != "" || g.fullControl != ""
+}
+
+// setMetadata sets the grants on the metadata of an uploaded or copied
+// object.
+func (g grants) setMetadata(metadata storage.Metadata) storage.Metadata {
+	if g.read != "" {
+		metadata.SetGrantRead(g.read)
+	}
+	if g.readACP != "" {
+		metadata.SetGrantReadACP(g.readACP)
+	}
+	if g.writeACP != "" {
+		metadata.SetGrantWriteACP(g.writeACP)
+	}
+	if g.fullControl != "" {
+		metadata.SetGrantFullControl(g.fullControl)
+	}
+	return metadata
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestGrantsFromFlags(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		read        []string
+		fullControl []string
+
+		expected storage.Metadata
+		wantErr  bool
+	}{
+		{
+			name:     "no grants",
+			expected: storage.Metadata{},
+		},
+		{
+			name: "multiple grantees",
+			read: []string{
+				"id=79a59df900b949e5",
+				`uri="http://acs.amazonaws.com/groups/global/AllUsers"`,
+			},
+			fullControl: []string{"emailaddress=owner@example.com"},
+			expected: storage.NewMetadata().
+				SetGrantRead(`id="79a59df900b949e5", uri="http://acs.amazonaws.com/groups/global/AllUsers"`).
+				SetGrantFullControl(`emailAddress="owner@example.com"`),
+		},
+		{
+			name:    "missing grantee type",
+			read:    []string{"79a59df900b949e5"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid grantee type",
+			read:    []string{"user=79a59df900b949e5"},
+			wantErr: true,
+		},
+		{
+			name:    "empty grantee",
+			read:    []string{"id="},
+			wantErr: true,
+		},
+		{
+			name:    "invalid email address",
+			read:    []string{"emailAddress=owner"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid uri",
+			read:    []string{"uri=AllUsers"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("cp", 0)
+			set.Var(cli.NewStringSlice(tc.read...), "grant-read", "")
+			set.Var(cli.NewStringSlice(), "grant-read-acp", "")
+			set.Var(cli.NewStringSlice(), "grant-write-acp", "")
+			set.Var(cli.NewStringSlice(tc.fullControl...), "grant-full-control", "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			grants, err := grantsFromFlags(ctx)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, grants.setMetadata(storage.NewMetadata()))
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// hooks are the commands which are run after each object is transferred, or
+// fails to be transferred. Commands are not run by a shell, so the
+// placeholders are substituted in each argument as is and the object keys
+// can't inject shell syntax. Supported placeholders are:
+//
+//	{src}   source of the object
+//	{dst}   destination of the object, empty if it's not known
+//	{key}   key of the remote object, the destination one unless only the
+//	        source is remote
+//	{path}  path of the local file, empty for S3 to S3 copies
+//	{size}  size of the object, only for --on-success
+//	{error} error of the object, only for --on-error
+type hooks struct {
+	onSuccess []string
+	onError   []string
+
+	// sem bounds the number of hooks running at once, since each of them
+	// runs a process.
+	sem chan struct{}
+}
+
+// hooksFromFlags parses the --on-success and --on-error commands. A nil hooks
+// is returned if none of them is given, which runs nothing.
+func hooksFromFlags(c *cli.Context) (*hooks, error) {
+	onSuccess, err := splitCommand(c.String("on-success"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --on-success command: %v", err)
+	}
+
+	onError, err := splitCommand(c.String("on-error"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --on-error command: %v", err)
+	}
+
+	if onSuccess == nil && onError == nil {
+		return nil, nil
+	}
+
+	return &hooks{
+		onSuccess: onSuccess,
+		onError:   onError,
+		sem:       make(chan struct{}, runtime.NumCPU()),
+	}, nil
+}
+
+// runSuccess runs the --on-success command for a transferred object.
+func (h *hooks) runSuccess(ctx context.Context, src, dst *url.URL, size int64) error {
+	if h == nil || h.onSuccess == nil {
+		return nil
+	}
+
+	replacer := hookReplacer(src, dst, "{size}", strconv.FormatInt(size, 10))
+	if err := h.run(ctx, h.onSuccess, replacer); err != nil {
+		return fmt.Errorf("--on-success command failed: %v", err)
+	}
+	return nil
+}
+
+// runError runs the --on-error command for an object which couldn't be
+// transferred.
+func (h *hooks) runError(ctx context.Context, src, dst *url.URL, err error) error {
+	if h == nil || h.onError == nil {
+		return nil
+	}
+
+	replacer := hookReplacer(src, dst, "{error}", newlineReplacer.Replace(err.Error()))
+	if err := h.run(ctx, h.onError, replacer); err != nil {
+		return fmt.Errorf("--on-error command failed: %v", err)
+	}
+	return nil
+}
+
+func (h *hooks) run(ctx context.Context, command []string, replacer *strings.Replacer) error {
+	h.sem <- struct{}{}
+	defer func() { <-h.sem }()
+
+	args := make([]string, 0, len(command))
+	for _, arg := range command {
+		args = append(args, replacer.Replace(arg))
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// hookReplacer returns the replacer of the placeholders of the given object,
+// along with the given extra placeholder and its value.
+func hookReplacer(src, dst *url.URL, placeholder, value string) *strings.Replacer {
+	var key, path, dstname string
+	if dst != nil {
+		dstname = dst.String()
+		if dst.IsRemote() {
+			key = dst.Path
+		} else {
+			path = dst.String()
+		}
+	}
+
+	if !src.IsRemote() {
+		path = src.String()
+	} else if key == "" {
+		key = src.Path
+	}
+
+	return strings.NewReplacer(
+		"{src}", src.String(),
+		"{dst}", dstname,
+		"{key}", key,
+		"{path}", path,
+		placeholder, value,
+	)
+}
+
+// splitCommand splits the command into its arguments by whitespace. Arguments
+// with whitespace can be enclosed in single or double quotes.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		arg     strings.Builder
+		inArg   bool
+		inQuote rune
+	)
+
+	for _, r := range command {
+		switch {
+		case inQuote != 0:
+			if r == inQuote {
+				inQuote = 0
+				continue
+			}
+			arg.WriteRune(r)
+		case r == '\'' || r == '"':
+			inQuote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inQuote != 0 {
+		return nil, fmt.Errorf("unclosed quote in %q", command)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
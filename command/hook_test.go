@@ -0,0 +1,87 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestSplitCommand(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		command string
+
+		expected []string
+		wantErr  bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name:     "words",
+			command:  "ingest  --size {size}\t{path}",
+			expected: []string{"ingest", "--size", "{size}", "{path}"},
+		},
+		{
+			name:     "quotes",
+			command:  `sh -c 'echo "$0" >> done.txt' {key}`,
+			expected: []string{"sh", "-c", `echo "$0" >> done.txt`, "{key}"},
+		},
+		{
+			name:     "empty quoted argument",
+			command:  `notify ""`,
+			expected: []string{"notify", ""},
+		},
+		{
+			name:    "unclosed quote",
+			command: `notify "{key}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		got, err := splitCommand(tc.command)
+		if tc.wantErr {
+			assert.Error(t, err, tc.name)
+			continue
+		}
+
+		assert.NoError(t, err, tc.name)
+		assert.Equal(t, tc.expected, got, tc.name)
+	}
+}
+
+func TestHookReplacer(t *testing.T) {
+	t.Parallel()
+
+	remote, err := url.New("s3://bucket/dir/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := url.New("dir/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := url.New("s3://target/file.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const tmpl = "{src}|{dst}|{key}|{path}|{size}"
+
+	download := hookReplacer(remote, local, "{size}", "42")
+	assert.Equal(t, "s3://bucket/dir/file.txt|dir/file.txt|dir/file.txt|dir/file.txt|42", download.Replace(tmpl))
+
+	upload := hookReplacer(local, target, "{size}", "42")
+	assert.Equal(t, "dir/file.txt|s3://target/file.txt|file.txt|dir/file.txt|42", upload.Replace(tmpl))
+
+	copy := hookReplacer(remote, target, "{size}", "42")
+	assert.Equal(t, "s3://bucket/dir/file.txt|s3://target/file.txt|file.txt||42", copy.Replace(tmpl))
+
+	failed := hookReplacer(remote, nil, "{error}", "access denied")
+	assert.Equal(t, "s3://bucket/dir/file.txt||dir/file.txt||access denied", failed.Replace("{src}|{dst}|{key}|{path}|{error}"))
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+var fromInventoryFlag = &cli.StringFlag{
+	Name:  "from-inventory",
+	Usage: "read the source objects from given S3 Inventory manifest instead of listing the bucket, e.g. s3://bucket/inventory/manifest.json (only CSV reports are supported)",
+}
+
+// inventoryClient is a storage client which lists the objects from an S3
+// Inventory report instead of listing the bucket. The other operations are
+// sent to the bucket as usual.
+type inventoryClient struct {
+	*storage.S3
+	manifest *url.URL
+}
+
+// List lists the objects which match the given url from the inventory report.
+func (c inventoryClient) List(ctx context.Context, u *url.URL, _ bool) <-chan *storage.Object {
+	return c.ListInventory(ctx, c.manifest, u)
+}
+
+// inventoryFromFlags returns the URL of the --from-inventory manifest, or nil
+// if it's not given.
+func inventoryFromFlags(c *cli.Context) (*url.URL, error) {
+	manifest := c.String("from-inventory")
+	if manifest == "" {
+		return nil, nil
+	}
+
+	u, err := url.New(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsRemote() || u.HasGlob() || u.IsBucket() || u.IsPrefix() {
+		return nil, fmt.Errorf("--from-inventory must be the S3 URL of an inventory manifest, e.g. s3://bucket/inventory/manifest.json")
+	}
+	return u, nil
+}
+
+// validateInventorySources checks that the sources of --from-inventory are
+// remote, since only the objects in S3 have inventory reports.
+func validateInventorySources(c *cli.Context, sources []string) error {
+	if _, err := inventoryFromFlags(c); err != nil {
+		return err
+	}
+
+	if c.String("from-inventory") == "" {
+		return nil
+	}
+
+	for _, src := range sources {
+		srcurl, err := url.New(src)
+		if err != nil {
+			return err
+		}
+		if !srcurl.IsRemote() {
+			return fmt.Errorf("--from-inventory can only be used with remote sources")
+		}
+	}
+	return nil
+}
+
+// withInventory returns a client which lists the objects from the given
+// inventory manifest, or the client itself if there is no manifest.
+func withInventory(client storage.Storage, manifest *url.URL) storage.Storage {
+	s3client, ok := client.(*storage.S3)
+	if manifest == nil || !ok {
+		return client
+	}
+	return inventoryClient{S3: s3client, manifest: manifest}
+}
@@ -0,0 +1,154 @@
+package command
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultDateLayout is used for the '{date}' token of key templates.
+const defaultDateLayout = "2006-01-02"
+
+// maxKeyLength is the maximum length of an S3 object key in bytes.
+const maxKeyLength = 1024
+
+// keyTemplate generates the destination keys of uploaded files from the name
+// of each file.
+//
+// Example:
+//
+//	template: {dir}{name}-{date}{ext}
+//	name: logs/access.log
+//	output: logs/access-2020-08-01.log
+type keyTemplate struct {
+	parts []keyTemplatePart
+}
+
+// keyTemplatePart is either a literal text or a token of a key template.
+type keyTemplatePart struct {
+	literal string
+	token   func(name string, now time.Time) string
+}
+
+// parseKeyTemplate parses the given template. Supported tokens are:
+//
+//	{dir}         directory of the file with a trailing slash, empty if none
+//	{name}        name of the file without its extension
+//	{ext}         extension of the file with the leading dot, empty if none
+//	{basename}    name of the file with its extension
+//	{date}        current date in UTC, formatted as 2006-01-02
+//	{date:layout} current time in UTC, formatted with given Go time layout
+func parseKeyTemplate(tmpl string) (*keyTemplate, error) {
+	if tmpl == "" {
+		return nil, fmt.Errorf("invalid key template: empty template")
+	}
+
+	var parts []keyTemplatePart
+	for rest := tmpl; rest != ""; {
+		start := strings.IndexAny(rest, "{}")
+		if start == -1 {
+			parts = append(parts, keyTemplatePart{literal: rest})
+			break
+		}
+
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("invalid key template %q: unexpected '}'", tmpl)
+		}
+
+		if start > 0 {
+			parts = append(parts, keyTemplatePart{literal: rest[:start]})
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf("invalid key template %q: unclosed '{'", tmpl)
+		}
+		end += start
+
+		token, err := parseKeyTemplateToken(rest[start+1 : end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key template %q: %v", tmpl, err)
+		}
+		parts = append(parts, keyTemplatePart{token: token})
+
+		rest = rest[end+1:]
+	}
+
+	return &keyTemplate{parts: parts}, nil
+}
+
+func parseKeyTemplateToken(token string) (func(string, time.Time) string, error) {
+	switch token {
+	case "dir":
+		return func(name string, _ time.Time) string {
+			dir, _ := path.Split(name)
+			return dir
+		}, nil
+	case "name":
+		return func(name string, _ time.Time) string {
+			base := path.Base(name)
+			return strings.TrimSuffix(base, path.Ext(base))
+		}, nil
+	case "ext":
+		return func(name string, _ time.Time) string {
+			return path.Ext(name)
+		}, nil
+	case "basename":
+		return func(name string, _ time.Time) string {
+			return path.Base(name)
+		}, nil
+	case "date":
+		return func(_ string, now time.Time) string {
+			return now.Format(defaultDateLayout)
+		}, nil
+	}
+
+	if strings.HasPrefix(token, "date:") {
+		layout := strings.TrimPrefix(token, "date:")
+		if layout == "" {
+			return nil, fmt.Errorf("empty date layout")
+		}
+		return func(_ string, now time.Time) string {
+			return now.Format(layout)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown token {%v}", token)
+}
+
+// apply returns the key generated for the given name, which is a slash
+// separated path of the file relative to the source. An error is returned if
+// the result is not a valid S3 key.
+func (t *keyTemplate) apply(name string, now time.Time) (string, error) {
+	if t == nil {
+		return name, nil
+	}
+
+	now = now.UTC()
+
+	var buf strings.Builder
+	for _, part := range t.parts {
+		if part.token != nil {
+			buf.WriteString(part.token(name, now))
+			continue
+		}
+		buf.WriteString(part.literal)
+	}
+
+	key := buf.String()
+	switch {
+	case key == "":
+		return "", fmt.Errorf("key template generated an empty key for %q", name)
+	case strings.HasPrefix(key, "/"):
+		return "", fmt.Errorf("key template generated key %q starting with '/' for %q", key, name)
+	case strings.HasSuffix(key, "/"):
+		return "", fmt.Errorf("key template generated key %q ending with '/' for %q", key, name)
+	case len(key) > maxKeyLength:
+		return "", fmt.Errorf("key template generated a key longer than %d bytes for %q", maxKeyLength, name)
+	case !utf8.ValidString(key):
+		return "", fmt.Errorf("key template generated key %q which is not valid UTF-8 for %q", key, name)
+	}
+	return key, nil
+}
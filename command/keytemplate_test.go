@@ -0,0 +1,131 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKeyTemplate(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 8, 1, 13, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		template    string
+		input       string
+		want        string
+		wantErr     bool
+		wantApplErr bool
+	}{
+		{
+			name:     "date_before_extension",
+			template: "{dir}{name}-{date}{ext}",
+			input:    "logs/access.log",
+			want:     "logs/access-2020-08-01.log",
+		},
+		{
+			name:     "file_without_directory",
+			template: "{dir}{name}-{date}{ext}",
+			input:    "access.log",
+			want:     "access-2020-08-01.log",
+		},
+		{
+			name:     "file_without_extension",
+			template: "{dir}{name}{ext}.bak",
+			input:    "a/b/README",
+			want:     "a/b/README.bak",
+		},
+		{
+			name:     "only_last_extension",
+			template: "{name}|{ext}",
+			input:    "a/file.tar.gz",
+			want:     "file.tar|.gz",
+		},
+		{
+			name:     "date_layout",
+			template: "{date:2006/01/02}/{basename}",
+			input:    "dir/file.gz",
+			want:     "2020/08/01/file.gz",
+		},
+		{
+			name:     "literal_only",
+			template: "constant.txt",
+			input:    "dir/file.gz",
+			want:     "constant.txt",
+		},
+		{
+			name:     "error_if_unknown_token",
+			template: "{dir}{filename}",
+			wantErr:  true,
+		},
+		{
+			name:     "error_if_unclosed_token",
+			template: "{dir}{name",
+			wantErr:  true,
+		},
+		{
+			name:     "error_if_unexpected_closing_brace",
+			template: "{dir}name}",
+			wantErr:  true,
+		},
+		{
+			name:     "error_if_empty_date_layout",
+			template: "{date:}{basename}",
+			wantErr:  true,
+		},
+		{
+			name:        "error_if_key_starts_with_slash",
+			template:    "/{basename}",
+			input:       "file.gz",
+			wantApplErr: true,
+		},
+		{
+			name:        "error_if_key_is_empty",
+			template:    "{dir}",
+			input:       "file.gz",
+			wantApplErr: true,
+		},
+		{
+			name:        "error_if_key_is_too_long",
+			template:    strings.Repeat("a", maxKeyLength) + "{ext}",
+			input:       "file.gz",
+			wantApplErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			kt, err := parseKeyTemplate(tc.template)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for template %q", tc.template)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := kt.apply(tc.input, now)
+			if tc.wantApplErr {
+				if err == nil {
+					t.Errorf("expected error for template %q, got %q", tc.template, got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+var listMultipartHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] argument
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. List in-progress multipart uploads in a bucket
+		 > s5cmd {{.HelpName}} s3://bucket/
+
+	2. List in-progress multipart uploads under a prefix, with human-readable sizes
+		 > s5cmd {{.HelpName}} --humanize s3://bucket/prefix/
+
+	3. List multipart uploads which are initiated more than a week ago, which are likely to be left by interrupted uploads
+		 > s5cmd {{.HelpName}} --older-than 168h 's3://bucket/*.iso'
+`
+
+var listMultipartCommand = &cli.Command{
+	Name:               "list-multipart",
+	HelpName:           "list-multipart",
+	Usage:              "list in-progress multipart uploads",
+	CustomHelpTemplate: listMultipartHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "older-than",
+			Usage: "only list the uploads initiated more than given duration ago, e.g. 24h",
+		},
+		&cli.BoolFlag{
+			Name:    "humanize",
+			Aliases: []string{"H"},
+			Usage:   "human-readable output for the sizes of the uploaded parts",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateListMultipartCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		return ListMultipart{
+			src:         c.Args().Get(0),
+			op:          c.Command.Name,
+			fullCommand: givenCommand(c),
+			// flags
+			olderThan: c.Duration("older-than"),
+			humanize:  c.Bool("humanize"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// ListMultipart holds list-multipart operation flags and states.
+type ListMultipart struct {
+	src         string
+	op          string
+	fullCommand string
+
+	// flags
+	olderThan time.Duration
+	humanize  bool
+
+	storageOpts storage.Options
+}
+
+// Run prints the in-progress multipart uploads of the objects which match
+// the source, along with the total size of their parts uploaded so far.
+func (l ListMultipart) Run(ctx context.Context) error {
+	srcurl, err := url.New(l.src)
+	if err != nil {
+		printError(l.fullCommand, l.op, err)
+		return err
+	}
+
+	client, err := storage.NewRemoteClient(ctx, srcurl, l.storageOpts)
+	if err != nil {
+		printError(l.fullCommand, l.op, err)
+		return err
+	}
+
+	uploads, err := client.ListMultipartUploads(ctx, srcurl)
+	if err != nil {
+		printError(l.fullCommand, l.op, err)
+		return err
+	}
+
+	now := time.Now()
+
+	var merror error
+	for _, upload := range uploads {
+		if parallel.IsDraining() {
+			break
+		}
+
+		if l.olderThan > 0 && now.Sub(upload.Initiated) < l.olderThan {
+			continue
+		}
+
+		parts, size, err := client.MultipartUploadSize(ctx, upload)
+		if err != nil {
+			merror = multierror.Append(merror, err)
+			printError(l.fullCommand, l.op, err)
+			continue
+		}
+
+		msg := MultipartUploadMessage{
+			URL:           upload.URL,
+			UploadID:      upload.UploadID,
+			Initiated:     upload.Initiated,
+			Parts:         parts,
+			Size:          size,
+			showHumanized: l.humanize,
+		}
+		log.Info(msg)
+	}
+
+	return merror
+}
+
+// MultipartUploadMessage is the structure for logging an in-progress
+// multipart upload.
+type MultipartUploadMessage struct {
+	URL       *url.URL  `json:"key"`
+	UploadID  string    `json:"upload_id"`
+	Initiated time.Time `json:"initiated"`
+	Parts     int64     `json:"parts"`
+	Size      int64     `json:"size"`
+
+	showHumanized bool
+}
+
+// humanize is a helper method to humanize bytes.
+func (m MultipartUploadMessage) humanize() string {
+	if m.showHumanized {
+		return strutil.HumanizeBytes(m.Size)
+	}
+	return fmt.Sprintf("%d", m.Size)
+}
+
+// String returns the string representation of MultipartUploadMessage.
+func (m MultipartUploadMessage) String() string {
+	return fmt.Sprintf(
+		"%19s %12s  %s  %s",
+		m.Initiated.Format(dateFormat),
+		m.humanize(),
+		m.UploadID,
+		m.URL,
+	)
+}
+
+// JSON returns the JSON representation of MultipartUploadMessage.
+func (m MultipartUploadMessage) JSON() string {
+	return strutil.JSON(m)
+}
+
+func validateListMultipartCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected only one argument")
+	}
+
+	src, err := url.New(c.Args().Get(0))
+	if err != nil {
+		return err
+	}
+
+	if !src.IsRemote() {
+		return fmt.Errorf("source must be a remote bucket, prefix or wildcard")
+	}
+
+	if c.Duration("older-than") < 0 {
+		return fmt.Errorf("--older-than can not be negative")
+	}
+	return nil
+}
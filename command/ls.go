@@ -3,6 +3,8 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/urfave/cli/v2"
@@ -10,6 +12,7 @@ import (
 	errorpkg "github.com/peak/s5cmd/error"
 	"github.com/peak/s5cmd/log"
 	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage"
 	"github.com/peak/s5cmd/storage/url"
 	"github.com/peak/s5cmd/strutil"
@@ -19,7 +22,7 @@ var listHelpTemplate = `Name:
 	{{.HelpName}} - {{.Usage}}
 
 Usage:
-	{{.HelpName}} [options] argument
+	{{.HelpName}} [options] argument [argument]
 
 Options:
 	{{range .VisibleFlags}}{{.}}
@@ -36,6 +39,30 @@ Examples:
 
 	4. List all objects that matches a wildcard
 		 > s5cmd {{.HelpName}} s3://bucket/prefix/*/*.gz
+
+	5. List all objects under a prefix recursively
+		 > s5cmd {{.HelpName}} --recursive s3://bucket/prefix/
+
+	6. List all objects under a prefix, up to two levels of directories deep
+		 > s5cmd {{.HelpName}} --recursive --max-depth 2 s3://bucket/prefix/
+
+	7. List all objects in a bucket as a JSON document per line, with all of their attributes
+		 > s5cmd {{.HelpName}} --json s3://bucket/*
+
+	8. List all objects under multiple prefixes
+		 > s5cmd {{.HelpName}} s3://bucket/logs/* s3://bucket/backups/*
+
+	9. List all objects in a bucket with their modification times in RFC3339 format in UTC
+		 > s5cmd {{.HelpName}} --time-format rfc3339 --utc s3://bucket/*
+
+	10. List the objects under a prefix from the latest S3 Inventory report of the bucket, instead of listing the bucket
+		 > s5cmd {{.HelpName}} --from-inventory s3://bucket/inventory/bucket/daily/2020-01-02T00-00Z/manifest.json s3://bucket/prefix/
+
+	11. List the prefixes in a bucket which have no objects under them, other than directory placeholders
+		 > s5cmd {{.HelpName}} --empty-prefixes s3://bucket/
+
+	12. List the empty directory placeholders at any depth under a prefix
+		 > s5cmd {{.HelpName}} --empty-prefixes --recursive s3://bucket/prefix/
 `
 
 var listCommand = &cli.Command{
@@ -59,6 +86,34 @@ var listCommand = &cli.Command{
 			Aliases: []string{"s"},
 			Usage:   "display full name of the object class",
 		},
+		&cli.BoolFlag{
+			Name:    "recursive",
+			Aliases: []string{"R"},
+			Usage:   "list all objects under the given prefix, without grouping them into directories",
+		},
+		&cli.IntFlag{
+			Name:  "max-depth",
+			Usage: "do not list objects which are more than given number of directory levels deep",
+		},
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print a JSON document with key, size, last_modified, storage_class, etag and is_prefix fields per line, or name and creation_date fields for buckets",
+		},
+		&cli.StringFlag{
+			Name:  "time-format",
+			Value: dateFormat,
+			Usage: "print times in given Go time layout, or rfc3339",
+		},
+		&cli.BoolFlag{
+			Name:  "utc",
+			Usage: "print times in UTC instead of the local time zone",
+		},
+		&cli.BoolFlag{
+			Name:  "empty-prefixes",
+			Usage: "only list the prefixes which have no objects under them, other than directory placeholders",
+		},
+		fromInventoryFlag,
+		progressFlag,
 	},
 	Before: func(c *cli.Context) error {
 		err := validateLSCommand(c)
@@ -70,21 +125,34 @@ var listCommand = &cli.Command{
 	Action: func(c *cli.Context) (err error) {
 		defer stat.Collect(c.Command.FullName(), &err)()
 		if !c.Args().Present() {
-			err := ListBuckets(c.Context, NewStorageOpts(c))
+			err := ListBuckets(c.Context, NewStorageOpts(c), timeFormatFromFlags(c), c.Bool("json"))
 			if err != nil {
 				printError(givenCommand(c), c.Command.Name, err)
 			}
 			return err
 		}
 
+		inventory, err := inventoryFromFlags(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
 		return List{
-			src:         c.Args().First(),
+			src:         c.Args().Slice(),
 			op:          c.Command.Name,
 			fullCommand: givenCommand(c),
 			// flags
 			showEtag:         c.Bool("etag"),
 			humanize:         c.Bool("humanize"),
 			showStorageClass: c.Bool("storage-class"),
+			recursive:        c.Bool("recursive"),
+			maxDepth:         maxDepthFromFlags(c),
+			jsonOutput:       c.Bool("json"),
+			showProgress:     progressFromFlags(c),
+			timeFormat:       timeFormatFromFlags(c),
+			inventory:        inventory,
+			emptyPrefixes:    c.Bool("empty-prefixes"),
 
 			storageOpts: NewStorageOpts(c),
 		}.Run(c.Context)
@@ -93,7 +161,7 @@ var listCommand = &cli.Command{
 
 // List holds list operation flags and states.
 type List struct {
-	src         string
+	src         []string
 	op          string
 	fullCommand string
 
@@ -101,15 +169,23 @@ type List struct {
 	showEtag         bool
 	humanize         bool
 	showStorageClass bool
+	recursive        bool
+	maxDepth         int
+	jsonOutput       bool
+	showProgress     bool
+	timeFormat       timeFormat
+	inventory        *url.URL
+	emptyPrefixes    bool
 
 	storageOpts storage.Options
 }
 
-// ListBuckets prints all buckets.
-func ListBuckets(ctx context.Context, storageOpts storage.Options) error {
+// ListBuckets prints all buckets. If jsonOutput is set, a JSON document is
+// printed per bucket, as the objects are printed by 'ls --json'.
+func ListBuckets(ctx context.Context, storageOpts storage.Options, timeFormat timeFormat, jsonOutput bool) error {
 	// set as remote storage
 	url := &url.URL{Type: 0}
-	client, err := storage.NewRemoteClient(url, storageOpts)
+	client, err := storage.NewRemoteClient(ctx, url, storageOpts)
 	if err != nil {
 		return err
 	}
@@ -120,29 +196,86 @@ func ListBuckets(ctx context.Context, storageOpts storage.Options) error {
 	}
 
 	for _, bucket := range buckets {
-		log.Info(bucket)
+		log.Info(ListBucketMessage{Bucket: bucket, timeFormat: timeFormat, jsonOutput: jsonOutput})
 	}
 
 	return nil
 }
 
-// Run prints objects at given source.
+// Run prints objects at given sources. If more than one source is given,
+// objects are printed with their full URLs, since their paths relative to
+// the sources would be ambiguous.
 func (l List) Run(ctx context.Context) error {
-	srcurl, err := url.New(l.src)
+	srcurls, err := newURLs(l.src...)
 	if err != nil {
 		printError(l.fullCommand, l.op, err)
 		return err
 	}
 
-	client, err := storage.NewClient(srcurl, l.storageOpts)
+	var merror error
+
+	progress := startListProgress(l.showProgress)
+	defer progress.Stop()
+
+	for _, srcurl := range srcurls {
+		if parallel.IsDraining() {
+			return appendInterrupted(merror, l.fullCommand, l.op)
+		}
+
+		interrupted, err := l.list(ctx, srcurl, progress, len(srcurls) > 1)
+		if err != nil {
+			merror = multierror.Append(merror, err)
+		}
+		if interrupted {
+			return appendInterrupted(merror, l.fullCommand, l.op)
+		}
+	}
+
+	return merror
+}
+
+// list prints objects at given source. It reports whether the listing is
+// stopped by an interrupt.
+func (l List) list(ctx context.Context, srcurl *url.URL, progress *listProgress, showFullURL bool) (bool, error) {
+	var err error
+	if l.recursive {
+		srcurl, err = srcurl.Recursive()
+		if err != nil {
+			printError(l.fullCommand, l.op, err)
+			return false, err
+		}
+	}
+
+	client, err := storage.NewClient(ctx, srcurl, l.storageOpts)
 	if err != nil {
 		printError(l.fullCommand, l.op, err)
-		return err
+		return false, err
 	}
+	client = withInventory(client, l.inventory)
 
-	var merror error
+	var (
+		merror   error
+		prefixes *emptyPrefixes
+	)
+
+	// objects of a recursive listing are under its placeholders, so the
+	// empty ones are printed once the listing is complete.
+	if l.emptyPrefixes && l.recursive {
+		prefixes = newEmptyPrefixes()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	objch := client.List(ctx, srcurl, false)
+	for object := range objch {
+		if parallel.IsDraining() {
+			discardObjects(cancel, objch)
+			return true, merror
+		}
+
+		progress.Add()
 
-	for object := range client.List(ctx, srcurl, false) {
 		if errorpkg.IsCancelation(object.Err) {
 			continue
 		}
@@ -153,17 +286,61 @@ func (l List) Run(ctx context.Context) error {
 			continue
 		}
 
-		msg := ListMessage{
-			Object:           object,
-			showEtag:         l.showEtag,
-			showHumanized:    l.humanize,
-			showStorageClass: l.showStorageClass,
+		// objects deeper than --max-depth still make their prefixes
+		// non-empty.
+		if prefixes != nil {
+			prefixes.add(object)
+			continue
 		}
 
-		log.Info(msg)
+		if exceedsMaxDepth(object.URL, l.maxDepth) {
+			continue
+		}
+
+		if l.emptyPrefixes {
+			if !object.Type.IsDir() {
+				continue
+			}
+
+			empty, err := isEmptyPrefix(ctx, client, object.URL)
+			if err != nil {
+				merror = multierror.Append(merror, err)
+				printError(l.fullCommand, l.op, err)
+				continue
+			}
+			if !empty {
+				continue
+			}
+		}
+
+		l.print(object, showFullURL)
 	}
 
-	return merror
+	if prefixes != nil {
+		for _, object := range prefixes.empty() {
+			if exceedsMaxDepth(object.URL, l.maxDepth) {
+				continue
+			}
+			l.print(object, showFullURL)
+		}
+	}
+
+	return false, merror
+}
+
+// print prints the listed object.
+func (l List) print(object *storage.Object, showFullURL bool) {
+	msg := ListMessage{
+		Object:           object,
+		showEtag:         l.showEtag,
+		showHumanized:    l.humanize,
+		showStorageClass: l.showStorageClass,
+		showFullURL:      showFullURL,
+		jsonOutput:       l.jsonOutput,
+		timeFormat:       l.timeFormat,
+	}
+
+	log.Info(msg)
 }
 
 // ListMessage is a structure for logging ls results.
@@ -173,6 +350,37 @@ type ListMessage struct {
 	showEtag         bool
 	showHumanized    bool
 	showStorageClass bool
+	showFullURL      bool
+	jsonOutput       bool
+	timeFormat       timeFormat
+}
+
+// listRecord is the representation of an object printed by 'ls --json'.
+// Unlike the general JSON output, all fields are always present.
+type listRecord struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	LastModified string `json:"last_modified"`
+	StorageClass string `json:"storage_class"`
+	Etag         string `json:"etag"`
+	IsPrefix     bool   `json:"is_prefix"`
+}
+
+// record returns the listRecord of the listed object.
+func (l ListMessage) record() listRecord {
+	var lastModified string
+	if l.Object.ModTime != nil {
+		lastModified = l.Object.ModTime.UTC().Format(time.RFC3339)
+	}
+
+	return listRecord{
+		Key:          l.Object.URL.Path,
+		Size:         l.Object.Size,
+		LastModified: lastModified,
+		StorageClass: string(l.Object.StorageClass),
+		Etag:         l.Object.Etag,
+		IsPrefix:     l.Object.Type.IsDir(),
+	}
 }
 
 // humanize is a helper function to humanize bytes.
@@ -192,6 +400,15 @@ const (
 
 // String returns the string representation of ListMessage.
 func (l ListMessage) String() string {
+	if l.jsonOutput {
+		return strutil.JSON(l.record())
+	}
+
+	name := l.Object.URL.Relative()
+	if l.showFullURL {
+		name = l.Object.URL.String()
+	}
+
 	var listFormat = "%19s %2s %-1s %12s %s"
 	var etag string
 	if l.showEtag {
@@ -206,7 +423,7 @@ func (l ListMessage) String() string {
 			"",
 			"",
 			"DIR",
-			l.Object.URL.Relative(),
+			name,
 		)
 		return s
 	}
@@ -218,23 +435,114 @@ func (l ListMessage) String() string {
 
 	s := fmt.Sprintf(
 		listFormat,
-		l.Object.ModTime.Format(dateFormat),
+		l.timeFormat.format(*l.Object.ModTime),
 		stclass,
 		etag,
 		l.humanize(),
-		l.Object.URL.Relative(),
+		name,
 	)
 	return s
 }
 
 // JSON returns the JSON representation of ListMessage.
 func (l ListMessage) JSON() string {
+	if l.jsonOutput {
+		return strutil.JSON(l.record())
+	}
 	return strutil.JSON(l.Object)
 }
 
+// ListBucketMessage is a structure for logging the buckets listed by ls.
+type ListBucketMessage struct {
+	Bucket storage.Bucket
+
+	timeFormat timeFormat
+	jsonOutput bool
+}
+
+// listBucketRecord is the representation of a bucket printed by 'ls --json'.
+type listBucketRecord struct {
+	Name         string `json:"name"`
+	CreationDate string `json:"creation_date"`
+}
+
+// record returns the listBucketRecord of the listed bucket.
+func (l ListBucketMessage) record() listBucketRecord {
+	return listBucketRecord{
+		Name:         l.Bucket.Name,
+		CreationDate: l.Bucket.CreationDate.UTC().Format(time.RFC3339),
+	}
+}
+
+// String returns the string representation of ListBucketMessage.
+func (l ListBucketMessage) String() string {
+	if l.jsonOutput {
+		return strutil.JSON(l.record())
+	}
+	return fmt.Sprintf("%s  s3://%s", l.timeFormat.format(l.Bucket.CreationDate), l.Bucket.Name)
+}
+
+// JSON returns the JSON representation of ListBucketMessage.
+func (l ListBucketMessage) JSON() string {
+	if l.jsonOutput {
+		return strutil.JSON(l.record())
+	}
+	return l.Bucket.JSON()
+}
+
+// timeFormat is the layout and the time zone of the times printed by ls.
+type timeFormat struct {
+	layout string
+	utc    bool
+}
+
+// timeFormatFromFlags returns the time format given with --time-format and
+// --utc flags. "rfc3339" is accepted as an alias of its Go layout.
+func timeFormatFromFlags(c *cli.Context) timeFormat {
+	layout := c.String("time-format")
+	if strings.EqualFold(layout, "rfc3339") {
+		layout = time.RFC3339
+	}
+	return timeFormat{layout: layout, utc: c.Bool("utc")}
+}
+
+// format formats the given time with the layout, in UTC if asked for.
+func (f timeFormat) format(t time.Time) string {
+	if f.utc {
+		t = t.UTC()
+	}
+	return t.Format(f.layout)
+}
+
 func validateLSCommand(c *cli.Context) error {
-	if c.Args().Len() > 1 {
-		return fmt.Errorf("expected only 1 argument")
+	if c.Int("max-depth") < 0 {
+		return fmt.Errorf("--max-depth can not be negative")
 	}
-	return nil
+
+	// a layout without any elements prints the same text for all times.
+	layout := timeFormatFromFlags(c).layout
+	if layout == "" || time.Unix(0, 0).UTC().Format(layout) == layout {
+		return fmt.Errorf("invalid --time-format %q: must be a Go time layout, e.g. %q, or rfc3339", c.String("time-format"), dateFormat)
+	}
+
+	if c.String("from-inventory") != "" && !c.Args().Present() {
+		return fmt.Errorf("--from-inventory can not be used for listing buckets")
+	}
+
+	if c.Bool("empty-prefixes") {
+		if !c.Args().Present() {
+			return fmt.Errorf("--empty-prefixes can not be used for listing buckets")
+		}
+
+		for _, arg := range c.Args().Slice() {
+			u, err := url.New(arg)
+			if err != nil {
+				return err
+			}
+			if !u.IsRemote() {
+				return fmt.Errorf("--empty-prefixes can only be used with remote sources")
+			}
+		}
+	}
+	return validateInventorySources(c, c.Args().Slice())
 }
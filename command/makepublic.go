@@ -0,0 +1,263 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+const (
+	aclPublicRead = "public-read"
+	aclPrivate    = "private"
+)
+
+var setACLHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] argument [argument]
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Change the access of an S3 object
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/object.jpg
+
+	2. Change the access of all jpg objects under a prefix
+		 > s5cmd {{.HelpName}} s3://bucket/images/*.jpg
+
+	3. Change the access of all objects under a prefix
+		 > s5cmd {{.HelpName}} --recursive s3://bucket/images/
+
+Arguments which match all the objects under a bucket or a prefix, such as
+'prefix/*' or 'prefix/', require --recursive flag. Existing grants of the
+objects are replaced.
+`
+
+var makePublicCommand = newSetACLCommand("make-public", aclPublicRead, "make objects publicly readable")
+
+var makePrivateCommand = newSetACLCommand("make-private", aclPrivate, "make objects accessible only by their owner")
+
+// newSetACLCommand creates a command which sets the given canned ACL of the
+// objects.
+func newSetACLCommand(name, acl, usage string) *cli.Command {
+	return &cli.Command{
+		Name:               name,
+		HelpName:           name,
+		Usage:              fmt.Sprintf("%v, sets '%v' acl", usage, acl),
+		CustomHelpTemplate: setACLHelpTemplate,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "recursive",
+				Aliases: []string{"R"},
+				Usage:   "allow changing all the objects under a bucket or a prefix",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			err := validateSetACLCommand(c)
+			if err != nil {
+				printError(givenCommand(c), c.Command.Name, err)
+			}
+			return err
+		},
+		Action: func(c *cli.Context) (err error) {
+			defer stat.Collect(c.Command.FullName(), &err)()
+
+			return SetACL{
+				src:         c.Args().Slice(),
+				op:          c.Command.Name,
+				fullCommand: givenCommand(c),
+				acl:         acl,
+				// flags
+				recursive: c.Bool("recursive"),
+
+				storageOpts: NewStorageOpts(c),
+			}.Run(c.Context)
+		},
+	}
+}
+
+// SetACL holds canned ACL update operation flags and states.
+type SetACL struct {
+	src         []string
+	op          string
+	fullCommand string
+	acl         string
+
+	// flags
+	recursive bool
+
+	storageOpts storage.Options
+}
+
+// Run sets the canned ACL of the given source objects, and prints the number
+// of the updated and the failed objects.
+func (s SetACL) Run(ctx context.Context) error {
+	srcurls, err := newURLs(s.src...)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	if s.recursive {
+		for i, srcurl := range srcurls {
+			srcurls[i], err = srcurl.Recursive()
+			if err != nil {
+				printError(s.fullCommand, s.op, err)
+				return err
+			}
+		}
+	}
+
+	client, err := storage.NewRemoteClient(ctx, srcurls[0], s.storageOpts)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	objch := expandSources(ctx, client, false, srcurls...)
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+
+		changed int64
+		failed  int64
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(s.fullCommand, s.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	for object := range objch {
+		if parallel.IsDraining() {
+			break
+		}
+
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			printError(s.fullCommand, s.op, err)
+			merror = multierror.Append(merror, err)
+			continue
+		}
+
+		srcurl := object.URL
+		task := func() error {
+			if err := client.SetACL(ctx, srcurl, s.acl); err != nil {
+				atomic.AddInt64(&failed, 1)
+				return &errorpkg.Error{
+					Op:  s.op,
+					Src: srcurl,
+					Err: err,
+				}
+			}
+			atomic.AddInt64(&changed, 1)
+
+			msg := log.InfoMessage{
+				Operation: s.op,
+				Source:    srcurl,
+			}
+			log.Info(msg)
+			return nil
+		}
+
+		parallel.Run(task, waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	log.Info(SetACLMessage{
+		Operation: s.op,
+		ACL:       s.acl,
+		Changed:   changed,
+		Failed:    failed,
+	})
+
+	return merror
+}
+
+// SetACLMessage is the structure for logging the number of the objects whose
+// canned ACL is set, and the ones which failed.
+type SetACLMessage struct {
+	Operation string `json:"operation"`
+	ACL       string `json:"acl"`
+	Changed   int64  `json:"changed"`
+	Failed    int64  `json:"failed"`
+}
+
+// String returns the string representation of SetACLMessage.
+func (m SetACLMessage) String() string {
+	return fmt.Sprintf("%v set %v acl of %d objects, %d failed", m.Operation, m.ACL, m.Changed, m.Failed)
+}
+
+// JSON returns the JSON representation of SetACLMessage.
+func (m SetACLMessage) JSON() string {
+	return strutil.JSON(m)
+}
+
+func validateSetACLCommand(c *cli.Context) error {
+	if !c.Args().Present() {
+		return fmt.Errorf("expected at least 1 object")
+	}
+
+	for _, src := range c.Args().Slice() {
+		srcurl, err := url.New(src)
+		if err != nil {
+			return err
+		}
+
+		if !srcurl.IsRemote() {
+			return fmt.Errorf("source must be a remote object")
+		}
+
+		if c.Bool("recursive") {
+			continue
+		}
+
+		if srcurl.IsBucket() || srcurl.IsPrefix() {
+			return fmt.Errorf("%q is a bucket or a prefix, use --recursive to change all the objects under it", src)
+		}
+
+		if matchesAllSubPrefixes(srcurl) {
+			return fmt.Errorf("%q matches all the objects under its sub-prefixes, use --recursive to change them", src)
+		}
+	}
+	return nil
+}
+
+// matchesAllSubPrefixes reports whether the remote wildcard matches every
+// object under a bucket or a prefix, at any depth, e.g. 's3://bucket/*' or
+// 's3://bucket/prefix/*'. Wildcards match the separators too, so such an
+// argument removes the whole tree under it.
+func matchesAllSubPrefixes(u *url.URL) bool {
+	if !u.IsRemote() || !u.HasGlob() {
+		return false
+	}
+
+	_, last := path.Split(u.Path)
+	return strings.Trim(last, "*") == ""
+}
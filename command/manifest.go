@@ -0,0 +1,154 @@
+package command
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+var (
+	manifestHeader      = []string{"source", "destination", "size", "etag", "timestamp"}
+	errorManifestHeader = []string{"source", "destination", "error", "timestamp"}
+)
+
+// manifest is a CSV record of the transferred objects. Rows are written by
+// concurrent workers, so the writes are serialized. Each row is flushed
+// immediately, so that the record stays accurate even if the program is
+// killed in the middle of a transfer.
+type manifest struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *csv.Writer
+}
+
+// openManifest opens the manifest file at the given path for appending. The
+// header is only written if the file is empty. A nil manifest is returned if
+// path is empty, which ignores all writes.
+func openManifest(path string, header []string) (*manifest, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	m := &manifest{
+		file: file,
+		w:    csv.NewWriter(file),
+	}
+
+	if info.Size() == 0 {
+		if err := m.write(header); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// writeTransfer appends a row for a successfully transferred object.
+func (m *manifest) writeTransfer(src, dst *url.URL, size int64, etag string) error {
+	if m == nil {
+		return nil
+	}
+
+	return m.write([]string{
+		src.String(),
+		dst.String(),
+		strconv.FormatInt(size, 10),
+		etag,
+		time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// newlineReplacer is used to keep each row of the error manifest on a single
+// line. AWS errors span multiple lines.
+var newlineReplacer = strings.NewReplacer("\r\n", " ", "\n\t", " ", "\n", " ")
+
+// writeError appends a row for an object which couldn't be transferred.
+func (m *manifest) writeError(src, dst *url.URL, err error) error {
+	if m == nil {
+		return nil
+	}
+
+	var dstname string
+	if dst != nil {
+		dstname = dst.String()
+	}
+
+	return m.write([]string{
+		src.String(),
+		dstname,
+		newlineReplacer.Replace(err.Error()),
+		time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (m *manifest) write(record []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.w.Write(record); err != nil {
+		return err
+	}
+	m.w.Flush()
+	return m.w.Error()
+}
+
+// readManifestSources returns the set of the sources recorded in the manifest
+// at the given path. A missing manifest is treated as an empty one, so that
+// the same command can be used for the first run and the resumed ones.
+func readManifestSources(path string) (map[string]struct{}, error) {
+	sources := map[string]struct{}{}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return sources, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = -1
+	r.ReuseRecord = true
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return sources, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read manifest %q: %v", path, err)
+		}
+
+		if len(record) == 0 || record[0] == manifestHeader[0] {
+			continue
+		}
+		sources[record[0]] = struct{}{}
+	}
+}
+
+// Close closes the manifest file.
+func (m *manifest) Close() error {
+	if m == nil {
+		return nil
+	}
+	return m.file.Close()
+}
@@ -0,0 +1,153 @@
+package command
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestManifestConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "manifest.csv")
+
+	const (
+		numRuns    = 2
+		numWorkers = 50
+	)
+
+	// rows of consecutive runs are appended to the same file.
+	for run := 0; run < numRuns; run++ {
+		m, err := openManifest(path, manifestHeader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < numWorkers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				src, _ := url.New(fmt.Sprintf("s3://bucket/key%d", i))
+				dst, _ := url.New(fmt.Sprintf("dir/key%d", i))
+				assert.NoError(t, m.writeTransfer(src, dst, int64(i), "etag"))
+			}(i)
+		}
+		wg.Wait()
+		assert.NoError(t, m.Close())
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, records, numRuns*numWorkers+1)
+	assert.Equal(t, manifestHeader, records[0])
+	for _, record := range records[1:] {
+		assert.Len(t, record, len(manifestHeader))
+	}
+}
+
+func TestManifestErrorRow(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "errors.csv")
+
+	m, err := openManifest(path, errorManifestHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, _ := url.New("s3://bucket/key")
+	assert.NoError(t, m.writeError(src, nil, errors.New("access denied, \"forbidden\"")))
+	assert.NoError(t, m.Close())
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, records, 2)
+	assert.Equal(t, []string{"s3://bucket/key", "", "access denied, \"forbidden\""}, records[1][:3])
+}
+
+func TestNilManifestIgnoresWrites(t *testing.T) {
+	t.Parallel()
+
+	m, err := openManifest("", manifestHeader)
+	assert.NoError(t, err)
+
+	src, _ := url.New("s3://bucket/key")
+	assert.NoError(t, m.writeTransfer(src, src, 1, ""))
+	assert.NoError(t, m.writeError(src, src, errors.New("error")))
+	assert.NoError(t, m.Close())
+}
+
+func TestReadManifestSources(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// missing manifest is an empty one
+	sources, err := readManifestSources(filepath.Join(dir, "missing.csv"))
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	path := filepath.Join(dir, "manifest.csv")
+	m, err := openManifest(path, manifestHeader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"a", "b, with comma"} {
+		src, _ := url.New("s3://bucket/" + key)
+		dst, _ := url.New("dir/" + key)
+		assert.NoError(t, m.writeTransfer(src, dst, 1, "etag"))
+	}
+	assert.NoError(t, m.Close())
+
+	sources, err = readManifestSources(path)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{
+		"s3://bucket/a":             {},
+		"s3://bucket/b, with comma": {},
+	}, sources)
+}
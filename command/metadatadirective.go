@@ -0,0 +1,158 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// preservableHeaders are the headers of the source object which can be
+// carried forward with --preserve when the metadata is replaced. "metadata"
+// stands for all of the user-defined metadata.
+var preservableHeaders = []string{
+	"cache-control",
+	"content-disposition",
+	"content-encoding",
+	"content-language",
+	"content-type",
+	"metadata",
+}
+
+// metadataDirective holds the metadata settings of the objects copied from
+// S3 to S3.
+type metadataDirective struct {
+	directive    string
+	preserve     []string
+	contentType  string
+	cacheControl string
+}
+
+// metadataDirectiveFromFlags parses the metadata directive flags. Headers
+// which are preserved or overridden are only meaningful if the metadata is
+// replaced, and a header can't be both preserved and overridden.
+func metadataDirectiveFromFlags(c *cli.Context) (metadataDirective, error) {
+	d := metadataDirective{
+		contentType:  c.String("content-type"),
+		cacheControl: c.String("cache-control"),
+	}
+
+	if directive := c.String("metadata-directive"); directive != "" {
+		d.directive = strings.ToUpper(directive)
+		if d.directive != s3.MetadataDirectiveCopy && d.directive != s3.MetadataDirectiveReplace {
+			return metadataDirective{}, fmt.Errorf("--metadata-directive must be one of COPY, REPLACE")
+		}
+	}
+
+	for _, header := range c.StringSlice("preserve") {
+		for _, h := range strings.Split(header, ",") {
+			h = strings.ToLower(strings.TrimSpace(h))
+			if !isPreservableHeader(h) {
+				return metadataDirective{}, fmt.Errorf("invalid --preserve %q: must be one of %v", h, strings.Join(preservableHeaders, ", "))
+			}
+			d.preserve = append(d.preserve, h)
+		}
+	}
+
+	if d.directive != s3.MetadataDirectiveReplace {
+		if len(d.preserve) > 0 {
+			return metadataDirective{}, fmt.Errorf("--preserve can only be used with --metadata-directive REPLACE")
+		}
+		if d.contentType != "" || d.cacheControl != "" {
+			return metadataDirective{}, fmt.Errorf("--content-type and --cache-control can only be used with --metadata-directive REPLACE")
+		}
+	}
+
+	if d.preserves("content-type") && d.contentType != "" {
+		return metadataDirective{}, fmt.Errorf("--preserve content-type can not be used with --content-type")
+	}
+	if d.preserves("cache-control") && d.cacheControl != "" {
+		return metadataDirective{}, fmt.Errorf("--preserve cache-control can not be used with --cache-control")
+	}
+
+	return d, nil
+}
+
+func isPreservableHeader(header string) bool {
+	for _, h := range preservableHeaders {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
+
+// isSet reports whether a metadata directive is given.
+func (d metadataDirective) isSet() bool {
+	return d.directive != ""
+}
+
+// replaces reports whether the metadata of the copied objects is replaced with
+// the given one.
+func (d metadataDirective) replaces() bool {
+	return d.directive == s3.MetadataDirectiveReplace
+}
+
+// preserves reports whether the given header is carried forward from the
+// source object.
+func (d metadataDirective) preserves(header string) bool {
+	for _, h := range d.preserve {
+		if h == header {
+			return true
+		}
+	}
+	return false
+}
+
+// needsSource reports whether the metadata of the source object is needed,
+// i.e. any of its headers is preserved.
+func (d metadataDirective) needsSource() bool {
+	return len(d.preserve) > 0
+}
+
+// setMetadata sets the directive on the metadata of a copied object. If the
+// metadata is replaced, the preserved headers are taken from the metadata of
+// the source object, and the given ones are overridden. Headers which are
+// neither preserved nor given are dropped.
+func (d metadataDirective) setMetadata(metadata, source storage.Metadata) storage.Metadata {
+	if !d.isSet() {
+		return metadata
+	}
+
+	metadata.SetMetadataDirective(d.directive)
+	if d.directive != s3.MetadataDirectiveReplace {
+		return metadata
+	}
+
+	if d.preserves("cache-control") {
+		metadata.SetCacheControl(source.CacheControl())
+	}
+	if d.preserves("content-disposition") {
+		metadata.SetContentDisposition(source.ContentDisposition())
+	}
+	if d.preserves("content-encoding") {
+		metadata.SetContentEncoding(source.ContentEncoding())
+	}
+	if d.preserves("content-language") {
+		metadata.SetContentLanguage(source.ContentLanguage())
+	}
+	if d.preserves("content-type") {
+		metadata.SetContentType(source.ContentType())
+	}
+	if d.preserves("metadata") {
+		for key, value := range source.UserDefined() {
+			metadata.SetUserDefined(key, value)
+		}
+	}
+
+	if d.contentType != "" {
+		metadata.SetContentType(d.contentType)
+	}
+	if d.cacheControl != "" {
+		metadata.SetCacheControl(d.cacheControl)
+	}
+	return metadata
+}
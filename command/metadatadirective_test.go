@@ -0,0 +1,115 @@
+package command
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestMetadataDirectiveFromFlags(t *testing.T) {
+	t.Parallel()
+
+	source := storage.NewMetadata().
+		SetContentType("text/html").
+		SetCacheControl("max-age=60").
+		SetContentEncoding("gzip").
+		SetUserDefined("owner", "data-team")
+
+	testcases := []struct {
+		name         string
+		directive    string
+		preserve     []string
+		contentType  string
+		cacheControl string
+
+		expected storage.Metadata
+		wantErr  bool
+	}{
+		{
+			name:     "no directive",
+			expected: storage.Metadata{},
+		},
+		{
+			name:      "copy",
+			directive: "copy",
+			expected: storage.Metadata{
+				"MetadataDirective": "COPY",
+			},
+		},
+		{
+			name:      "replace drops all headers",
+			directive: "REPLACE",
+			expected: storage.Metadata{
+				"MetadataDirective": "REPLACE",
+			},
+		},
+		{
+			name:        "replace preserves given headers and overrides the rest",
+			directive:   "replace",
+			preserve:    []string{"cache-control,Content-Encoding", "metadata"},
+			contentType: "text/plain",
+			expected: storage.NewMetadata().
+				SetMetadataDirective("REPLACE").
+				SetCacheControl("max-age=60").
+				SetContentEncoding("gzip").
+				SetContentType("text/plain").
+				SetUserDefined("owner", "data-team"),
+		},
+		{
+			name:      "invalid directive",
+			directive: "merge",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid header",
+			directive: "REPLACE",
+			preserve:  []string{"content-md5"},
+			wantErr:   true,
+		},
+		{
+			name:     "preserve without replace",
+			preserve: []string{"cache-control"},
+			wantErr:  true,
+		},
+		{
+			name:        "override without replace",
+			directive:   "COPY",
+			contentType: "text/plain",
+			wantErr:     true,
+		},
+		{
+			name:         "preserve and override the same header",
+			directive:    "REPLACE",
+			preserve:     []string{"cache-control"},
+			cacheControl: "no-cache",
+			wantErr:      true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("cp", 0)
+			set.String("metadata-directive", tc.directive, "")
+			set.Var(cli.NewStringSlice(tc.preserve...), "preserve", "")
+			set.String("content-type", tc.contentType, "")
+			set.String("cache-control", tc.cacheControl, "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			directive, err := metadataDirectiveFromFlags(ctx)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, directive.setMetadata(storage.NewMetadata(), source))
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var modifyWindowFlag = &cli.DurationFlag{
+	Name:  "modify-window",
+	Usage: "consider modification times equal if they differ by at most given duration, e.g. 1s, as S3 keeps them in seconds",
+}
+
+// modifyWindowFromFlags returns the duration given with --modify-window, or 0
+// if it's not given.
+func modifyWindowFromFlags(c *cli.Context) (time.Duration, error) {
+	window := c.Duration("modify-window")
+	if window < 0 {
+		return 0, fmt.Errorf("--modify-window can not be negative")
+	}
+	return window, nil
+}
+
+// isNewer reports whether a is newer than b by more than the given window.
+// Modification times of S3 objects have second precision while local files
+// may have nanosecond precision, so the same file may otherwise look newer on
+// either side.
+func isNewer(a, b time.Time, window time.Duration) bool {
+	return a.Sub(b) > window
+}
@@ -2,7 +2,6 @@ package command
 
 import (
 	"github.com/peak/s5cmd/log/stat"
-	"github.com/peak/s5cmd/storage"
 
 	"github.com/urfave/cli/v2"
 )
@@ -31,6 +30,12 @@ Examples:
 
 	5. Move a directory to S3 bucket recursively
 		 > s5cmd {{.HelpName}} dir/ s3://bucket/
+
+	6. Move S3 objects to another bucket, deleting the sources only if the sizes and checksums of the copies match
+		 > s5cmd {{.HelpName}} --verify-before-delete s3://bucket/prefix/* s3://target-bucket/prefix/
+
+	7. Move a directory to S3 bucket recursively, removing the directories which are emptied by the move
+		 > s5cmd {{.HelpName}} --remove-empty-dirs dir/ s3://bucket/
 `
 
 var moveCommand = &cli.Command{
@@ -40,7 +45,7 @@ var moveCommand = &cli.Command{
 	Flags:              copyCommandFlags, // move and copy commands share the same flags
 	CustomHelpTemplate: moveHelpTemplate,
 	Before: func(c *cli.Context) error {
-		err := copyCommand.Before(c)
+		err := validateCopyCommand(c)
 		if err != nil {
 			printError(givenCommand(c), c.Command.Name, err)
 		}
@@ -49,23 +54,9 @@ var moveCommand = &cli.Command{
 	Action: func(c *cli.Context) (err error) {
 		defer stat.Collect(c.Command.FullName(), &err)()
 
-		copyCommand := Copy{
-			src:          c.Args().Get(0),
-			dst:          c.Args().Get(1),
-			op:           c.Command.Name,
-			fullCommand:  givenCommand(c),
-			deleteSource: true, // delete source
-			// flags
-			noClobber:        c.Bool("no-clobber"),
-			ifSizeDiffer:     c.Bool("if-size-differ"),
-			ifSourceNewer:    c.Bool("if-source-newer"),
-			flatten:          c.Bool("flatten"),
-			storageClass:     storage.StorageClass(c.String("storage-class")),
-			encryptionMethod: c.String("sse"),
-			encryptionKeyID:  c.String("sse-kms-key-id"),
-			acl:              c.String("acl"),
-
-			storageOpts: NewStorageOpts(c),
+		copyCommand, err := NewCopy(c, true) // delete source
+		if err != nil {
+			return err
 		}
 
 		return copyCommand.Run(c.Context)
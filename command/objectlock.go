@@ -0,0 +1,86 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// objectLock holds the object lock settings of the uploaded objects.
+type objectLock struct {
+	mode        string
+	retainUntil time.Time
+	legalHold   string
+}
+
+// objectLockFromFlags parses the object lock flags. A retention mode and a
+// retain until date are either given together, or not at all.
+func objectLockFromFlags(c *cli.Context) (objectLock, error) {
+	var lock objectLock
+
+	if mode := c.String("object-lock-mode"); mode != "" {
+		lock.mode = strings.ToUpper(mode)
+		if lock.mode != "GOVERNANCE" && lock.mode != "COMPLIANCE" {
+			return objectLock{}, fmt.Errorf("--object-lock-mode must be one of GOVERNANCE, COMPLIANCE")
+		}
+	}
+
+	if date := c.String("object-lock-retain-until"); date != "" {
+		retainUntil, err := parseRetainUntil(date)
+		if err != nil {
+			return objectLock{}, err
+		}
+		lock.retainUntil = retainUntil
+	}
+
+	if (lock.mode == "") != lock.retainUntil.IsZero() {
+		return objectLock{}, fmt.Errorf("--object-lock-mode and --object-lock-retain-until must be given together")
+	}
+
+	if status := c.String("legal-hold"); status != "" {
+		lock.legalHold = strings.ToUpper(status)
+		if lock.legalHold != "ON" && lock.legalHold != "OFF" {
+			return objectLock{}, fmt.Errorf("--legal-hold must be one of on, off")
+		}
+	}
+
+	return lock, nil
+}
+
+// parseRetainUntil parses the retain until date, either in RFC3339 format or
+// as a date, which means the beginning of the day in UTC.
+func parseRetainUntil(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(defaultDateLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(
+			"invalid --object-lock-retain-until %q: must be in 2006-01-02 or 2006-01-02T15:04:05Z07:00 format", s,
+		)
+	}
+	return t, nil
+}
+
+// isSet reports whether any object lock setting is given.
+func (l objectLock) isSet() bool {
+	return l.mode != "" || l.legalHold != ""
+}
+
+// setMetadata sets the object lock settings on the metadata of an uploaded
+// object.
+func (l objectLock) setMetadata(metadata storage.Metadata) storage.Metadata {
+	if l.mode != "" {
+		metadata.SetObjectLockMode(l.mode)
+		metadata.SetObjectLockRetainUntilDate(l.retainUntil.UTC().Format(time.RFC3339))
+	}
+	if l.legalHold != "" {
+		metadata.SetObjectLockLegalHoldStatus(l.legalHold)
+	}
+	return metadata
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestObjectLockFromFlags(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name        string
+		mode        string
+		retainUntil string
+		legalHold   string
+
+		expected storage.Metadata
+		wantErr  bool
+	}{
+		{
+			name:     "no object lock",
+			expected: storage.Metadata{},
+		},
+		{
+			name:        "retention with date",
+			mode:        "compliance",
+			retainUntil: "2030-01-01",
+			expected: storage.Metadata{
+				"ObjectLockMode":            "COMPLIANCE",
+				"ObjectLockRetainUntilDate": "2030-01-01T00:00:00Z",
+			},
+		},
+		{
+			name:        "retention with time",
+			mode:        "GOVERNANCE",
+			retainUntil: "2030-01-01T12:00:00+03:00",
+			legalHold:   "on",
+			expected: storage.Metadata{
+				"ObjectLockMode":            "GOVERNANCE",
+				"ObjectLockRetainUntilDate": "2030-01-01T09:00:00Z",
+				"ObjectLockLegalHoldStatus": "ON",
+			},
+		},
+		{
+			name:      "legal hold only",
+			legalHold: "off",
+			expected: storage.Metadata{
+				"ObjectLockLegalHoldStatus": "OFF",
+			},
+		},
+		{
+			name:        "invalid mode",
+			mode:        "forever",
+			retainUntil: "2030-01-01",
+			wantErr:     true,
+		},
+		{
+			name:        "invalid date",
+			mode:        "GOVERNANCE",
+			retainUntil: "01/01/2030",
+			wantErr:     true,
+		},
+		{
+			name:    "mode without date",
+			mode:    "GOVERNANCE",
+			wantErr: true,
+		},
+		{
+			name:        "date without mode",
+			retainUntil: "2030-01-01",
+			wantErr:     true,
+		},
+		{
+			name:      "invalid legal hold",
+			legalHold: "yes",
+			wantErr:   true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			set := flag.NewFlagSet("cp", 0)
+			set.String("object-lock-mode", tc.mode, "")
+			set.String("object-lock-retain-until", tc.retainUntil, "")
+			set.String("legal-hold", tc.legalHold, "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			lock, err := objectLockFromFlags(ctx)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, lock.setMetadata(storage.NewMetadata()))
+		})
+	}
+}
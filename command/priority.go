@@ -0,0 +1,24 @@
+package command
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/parallel"
+)
+
+var priorityFlag = &cli.StringFlag{
+	Name:  "priority",
+	Value: "normal",
+	Usage: "scheduling priority of the transfers when all workers are busy, one of high, normal or low; e.g. to run an urgent command of a run file before a large one",
+}
+
+// priorityFromFlags returns the priority given with --priority.
+func priorityFromFlags(c *cli.Context) (parallel.Priority, error) {
+	priority, err := parallel.ParsePriority(c.String("priority"))
+	if err != nil {
+		return parallel.PriorityNormal, fmt.Errorf("--priority must be one of high, normal or low")
+	}
+	return priority, nil
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// listProgressInterval is the interval between the progress messages of long
+// listings.
+const listProgressInterval = 5 * time.Second
+
+// progressFlag is shared by the commands which list objects.
+var progressFlag = &cli.BoolFlag{
+	Name:  "progress",
+	Usage: "periodically print the number of listed objects to stderr, enabled by default if stderr is a terminal",
+}
+
+// listProgress periodically prints the number of objects listed so far, so
+// that listing millions of keys doesn't look like a hang. Messages are printed
+// to stderr to keep the command output intact.
+type listProgress struct {
+	count int64
+
+	w      io.Writer
+	donech chan struct{}
+	wg     sync.WaitGroup
+}
+
+// progressFromFlags reports whether the listing progress should be printed.
+func progressFromFlags(c *cli.Context) bool {
+	return c.Bool("progress") || isTerminal(os.Stderr)
+}
+
+// startListProgress starts printing the listing progress to stderr. A nil
+// listProgress is returned if enabled is false, which ignores all calls.
+func startListProgress(enabled bool) *listProgress {
+	if !enabled {
+		return nil
+	}
+	return newListProgress(os.Stderr, listProgressInterval)
+}
+
+func newListProgress(w io.Writer, interval time.Duration) *listProgress {
+	p := &listProgress{
+		w:      w,
+		donech: make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintf(p.w, "listed %d objects so far...\n", atomic.LoadInt64(&p.count))
+			case <-p.donech:
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Add increments the number of listed objects.
+func (p *listProgress) Add() {
+	if p == nil {
+		return
+	}
+	atomic.AddInt64(&p.count, 1)
+}
+
+// Stop stops printing the progress. It must be called once the listing ends.
+func (p *listProgress) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.donech)
+	p.wg.Wait()
+}
+
+// isTerminal reports whether the given file is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
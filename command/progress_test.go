@@ -0,0 +1,67 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer which is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestListProgress(t *testing.T) {
+	t.Parallel()
+
+	var out syncBuffer
+	p := newListProgress(&out, 10*time.Millisecond)
+	for i := 0; i < 3; i++ {
+		p.Add()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), "listed 3 objects so far...\n") {
+		if time.Now().After(deadline) {
+			t.Fatalf("progress is not printed, got %q", out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	p.Stop()
+
+	// nothing is printed after the listing ends.
+	printed := out.String()
+	time.Sleep(50 * time.Millisecond)
+	if got := out.String(); got != printed {
+		t.Errorf("progress is printed after stop: %q", strings.TrimPrefix(got, printed))
+	}
+}
+
+func TestListProgressDisabled(t *testing.T) {
+	t.Parallel()
+
+	p := startListProgress(false)
+	if p != nil {
+		t.Fatalf("expected nil progress, got %v", p)
+	}
+
+	// calls are ignored
+	p.Add()
+	p.Stop()
+}
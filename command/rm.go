@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/urfave/cli/v2"
@@ -10,6 +11,7 @@ import (
 	errorpkg "github.com/peak/s5cmd/error"
 	"github.com/peak/s5cmd/log"
 	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
 	"github.com/peak/s5cmd/storage"
 	"github.com/peak/s5cmd/storage/url"
 )
@@ -27,14 +29,21 @@ Examples:
 	1. Delete an S3 object
 		 > s5cmd {{.HelpName}} s3://bucketname/prefix/object.gz
 
-	2. Delete all objects with a prefix
-		 > s5cmd {{.HelpName}} s3://bucketname/prefix/*
+	2. Delete all objects with a prefix, including the objects under its sub-prefixes
+		 > s5cmd {{.HelpName}} --recursive s3://bucketname/prefix/*
 
 	3. Delete all objects that matches a wildcard
-		 > s5cmd {{.HelpName}} s3://bucketname/*/obj*.gz
+		 > s5cmd {{.HelpName}} --recursive s3://bucketname/*/obj*.gz
 
 	4. Delete all matching objects and a specific object
-		 > s5cmd {{.HelpName}} s3://bucketname/prefix/* s3://bucketname/object1.gz
+		 > s5cmd {{.HelpName}} --recursive s3://bucketname/prefix/*.gz s3://bucketname/object1.gz
+
+	5. Report the number and the total size of the objects to be deleted, without deleting them
+		 > s5cmd {{.HelpName}} --estimate s3://bucketname/prefix/*
+
+Wildcards match the objects under the sub-prefixes too, e.g. 'prefix/*.gz'
+matches 'prefix/2020/file.gz', so remote arguments with a wildcard require
+--recursive flag.
 `
 
 var deleteCommand = &cli.Command{
@@ -42,6 +51,19 @@ var deleteCommand = &cli.Command{
 	HelpName:           "rm",
 	Usage:              "remove objects",
 	CustomHelpTemplate: deleteHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "recursive",
+			Aliases: []string{"R"},
+			Usage:   "allow removing all the objects under a bucket or a prefix",
+		},
+		&cli.BoolFlag{
+			Name:  "estimate",
+			Usage: "only list the objects and report how many objects and bytes would be removed",
+		},
+		fromInventoryFlag,
+		progressFlag,
+	},
 	Before: func(c *cli.Context) error {
 		err := validateRMCommand(c)
 		if err != nil {
@@ -51,10 +73,20 @@ var deleteCommand = &cli.Command{
 	},
 	Action: func(c *cli.Context) (err error) {
 		defer stat.Collect(c.Command.FullName(), &err)()
+
+		inventory, err := inventoryFromFlags(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return err
+		}
+
 		return Delete{
 			src:         c.Args().Slice(),
 			op:          c.Command.Name,
 			fullCommand: givenCommand(c),
+			estimate:    c.Bool("estimate"),
+			progress:    progressFromFlags(c),
+			inventory:   inventory,
 			storageOpts: NewStorageOpts(c),
 		}.Run(c.Context)
 	},
@@ -66,6 +98,11 @@ type Delete struct {
 	op          string
 	fullCommand string
 
+	// flags
+	estimate  bool
+	progress  bool
+	inventory *url.URL
+
 	// storage options
 	storageOpts storage.Options
 }
@@ -79,20 +116,43 @@ func (d Delete) Run(ctx context.Context) error {
 	}
 	srcurl := srcurls[0]
 
-	client, err := storage.NewClient(srcurl, d.storageOpts)
+	client, err := storage.NewClient(ctx, srcurl, d.storageOpts)
 	if err != nil {
 		printError(d.fullCommand, d.op, err)
 		return err
 	}
+	client = withInventory(client, d.inventory)
+
+	listctx, cancelList := context.WithCancel(ctx)
+	defer cancelList()
+
+	objChan := expandSources(listctx, client, false, srcurls...)
+
+	progress := startListProgress(d.progress)
+
+	if d.estimate {
+		return d.runEstimate(objChan, cancelList, progress)
+	}
 
-	objChan := expandSources(ctx, client, false, srcurls...)
+	// interrupted is set if the listing is stopped by an interrupt. It's
+	// read once the results are consumed, after urlch is closed.
+	var interrupted bool
 
 	// do object->url transformation
 	urlch := make(chan *url.URL)
 	go func() {
 		defer close(urlch)
+		defer progress.Stop()
 
 		for object := range objChan {
+			if parallel.IsDraining() {
+				interrupted = true
+				discardObjects(cancelList, objChan)
+				break
+			}
+
+			progress.Add()
+
 			if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
 				continue
 			}
@@ -126,9 +186,44 @@ func (d Delete) Run(ctx context.Context) error {
 		log.Info(msg)
 	}
 
+	if interrupted {
+		merror = appendInterrupted(merror, d.fullCommand, d.op)
+	}
 	return merror
 }
 
+// runEstimate reports the number and the total size of the objects that
+// would be removed, without removing them. Estimates of the listings which
+// are stopped by an interrupt are not printed.
+func (d Delete) runEstimate(objChan <-chan *storage.Object, cancel context.CancelFunc, progress *listProgress) error {
+	estimate := EstimateMessage{Operation: d.op}
+
+	for object := range objChan {
+		if parallel.IsDraining() {
+			discardObjects(cancel, objChan)
+			progress.Stop()
+			return appendInterrupted(nil, d.fullCommand, d.op)
+		}
+
+		progress.Add()
+
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			printError(d.fullCommand, d.op, err)
+			continue
+		}
+		estimate.addObject(object)
+	}
+
+	progress.Stop()
+
+	log.Info(estimate)
+	return nil
+}
+
 // newSources creates object URL list from given sources.
 func newURLs(sources ...string) ([]*url.URL, error) {
 	var urls []*url.URL
@@ -174,5 +269,35 @@ func validateRMCommand(c *cli.Context) error {
 		return fmt.Errorf("expected at least 1 object to remove")
 	}
 
-	return sourcesHaveSameType(c.Args().Slice()...)
+	if err := sourcesHaveSameType(c.Args().Slice()...); err != nil {
+		return err
+	}
+
+	if err := validateInventorySources(c, c.Args().Slice()); err != nil {
+		return err
+	}
+
+	// nothing is removed while estimating.
+	if c.Bool("recursive") || c.Bool("estimate") {
+		return nil
+	}
+
+	for _, src := range c.Args().Slice() {
+		srcurl, err := url.New(src)
+		if err != nil {
+			return err
+		}
+		if matchesAnyDepth(srcurl) {
+			return fmt.Errorf("%q matches the objects under its sub-prefixes too, use --recursive to remove them", src)
+		}
+	}
+	return nil
+}
+
+// matchesAnyDepth reports whether the remote argument has a wildcard.
+// Wildcards match the separators too, e.g. 's3://bucket/*.gz' matches
+// 's3://bucket/logs/2020/file.gz', so such an argument removes the objects at
+// any depth under the prefix before the wildcard.
+func matchesAnyDepth(u *url.URL) bool {
+	return u.IsRemote() && strings.Contains(u.Path, "*")
 }
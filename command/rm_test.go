@@ -2,6 +2,8 @@ package command
 
 import (
 	"testing"
+
+	"github.com/peak/s5cmd/storage/url"
 )
 
 func TestSourcesHaveSameType(t *testing.T) {
@@ -54,3 +56,34 @@ func TestSourcesHaveSameType(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesAnyDepth(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		src  string
+		want bool
+	}{
+		{src: "s3://bucket/*", want: true},
+		{src: "s3://bucket/prefix/*", want: true},
+		{src: "s3://bucket/p*", want: true},
+		{src: "s3://bucket/*.gz", want: true},
+		{src: "s3://bucket/prefix/**/file.gz", want: true},
+		{src: "s3://bucket/prefix/file.gz", want: false},
+		{src: "*.gz", want: false},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.src, func(t *testing.T) {
+			t.Parallel()
+
+			srcurl, err := url.New(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := matchesAnyDepth(srcurl); got != tc.want {
+				t.Errorf("matchesAnyDepth() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
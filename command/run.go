@@ -74,6 +74,10 @@ var runCommand = &cli.Command{
 		scanner := NewScanner(c.Context, reader)
 		lineno := -1
 		for line := range scanner.Scan() {
+			if parallel.IsDraining() {
+				break
+			}
+
 			lineno++
 
 			// support inline comments
@@ -0,0 +1,291 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+	"github.com/peak/s5cmd/strutil"
+)
+
+var setMetaHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Set content type of an S3 object
+		 > s5cmd {{.HelpName}} --content-type application/json s3://bucket/prefix/object.json
+
+	2. Fix content type and cache control of all html objects with a prefix
+		 > s5cmd {{.HelpName}} --content-type text/html --cache-control max-age=60 s3://bucket/site/*.html
+
+	3. Update only the html objects whose content type or cache control is different, and report how many are updated
+		 > s5cmd {{.HelpName}} --if-differ --content-type text/html --cache-control max-age=60 s3://bucket/site/*.html
+
+Metadata is updated by copying the objects onto themselves. Data, storage
+class, encryption and the metadata which is not given are preserved. ACLs of
+the objects are not preserved, use --acl to set them.
+`
+
+var setMetaCommand = &cli.Command{
+	Name:               "set-meta",
+	HelpName:           "set-meta",
+	Usage:              "update metadata of objects without changing their data",
+	CustomHelpTemplate: setMetaHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "content-type",
+			Usage: "set Content-Type of the objects",
+		},
+		&cli.StringFlag{
+			Name:  "cache-control",
+			Usage: "set Cache-Control of the objects",
+		},
+		&cli.StringFlag{
+			Name:  "acl",
+			Usage: "set acl for the objects",
+		},
+		&cli.BoolFlag{
+			Name:  "if-differ",
+			Usage: "only update the objects whose content type or cache control is different from the given ones, and report the number of updated and skipped objects",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateSetMetaCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		return SetMeta{
+			src:         c.Args().First(),
+			op:          c.Command.Name,
+			fullCommand: givenCommand(c),
+			// flags
+			contentType:  c.String("content-type"),
+			cacheControl: c.String("cache-control"),
+			acl:          c.String("acl"),
+			ifDiffer:     c.Bool("if-differ"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// SetMeta holds metadata update operation flags and states.
+type SetMeta struct {
+	src         string
+	op          string
+	fullCommand string
+
+	// flags
+	contentType  string
+	cacheControl string
+	acl          string
+	ifDiffer     bool
+
+	storageOpts storage.Options
+}
+
+// Run updates metadata of the given source objects.
+func (s SetMeta) Run(ctx context.Context) error {
+	srcurl, err := url.New(s.src)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	client, err := storage.NewRemoteClient(ctx, srcurl, s.storageOpts)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	objch, err := expandSource(ctx, client, false, srcurl)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+
+		// number of the updated and the skipped objects, for --if-differ.
+		changed int64
+		skipped int64
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(s.fullCommand, s.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	for object := range objch {
+		if parallel.IsDraining() {
+			break
+		}
+
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			printError(s.fullCommand, s.op, err)
+			continue
+		}
+
+		srcurl := object.URL
+		task := func() error {
+			updated, err := s.doSetMeta(ctx, client, srcurl)
+			if err != nil {
+				return &errorpkg.Error{
+					Op:  s.op,
+					Src: srcurl,
+					Err: err,
+				}
+			}
+			if updated {
+				atomic.AddInt64(&changed, 1)
+			} else {
+				atomic.AddInt64(&skipped, 1)
+			}
+			return nil
+		}
+
+		parallel.Run(task, waiter)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	if s.ifDiffer {
+		log.Info(SetMetaMessage{
+			Operation: s.op,
+			Changed:   changed,
+			Skipped:   skipped,
+		})
+	}
+
+	return merror
+}
+
+// doSetMeta replaces the metadata of the object by copying it onto itself.
+// Current metadata of the object is fetched first, so that only the given
+// fields are changed. It reports whether the object is updated, which it isn't
+// with --if-differ if the object already has the given metadata.
+func (s SetMeta) doSetMeta(ctx context.Context, client *storage.S3, srcurl *url.URL) (bool, error) {
+	obj, err := client.Stat(ctx, srcurl)
+	if err != nil {
+		return false, err
+	}
+
+	if s.ifDiffer && !s.differs(obj.Metadata) {
+		return false, nil
+	}
+
+	metadata := obj.Metadata.
+		SetMetadataDirective(s3.MetadataDirectiveReplace).
+		SetACL(s.acl)
+
+	if s.contentType != "" {
+		metadata.SetContentType(s.contentType)
+	}
+
+	if s.cacheControl != "" {
+		metadata.SetCacheControl(s.cacheControl)
+	}
+
+	err = client.Copy(ctx, srcurl, srcurl, metadata)
+	if err != nil {
+		return false, err
+	}
+
+	msg := log.InfoMessage{
+		Operation: s.op,
+		Source:    srcurl,
+	}
+	log.Info(msg)
+
+	return true, nil
+}
+
+// differs reports whether the given metadata of an object is different from
+// the content type or the cache control to set.
+func (s SetMeta) differs(metadata storage.Metadata) bool {
+	if s.contentType != "" && metadata.ContentType() != s.contentType {
+		return true
+	}
+	return s.cacheControl != "" && metadata.CacheControl() != s.cacheControl
+}
+
+// SetMetaMessage is the structure for logging the number of the objects whose
+// metadata is updated, and the ones skipped since they're up to date.
+type SetMetaMessage struct {
+	Operation string `json:"operation"`
+	Changed   int64  `json:"changed"`
+	Skipped   int64  `json:"skipped"`
+}
+
+// String returns the string representation of SetMetaMessage.
+func (m SetMetaMessage) String() string {
+	return fmt.Sprintf("%v updated %d objects, skipped %d objects which are up to date", m.Operation, m.Changed, m.Skipped)
+}
+
+// JSON returns the JSON representation of SetMetaMessage.
+func (m SetMetaMessage) JSON() string {
+	return strutil.JSON(m)
+}
+
+func validateSetMetaCommand(c *cli.Context) error {
+	if c.Args().Len() != 1 {
+		return fmt.Errorf("expected only 1 argument")
+	}
+
+	srcurl, err := url.New(c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	if !srcurl.IsRemote() {
+		return fmt.Errorf("source must be a remote object")
+	}
+
+	if srcurl.IsBucket() || srcurl.IsPrefix() {
+		return fmt.Errorf("source argument must contain wildcard character")
+	}
+
+	if c.String("content-type") == "" && c.String("cache-control") == "" {
+		return fmt.Errorf("at least one of --content-type or --cache-control must be given")
+	}
+
+	// acl of the objects is not returned with their metadata.
+	if c.Bool("if-differ") && c.String("acl") != "" {
+		return fmt.Errorf("--if-differ can not be used with --acl")
+	}
+
+	return nil
+}
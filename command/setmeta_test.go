@@ -0,0 +1,53 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestSetMetaDiffers(t *testing.T) {
+	t.Parallel()
+
+	metadata := storage.NewMetadata().
+		SetContentType("text/html").
+		SetCacheControl("max-age=60")
+
+	testcases := []struct {
+		name         string
+		contentType  string
+		cacheControl string
+
+		expected bool
+	}{
+		{
+			name:        "same content type",
+			contentType: "text/html",
+			expected:    false,
+		},
+		{
+			name:         "same content type and cache control",
+			contentType:  "text/html",
+			cacheControl: "max-age=60",
+			expected:     false,
+		},
+		{
+			name:        "different content type",
+			contentType: "text/plain",
+			expected:    true,
+		},
+		{
+			name:         "same content type, different cache control",
+			contentType:  "text/html",
+			cacheControl: "no-cache",
+			expected:     true,
+		},
+	}
+
+	for _, tc := range testcases {
+		s := SetMeta{contentType: tc.contentType, cacheControl: tc.cacheControl}
+		assert.Equal(t, tc.expected, s.differs(metadata), tc.name)
+	}
+}
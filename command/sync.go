@@ -0,0 +1,412 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/urfave/cli/v2"
+
+	errorpkg "github.com/peak/s5cmd/error"
+	"github.com/peak/s5cmd/log"
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+var syncHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] source destination
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Copy the new and changed objects of a bucket to another bucket
+		 > s5cmd {{.HelpName}} s3://bucket/ s3://target-bucket/
+
+	2. Sync an S3 prefix to another prefix, removing the objects which don't exist in the source
+		 > s5cmd {{.HelpName}} --delete s3://bucket/source-prefix/ s3://bucket/target-prefix/
+
+	3. Sync a bucket in MinIO to a bucket in AWS S3
+		 > s5cmd {{.HelpName}} --source-endpoint http://minio:9000 --source-profile minio s3://bucket/ s3://target-bucket/
+
+	4. Sync a bucket, considering the objects modified within a second of each other unchanged
+		 > s5cmd {{.HelpName}} --modify-window 1s s3://bucket/ s3://target-bucket/
+
+An object is copied if it doesn't exist at the destination, or its size or
+ETag is different. ETags of the objects uploaded in multiple parts are not
+comparable, they are considered changed if the source is newer instead. Use
+--modify-window to ignore the differences of modification times within the
+given duration.
+
+Objects are copied on the server side, unless the source and the destination
+are on different endpoints, in which case they are streamed through s5cmd.
+`
+
+var syncCommand = &cli.Command{
+	Name:               "sync",
+	HelpName:           "sync",
+	Usage:              "copy new and changed objects from an S3 prefix to another",
+	CustomHelpTemplate: syncHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "delete",
+			Usage: "remove the destination objects which don't exist in the source",
+		},
+		&cli.BoolFlag{
+			Name:    "force",
+			Aliases: []string{"overwrite"},
+			Usage:   "copy all source objects, even if they are not changed",
+		},
+		modifyWindowFlag,
+		priorityFlag,
+		&cli.StringFlag{
+			Name:  "storage-class",
+			Usage: "set storage class for target ('STANDARD','REDUCED_REDUNDANCY','GLACIER','STANDARD_IA','ONEZONE_IA','INTELLIGENT_TIERING','DEEP_ARCHIVE')",
+		},
+		&cli.StringFlag{
+			Name:  "sse",
+			Usage: "perform server side encryption of the data at its destination, e.g. aws:kms",
+		},
+		&cli.StringFlag{
+			Name:  "sse-kms-key-id",
+			Usage: "customer master key (CMK) id for SSE-KMS encryption; leave it out if server-side generated key is desired",
+		},
+		&cli.StringFlag{
+			Name:  "acl",
+			Usage: "set acl for target: defines granted accesses and their types on different accounts/groups",
+		},
+		&cli.StringFlag{
+			Name:  "source-endpoint",
+			Usage: "override the S3 host of the source objects, e.g. to sync objects between different S3 compatible services",
+		},
+		&cli.StringFlag{
+			Name:  "source-region",
+			Usage: "set the region of the source objects",
+		},
+		&cli.StringFlag{
+			Name:  "source-profile",
+			Usage: "use the credentials of given AWS profile to access the source objects",
+		},
+		&cli.StringFlag{
+			Name:  "dest-endpoint",
+			Usage: "override the S3 host of the target objects, e.g. to sync objects between different S3 compatible services",
+		},
+		&cli.StringFlag{
+			Name:  "dest-region",
+			Usage: "set the region of the target objects",
+		},
+		&cli.StringFlag{
+			Name:  "dest-profile",
+			Usage: "use the credentials of given AWS profile to access the target objects",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateSyncCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+		}
+		return err
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		priority, err := priorityFromFlags(c)
+		if err != nil {
+			return err
+		}
+
+		return Sync{
+			src:         c.Args().Get(0),
+			dst:         c.Args().Get(1),
+			op:          c.Command.Name,
+			fullCommand: givenCommand(c),
+			// flags
+			delete:           c.Bool("delete"),
+			force:            c.Bool("force"),
+			modifyWindow:     c.Duration("modify-window"),
+			priority:         priority,
+			storageClass:     storage.StorageClass(c.String("storage-class")),
+			encryptionMethod: c.String("sse"),
+			encryptionKeyID:  c.String("sse-kms-key-id"),
+			acl:              c.String("acl"),
+			srcEndpoint:      endpointFromFlags(c, "source"),
+			dstEndpoint:      endpointFromFlags(c, "dest"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// Sync holds sync operation flags and states.
+type Sync struct {
+	src         string
+	dst         string
+	op          string
+	fullCommand string
+
+	// flags
+	delete           bool
+	force            bool
+	modifyWindow     time.Duration
+	priority         parallel.Priority
+	storageClass     storage.StorageClass
+	encryptionMethod string
+	encryptionKeyID  string
+	acl              string
+	srcEndpoint      endpoint
+	dstEndpoint      endpoint
+
+	storageOpts storage.Options
+}
+
+// Run copies the source objects which are missing or changed at the
+// destination. If delete is set, the destination objects which don't exist in
+// the source are removed once all objects are copied.
+func (s Sync) Run(ctx context.Context) error {
+	srcurl, err := newSyncURL(s.src)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	dsturl, err := url.New(s.dst)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	dstlisturl, err := newSyncURL(s.dst)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	srcClient, err := storage.NewRemoteClient(ctx, srcurl, s.srcEndpoint.storageOpts(s.storageOpts))
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	dstClient, err := storage.NewRemoteClient(ctx, dsturl, s.dstEndpoint.storageOpts(s.storageOpts))
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	// destination objects are listed first and kept in memory, so that each
+	// source object can be compared with its counterpart as it's listed.
+	dstObjects, err := s.listDestination(ctx, dstClient, dstlisturl)
+	if err != nil {
+		printError(s.fullCommand, s.op, err)
+		return err
+	}
+
+	copyCommand := Copy{
+		op:               s.op,
+		fullCommand:      s.fullCommand,
+		storageClass:     s.storageClass,
+		encryptionMethod: s.encryptionMethod,
+		encryptionKeyID:  s.encryptionKeyID,
+		acl:              s.acl,
+		srcEndpoint:      s.srcEndpoint,
+		dstEndpoint:      s.dstEndpoint,
+		storageOpts:      s.storageOpts,
+		priority:         s.priority,
+	}
+
+	waiter := parallel.NewWaiter()
+
+	var (
+		merror    error
+		errDoneCh = make(chan bool)
+	)
+
+	go func() {
+		defer close(errDoneCh)
+		for err := range waiter.Err() {
+			printError(s.fullCommand, s.op, err)
+			merror = multierror.Append(merror, err)
+		}
+	}()
+
+	var interrupted bool
+	for object := range srcClient.List(ctx, srcurl, false) {
+		if parallel.IsDraining() {
+			interrupted = true
+			break
+		}
+
+		if object.Type.IsDir() || errorpkg.IsCancelation(object.Err) {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			// the destination is pruned only if the source is fully listed.
+			interrupted = true
+			printError(s.fullCommand, s.op, err)
+			continue
+		}
+
+		key := object.URL.Relative()
+		dstobj, ok := dstObjects[key]
+		delete(dstObjects, key)
+
+		if ok && !s.force && !shouldSync(object, dstobj, s.modifyWindow) {
+			continue
+		}
+
+		task := copyCommand.prepareCopyTask(ctx, object, dsturl, true)
+		parallel.RunWithPriority(task, waiter, s.priority)
+	}
+
+	waiter.Wait()
+	<-errDoneCh
+
+	if !s.delete || interrupted || ctx.Err() != nil {
+		return merror
+	}
+
+	if err := s.deleteStale(ctx, dstClient, dstObjects); err != nil {
+		merror = multierror.Append(merror, err)
+	}
+
+	return merror
+}
+
+// listDestination returns the destination objects by their keys relative to
+// the destination prefix.
+func (s Sync) listDestination(
+	ctx context.Context,
+	client *storage.S3,
+	dsturl *url.URL,
+) (map[string]*storage.Object, error) {
+	objects := map[string]*storage.Object{}
+	for object := range client.List(ctx, dsturl, false) {
+		if object.Err == storage.ErrNoObjectFound {
+			continue
+		}
+
+		if err := object.Err; err != nil {
+			return nil, err
+		}
+
+		if object.Type.IsDir() {
+			continue
+		}
+		objects[object.URL.Relative()] = object
+	}
+	return objects, nil
+}
+
+// deleteStale removes the given destination objects.
+func (s Sync) deleteStale(
+	ctx context.Context,
+	client *storage.S3,
+	objects map[string]*storage.Object,
+) error {
+	urlch := make(chan *url.URL)
+	go func() {
+		defer close(urlch)
+		for _, object := range objects {
+			urlch <- object.URL
+		}
+	}()
+
+	var merror error
+	for obj := range client.MultiDelete(ctx, urlch) {
+		if err := obj.Err; err != nil {
+			if errorpkg.IsCancelation(obj.Err) {
+				continue
+			}
+
+			merror = multierror.Append(merror, obj.Err)
+			printError(s.fullCommand, s.op, obj.Err)
+			continue
+		}
+
+		msg := log.InfoMessage{
+			Operation: "rm",
+			Source:    obj.URL,
+		}
+		log.Info(msg)
+	}
+	return merror
+}
+
+// shouldSync reports whether the source object is different from the
+// destination object. ETags are compared if both are MD5 digests of the
+// content. Otherwise, the source is considered changed if it's newer by more
+// than the given window.
+func shouldSync(src, dst *storage.Object, window time.Duration) bool {
+	if src.Size != dst.Size {
+		return true
+	}
+
+	if isComparableETag(src.Etag) && isComparableETag(dst.Etag) {
+		return src.Etag != dst.Etag
+	}
+
+	if src.ModTime == nil || dst.ModTime == nil {
+		return true
+	}
+	return isNewer(*src.ModTime, *dst.ModTime, window)
+}
+
+// isComparableETag reports whether the ETag is an MD5 digest of the object
+// content. ETags of the objects uploaded in multiple parts have a '-<parts>'
+// suffix and depend on the part size, so they can't be compared.
+func isComparableETag(etag string) bool {
+	return etag != "" && !strings.Contains(etag, "-")
+}
+
+// newSyncURL returns a URL to list all objects under the given bucket or
+// prefix.
+func newSyncURL(s string) (*url.URL, error) {
+	u, err := url.New(s)
+	if err != nil {
+		return nil, err
+	}
+	return u.Recursive()
+}
+
+func validateSyncCommand(c *cli.Context) error {
+	if c.Args().Len() != 2 {
+		return fmt.Errorf("expected source and destination arguments")
+	}
+
+	for _, arg := range c.Args().Slice() {
+		u, err := url.New(arg)
+		if err != nil {
+			return err
+		}
+
+		if !u.IsRemote() {
+			return fmt.Errorf("%q must be a remote bucket or prefix", arg)
+		}
+
+		if u.HasGlob() {
+			return fmt.Errorf("%q can not contain glob characters", arg)
+		}
+
+		if !u.IsBucket() && !u.IsPrefix() {
+			return fmt.Errorf("%q must be a bucket or a prefix", arg)
+		}
+	}
+
+	if _, err := modifyWindowFromFlags(c); err != nil {
+		return err
+	}
+
+	if _, err := priorityFromFlags(c); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"testing"
+	"time"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestShouldSync(t *testing.T) {
+	t.Parallel()
+
+	older := time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		src    *storage.Object
+		dst    *storage.Object
+		window time.Duration
+		want   bool
+	}{
+		{
+			name: "different_size",
+			src:  &storage.Object{Size: 10, Etag: "5d41402abc4b2a76b9719d911017c592", ModTime: &older},
+			dst:  &storage.Object{Size: 11, Etag: "5d41402abc4b2a76b9719d911017c592", ModTime: &newer},
+			want: true,
+		},
+		{
+			name: "same_etag",
+			src:  &storage.Object{Size: 10, Etag: "5d41402abc4b2a76b9719d911017c592", ModTime: &newer},
+			dst:  &storage.Object{Size: 10, Etag: "5d41402abc4b2a76b9719d911017c592", ModTime: &older},
+			want: false,
+		},
+		{
+			name: "different_etag",
+			src:  &storage.Object{Size: 10, Etag: "5d41402abc4b2a76b9719d911017c592", ModTime: &older},
+			dst:  &storage.Object{Size: 10, Etag: "7d793037a0760186574b0282f2f435e7", ModTime: &newer},
+			want: true,
+		},
+		{
+			name: "multipart_etag_and_newer_source",
+			src:  &storage.Object{Size: 10, Etag: "9b2cf535f27731c974343645a3985328-2", ModTime: &newer},
+			dst:  &storage.Object{Size: 10, Etag: "7d793037a0760186574b0282f2f435e7", ModTime: &older},
+			want: true,
+		},
+		{
+			name: "multipart_etag_and_older_source",
+			src:  &storage.Object{Size: 10, Etag: "9b2cf535f27731c974343645a3985328-2", ModTime: &older},
+			dst:  &storage.Object{Size: 10, Etag: "7d793037a0760186574b0282f2f435e7", ModTime: &newer},
+			want: false,
+		},
+		{
+			name:   "multipart_etag_and_newer_source_within_window",
+			src:    &storage.Object{Size: 10, Etag: "9b2cf535f27731c974343645a3985328-2", ModTime: &newer},
+			dst:    &storage.Object{Size: 10, Etag: "7d793037a0760186574b0282f2f435e7", ModTime: &older},
+			window: time.Hour,
+			want:   false,
+		},
+		{
+			name:   "multipart_etag_and_newer_source_out_of_window",
+			src:    &storage.Object{Size: 10, Etag: "9b2cf535f27731c974343645a3985328-2", ModTime: &newer},
+			dst:    &storage.Object{Size: 10, Etag: "7d793037a0760186574b0282f2f435e7", ModTime: &older},
+			window: time.Second,
+			want:   true,
+		},
+		{
+			name: "missing_etag_and_modification_time",
+			src:  &storage.Object{Size: 10},
+			dst:  &storage.Object{Size: 10},
+			want: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := shouldSync(tc.src, tc.dst, tc.window); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
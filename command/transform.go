@@ -0,0 +1,127 @@
+package command
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// transform is a sed-like substitution expression which rewrites the names of
+// the objects before they are written to the destination.
+//
+// Example:
+//
+//	expression: s/^raw/processed/
+//	name: raw/2020/file.gz
+//	output: processed/2020/file.gz
+type transform struct {
+	re          *regexp.Regexp
+	replacement string
+	global      bool
+}
+
+// parseTransform parses the given 's/regex/replacement/flags' expression. Any
+// character can be used as the delimiter, i.e. 's|a|b|'. The only supported
+// flag is 'g', which replaces all matches instead of the first one.
+// Backreferences in the replacement can be given as '\1' or '${1}'.
+func parseTransform(expr string) (*transform, error) {
+	if len(expr) < 2 || expr[0] != 's' {
+		return nil, fmt.Errorf("invalid transform expression %q: must be in 's/regex/replacement/' format", expr)
+	}
+
+	delim := expr[1]
+	parts := splitUnescaped(expr[2:], delim)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid transform expression %q: must be in 's/regex/replacement/' format", expr)
+	}
+
+	pattern, replacement, flags := parts[0], parts[1], parts[2]
+	if pattern == "" {
+		return nil, fmt.Errorf("invalid transform expression %q: empty regex", expr)
+	}
+
+	var global bool
+	for _, flag := range flags {
+		switch flag {
+		case 'g':
+			global = true
+		default:
+			return nil, fmt.Errorf("invalid transform expression %q: unknown flag %q", expr, flag)
+		}
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transform expression %q: %v", expr, err)
+	}
+
+	return &transform{
+		re:          re,
+		replacement: sedBackrefRe.ReplaceAllString(replacement, "$${$1}"),
+		global:      global,
+	}, nil
+}
+
+// sedBackrefRe matches sed style backreferences, such as '\1'.
+var sedBackrefRe = regexp.MustCompile(`\\(\d)`)
+
+// apply returns the transformed name. If the expression doesn't match, name is
+// returned as is.
+func (t *transform) apply(name string) string {
+	if t == nil {
+		return name
+	}
+
+	if t.global {
+		return t.re.ReplaceAllString(name, t.replacement)
+	}
+
+	loc := t.re.FindStringSubmatchIndex(name)
+	if loc == nil {
+		return name
+	}
+
+	var dst []byte
+	dst = t.re.ExpandString(dst, t.replacement, name, loc)
+	return name[:loc[0]] + string(dst) + name[loc[1]:]
+}
+
+// isOutsideDir reports whether the name refers to a path outside of the
+// directory it's joined to, i.e. it's absolute or it starts with '..' once
+// it's cleaned.
+func isOutsideDir(name string) bool {
+	if filepath.IsAbs(name) {
+		return true
+	}
+
+	name = path.Clean(filepath.ToSlash(name))
+	return path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../")
+}
+
+// splitUnescaped splits s by the given delimiter, ignoring the delimiters that
+// are escaped with a backslash. Escaped delimiters are unescaped in the
+// output.
+func splitUnescaped(s string, delim byte) []string {
+	var (
+		parts []string
+		buf   strings.Builder
+	)
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && s[i+1] == delim {
+			buf.WriteByte(delim)
+			i++
+			continue
+		}
+
+		if s[i] == delim {
+			parts = append(parts, buf.String())
+			buf.Reset()
+			continue
+		}
+		buf.WriteByte(s[i])
+	}
+	return append(parts, buf.String())
+}
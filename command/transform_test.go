@@ -0,0 +1,141 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		expr    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "replace_prefix",
+			expr:  "s/^raw/processed/",
+			input: "raw/2020/raw.gz",
+			want:  "processed/2020/raw.gz",
+		},
+		{
+			name:  "replace_first_match_only",
+			expr:  "s/a/b/",
+			input: "a/a/a",
+			want:  "b/a/a",
+		},
+		{
+			name:  "replace_all_matches",
+			expr:  "s/a/b/g",
+			input: "a/a/a",
+			want:  "b/b/b",
+		},
+		{
+			name:  "no_match",
+			expr:  "s/^raw/processed/",
+			input: "data/raw.gz",
+			want:  "data/raw.gz",
+		},
+		{
+			name:  "custom_delimiter",
+			expr:  "s|^raw/|processed/|",
+			input: "raw/file.gz",
+			want:  "processed/file.gz",
+		},
+		{
+			name:  "escaped_delimiter",
+			expr:  `s/^raw\//processed\//`,
+			input: "raw/file.gz",
+			want:  "processed/file.gz",
+		},
+		{
+			name:  "backreference",
+			expr:  `s/^(\d+)-(\w+)/\2-\1/`,
+			input: "2020-logs.txt",
+			want:  "logs-2020.txt",
+		},
+		{
+			name:  "prepend",
+			expr:  "s/^/processed\\//",
+			input: "file.gz",
+			want:  "processed/file.gz",
+		},
+		{
+			name:    "error_if_not_substitution",
+			expr:    "y/a/b/",
+			wantErr: true,
+		},
+		{
+			name:    "error_if_missing_delimiter",
+			expr:    "s/a/b",
+			wantErr: true,
+		},
+		{
+			name:    "error_if_empty_regex",
+			expr:    "s//b/",
+			wantErr: true,
+		},
+		{
+			name:    "error_if_unknown_flag",
+			expr:    "s/a/b/x",
+			wantErr: true,
+		},
+		{
+			name:    "error_if_invalid_regex",
+			expr:    "s/(a/b/",
+			wantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tr, err := parseTransform(tc.expr)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error for expression %q", tc.expr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := tr.apply(tc.input); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestIsOutsideDir(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "file.txt", want: false},
+		{name: "a/b/file.txt", want: false},
+		{name: "a/../file.txt", want: false},
+		{name: "..file.txt", want: false},
+		{name: "..", want: true},
+		{name: "../file.txt", want: true},
+		{name: "a/../../file.txt", want: true},
+		{name: "/etc/file.txt", want: true},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := isOutsideDir(tc.name); got != tc.want {
+				t.Errorf("got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// maxUserMetadataSize is the maximum total size of the keys and the values of
+// user-defined metadata, in bytes.
+const maxUserMetadataSize = 2 * 1024
+
+// userMetadata holds the user-defined metadata of the uploaded objects.
+type userMetadata map[string]string
+
+// userMetadataFromFlags loads the user-defined metadata from the file given
+// with --metadata-from-file, and then from --meta flags, which override the
+// ones in the file. Keys are case-insensitive, so they are lowercased as S3
+// does.
+func userMetadataFromFlags(c *cli.Context) (userMetadata, error) {
+	meta := userMetadata{}
+
+	if path := c.String("metadata-from-file"); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --metadata-from-file %q: %v", path, err)
+		}
+
+		var fromFile map[string]string
+		if err := json.Unmarshal(data, &fromFile); err != nil {
+			return nil, fmt.Errorf("invalid --metadata-from-file %q: must be a JSON object of string values: %v", path, err)
+		}
+
+		for key, value := range fromFile {
+			meta[strings.ToLower(key)] = value
+		}
+	}
+
+	for _, kv := range c.StringSlice("meta") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid --meta %q: must be in key=value format", kv)
+		}
+		meta[strings.ToLower(parts[0])] = parts[1]
+	}
+
+	if err := meta.validate(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// validate checks that the keys are valid header names and the total size
+// is within the limit of S3.
+func (m userMetadata) validate() error {
+	var size int
+	for key, value := range m {
+		if key == "" {
+			return fmt.Errorf("metadata key can not be empty")
+		}
+
+		for _, r := range key {
+			if r > unicode.MaxASCII || !unicode.IsPrint(r) || unicode.IsSpace(r) || r == ':' {
+				return fmt.Errorf("metadata key %q must only contain printable ASCII characters without spaces and colons", key)
+			}
+		}
+
+		// non-ASCII values are sent encoded, which makes them longer.
+		size += len(key) + len(storage.EncodeMetadataValue(value))
+	}
+
+	if size > maxUserMetadataSize {
+		return fmt.Errorf("user-defined metadata is %v bytes, can not be more than %v bytes", size, maxUserMetadataSize)
+	}
+	return nil
+}
+
+// isSet reports whether any user-defined metadata is given.
+func (m userMetadata) isSet() bool {
+	return len(m) > 0
+}
+
+// setMetadata sets the user-defined metadata on the metadata of an uploaded
+// object.
+func (m userMetadata) setMetadata(metadata storage.Metadata) storage.Metadata {
+	for key, value := range m {
+		metadata.SetUserDefined(key, value)
+	}
+	return metadata
+}
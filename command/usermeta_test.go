@@ -0,0 +1,138 @@
+package command
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestUserMetadataFromFlags(t *testing.T) {
+	t.Parallel()
+
+	f, err := ioutil.TempFile("", "s5cmd-meta-")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(`{"Owner": "data-team", "build-id": "42"}`)
+	assert.NoError(t, err)
+	f.Close()
+
+	invalid, err := ioutil.TempFile("", "s5cmd-meta-")
+	assert.NoError(t, err)
+	defer os.Remove(invalid.Name())
+
+	_, err = invalid.WriteString(`{"retries": 3}`)
+	assert.NoError(t, err)
+	invalid.Close()
+
+	testcases := []struct {
+		name string
+		file string
+		meta []string
+
+		expected storage.Metadata
+		wantErr  bool
+	}{
+		{
+			name:     "no metadata",
+			expected: storage.Metadata{},
+		},
+		{
+			name: "inline",
+			meta: []string{"owner=data-team", "query=a=b"},
+			expected: storage.Metadata{
+				"X-Amz-Meta-owner": "data-team",
+				"X-Amz-Meta-query": "a=b",
+			},
+		},
+		{
+			name: "from file",
+			file: f.Name(),
+			expected: storage.Metadata{
+				"X-Amz-Meta-owner":    "data-team",
+				"X-Amz-Meta-build-id": "42",
+			},
+		},
+		{
+			name: "inline overrides file",
+			file: f.Name(),
+			meta: []string{"OWNER=ops-team"},
+			expected: storage.Metadata{
+				"X-Amz-Meta-owner":    "ops-team",
+				"X-Amz-Meta-build-id": "42",
+			},
+		},
+		{
+			name:    "missing file",
+			file:    f.Name() + "-missing",
+			wantErr: true,
+		},
+		{
+			name:    "non-string value",
+			file:    invalid.Name(),
+			wantErr: true,
+		},
+		{
+			name:    "inline without value",
+			meta:    []string{"owner"},
+			wantErr: true,
+		},
+		{
+			name:    "non-ascii key",
+			meta:    []string{"sahibi=data-team", "şahibi=data-team"},
+			wantErr: true,
+		},
+		{
+			name:    "key with space",
+			meta:    []string{"build id=42"},
+			wantErr: true,
+		},
+		{
+			name:    "too large",
+			meta:    []string{"owner=" + strings.Repeat("a", maxUserMetadataSize)},
+			wantErr: true,
+		},
+		{
+			name: "non-ascii value",
+			meta: []string{"title=café"},
+			expected: storage.Metadata{
+				"X-Amz-Meta-title": "café",
+			},
+		},
+		{
+			// each non-ASCII character takes 6 bytes once it's encoded.
+			name:    "too large once encoded",
+			meta:    []string{"title=" + strings.Repeat("é", maxUserMetadataSize/4)},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		// subtests are not parallel, the metadata files are removed once the
+		// test returns.
+		t.Run(tc.name, func(t *testing.T) {
+			set := flag.NewFlagSet("cp", 0)
+			set.String("metadata-from-file", tc.file, "")
+			meta := cli.NewStringSlice(tc.meta...)
+			set.Var(meta, "meta", "")
+
+			ctx := cli.NewContext(nil, set, nil)
+			got, err := userMetadataFromFlags(ctx)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got.setMetadata(storage.NewMetadata()))
+		})
+	}
+}
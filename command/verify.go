@@ -0,0 +1,127 @@
+package command
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// verifyBeforeDelete checks the destination against the source before the
+// source of a move operation is deleted, so that the source is kept if the
+// destination is missing or incomplete.
+func (c Copy) verifyBeforeDelete(
+	ctx context.Context,
+	srcClient storage.Storage,
+	dstClient storage.Storage,
+	srcurl *url.URL,
+	dsturl *url.URL,
+) error {
+	if !c.verify || c.storageOpts.DryRun {
+		return nil
+	}
+
+	mismatch, err := compareWithSource(ctx, srcClient, dstClient, srcurl, dsturl, c.compress || c.decompress)
+	if err != nil {
+		return fmt.Errorf("verification failed, source is kept: %v", err)
+	}
+	if mismatch != "" {
+		return fmt.Errorf("verification failed, source is kept: %v", mismatch)
+	}
+	return nil
+}
+
+// compareWithSource compares the destination with the source, and returns the
+// description of the difference if they don't match. Sizes are compared unless
+// the content is transformed, i.e. compressed or decompressed. ETags of the
+// remote objects are compared with each other, or with the MD5 digests of the
+// local files, if they are MD5 digests of the content.
+func compareWithSource(
+	ctx context.Context,
+	srcClient storage.Storage,
+	dstClient storage.Storage,
+	srcurl *url.URL,
+	dsturl *url.URL,
+	transformed bool,
+) (string, error) {
+	srcobj, err := srcClient.Stat(ctx, srcurl)
+	if err != nil {
+		return "", err
+	}
+
+	dstobj, err := dstClient.Stat(ctx, dsturl)
+	if err == storage.ErrGivenObjectNotFound {
+		return fmt.Sprintf("%v does not exist", dsturl), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// content of the destination is different by design.
+	if transformed {
+		return "", nil
+	}
+
+	if srcobj.Size != dstobj.Size {
+		return fmt.Sprintf("size of %v is %d, size of %v is %d", srcurl, srcobj.Size, dsturl, dstobj.Size), nil
+	}
+
+	srcsum, err := contentDigest(srcobj, dstobj)
+	if err != nil {
+		return "", err
+	}
+
+	dstsum, err := contentDigest(dstobj, srcobj)
+	if err != nil {
+		return "", err
+	}
+
+	if srcsum != "" && dstsum != "" && srcsum != dstsum {
+		return fmt.Sprintf("checksum of %v is %v, checksum of %v is %v", srcurl, srcsum, dsturl, dstsum), nil
+	}
+	return "", nil
+}
+
+// contentDigest returns the MD5 digest of the object's content in hex, which
+// is compared with the digest of the other object. The ETag is used for
+// remote objects. Local files are only read if the other object's digest is
+// known. An empty string is returned if the digest is not known.
+func contentDigest(object, other *storage.Object) (string, error) {
+	if object.URL.IsRemote() {
+		if !isMD5ETag(object) {
+			return "", nil
+		}
+		return object.Etag, nil
+	}
+
+	if !other.URL.IsRemote() || !isMD5ETag(other) {
+		return "", nil
+	}
+
+	f, err := os.Open(object.URL.Absolute())
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isMD5ETag reports whether the ETag of the remote object is the MD5 digest of
+// its content. It isn't for the objects uploaded in multiple parts, or
+// encrypted with SSE-KMS.
+func isMD5ETag(object *storage.Object) bool {
+	if object.Metadata.SSE() == "aws:kms" {
+		return false
+	}
+	return isComparableETag(object.Etag)
+}
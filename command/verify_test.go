@@ -0,0 +1,168 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestVerifyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-verify-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newFile := func(name, content string) *url.URL {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+		u, err := url.New(path)
+		assert.NoError(t, err)
+		return u
+	}
+
+	src := newFile("src.txt", "content")
+	same := newFile("same.txt", "content")
+	truncated := newFile("truncated.txt", "cont")
+
+	missing, err := url.New(filepath.Join(dir, "missing.txt"))
+	assert.NoError(t, err)
+
+	client := storage.NewLocalClient(storage.Options{})
+	c := Copy{verify: true}
+
+	ctx := context.Background()
+	assert.NoError(t, c.verifyBeforeDelete(ctx, client, client, src, same))
+	assert.Error(t, c.verifyBeforeDelete(ctx, client, client, src, truncated))
+	assert.Error(t, c.verifyBeforeDelete(ctx, client, client, src, missing))
+
+	// nothing is checked unless asked for.
+	c = Copy{}
+	assert.NoError(t, c.verifyBeforeDelete(ctx, client, client, src, missing))
+}
+
+func TestCompareWithSource(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-compare-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	newFile := func(name, content string) *url.URL {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+		u, err := url.New(path)
+		assert.NoError(t, err)
+		return u
+	}
+
+	src := newFile("src.txt", "content")
+	same := newFile("same.txt", "content")
+	truncated := newFile("truncated.txt", "cont")
+
+	missing, err := url.New(filepath.Join(dir, "missing.txt"))
+	assert.NoError(t, err)
+
+	client := storage.NewLocalClient(storage.Options{})
+	ctx := context.Background()
+
+	mismatch, err := compareWithSource(ctx, client, client, src, same, false)
+	assert.NoError(t, err)
+	assert.Empty(t, mismatch)
+
+	mismatch, err = compareWithSource(ctx, client, client, src, truncated, false)
+	assert.NoError(t, err)
+	assert.Contains(t, mismatch, "size of")
+
+	mismatch, err = compareWithSource(ctx, client, client, src, missing, false)
+	assert.NoError(t, err)
+	assert.Contains(t, mismatch, "does not exist")
+
+	// sizes of the transformed content are not compared.
+	mismatch, err = compareWithSource(ctx, client, client, src, truncated, true)
+	assert.NoError(t, err)
+	assert.Empty(t, mismatch)
+
+	// missing sources are errors, not mismatches.
+	_, err = compareWithSource(ctx, client, client, missing, src, false)
+	assert.Error(t, err)
+}
+
+func TestContentDigest(t *testing.T) {
+	t.Parallel()
+
+	f, err := ioutil.TempFile("", "s5cmd-digest-")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("hello")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	localurl, err := url.New(f.Name())
+	assert.NoError(t, err)
+
+	remoteurl, err := url.New("s3://bucket/key")
+	assert.NoError(t, err)
+
+	const md5sum = "5d41402abc4b2a76b9719d911017c592"
+
+	testcases := []struct {
+		name     string
+		object   *storage.Object
+		other    *storage.Object
+		expected string
+	}{
+		{
+			name:     "remote object",
+			object:   &storage.Object{URL: remoteurl, Etag: md5sum},
+			expected: md5sum,
+		},
+		{
+			name:   "remote object uploaded in multiple parts",
+			object: &storage.Object{URL: remoteurl, Etag: md5sum + "-2"},
+		},
+		{
+			name: "remote object encrypted with kms",
+			object: &storage.Object{
+				URL:      remoteurl,
+				Etag:     md5sum,
+				Metadata: storage.NewMetadata().SetSSE("aws:kms"),
+			},
+		},
+		{
+			name:     "local file compared with remote object",
+			object:   &storage.Object{URL: localurl},
+			other:    &storage.Object{URL: remoteurl, Etag: md5sum},
+			expected: md5sum,
+		},
+		{
+			name:   "local file compared with remote object uploaded in multiple parts",
+			object: &storage.Object{URL: localurl},
+			other:  &storage.Object{URL: remoteurl, Etag: md5sum + "-2"},
+		},
+		{
+			name:   "local file compared with local file",
+			object: &storage.Object{URL: localurl},
+			other:  &storage.Object{URL: localurl},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := contentDigest(tc.object, tc.other)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
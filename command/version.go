@@ -8,12 +8,24 @@ import (
 	"github.com/peak/s5cmd/version"
 )
 
+var versionHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}}
+
+Examples:
+	1. Print the version of s5cmd, the commit and the date it was built from, and the versions of Go and AWS SDK
+		 > s5cmd {{.HelpName}}
+`
+
 var versionCommand = &cli.Command{
-	Name:     "version",
-	HelpName: "version",
-	Usage:    "print version",
+	Name:               "version",
+	HelpName:           "version",
+	Usage:              "print version and build information",
+	CustomHelpTemplate: versionHelpTemplate,
 	Action: func(c *cli.Context) error {
-		fmt.Println(version.GetHumanVersion())
+		fmt.Println(version.GetBuildInfo())
 		return nil
 	},
 }
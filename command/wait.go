@@ -0,0 +1,210 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/peak/s5cmd/log/stat"
+	"github.com/peak/s5cmd/parallel"
+	"github.com/peak/s5cmd/storage"
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// Conditions of the wait command.
+const (
+	waitUntilExists    = "exists"
+	waitUntilNotExists = "not-exists"
+)
+
+// Exit codes of the wait command. They are part of the interface of the
+// command and must not be changed.
+const (
+	waitExitCodeTimeout = 1
+	waitExitCodeError   = 2
+)
+
+const (
+	defaultWaitTimeout  = 5 * time.Minute
+	defaultWaitInterval = 10 * time.Second
+)
+
+var waitHelpTemplate = `Name:
+	{{.HelpName}} - {{.Usage}}
+
+Usage:
+	{{.HelpName}} [options] argument
+
+Options:
+	{{range .VisibleFlags}}{{.}}
+	{{end}}
+Examples:
+	1. Wait until an object is uploaded
+		 > s5cmd {{.HelpName}} s3://bucket/prefix/_SUCCESS
+
+	2. Wait until an object is deleted, checking every minute for an hour at most
+		 > s5cmd {{.HelpName}} --until not-exists --timeout 1h --interval 1m s3://bucket/prefix/object.lock
+
+	3. Wait until any object matches a wildcard, without a time limit
+		 > s5cmd {{.HelpName}} --timeout 0 's3://bucket/prefix/*.parquet'
+
+Exit status is 0 if the condition is met, 1 if the timeout elapses and 2 if an
+error occurs.
+`
+
+var waitCommand = &cli.Command{
+	Name:               "wait",
+	HelpName:           "wait",
+	Usage:              "wait until an object exists or is gone",
+	CustomHelpTemplate: waitHelpTemplate,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "until",
+			Value: waitUntilExists,
+			Usage: "condition to wait for: (exists, not-exists)",
+		},
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Value: defaultWaitTimeout,
+			Usage: "give up waiting after given duration, 0 means no limit",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Value: defaultWaitInterval,
+			Usage: "duration between the checks",
+		},
+	},
+	Before: func(c *cli.Context) error {
+		err := validateWaitCommand(c)
+		if err != nil {
+			printError(givenCommand(c), c.Command.Name, err)
+			return &ExitError{Code: waitExitCodeError, Err: err}
+		}
+		return nil
+	},
+	Action: func(c *cli.Context) (err error) {
+		defer stat.Collect(c.Command.FullName(), &err)()
+
+		src, err := url.New(c.Args().Get(0))
+		op := c.Command.Name
+		fullCommand := givenCommand(c)
+		if err != nil {
+			printError(fullCommand, op, err)
+			return &ExitError{Code: waitExitCodeError, Err: err}
+		}
+
+		return Wait{
+			src:         src,
+			op:          op,
+			fullCommand: fullCommand,
+			// flags
+			until:    c.String("until"),
+			timeout:  c.Duration("timeout"),
+			interval: c.Duration("interval"),
+
+			storageOpts: NewStorageOpts(c),
+		}.Run(c.Context)
+	},
+}
+
+// Wait holds wait operation flags and states.
+type Wait struct {
+	src         *url.URL
+	op          string
+	fullCommand string
+
+	// flags
+	until    string
+	timeout  time.Duration
+	interval time.Duration
+
+	storageOpts storage.Options
+}
+
+// Run checks the existence of the given object periodically, until the
+// condition is met. An ExitError is returned if the timeout elapses, the
+// check fails or the wait is interrupted.
+func (w Wait) Run(ctx context.Context) error {
+	client, err := storage.NewRemoteClient(ctx, w.src, w.storageOpts)
+	if err != nil {
+		printError(w.fullCommand, w.op, err)
+		return &ExitError{Code: waitExitCodeError, Err: err}
+	}
+
+	waitctx := ctx
+	if w.timeout > 0 {
+		var cancel context.CancelFunc
+		waitctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		done, err := w.check(waitctx, client)
+		switch {
+		case err == nil && done:
+			return nil
+		case ctx.Err() != nil:
+			return &ExitError{Code: waitExitCodeError, Err: ctx.Err()}
+		case waitctx.Err() != nil:
+			err := fmt.Errorf("timed out after %v waiting for %v to %v", w.timeout, w.src, w.condition())
+			printError(w.fullCommand, w.op, err)
+			return &ExitError{Code: waitExitCodeTimeout, Err: err}
+		case err != nil:
+			printError(w.fullCommand, w.op, err)
+			return &ExitError{Code: waitExitCodeError, Err: err}
+		}
+
+		// the timeout is handled by the next check, which fails immediately.
+		select {
+		case <-ticker.C:
+		case <-waitctx.Done():
+		case <-parallel.Draining():
+			return &ExitError{Code: waitExitCodeError, Err: context.Canceled}
+		}
+	}
+}
+
+// check reports whether the condition is met.
+func (w Wait) check(ctx context.Context, client *storage.S3) (bool, error) {
+	object, err := Exists{src: w.src}.find(ctx, client)
+	if err != nil {
+		return false, err
+	}
+
+	if w.until == waitUntilNotExists {
+		return object == nil, nil
+	}
+	return object != nil, nil
+}
+
+// condition returns the awaited condition in a human readable form.
+func (w Wait) condition() string {
+	if w.until == waitUntilNotExists {
+		return "not exist"
+	}
+	return "exist"
+}
+
+func validateWaitCommand(c *cli.Context) error {
+	if err := validateExistsCommand(c); err != nil {
+		return err
+	}
+
+	if until := c.String("until"); until != waitUntilExists && until != waitUntilNotExists {
+		return fmt.Errorf("--until must be one of %v, %v", waitUntilExists, waitUntilNotExists)
+	}
+
+	if c.Duration("timeout") < 0 {
+		return fmt.Errorf("--timeout can not be negative")
+	}
+
+	if c.Duration("interval") <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	return nil
+}
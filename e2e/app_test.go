@@ -2,11 +2,14 @@ package e2e
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"gotest.tools/v3/fs"
 	"gotest.tools/v3/icmd"
 )
 
@@ -69,6 +72,55 @@ func TestAppRetryCount(t *testing.T) {
 	}
 }
 
+func TestAppInvalidListConcurrency(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--list-concurrency", "0", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": list concurrency must be a positive value`),
+	})
+}
+
+func TestAppInvalidMaxInflight(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--max-inflight", "-1", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": max inflight cannot be a negative value`),
+	})
+}
+
+func TestAppUseAccelerateEndpointWithEndpointURL(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	// the test server's endpoint is given with --endpoint-url.
+	cmd := s5cmd("--use-accelerate-endpoint", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": --use-accelerate-endpoint can not be used with --endpoint-url`),
+	})
+}
+
 func TestAppDashStat(t *testing.T) {
 	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
@@ -83,3 +135,270 @@ func TestAppDashStat(t *testing.T) {
 	tsv := fmt.Sprintf("%s\t%s\t%s\t%s\t", "Operation", "Total", "Error", "Success")
 	assert.Assert(t, strings.Contains(out, tsv))
 }
+
+func TestAppDashStatWithTransfers(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
+	defer workdir.Remove()
+
+	cmd := s5cmd("--stat", "cp", workdir.Join("file.txt"), "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assert.Assert(t, strings.Contains(result.Stdout(), "Transferred 1 objects (7 uploaded, 0 downloaded, 0 copied) in "))
+	assert.Assert(t, strings.Contains(result.Stdout(), " MB/s"))
+}
+
+func TestAppSummary(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("file1.txt", "new content"),
+		fs.WithFile("file2.txt", "new content"),
+		fs.WithFile("file3.txt", "new content"),
+	)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("--summary", "cp", "-n", "--fail-on-skip", srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 8})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/file3.txt %vfile3.txt`, srcpath, dstpath),
+	})
+
+	// failed objects are listed once more at the end, indented with a tab which
+	// assertLines replaces with a space.
+	stderr := strings.Split(strings.TrimSpace(result.Stderr()), "\n")
+	assert.Equal(t, len(stderr), 5)
+
+	assertLines(t, strings.Join(stderr[:2], "\n"), map[int]compareFunc{
+		0: equals(`ERROR "cp %v/file1.txt %vfile1.txt": object already exists`, srcpath, dstpath),
+		1: equals(`ERROR "cp %v/file2.txt %vfile2.txt": object already exists`, srcpath, dstpath),
+	}, sortInput(true))
+
+	assertLines(t, strings.Join(stderr[2:], "\n"), map[int]compareFunc{
+		0: equals(`ERROR 2 objects failed:`),
+		1: equals(" %v/file1.txt: object already exists", srcpath),
+		2: equals(" %v/file2.txt: object already exists", srcpath),
+	})
+}
+
+func TestAppSummaryWithoutFailures(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	cmd := s5cmd("--summary", "ls", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+}
+
+func TestAppContinueOnErrorWithStopOnFirstError(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--continue-on-error", "--stop-on-first-error", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": --continue-on-error can not be used with --stop-on-first-error`),
+	})
+}
+
+func TestAppStopOnFirstError(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	srcpath := fmt.Sprintf("s3://%v", bucket)
+
+	// sources are expanded in order, the second one is not copied once the
+	// first one fails.
+	cmd := s5cmd("--stop-on-first-error", "cp", srcpath+"/missing*", srcpath+"/file.txt", workdir.Path()+"/")
+	result := icmd.RunCmd(cmd)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`no object found`),
+	})
+
+	_, err := os.Stat(workdir.Join("file.txt"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestAppContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	srcpath := fmt.Sprintf("s3://%v", bucket)
+
+	cmd := s5cmd("--continue-on-error", "cp", srcpath+"/missing*", srcpath+"/file.txt", workdir.Path()+"/")
+	result := icmd.RunCmd(cmd)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`file.txt`),
+	})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`no object found`),
+	})
+
+	expected := fs.Expected(t, fs.WithFile("file.txt", "content"))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+func TestAppConfigFile(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	configdir := fs.NewDir(t, t.Name(), fs.WithFile("config.yaml", "json: true\nretry-count: 2\n"))
+	defer configdir.Remove()
+
+	cmd := s5cmd("--config", configdir.Join("config.yaml"), "ls", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: contains(`"key":"s3://%v/file.txt"`, bucket),
+	})
+}
+
+func TestAppConfigFileFlagPrecedence(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	configdir := fs.NewDir(t, t.Name(), fs.WithFile("config.yaml", "retry-count: -1\n"))
+	defer configdir.Remove()
+
+	cmd := s5cmd("--config", configdir.Join("config.yaml"), "--retry-count", "1", "ls", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`file.txt`),
+	})
+}
+
+func TestAppConfigFileWithUnknownKey(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	configdir := fs.NewDir(t, t.Name(), fs.WithFile("config.yaml", "region: us-east-1\n"))
+	defer configdir.Remove()
+
+	configpath := configdir.Join("config.yaml")
+	cmd := s5cmd("--config", configpath, "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": invalid config file %q: unknown key "region"`, configpath),
+	})
+}
+
+func TestAppConfigFileNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--config", "missing.yaml", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`could not read config file`),
+	})
+}
+
+func TestAppInsecureHostsWithNoVerifySSL(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--insecure-hosts", "minio.local", "--no-verify-ssl", "ls")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR " ls": --insecure-hosts can not be used with --no-verify-ssl`),
+	})
+}
@@ -2,9 +2,13 @@ package e2e
 
 import (
 	"fmt"
+	"os"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"gotest.tools/v3/icmd"
 )
 
@@ -66,6 +70,75 @@ func TestCatS3Object(t *testing.T) {
 
 }
 
+func TestCatS3ObjectWithRange(t *testing.T) {
+	const (
+		bucket   = "bucket"
+		filename = "file.txt"
+		content  = "0123456789abcdef"
+	)
+
+	src := fmt.Sprintf("s3://%v/%v", bucket, filename)
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "length",
+			args:     []string{"--length", "4"},
+			expected: "0123",
+		},
+		{
+			name:     "offset",
+			args:     []string{"--offset", "10"},
+			expected: "abcdef",
+		},
+		{
+			name:     "offset and length",
+			args:     []string{"--offset", "2", "--length", "3"},
+			expected: "234",
+		},
+		{
+			name:     "length past the end",
+			args:     []string{"--offset", "14", "--length", "10"},
+			expected: "ef",
+		},
+		{
+			name:     "range",
+			args:     []string{"--range", "bytes=4-5"},
+			expected: "45",
+		},
+		{
+			name:     "suffix range",
+			args:     []string{"--range", "bytes=-3"},
+			expected: "def",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, filename, content)
+
+			cmd := s5cmd(append(append([]string{"cat"}, tc.args...), src)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Success)
+
+			if diff := cmp.Diff(tc.expected, result.Stdout()); diff != "" {
+				t.Errorf("(-want +got):\n%v", diff)
+			}
+		})
+	}
+}
+
 func TestCatS3ObjectFail(t *testing.T) {
 	const (
 		bucket   = "bucket"
@@ -79,6 +152,7 @@ func TestCatS3ObjectFail(t *testing.T) {
 	testcases := []struct {
 		name      string
 		cmd       []string
+		exitCode  int
 		expected  map[int]compareFunc
 		assertOps []assertOp
 	}{
@@ -88,10 +162,99 @@ func TestCatS3ObjectFail(t *testing.T) {
 				"cat",
 				src,
 			},
+			exitCode: 3,
 			expected: map[int]compareFunc{
 				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": NoSuchKey: status code: 404`),
 			},
 		},
+		{
+			name: "cat remote object with both if-match and if-none-match",
+			cmd: []string{
+				"cat",
+				"--if-match", "etag",
+				"--if-none-match", "etag",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --if-match can not be used with --if-none-match`),
+			},
+		},
+		{
+			name: "cat remote object with both range and offset",
+			cmd: []string{
+				"cat",
+				"--range", "bytes=0-9",
+				"--offset", "10",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --range can not be used with --offset or --length`),
+			},
+		},
+		{
+			name: "cat remote object with multiple ranges",
+			cmd: []string{
+				"cat",
+				"--range", "bytes=0-9,20-29",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": invalid --range "bytes=0-9,20-29": must be a single byte range`),
+			},
+		},
+		{
+			name: "cat remote object with zero length",
+			cmd: []string{
+				"cat",
+				"--length", "0",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --length must be a positive value`),
+			},
+		},
+		{
+			name: "cat remote object with both follow and range",
+			cmd: []string{
+				"cat",
+				"--follow",
+				"--range", "bytes=0-9",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --follow can not be used with --range or --length`),
+			},
+		},
+		{
+			name: "cat remote object with zero interval",
+			cmd: []string{
+				"cat",
+				"--follow",
+				"--interval", "0s",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --interval must be a positive duration`),
+			},
+		},
+		{
+			name: "cat remote object with interval but without follow",
+			cmd: []string{
+				"cat",
+				"--interval", "1s",
+				src,
+			},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains(`ERROR "cat s3://bucket/prefix/file.txt": --interval can only be used with --follow`),
+			},
+		},
 		{
 			name: "cat non existent remote object with json flag",
 			cmd: []string{
@@ -99,6 +262,7 @@ func TestCatS3ObjectFail(t *testing.T) {
 				"cat",
 				src,
 			},
+			exitCode: 3,
 			expected: map[int]compareFunc{
 				0: contains(`{"operation":"cat","command":"cat s3://bucket/prefix/file.txt","error":"NoSuchKey: status code: 404,`),
 			},
@@ -113,6 +277,7 @@ func TestCatS3ObjectFail(t *testing.T) {
 				"cat",
 				src + "/*",
 			},
+			exitCode: 1,
 			expected: map[int]compareFunc{
 				0: equals(`{"operation":"cat","command":"cat s3://bucket/prefix/file.txt/*","error":"remote source \"s3://bucket/prefix/file.txt/*\" can not contain glob characters"}`),
 			},
@@ -126,6 +291,7 @@ func TestCatS3ObjectFail(t *testing.T) {
 				"cat",
 				bucketSrc,
 			},
+			exitCode: 1,
 			expected: map[int]compareFunc{
 				0: contains(`ERROR "cat s3://bucket": remote source must be an object`),
 			},
@@ -144,7 +310,7 @@ func TestCatS3ObjectFail(t *testing.T) {
 			cmd := s5cmd(tc.cmd...)
 			result := icmd.RunCmd(cmd)
 
-			result.Assert(t, icmd.Expected{ExitCode: 1})
+			result.Assert(t, icmd.Expected{ExitCode: tc.exitCode})
 			assertLines(t, result.Stderr(), tc.expected, tc.assertOps...)
 		})
 	}
@@ -215,3 +381,74 @@ func getSequentialFileContent() (string, map[int]compareFunc) {
 
 	return sb.String(), expectedLines
 }
+
+// cat --offset 100 s3://bucket/file.txt
+func TestCatS3ObjectWithOffsetPastTheEnd(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", "content")
+
+	cmd := s5cmd("cat", "--offset", "100", "s3://"+bucket+"/file.txt")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`ERROR "cat s3://%v/file.txt": InvalidRange`, bucket),
+	})
+}
+
+func TestCatS3ObjectWithFollow(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupt signal can not be sent on windows")
+	}
+
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "app.log", "line 1\n")
+
+	cmd := s5cmd("cat", "--follow", "--interval", "100ms", "s3://"+bucket+"/app.log")
+	result := icmd.StartCmd(cmd)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
+
+	waitForStdout := func(expected string) {
+		t.Helper()
+		for i := 0; i < 100 && result.Stdout() != expected; i++ {
+			time.Sleep(50 * time.Millisecond)
+		}
+		if diff := cmp.Diff(expected, result.Stdout()); diff != "" {
+			t.Fatalf("(-want +got):\n%v", diff)
+		}
+	}
+
+	waitForStdout("line 1\n")
+
+	// appended bytes are printed.
+	putFile(t, s3client, bucket, "app.log", "line 1\nline 2\n")
+	waitForStdout("line 1\nline 2\n")
+
+	// object is printed from the start once it shrinks.
+	putFile(t, s3client, bucket, "app.log", "new\n")
+	waitForStdout("line 1\nline 2\nnew\n")
+
+	if err := result.Cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+
+	result = icmd.WaitOnCmd(10*time.Second, result)
+	result.Assert(t, icmd.Success)
+}
@@ -0,0 +1,110 @@
+package e2e
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"testing"
+
+	"gotest.tools/v3/icmd"
+)
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	const bucket = "bucket"
+
+	testcases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		expected map[int]compareFunc
+	}{
+		{
+			name:     "single object",
+			args:     []string{"checksum", "s3://bucket/dir/file1.txt"},
+			exitCode: 0,
+			expected: map[int]compareFunc{
+				0: equals("%v s3://bucket/dir/file1.txt", md5Hex("content 1")),
+			},
+		},
+		{
+			name:     "wildcard",
+			args:     []string{"checksum", "s3://bucket/dir/*.txt"},
+			exitCode: 0,
+			// lines are sorted by the checksums.
+			expected: map[int]compareFunc{
+				0: equals("%v s3://bucket/dir/file2.txt", md5Hex("content 2")),
+				1: equals("%v s3://bucket/dir/file1.txt", md5Hex("content 1")),
+			},
+		},
+		{
+			name:     "single object with json flag",
+			args:     []string{"--json", "checksum", "s3://bucket/dir/file1.txt"},
+			exitCode: 0,
+			expected: map[int]compareFunc{
+				0: json(`
+					{
+						"source": "s3://bucket/dir/file1.txt",
+						"checksum": "%v",
+						"algorithm": "md5",
+						"computed": false
+					}
+				`, md5Hex("content 1")),
+			},
+		},
+		{
+			name:     "missing object",
+			args:     []string{"checksum", "s3://bucket/dir/missing.txt"},
+			exitCode: 3,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "dir/file1.txt", "content 1")
+			putFile(t, s3client, bucket, "dir/file2.txt", "content 2")
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: tc.exitCode})
+
+			if tc.expected == nil {
+				return
+			}
+			assertLines(t, result.Stdout(), tc.expected, sortInput(true), jsonCheck(tc.args[0] == "--json"))
+		})
+	}
+}
+
+func TestChecksumWithBucketArgument(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("checksum", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains("remote source must be an object or contain a wildcard"),
+	})
+}
@@ -0,0 +1,99 @@
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/icmd"
+)
+
+func TestConcatWithInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "missing destination",
+			args:     []string{"s3://bucket/parts/*"},
+			expected: "expected source and destination arguments",
+		},
+		{
+			name:     "local source",
+			args:     []string{"parts/*", "s3://bucket/combined"},
+			expected: "source and destination must be remote",
+		},
+		{
+			name:     "source without wildcard",
+			args:     []string{"s3://bucket/parts/part-1", "s3://bucket/combined"},
+			expected: "source must contain a wildcard",
+		},
+		{
+			name:     "prefix destination",
+			args:     []string{"s3://bucket/parts/*", "s3://bucket/combined/"},
+			expected: "destination must be an object",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"concat"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+func TestConcatWithSmallParts(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "parts/part-1", "content 1")
+	putFile(t, s3client, bucket, "parts/part-2", "content 2")
+
+	cmd := s5cmd("concat", "s3://"+bucket+"/parts/*", "s3://"+bucket+"/combined")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`s3://%v/parts/part-1 is smaller than 5MB`, bucket),
+	})
+}
+
+func TestConcatWithNoMatchingObject(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("concat", "s3://"+bucket+"/parts/*", "s3://"+bucket+"/combined")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 3})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`no object found`),
+	})
+}
@@ -23,13 +23,22 @@
 package e2e
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/fs"
 	"gotest.tools/v3/icmd"
@@ -759,6 +768,217 @@ func TestCopySingleFileToS3JSON(t *testing.T) {
 	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
 }
 
+// cp --print-url file s3://bucket
+func TestCopySingleFileToS3WithPrintURL(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is a test file"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--acl", "public-read", "--print-url", filename, "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	// a path-style URL is printed since the endpoint is a custom one.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^cp %v s3://%v/%v http://127\.0\.0\.1:\d+/%v/%v$`, filename, bucket, filename, bucket, filename)),
+	})
+
+	objectURL := strings.Fields(result.Stdout())[3]
+	resp, err := http.Get(objectURL)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+	assert.Equal(t, string(body), content)
+}
+
+// --json cp --print-url file s3://bucket
+func TestCopySingleFileToS3WithPrintURLJSON(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is a test file"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	cmd := s5cmd("-json", "cp", "--print-url", filename, "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`"url":"http://127\.0\.0\.1:\d+/%v/%v"`, bucket, filename)),
+	}, jsonCheck(true))
+}
+
+// cp --object-lock-mode GOVERNANCE --object-lock-retain-until <date> file s3://bucket/
+func TestCopySingleFileToS3WithInvalidObjectLock(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid date",
+			args:     []string{"--object-lock-mode", "GOVERNANCE", "--object-lock-retain-until", "01/01/2030"},
+			expected: `invalid --object-lock-retain-until "01/01/2030"`,
+		},
+		{
+			name:     "mode without date",
+			args:     []string{"--object-lock-mode", "GOVERNANCE"},
+			expected: `--object-lock-mode and --object-lock-retain-until must be given together`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]string{"cp"}, tc.args...)
+			args = append(args, "file.txt", "s3://"+bucket+"/")
+
+			cmd := s5cmd(args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --preserve-acl s3://bucket/* s3://bucket/copy/
+func TestCopyMultipleS3ObjectsWithPreserveACL(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content 1")
+	putFile(t, s3client, bucket, "file2.txt", "content 2")
+
+	cmd := s5cmd("cp", "--preserve-acl", "s3://"+bucket+"/file*", "s3://"+bucket+"/copy/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/file1.txt s3://%v/copy/file1.txt`, bucket, bucket),
+		1: equals(`cp s3://%v/file2.txt s3://%v/copy/file2.txt`, bucket, bucket),
+	}, sortInput(true))
+
+	// the test server doesn't support ACLs, the content of the copied objects
+	// is replaced by the ACL request, so only the output is compared.
+}
+
+// cp --copy-acl ...
+func TestCopyWithInvalidCopyACL(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--copy-acl", "file.txt", "s3://" + bucket + "/"},
+			expected: `--copy-acl can only be used for copying S3 objects`,
+		},
+		{
+			name:     "download",
+			args:     []string{"cp", "--copy-acl", "s3://" + bucket + "/file.txt", "."},
+			expected: `--copy-acl can only be used for copying S3 objects`,
+		},
+		{
+			name:     "with acl",
+			args:     []string{"cp", "--copy-acl", "--acl", "public-read", "s3://" + bucket + "/file.txt", "s3://" + bucket + "/copy.txt"},
+			expected: `--copy-acl can not be used with --acl or --bucket-owner-full-control`,
+		},
+		{
+			name:     "preserve acl",
+			args:     []string{"cp", "--preserve-acl", "file.txt", "s3://" + bucket + "/"},
+			expected: `--copy-acl can only be used for copying S3 objects`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --print-url s3://bucket/object dir/
+func TestCopyS3ToLocalWithPrintURL(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--print-url", "s3://"+bucket+"/object", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--print-url can only be used for uploads`),
+	})
+}
+
 // cp dir/ s3://bucket/
 func TestCopyDirToS3(t *testing.T) {
 	t.Parallel()
@@ -896,8 +1116,8 @@ func TestCopySingleFileToS3WithStorageClassGlacier(t *testing.T) {
 	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content, ensureStorageClass(expectedStorageClass)))
 }
 
-// cp --flatten dir/ s3://bucket/
-func TestFlattenCopyDirToS3(t *testing.T) {
+// cp --class-rule '>1KB:GLACIER' dir/ s3://bucket/
+func TestCopyDirToS3WithClassRule(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -907,47 +1127,36 @@ func TestFlattenCopyDirToS3(t *testing.T) {
 
 	createBucket(t, s3client, bucket)
 
-	folderLayout := []fs.PathOp{
-		fs.WithFile("file1.txt", "this is the first test file"),
-		fs.WithFile("readme.md", "this is a readme file"),
-		fs.WithDir(
-			"c",
-			fs.WithFile("file2.txt", "this is the second test file"),
-		),
-	}
+	largeContent := strings.Repeat("x", 2048)
 
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	workdir := fs.NewDir(
+		t,
+		bucket,
+		fs.WithFile("small.txt", "content"),
+		fs.WithFile("large.txt", largeContent),
+	)
 	defer workdir.Remove()
-	srcpath := workdir.Path()
-	srcpath = filepath.ToSlash(srcpath)
+
+	srcpath := filepath.ToSlash(workdir.Path())
 	dstpath := fmt.Sprintf("s3://%v/", bucket)
 
-	// this command ('s5cmd cp dir/ s3://bucket/') will run in 'walk' mode,
-	// which is different than 'glob' mode.
-	cmd := s5cmd("cp", "--flatten", workdir.Path()+"/", dstpath)
+	cmd := s5cmd("cp", "--class-rule", ">1KB:GLACIER", srcpath+"/", dstpath)
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v/c/file2.txt %vfile2.txt`, srcpath, dstpath),
-		1: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
-		2: equals(`cp %v/readme.md %vreadme.md`, srcpath, dstpath),
+		0: equals(`cp %v/large.txt %vlarge.txt`, srcpath, dstpath),
+		1: equals(`cp %v/small.txt %vsmall.txt`, srcpath, dstpath),
 	}, sortInput(true))
 
-	// assert local filesystem
-	expected := fs.Expected(t, folderLayout...)
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
-
-	// assert s3
-	assert.Assert(t, ensureS3Object(s3client, bucket, "file1.txt", "this is the first test file"))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "readme.md", "this is a readme file"))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "file2.txt", "this is the second test file"))
+	// assert S3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "large.txt", largeContent, ensureStorageClass("GLACIER")))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "small.txt", "content"))
 }
 
-// cp dir/* s3://bucket/
-func TestCopyMultipleFilesToS3Bucket(t *testing.T) {
-
+// -log=debug cp --storage-class STANDARD_IA --min-ia-size 1KB dir/ s3://bucket/
+func TestCopyDirToS3WithMinIASize(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -957,56 +1166,58 @@ func TestCopyMultipleFilesToS3Bucket(t *testing.T) {
 
 	createBucket(t, s3client, bucket)
 
-	folderLayout := []fs.PathOp{
-		fs.WithFile("testfile1.txt", "this is a test file 1"),
-		fs.WithFile("readme.md", "this is a readme file"),
-		fs.WithDir(
-			"a",
-			fs.WithFile("another_test_file.txt", "yet another txt file. yatf."),
-		),
-		fs.WithDir(
-			"b",
-			fs.WithFile("filename-with-hypen.gz", "file has hypen in its name"),
-		),
-	}
+	largeContent := strings.Repeat("x", 2048)
 
-	workdir := fs.NewDir(t, "somedir", folderLayout...)
-	dstpath := fmt.Sprintf("s3://%v/", bucket)
-	srcpath := workdir.Path()
-	srcpath = filepath.ToSlash(srcpath)
+	workdir := fs.NewDir(
+		t,
+		bucket,
+		fs.WithFile("small.txt", "content"),
+		fs.WithFile("large.txt", largeContent),
+	)
 	defer workdir.Remove()
 
-	cmd := s5cmd("cp", srcpath+"/*", dstpath)
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("-log=debug", "cp", "--storage-class", "STANDARD_IA", "--min-ia-size", "1KB", srcpath+"/", dstpath)
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
+
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v/a/another_test_file.txt %va/another_test_file.txt`, srcpath, dstpath),
-		1: equals(`cp %v/b/filename-with-hypen.gz %vb/filename-with-hypen.gz`, srcpath, dstpath),
-		2: equals(`cp %v/readme.md %vreadme.md`, srcpath, dstpath),
-		3: equals(`cp %v/testfile1.txt %vtestfile1.txt`, srcpath, dstpath),
+		0: equals(`DEBUG "cp %v/small.txt %vsmall.txt": file is smaller than --min-ia-size, stored in STANDARD storage class instead of STANDARD_IA`, srcpath, dstpath),
+		1: equals(`cp %v/large.txt %vlarge.txt`, srcpath, dstpath),
+		2: equals(`cp %v/small.txt %vsmall.txt`, srcpath, dstpath),
 	}, sortInput(true))
 
-	// assert local filesystem
-	expected := fs.Expected(t, folderLayout...)
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	// assert S3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "large.txt", largeContent, ensureStorageClass("STANDARD_IA")))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "small.txt", "content", ensureStorageClass("STANDARD")))
+}
 
-	expectedS3Content := map[string]string{
-		"testfile1.txt":            "this is a test file 1",
-		"readme.md":                "this is a readme file",
-		"b/filename-with-hypen.gz": "file has hypen in its name",
-		"a/another_test_file.txt":  "yet another txt file. yatf.",
-	}
+// cp --min-ia-size 128XB file s3://bucket/
+func TestCopyWithInvalidMinIASize(t *testing.T) {
+	t.Parallel()
 
-	// assert s3
-	for filename, content := range expectedS3Content {
-		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
-	}
-}
+	bucket := s3BucketFromTestName(t)
 
-// cp --flatten dir/* s3://bucket/
-func TestFlattenCopyMultipleFilesToS3Bucket(t *testing.T) {
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--min-ia-size", "128XB", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
 
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid --min-ia-size "128XB"`),
+	})
+}
+
+// cp --newer-than-file .lastrun dir/ s3://bucket/
+func TestCopyDirToS3WithNewerThanFile(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -1016,27 +1227,271 @@ func TestFlattenCopyMultipleFilesToS3Bucket(t *testing.T) {
 
 	createBucket(t, s3client, bucket)
 
-	folderLayout := []fs.PathOp{
-		fs.WithFile("testfile1.txt", "this is a test file 1"),
-		fs.WithFile("readme.md", "this is a readme file"),
-		fs.WithDir(
-			"a",
-			fs.WithFile("another_test_file.txt", "yet another txt file. yatf."),
-		),
-		fs.WithDir(
-			"b",
-			fs.WithFile("filename-with-hypen.gz", "file has hypen in its name"),
-		),
-	}
+	lastRun := time.Now().Add(-time.Hour)
 
-	workdir := fs.NewDir(t, "somedir", folderLayout...)
-	dstpath := fmt.Sprintf("s3://%v/", bucket)
-	srcpath := workdir.Path()
-	srcpath = filepath.ToSlash(srcpath)
+	workdir := fs.NewDir(
+		t,
+		bucket,
+		fs.WithFile("old.txt", "old", fs.WithTimestamps(lastRun, lastRun.Add(-time.Minute))),
+		fs.WithFile("new.txt", "new", fs.WithTimestamps(lastRun, lastRun.Add(time.Minute))),
+	)
 	defer workdir.Remove()
 
-	cmd := s5cmd("cp", "--flatten", srcpath+"/*", dstpath)
-	result := icmd.RunCmd(cmd)
+	reference := fs.NewFile(t, "lastrun", fs.WithTimestamps(lastRun, lastRun))
+	defer reference.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--newer-than-file", reference.Path(), srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/new.txt %vnew.txt`, srcpath, dstpath),
+	})
+
+	// assert S3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "new.txt", "new"))
+	err := ensureS3Object(s3client, bucket, "old.txt", "old")
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// cp --older-than-file missing s3://bucket/* dir/
+func TestCopyWithMissingOlderThanFile(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--older-than-file", "missing-reference", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid --older-than-file "missing-reference"`),
+	})
+}
+
+// cp --class-rule '1GB:GLACIER' file s3://bucket/
+func TestCopyWithInvalidClassRule(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--class-rule", "1GB:GLACIER", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid storage class rule "1GB:GLACIER"`),
+	})
+}
+
+// cp --flatten dir/ s3://bucket/
+func TestFlattenCopyDirToS3(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "this is the first test file"),
+		fs.WithFile("readme.md", "this is a readme file"),
+		fs.WithDir(
+			"c",
+			fs.WithFile("file2.txt", "this is the second test file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+	srcpath := workdir.Path()
+	srcpath = filepath.ToSlash(srcpath)
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	// this command ('s5cmd cp dir/ s3://bucket/') will run in 'walk' mode,
+	// which is different than 'glob' mode.
+	cmd := s5cmd("cp", "--flatten", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/c/file2.txt %vfile2.txt`, srcpath, dstpath),
+		1: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
+		2: equals(`cp %v/readme.md %vreadme.md`, srcpath, dstpath),
+	}, sortInput(true))
+
+	// assert local filesystem
+	expected := fs.Expected(t, folderLayout...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// assert s3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file1.txt", "this is the first test file"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "readme.md", "this is a readme file"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file2.txt", "this is the second test file"))
+}
+
+// cp dir/* s3://bucket/
+func TestCopyMultipleFilesToS3Bucket(t *testing.T) {
+
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("testfile1.txt", "this is a test file 1"),
+		fs.WithFile("readme.md", "this is a readme file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("another_test_file.txt", "yet another txt file. yatf."),
+		),
+		fs.WithDir(
+			"b",
+			fs.WithFile("filename-with-hypen.gz", "file has hypen in its name"),
+		),
+	}
+
+	workdir := fs.NewDir(t, "somedir", folderLayout...)
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+	srcpath := workdir.Path()
+	srcpath = filepath.ToSlash(srcpath)
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", srcpath+"/*", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/a/another_test_file.txt %va/another_test_file.txt`, srcpath, dstpath),
+		1: equals(`cp %v/b/filename-with-hypen.gz %vb/filename-with-hypen.gz`, srcpath, dstpath),
+		2: equals(`cp %v/readme.md %vreadme.md`, srcpath, dstpath),
+		3: equals(`cp %v/testfile1.txt %vtestfile1.txt`, srcpath, dstpath),
+	}, sortInput(true))
+
+	// assert local filesystem
+	expected := fs.Expected(t, folderLayout...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	expectedS3Content := map[string]string{
+		"testfile1.txt":            "this is a test file 1",
+		"readme.md":                "this is a readme file",
+		"b/filename-with-hypen.gz": "file has hypen in its name",
+		"a/another_test_file.txt":  "yet another txt file. yatf.",
+	}
+
+	// assert s3
+	for filename, content := range expectedS3Content {
+		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	}
+}
+
+// cp dir/**/*.csv s3://bucket/
+func TestCopyMultipleFilesToS3BucketWithDoubleStar(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.csv", "content 1"),
+		fs.WithFile("readme.md", "this is a readme file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("file2.csv", "content 2"),
+			fs.WithDir("b", fs.WithFile("file3.csv", "content 3"), fs.WithFile("file3.txt", "content 3")),
+		),
+	}
+
+	workdir := fs.NewDir(t, "somedir", folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", srcpath+"/**/*.csv", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/a/b/file3.csv %va/b/file3.csv`, srcpath, dstpath),
+		1: equals(`cp %v/a/file2.csv %va/file2.csv`, srcpath, dstpath),
+		2: equals(`cp %v/file1.csv %vfile1.csv`, srcpath, dstpath),
+	}, sortInput(true))
+
+	expectedS3Content := map[string]string{
+		"file1.csv":     "content 1",
+		"a/file2.csv":   "content 2",
+		"a/b/file3.csv": "content 3",
+	}
+
+	for filename, content := range expectedS3Content {
+		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	}
+
+	err := ensureS3Object(s3client, bucket, "a/b/file3.txt", "content 3")
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// cp --flatten dir/* s3://bucket/
+func TestFlattenCopyMultipleFilesToS3Bucket(t *testing.T) {
+
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("testfile1.txt", "this is a test file 1"),
+		fs.WithFile("readme.md", "this is a readme file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("another_test_file.txt", "yet another txt file. yatf."),
+		),
+		fs.WithDir(
+			"b",
+			fs.WithFile("filename-with-hypen.gz", "file has hypen in its name"),
+		),
+	}
+
+	workdir := fs.NewDir(t, "somedir", folderLayout...)
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+	srcpath := workdir.Path()
+	srcpath = filepath.ToSlash(srcpath)
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--flatten", srcpath+"/*", dstpath)
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
@@ -1970,8 +2425,8 @@ func TestCopyS3ToLocalWithSameFilenameWithNoClobber(t *testing.T) {
 	assert.Assert(t, fs.Equal(workdir.Path(), expected))
 }
 
-// cp -n -s s3://bucket/object dir/
-func TestCopyS3ToLocalWithSameFilenameOverrideIfSizeDiffers(t *testing.T) {
+// -log=debug cp --only-missing s3://bucket/* .
+func TestCopyMultipleS3ObjectsToLocalWithOnlyMissing(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -1979,124 +2434,3745 @@ func TestCopyS3ToLocalWithSameFilenameOverrideIfSizeDiffers(t *testing.T) {
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename        = "testfile1.txt"
-		content         = "this is the content"
-		expectedContent = content + "\n"
-	)
-
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file1.txt", "local content"))
 	defer workdir.Remove()
 
 	createBucket(t, s3client, bucket)
-	// upload a modified version of the file
-	putFile(t, s3client, bucket, filename, expectedContent)
+	putFile(t, s3client, bucket, "file1.txt", "remote content")
+	putFile(t, s3client, bucket, "dir/file2.txt", "remote content")
 
-	cmd := s5cmd("cp", "-n", "-s", "s3://"+bucket+"/"+filename, ".")
+	cmd := s5cmd("-log=debug", "cp", "--only-missing", "s3://"+bucket+"/*", ".")
 	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
 
-	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
-	// size differs.
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp s3://%v/%v %v`, bucket, filename, filename),
-	})
+		0: equals(`DEBUG "cp s3://%v/file1.txt file1.txt": object already exists`, bucket),
+		1: equals(`cp s3://%v/dir/file2.txt dir/file2.txt`, bucket),
+	}, sortInput(true))
 
-	expected := fs.Expected(t, fs.WithFile(filename, expectedContent))
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	// existing files are kept even if their sizes differ.
+	expected := fs.Expected(t,
+		fs.WithFile("file1.txt", "local content"),
+		fs.WithDir("dir", fs.WithFile("file2.txt", "remote content")),
+	)
 	assert.Assert(t, fs.Equal(workdir.Path(), expected))
 }
 
-// cp -n -u s3://bucket/object dir/ (source is newer)
-func TestCopyS3ToLocalWithSameFilenameOverrideIfSourceIsNewer(t *testing.T) {
+// -log=debug cp --only-new-keys dir/ s3://bucket/prefix/
+func TestCopyDirToS3WithOnlyNewKeys(t *testing.T) {
 	t.Parallel()
 
-	bucket := s3BucketFromTestName(t)
-
-	s3client, s5cmd, cleanup := setup(t)
-	defer cleanup()
-
-	const (
-		filename        = "testfile1.txt"
+	testcases := []struct {
+		name            string
+		args            []string
+		expected        []string
+		expectedChanged string
+	}{
+		{
+			name: "only_new_keys",
+			args: []string{"--only-new-keys"},
+			expected: []string{
+				`DEBUG "cp changed.txt s3://%v/prefix/changed.txt": object already exists`,
+				`DEBUG "cp same.txt s3://%v/prefix/same.txt": object already exists`,
+				`cp new.txt s3://%v/prefix/new.txt`,
+			},
+			expectedChanged: "local content",
+		},
+		{
+			name: "only_new_keys_and_if_size_differ",
+			args: []string{"--only-new-keys", "--if-size-differ"},
+			expected: []string{
+				`DEBUG "cp same.txt s3://%v/prefix/same.txt": object size matches`,
+				`cp changed.txt s3://%v/prefix/changed.txt`,
+				`cp new.txt s3://%v/prefix/new.txt`,
+			},
+			expectedChanged: "changed local content",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			workdir := fs.NewDir(
+				t,
+				t.Name(),
+				fs.WithFile("same.txt", "local content"),
+				fs.WithFile("changed.txt", "changed local content"),
+				fs.WithFile("new.txt", "local content"),
+			)
+			defer workdir.Remove()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "prefix/same.txt", "local content")
+			putFile(t, s3client, bucket, "prefix/changed.txt", "local content")
+
+			args := append([]string{"-log=debug", "cp"}, tc.args...)
+			cmd := s5cmd(append(args, ".", "s3://"+bucket+"/prefix/")...)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Success)
+
+			expected := map[int]compareFunc{}
+			for i, line := range tc.expected {
+				expected[i] = equals(line, bucket)
+			}
+			assertLines(t, result.Stdout(), expected, sortInput(true))
+
+			assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/changed.txt", tc.expectedChanged))
+			assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/new.txt", "local content"))
+		})
+	}
+}
+
+// cp --only-new-keys ...
+func TestCopyWithInvalidOnlyNewKeys(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "download",
+			args:     []string{"cp", "--only-new-keys", "s3://" + bucket + "/*", "dir/"},
+			expected: `--only-new-keys can only be used for uploads`,
+		},
+		{
+			name:     "with force",
+			args:     []string{"cp", "--only-new-keys", "--force", "dir/", "s3://" + bucket + "/"},
+			expected: `--only-new-keys can not be used with --force`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --only-missing ...
+func TestCopyWithInvalidOnlyMissing(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--only-missing", "file.txt", "s3://" + bucket + "/"},
+			expected: `--only-missing can only be used for downloads`,
+		},
+		{
+			name:     "with force",
+			args:     []string{"cp", "--only-missing", "--force", "s3://" + bucket + "/*", "dir/"},
+			expected: `--only-missing can not be used with --force`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// -log=debug cp --only-missing --replace-empty s3://bucket/* .
+// -log=debug cp -n --replace-empty s3://bucket/* .
+func TestCopyMultipleS3ObjectsToLocalWithReplaceEmpty(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		flag string
+	}{
+		{name: "only missing", flag: "--only-missing"},
+		{name: "no clobber", flag: "--no-clobber"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			workdir := fs.NewDir(t, t.Name(),
+				fs.WithFile("empty.txt", ""),
+				fs.WithFile("file.txt", "local content"),
+			)
+			defer workdir.Remove()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "empty.txt", "remote content")
+			putFile(t, s3client, bucket, "file.txt", "remote content")
+
+			cmd := s5cmd("-log=debug", "cp", tc.flag, "--replace-empty", "s3://"+bucket+"/*", ".")
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals(`DEBUG "cp s3://%v/file.txt file.txt": object already exists`, bucket),
+				1: equals(`cp s3://%v/empty.txt empty.txt`, bucket),
+			}, sortInput(true))
+
+			// only the empty files are downloaded again.
+			expected := fs.Expected(t,
+				fs.WithFile("empty.txt", "remote content"),
+				fs.WithFile("file.txt", "local content"),
+			)
+			assert.Assert(t, fs.Equal(workdir.Path(), expected))
+		})
+	}
+}
+
+// cp --replace-empty file.txt s3://bucket/
+func TestCopyWithInvalidReplaceEmpty(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--replace-empty", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--replace-empty can only be used for downloads`),
+	})
+}
+
+// cp --trailing-slash-as-dir s3://bucket/file.txt <target>
+func TestCopyS3ObjectToLocalWithTrailingSlashAsDir(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		target   string
+		expected fs.PathOp
+	}{
+		{
+			name:   "target with trailing slash",
+			target: "dir/",
+			expected: fs.WithDir("dir",
+				fs.WithFile("existing.txt", ""),
+				fs.WithFile("file.txt", "content"),
+			),
+		},
+		{
+			name:     "target without trailing slash",
+			target:   "renamed.txt",
+			expected: fs.WithFile("renamed.txt", "content"),
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			workdir := fs.NewDir(t, t.Name(), fs.WithDir("dir", fs.WithFile("existing.txt", "")))
+			defer workdir.Remove()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "file.txt", "content")
+
+			cmd := s5cmd("cp", "--trailing-slash-as-dir", "s3://"+bucket+"/file.txt", tc.target)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Success)
+
+			ops := []fs.PathOp{tc.expected}
+			if tc.target != "dir/" {
+				ops = append(ops, fs.WithDir("dir", fs.WithFile("existing.txt", "")))
+			}
+			assert.Assert(t, fs.Equal(workdir.Path(), fs.Expected(t, ops...)))
+		})
+	}
+}
+
+// cp --trailing-slash-as-dir file.txt s3://bucket/<target>
+func TestCopySingleFileToS3WithTrailingSlashAsDir(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		target   string
+		expected string
+	}{
+		{
+			name:     "target with trailing slash",
+			target:   "prefix/",
+			expected: "prefix/file.txt",
+		},
+		{
+			name:     "target without trailing slash",
+			target:   "prefix",
+			expected: "prefix",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
+			defer workdir.Remove()
+
+			createBucket(t, s3client, bucket)
+
+			cmd := s5cmd("cp", "--trailing-slash-as-dir", "file.txt", "s3://"+bucket+"/"+tc.target)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Success)
+
+			assert.Assert(t, ensureS3Object(s3client, bucket, tc.expected, "content"))
+		})
+	}
+}
+
+// cp --trailing-slash-as-dir ...
+func TestCopyWithInvalidTrailingSlashAsDir(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithDir("dir"))
+	defer workdir.Remove()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "existing directory without trailing slash",
+			args:     []string{"cp", "--trailing-slash-as-dir", "s3://" + bucket + "/file.txt", "dir"},
+			expected: `target "dir" is a directory, add a trailing slash to copy into it`,
+		},
+		{
+			name:     "multiple objects without trailing slash",
+			args:     []string{"cp", "--trailing-slash-as-dir", "s3://" + bucket + "/*", "out"},
+			expected: `target "out" must end with a slash to copy multiple objects into it`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --create-empty-dirs ...
+func TestCopyWithInvalidCreateEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--create-empty-dirs", "dir/", "s3://" + bucket + "/"},
+			expected: `--create-empty-dirs can only be used for downloads`,
+		},
+		{
+			name:     "with flatten",
+			args:     []string{"cp", "--create-empty-dirs", "--flatten", "s3://" + bucket + "/*", "dir/"},
+			expected: `--create-empty-dirs can not be used with --flatten`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --skip-placeholders ...
+func TestCopyWithInvalidSkipPlaceholders(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--skip-placeholders", "dir/", "s3://" + bucket + "/"},
+			expected: `--skip-placeholders can only be used for downloads`,
+		},
+		{
+			name:     "with create empty dirs",
+			args:     []string{"cp", "--skip-placeholders", "--create-empty-dirs", "s3://" + bucket + "/*", "dir/"},
+			expected: `--skip-placeholders can not be used with --create-empty-dirs`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp -n -s -u --force s3://bucket/object dir/
+func TestCopyS3ToLocalWithSameFilenameWithForce(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is the content"
+	)
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, filename, content)
+
+	// the local file has the same size and it's newer than the object.
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, strings.ToUpper(content)))
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "-n", "-s", "-u", "--force", "s3://"+bucket+"/"+filename, ".")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '--force' takes precedence over all the other override flags.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/%v %v`, bucket, filename, filename),
+	})
+
+	expected := fs.Expected(t, fs.WithFile(filename, content))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp -n -s s3://bucket/object dir/
+func TestCopyS3ToLocalWithSameFilenameOverrideIfSizeDiffers(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename        = "testfile1.txt"
+		content         = "this is the content"
+		expectedContent = content + "\n"
+	)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file
+	putFile(t, s3client, bucket, filename, expectedContent)
+
+	cmd := s5cmd("cp", "-n", "-s", "s3://"+bucket+"/"+filename, ".")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
+	// size differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/%v %v`, bucket, filename, filename),
+	})
+
+	expected := fs.Expected(t, fs.WithFile(filename, expectedContent))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp -n -u s3://bucket/object dir/ (source is newer)
+func TestCopyS3ToLocalWithSameFilenameOverrideIfSourceIsNewer(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename        = "testfile1.txt"
+		content         = "this is the content"
+		expectedContent = content + "\n"
+	)
+
+	now := time.Now().UTC()
+	timestamp := fs.WithTimestamps(
+		now.Add(-time.Minute), // access time
+		now.Add(-time.Minute), // mod time
+	)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content, timestamp))
+	defer workdir.Remove()
+
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file. also uploaded file is newer than
+	// the file on local fs.
+	putFile(t, s3client, bucket, filename, expectedContent)
+
+	cmd := s5cmd("cp", "-n", "-u", "s3://"+bucket+"/"+filename, ".")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
+	// size differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/%v %v`, bucket, filename, filename),
+	})
+
+	expected := fs.Expected(t, fs.WithFile(filename, expectedContent))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp -n -u s3://bucket/object dir/ (source is older)
+func TestCopyS3ToLocalWithSameFilenameDontOverrideIfS3ObjectIsOlder(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is the content"
+	)
+
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file.
+	putFile(t, s3client, bucket, filename, content+"\n")
+
+	// file on the fs is newer than the file on s3. expect an 'dont override'
+	// behaviour.
+	now := time.Now().UTC()
+	timestamp := fs.WithTimestamps(
+		now.Add(time.Minute), // access time
+		now.Add(time.Minute), // mod time
+	)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content, timestamp))
+	defer workdir.Remove()
+
+	cmd := s5cmd("-log=debug", "cp", "-n", "-u", "s3://"+bucket+"/"+filename, ".")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
+	// size differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "cp s3://%v/%v %v": object is newer or same age`, bucket, filename, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	expected := fs.Expected(t, fs.WithFile(filename, content))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp -u -s s3://bucket/prefix/* dir/
+func TestCopyS3ToLocal_Issue70(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	filesToContent := map[string]string{
+		"config/.local/folder1/file1.txt": "this is a test file 1",
+		"config/.local/folder2/file2.txt": "this is a test file 2",
+	}
+
+	for filename, content := range filesToContent {
+		putFile(t, s3client, bucket, filename, content)
+	}
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	srcpath := fmt.Sprintf("s3://%v/config/.local/*", bucket)
+	dstpath := filepath.Join(workdir.Path(), ".local")
+
+	dstpath = filepath.ToSlash(dstpath)
+	cmd := s5cmd("cp", "-u", "-s", srcpath, dstpath)
+
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/config/.local/folder1/file1.txt %v/folder1/file1.txt`, bucket, dstpath),
+		1: equals(`cp s3://%v/config/.local/folder2/file2.txt %v/folder2/file2.txt`, bucket, dstpath),
+	}, sortInput(true))
+
+	// assert local filesystem
+	expectedFiles := []fs.PathOp{
+		fs.WithDir(
+			".local",
+			fs.WithMode(0755),
+			fs.WithDir("folder1", fs.WithMode(0755), fs.WithFile("file1.txt", "this is a test file 1")),
+			fs.WithDir("folder2", fs.WithMode(0755), fs.WithFile("file2.txt", "this is a test file 2")),
+		),
+	}
+
+	expectedResult := fs.Expected(t, expectedFiles...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expectedResult))
+
+	// assert s3 objects
+	for filename, content := range filesToContent {
+		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	}
+}
+
+// cp file s3://bucket (bucket/file exists)
+func TestCopyLocalFileToS3WithTheSameFilename(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename   = "testfile1.txt"
+		content    = "this is the content"
+		newContent = content + "\n"
+	)
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, filename, content)
+
+	// the file to be uploaded is modified
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
+	defer workdir.Remove()
+
+	dst := "s3://" + bucket
+	cmd := s5cmd("cp", filename, dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v/%v`, filename, dst, filename),
+	})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, newContent))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// expect s3 object to be updated with new content
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, newContent))
+}
+
+// -log=debug cp -n file s3://bucket (bucket/file exists)
+func TestCopyLocalFileToS3WithSameFilenameWithNoClobber(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename   = "testfile1.txt"
+		content    = "this is the content"
+		newContent = content + "\n"
+	)
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, filename, content)
+
+	// the file to be uploaded is modified
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
+	defer workdir.Remove()
+
+	cmd := s5cmd("-log=debug", "cp", "-n", filename, "s3://"+bucket)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "cp %v s3://%v/%v": object already exists`, filename, bucket, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, newContent))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// expect s3 object is not overridden
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// cp -n --fail-on-skip dir/ s3://bucket (bucket/file exists)
+func TestCopyDirToS3WithNoClobberAndFailOnSkip(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "existing.txt", "content")
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("existing.txt", "new content"),
+		fs.WithFile("missing.txt", "new content"),
+	)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "-n", "--fail-on-skip", srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	// the error is classified as a conflict.
+	result.Assert(t, icmd.Expected{ExitCode: 8})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/missing.txt %vmissing.txt`, srcpath, dstpath),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp %v/existing.txt %vexisting.txt": object already exists`, srcpath, dstpath),
+	})
+
+	// expect s3 object is not overridden
+	assert.Assert(t, ensureS3Object(s3client, bucket, "existing.txt", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "missing.txt", "new content"))
+}
+
+// cp --fail-on-skip --only-missing s3://bucket/* dir/
+func TestCopyWithFailOnSkipAndOnlyMissing(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--fail-on-skip", "--only-missing", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--fail-on-skip can not be used with --only-missing`),
+	})
+}
+
+// cp --disable-multipart dir/ s3://bucket/
+func TestCopyDirToS3WithDisableMultipart(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	// larger than the minimum part size, which would be uploaded in multiple
+	// parts otherwise.
+	largeContent := strings.Repeat("0", 6*1024*1024)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "content"),
+		fs.WithFile("file2.txt", largeContent),
+	}
+
+	workdir := fs.NewDir(t, "somedir", folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--disable-multipart", "--part-size", "5", srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
+		1: equals(`cp %v/file2.txt %vfile2.txt`, srcpath, dstpath),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file1.txt", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file2.txt", largeContent))
+}
+
+func TestCopyWithInvalidSinglePartFlags(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "disable multipart with multipart threshold",
+			args:     []string{"--disable-multipart", "--multipart-threshold", "100", "file.txt", "s3://bucket/"},
+			expected: "--disable-multipart can not be used with --multipart-threshold",
+		},
+		{
+			name:     "multipart threshold less than part size",
+			args:     []string{"--multipart-threshold", "10", "file.txt", "s3://bucket/"},
+			expected: "--multipart-threshold can not be less than --part-size",
+		},
+		{
+			name:     "multipart threshold more than maximum single part size",
+			args:     []string{"--multipart-threshold", "6000", "file.txt", "s3://bucket/"},
+			expected: "--multipart-threshold can not be more than 5120 MiB",
+		},
+		{
+			name:     "disable multipart with compress",
+			args:     []string{"--disable-multipart", "--compress", "file.txt", "s3://bucket/"},
+			expected: "--disable-multipart can not be used with --compress",
+		},
+		{
+			name:     "disable multipart for download",
+			args:     []string{"--disable-multipart", "s3://bucket/file.txt", "."},
+			expected: "--disable-multipart can only be used for uploading files",
+		},
+		{
+			name:     "part size less than minimum",
+			args:     []string{"--part-size", "4", "file.txt", "s3://bucket/"},
+			expected: "--part-size must be between 5 and 5120 MiB",
+		},
+		{
+			name:     "part size more than maximum",
+			args:     []string{"--part-size", "6000", "file.txt", "s3://bucket/"},
+			expected: "--part-size must be between 5 and 5120 MiB",
+		},
+		{
+			name:     "minimum part size more than maximum",
+			args:     []string{"--min-part-size", "6000", "file.txt", "s3://bucket/"},
+			expected: "--min-part-size must be between 5 and 5120 MiB",
+		},
+		{
+			name:     "minimum part size for download",
+			args:     []string{"--min-part-size", "100", "s3://bucket/file.txt", "."},
+			expected: "--min-part-size can only be used for uploads",
+		},
+		{
+			name:     "multipart threshold for remote copy",
+			args:     []string{"--multipart-threshold", "100", "s3://bucket/file.txt", "s3://bucket/copy.txt"},
+			expected: "--multipart-threshold can only be used for uploading files",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp -p 1 s3://bucket/object .
+// cp -p 1 s3://bucket/object s3://bucket/copy
+func TestCopyWithSmallPartSizeForDownloadsAndRemoteCopies(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const content = "this is a file content"
+	putFile(t, s3client, bucket, "file.txt", content)
+
+	// limits of the part sizes only apply to uploads.
+	cmd := s5cmd("cp", "-p", "1", "s3://"+bucket+"/file.txt", ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/file.txt file.txt`, bucket),
+	})
+
+	cmd = s5cmd("cp", "-p", "1", "s3://"+bucket+"/file.txt", "s3://"+bucket+"/copy.txt")
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/file.txt s3://%v/copy.txt`, bucket, bucket),
+	})
+
+	expected := fs.Expected(t, fs.WithFile("file.txt", content))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "copy.txt", content))
+}
+
+// cp --metadata-from-file meta.json --meta key=value file s3://bucket
+func TestCopySingleFileToS3WithUserMetadata(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile.txt"
+		content  = "this is a file content"
+		metadata = `{"owner": "data-team", "build-id": "42"}`
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content), fs.WithFile("meta.json", metadata))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--metadata-from-file", workdir.Join("meta.json"), "--meta", "owner=ops-team", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`cp %v %v%v`, srcpath, dstpath, filename),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+
+	output, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(filename),
+	})
+	assert.NilError(t, err)
+
+	// inline metadata overrides the one in the file.
+	assert.Equal(t, "ops-team", aws.StringValue(output.Metadata["Owner"]))
+	assert.Equal(t, "42", aws.StringValue(output.Metadata["Build-Id"]))
+}
+
+// cp --website-redirect /new-page.html file s3://bucket
+func TestCopySingleFileToS3WithWebsiteRedirect(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile("old-page.html", "moved"))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join("old-page.html"))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--website-redirect", "/new-page.html", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	output, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("old-page.html"),
+	})
+	assert.NilError(t, err)
+
+	assert.Equal(t, "/new-page.html", aws.StringValue(output.WebsiteRedirectLocation))
+}
+
+// cp --website-redirect new-page.html file s3://bucket
+func TestCopyWithInvalidWebsiteRedirect(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "relative path",
+			args:     []string{"--website-redirect", "new-page.html", "file.txt", "s3://bucket/"},
+			expected: `--website-redirect must be a path starting with '/' or an http or https URL, got "new-page.html"`,
+		},
+		{
+			name:     "unsupported scheme",
+			args:     []string{"--website-redirect", "ftp://example.com/", "file.txt", "s3://bucket/"},
+			expected: `--website-redirect must be a path starting with '/' or an http or https URL, got "ftp://example.com/"`,
+		},
+		{
+			name:     "download",
+			args:     []string{"--website-redirect", "/new-page.html", "s3://bucket/file.txt", "."},
+			expected: `--website-redirect can only be used for uploads`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --meta key=non-ascii-value file s3://bucket
+func TestCopySingleFileToS3WithNonASCIIUserMetadata(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile("file.txt", "content"))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join("file.txt"))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--meta", "title=café", "--meta", "owner=data-team", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	output, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("file.txt"),
+	})
+	assert.NilError(t, err)
+
+	// S3 only accepts US-ASCII metadata, non-ASCII values are encoded.
+	assert.Equal(t, "=?utf-8?q?caf=C3=A9?=", aws.StringValue(output.Metadata["Title"]))
+	assert.Equal(t, "data-team", aws.StringValue(output.Metadata["Owner"]))
+}
+
+func TestCopyWithInvalidUserMetadata(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "missing metadata file",
+			args:     []string{"--metadata-from-file", "missing.json", "file.txt", "s3://" + bucket + "/"},
+			expected: `invalid --metadata-from-file "missing.json"`,
+		},
+		{
+			name:     "invalid inline metadata",
+			args:     []string{"--meta", "owner", "file.txt", "s3://" + bucket + "/"},
+			expected: `invalid --meta "owner": must be in key=value format`,
+		},
+		{
+			name:     "download",
+			args:     []string{"--meta", "owner=data-team", "s3://" + bucket + "/file.txt", "."},
+			expected: `--meta and --metadata-from-file can only be used for uploads`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --prioritize-small dir/ s3://bucket/
+func TestCopyDirToS3WithPrioritizeSmall(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	largeContent := strings.Repeat("large", 1024)
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("large.txt", largeContent),
+		fs.WithFile("small.txt", "small"),
+		fs.WithDir("a", fs.WithFile("medium.txt", "medium content")),
+	)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--prioritize-small", srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects are transferred in parallel, the order they are completed is
+	// not deterministic.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/a/medium.txt %va/medium.txt`, srcpath, dstpath),
+		1: equals(`cp %v/large.txt %vlarge.txt`, srcpath, dstpath),
+		2: equals(`cp %v/small.txt %vsmall.txt`, srcpath, dstpath),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "small.txt", "small"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "a/medium.txt", "medium content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "large.txt", largeContent))
+}
+
+// --json cp --retry-on-checksum-mismatch 2 s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithRetryOnChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content 1")
+	putFile(t, s3client, bucket, "a/file2.txt", "content 2")
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("--json", "cp", "--retry-on-checksum-mismatch", "2", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	// files which match the objects are not downloaded again.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`{"operation":"cp","success":true,"source":"s3://%v/a/file2.txt","destination":"dir/a/file2.txt","object":{"type":"file","size":9}}`, bucket),
+		1: equals(`{"operation":"cp","success":true,"source":"s3://%v/file1.txt","destination":"dir/file1.txt","object":{"type":"file","size":9}}`, bucket),
+	}, sortInput(true), jsonCheck(true))
+
+	expected := fs.Expected(t, fs.WithDir("dir",
+		fs.WithFile("file1.txt", "content 1"),
+		fs.WithDir("a", fs.WithFile("file2.txt", "content 2")),
+	))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp --retry-on-checksum-mismatch ...
+func TestCopyWithInvalidRetryOnChecksumMismatch(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--retry-on-checksum-mismatch", "2", "file.txt", "s3://" + bucket + "/"},
+			expected: `--retry-on-checksum-mismatch can only be used for downloads`,
+		},
+		{
+			name:     "negative",
+			args:     []string{"cp", "--retry-on-checksum-mismatch", "-1", "s3://" + bucket + "/*", "dir/"},
+			expected: `--retry-on-checksum-mismatch can not be negative`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --atomic s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithAtomic(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content 1")
+	putFile(t, s3client, bucket, "a/file2.txt", "content 2")
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithDir("dir", fs.WithFile("file1.txt", "old content")))
+	defer workdir.Remove()
+
+	cmd := s5cmd("--json", "cp", "--atomic", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`{"operation":"cp","success":true,"source":"s3://%v/a/file2.txt","destination":"dir/a/file2.txt","object":{"type":"file","size":9}}`, bucket),
+		1: equals(`{"operation":"cp","success":true,"source":"s3://%v/file1.txt","destination":"dir/file1.txt","object":{"type":"file","size":9}}`, bucket),
+	}, sortInput(true), jsonCheck(true))
+
+	// temporary files are renamed to the destinations.
+	expected := fs.Expected(t, fs.WithDir("dir",
+		fs.WithFile("file1.txt", "content 1"),
+		fs.WithDir("a", fs.WithFile("file2.txt", "content 2")),
+	))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp --atomic file s3://bucket/
+func TestCopyWithInvalidAtomic(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--atomic", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--atomic can only be used for downloads`),
+	})
+}
+
+// cp --source-file-list paths.txt --meta owner=ops-team s3://bucket/prefix/
+func TestCopyWithSourceFileList(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(),
+		fs.WithFile("paths.txt", "a/file1.txt\na/b/file2.txt\n\na/missing.txt\n"),
+		fs.WithDir("a",
+			fs.WithFile("file1.txt", "content 1"),
+			fs.WithFile("unlisted.txt", "unlisted"),
+			fs.WithDir("b", fs.WithFile("file2.txt", "content 2")),
+		),
+	)
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--source-file-list", "paths.txt", "--meta", "owner=ops-team", "s3://"+bucket+"/prefix/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// missing files fail without aborting the others, with the exit code of
+	// the not found errors.
+	result.Assert(t, icmd.Expected{ExitCode: 3})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp a/b/file2.txt s3://%v/prefix/b/file2.txt`, bucket),
+		1: equals(`cp a/file1.txt s3://%v/prefix/file1.txt`, bucket),
+	}, sortInput(true))
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`ERROR "cp a/missing.txt s3://%v/prefix/missing.txt": open a/missing.txt: no such file or directory`, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/file1.txt", "content 1"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/b/file2.txt", "content 2"))
+	err := ensureS3Object(s3client, bucket, "prefix/unlisted.txt", "unlisted")
+	assertError(t, err, errS3NoSuchKey)
+
+	output, err := s3client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("prefix/file1.txt"),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, "ops-team", aws.StringValue(output.Metadata["Owner"]))
+}
+
+// cp --source-file-list ...
+func TestCopyWithInvalidSourceFileList(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name(),
+		fs.WithFile("paths.txt", "file.txt\n"),
+		fs.WithFile("empty.txt", "\n"),
+	)
+	defer workdir.Remove()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "with source arguments",
+			args:     []string{"cp", "--source-file-list", "paths.txt", "file.txt", "s3://" + bucket + "/"},
+			expected: `expected only destination argument with --source-file-list`,
+		},
+		{
+			name:     "download",
+			args:     []string{"cp", "--source-file-list", "paths.txt", "dir/"},
+			expected: `--source-file-list can only be used for uploads`,
+		},
+		{
+			name:     "missing list",
+			args:     []string{"cp", "--source-file-list", "missing.txt", "s3://" + bucket + "/"},
+			expected: `invalid --source-file-list "missing.txt"`,
+		},
+		{
+			name:     "empty list",
+			args:     []string{"cp", "--source-file-list", "empty.txt", "s3://" + bucket + "/"},
+			expected: `invalid --source-file-list "empty.txt": no paths are listed`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --check-space s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithCheckSpace(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content 1")
+	putFile(t, s3client, bucket, "a/file2.txt", "content 2")
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--check-space", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/a/file2.txt dir/a/file2.txt`, bucket),
+		1: equals(`cp s3://%v/file1.txt dir/file1.txt`, bucket),
+	}, sortInput(true))
+
+	expected := fs.Expected(t, fs.WithDir("dir",
+		fs.WithFile("file1.txt", "content 1"),
+		fs.WithDir("a", fs.WithFile("file2.txt", "content 2")),
+	))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// -log=debug cp --check-space --only-missing --flatten s3://bucket/* .
+func TestCopyMultipleS3ObjectsToLocalWithCheckSpaceAndOnlyMissing(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file1.txt", "local content"))
+	defer workdir.Remove()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "a/file1.txt", "remote content")
+	putFile(t, s3client, bucket, "b/file2.txt", "remote content")
+
+	cmd := s5cmd("-log=debug", "cp", "--check-space", "--only-missing", "--flatten", "s3://"+bucket+"/*", ".")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	// the existing file is reported once.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "cp s3://%v/a/file1.txt file1.txt": object already exists`, bucket),
+		1: equals(`cp s3://%v/b/file2.txt file2.txt`, bucket),
+	}, sortInput(true))
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	expected := fs.Expected(t,
+		fs.WithFile("file1.txt", "local content"),
+		fs.WithFile("file2.txt", "remote content"),
+	)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp --check-space --space-margin 1000000TB s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithCheckSpaceNotEnoughSpace(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content 1")
+	putFile(t, s3client, bucket, "a/file2.txt", "content 2")
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--check-space", "--space-margin", "1000000TB", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: match(`ERROR "cp s3://.*/\* dir/": not enough space at "dir/": \d+ bytes are required, including 1099511627776000000 bytes of --space-margin, but \d+ bytes are available`),
+	})
+
+	// none of the objects are downloaded.
+	assert.Assert(t, fs.Equal(workdir.Path(), fs.Expected(t)))
+}
+
+// cp --check-space ...
+func TestCopyWithInvalidCheckSpace(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"cp", "--check-space", "file.txt", "s3://" + bucket + "/"},
+			expected: `--check-space can only be used for downloads`,
+		},
+		{
+			name:     "space margin without check space",
+			args:     []string{"cp", "--space-margin", "1GB", "s3://" + bucket + "/*", "dir/"},
+			expected: `--space-margin can only be used with --check-space`,
+		},
+		{
+			name:     "invalid space margin",
+			args:     []string{"cp", "--check-space", "--space-margin", "1XB", "s3://" + bucket + "/*", "dir/"},
+			expected: `invalid --space-margin "1XB"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --max-object-size size dir/ s3://bucket/
+func TestCopyDirToS3WithMaxObjectSize(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("small.txt", "small"),
+		fs.WithFile("large.txt", "this file is larger than the limit"),
+	)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("--summary", "cp", "--max-object-size", "10B", srcpath+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/small.txt %vsmall.txt`, srcpath, dstpath),
+	})
+
+	// skipped objects are listed in the summary, indented with a tab which
+	// assertLines replaces with a space.
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR 1 objects failed:`),
+		1: equals(` %v/large.txt: object is larger than --max-object-size`, srcpath),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "small.txt", "small"))
+
+	err := ensureS3Object(s3client, bucket, "large.txt", "this file is larger than the limit")
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// cp --max-object-size size s3://bucket/* dir/
+func TestCopyS3ObjectsToLocalWithMaxObjectSize(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "small.txt", "small")
+	putFile(t, s3client, bucket, "large.txt", strings.Repeat("large", 1024))
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--max-object-size", "2KB", "--fail-on-skip", "s3://"+bucket+"/*", workdir.Path()+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 8})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`small.txt`),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`large.txt": object is larger than --max-object-size`),
+	})
+
+	expected := fs.Expected(t, fs.WithFile("small.txt", "small"))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+func TestCopyWithInvalidSourceVersionID(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	src := "s3://" + bucket + "/file.txt"
+
+	testcases := []struct {
+		name     string
+		command  string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid version id",
+			command:  "cp",
+			args:     []string{"--source-version-id", "invalid version", src, "s3://" + bucket + "/copy.txt"},
+			expected: `invalid --source-version-id "invalid version"`,
+		},
+		{
+			name:     "download",
+			command:  "cp",
+			args:     []string{"--source-version-id", "null", src, "."},
+			expected: `--source-version-id can only be used for copying S3 objects`,
+		},
+		{
+			name:     "wildcard",
+			command:  "cp",
+			args:     []string{"--source-version-id", "null", "s3://" + bucket + "/*", "s3://" + bucket + "/copy/"},
+			expected: `--source-version-id can only be used with a single source object`,
+		},
+		{
+			name:     "move",
+			command:  "mv",
+			args:     []string{"--source-version-id", "null", src, "s3://" + bucket + "/copy.txt"},
+			expected: `--source-version-id can not be used with mv`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{tc.command}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyWithInvalidMetadataDirective(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid directive",
+			args:     []string{"--metadata-directive", "merge", "s3://" + bucket + "/file.txt", "s3://" + bucket + "/copy.txt"},
+			expected: `--metadata-directive must be one of COPY, REPLACE`,
+		},
+		{
+			name:     "preserve without replace",
+			args:     []string{"--preserve", "cache-control", "s3://" + bucket + "/file.txt", "s3://" + bucket + "/copy.txt"},
+			expected: `--preserve can only be used with --metadata-directive REPLACE`,
+		},
+		{
+			name:     "download",
+			args:     []string{"--metadata-directive", "REPLACE", "s3://" + bucket + "/file.txt", "."},
+			expected: `--metadata-directive can only be used for copying S3 objects`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp -n file s3://bucket
+func TestCopyLocalFileToS3WithNoClobber(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename   = "testfile1.txt"
+		content    = "this is the content"
+		newContent = content + "\n"
+	)
+
+	createBucket(t, s3client, bucket)
+
+	// the file to be uploaded is modified
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
+	defer workdir.Remove()
+
+	dst := "s3://" + bucket
+	cmd := s5cmd("cp", "-n", filename, dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v/%v`, filename, dst, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, newContent))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// expect s3 object is not overridden
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, newContent))
+}
+
+// cp -n -s file s3://bucket (bucket/file exists)
+func TestCopyLocalFileToS3WithSameFilenameOverrideIfSizeDiffers(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename        = "testfile1.txt"
+		content         = "this is the content"
+		expectedContent = content + "\n"
+	)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent))
+	defer workdir.Remove()
+
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file
+	putFile(t, s3client, bucket, filename, content)
+
+	dst := "s3://" + bucket
+	cmd := s5cmd("cp", "-n", "-s", filename, dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
+	// size differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v/%v`, filename, dst, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	assert.NilError(t, ensureS3Object(s3client, bucket, filename, expectedContent))
+}
+
+// cp -n -u file s3://bucket (bucket/file exists, source is newer)
+func TestCopyLocalFileToS3WithSameFilenameOverrideIfSourceIsNewer(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename        = "testfile1.txt"
+		content         = "this is the content"
+		expectedContent = content + "\n"
+	)
+
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file. also uploaded file is newer than
+	// the file on local fs.
+	putFile(t, s3client, bucket, filename, content)
+
+	now := time.Now().UTC()
+	timestamp := fs.WithTimestamps(
+		now.Add(time.Minute), // access time
+		now.Add(time.Minute), // mod time
+	)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent, timestamp))
+	defer workdir.Remove()
+
+	dst := "s3://" + bucket
+	cmd := s5cmd("cp", "-n", "-u", filename, dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-u' overrides '-n' if the file
+	// modtime differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v/%v`, filename, dst, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	assert.NilError(t, ensureS3Object(s3client, bucket, filename, expectedContent))
+}
+
+// cp -n -u file s3://bucket (bucket/file exists, source is older)
+func TestCopyLocalFileToS3WithSameFilenameDontOverrideIfS3ObjectIsOlder(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename        = "testfile1.txt"
 		content         = "this is the content"
 		expectedContent = content + "\n"
 	)
 
-	now := time.Now().UTC()
-	timestamp := fs.WithTimestamps(
-		now.Add(-time.Minute), // access time
-		now.Add(-time.Minute), // mod time
-	)
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content, timestamp))
-	defer workdir.Remove()
+	createBucket(t, s3client, bucket)
+	// upload a modified version of the file. also uploaded file is newer than
+	// the file on local fs.
+	putFile(t, s3client, bucket, filename, content)
+
+	now := time.Now().UTC()
+	timestamp := fs.WithTimestamps(
+		now.Add(-time.Minute), // access time
+		now.Add(-time.Minute), // mod time
+	)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent, timestamp))
+	defer workdir.Remove()
+
+	cmd := s5cmd("-log=debug", "cp", "-n", "-u", filename, "s3://"+bucket)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	// '-n' prevents overriding the file, but '-u' overrides '-n' if the file
+	// modtime differs.
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "cp %v s3://%v/%v": object is newer or same age`, filename, bucket, filename),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	assert.NilError(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// cp file s3://bucket/object
+func TestCopyLocalFileToS3WithCustomName(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is the content"
+	)
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	dstpath := fmt.Sprintf("s3://%v/%v", bucket, filename)
+
+	cmd := s5cmd("cp", filename, dstpath)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v`, filename, dstpath),
+	})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, content))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// assert s3 object
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// cp file s3://bucket/prefix/
+func TestCopyLocalFileToS3WithPrefix(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is the content"
+	)
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	dstpath := fmt.Sprintf("s3://%v/s5cmdtest/", bucket)
+
+	cmd := s5cmd("cp", filename, dstpath)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v%v`, filename, dstpath, filename),
+	})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, content))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// assert s3 object
+	assert.Assert(t, ensureS3Object(s3client, bucket, fmt.Sprintf("s5cmdtest/%v", filename), content))
+}
+
+// cp file s3://bucket
+func TestMultipleLocalFileToS3Bucket(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is the content"
+	)
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	dstpath := fmt.Sprintf("s3://%v", bucket)
+
+	cmd := s5cmd("cp", filename, dstpath)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v %v/%v`, filename, dstpath, filename),
+	})
+
+	// assert local filesystem
+	expected := fs.Expected(t, fs.WithFile(filename, content))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// assert s3 object
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// cp * s3://bucket/prefix/
+func TestCopyMultipleLocalNestedFilesToS3(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const bucket = "bucket"
+	createBucket(t, s3client, bucket)
+
+	// nested folder layout
+	//
+	// ├─a
+	// │ ├─readme.md
+	// │ └─file1.txt
+	// └─b
+	//   └─c
+	//     └─file2.txt
+	//
+	// after `s5cmd cp * s3://bucket/prefix/`, expect:
+	//
+	// prefix
+	//  ├─a
+	//  │ ├─readme.md
+	//  │ └─file1.txt
+	//  └─b
+	//    └─c
+	//      └─file2.txt
+
+	folderLayout := []fs.PathOp{
+		fs.WithDir(
+			"a",
+			fs.WithFile("file1.txt", "file1"),
+			fs.WithFile("readme.md", "readme"),
+		),
+		fs.WithDir(
+			"b",
+			fs.WithDir(
+				"c",
+				fs.WithFile("file2.txt", "file2"),
+			),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+	cmd := s5cmd("cp", "*", dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("cp a/file1.txt %va/file1.txt", dst),
+		1: equals("cp a/readme.md %va/readme.md", dst),
+		2: equals("cp b/c/file2.txt %vb/c/file2.txt", dst),
+	}, sortInput(true))
+
+	// assert local filesystem
+	expected := fs.Expected(t, folderLayout...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	// assert s3 objects
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/readme.md", "readme"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/file1.txt", "file1"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/b/c/file2.txt", "file2"))
+}
+
+// cp --no-follow-symlinks my_link s3://bucket/prefix/
+func TestCopyLinkToASingleFileWithFollowSymlinkDisabled(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const bucket = "bucket"
+	createBucket(t, s3client, bucket)
+
+	fileContent := "CAFEBABE"
+	folderLayout := []fs.PathOp{
+		fs.WithDir(
+			"a",
+			fs.WithFile("f1.txt", fileContent),
+		),
+		fs.WithDir("b"),
+		fs.WithSymlink("b/my_link", "a/f1.txt"),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+	cmd := s5cmd("cp", "--no-follow-symlinks", "b/my_link", dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
+
+// cp * s3://bucket/prefix/
+func TestCopyWithFollowSymlink(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const bucket = "bucket"
+	createBucket(t, s3client, bucket)
+
+	fileContent := "CAFEBABE"
+	folderLayout := []fs.PathOp{
+		fs.WithDir(
+			"a",
+			fs.WithFile("f1.txt", fileContent),
+		),
+		fs.WithDir("b"),
+		fs.WithDir("c"),
+		fs.WithSymlink("b/link1", "a/f1.txt"),
+		fs.WithSymlink("c/link2", "b/link1"),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+	cmd := s5cmd("cp", "*", dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("cp a/f1.txt %va/f1.txt", dst),
+		1: equals("cp b/link1 %vb/link1", dst),
+		2: equals("cp c/link2 %vc/link2", dst),
+	}, sortInput(true))
+
+	// assert s3 objects
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/f1.txt", fileContent))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/b/link1", fileContent))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/c/link2", fileContent))
+}
+
+// cp --no-follow-symlinks * s3://bucket/prefix/
+func TestCopyWithNoFollowSymlink(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	const bucket = "bucket"
+	createBucket(t, s3client, bucket)
+
+	fileContent := "CAFEBABE"
+	folderLayout := []fs.PathOp{
+		fs.WithDir(
+			"a",
+			fs.WithFile("f1.txt", fileContent),
+		),
+		fs.WithDir("b"),
+		fs.WithDir("c"),
+		fs.WithSymlink("b/link1", "a/f1.txt"),
+		fs.WithSymlink("c/link2", "b/link1"),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+	cmd := s5cmd("cp", "--no-follow-symlinks", "*", dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("cp a/f1.txt %va/f1.txt", dst),
+	}, sortInput(true))
+
+	// assert s3 objects
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/f1.txt", fileContent))
+}
+
+// --dry-run cp dir/ s3://bucket/
+func TestCopyDirToS3DryRun(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "content"),
+		fs.WithDir(
+			"c",
+			fs.WithFile("file2.txt", "content"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("--dry-run", "cp", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/c/file2.txt %vc/file2.txt`, srcpath, dstpath),
+		1: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
+	}, sortInput(true))
+
+	// assert no change in s3
+	objs := []string{"c/file2.txt", "file1.txt"}
+	for _, obj := range objs {
+		err := ensureS3Object(s3client, bucket, obj, "content")
+		assertError(t, err, errS3NoSuchKey)
+	}
+
+	// assert local filesystem
+	expected := fs.Expected(t, folderLayout...)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// --dry-run cp s3://bucket/* dir/
+func TestCopyS3ToDirDryRun(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	files := [...]string{"c/file2.txt", "file1.txt"}
+
+	putFile(t, s3client, bucket, files[0], "content")
+	putFile(t, s3client, bucket, files[1], "content")
+
+	srcpath := fmt.Sprintf("s3://%s", bucket)
+
+	cmd := s5cmd("--dry-run", "cp", srcpath+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("cp %v/c/file2.txt dir/%s", srcpath, files[0]),
+		1: equals("cp %v/file1.txt dir/%s", srcpath, files[1]),
+	}, sortInput(true))
+
+	// not even outermost directory should be created
+	_, err := os.Stat(cmd.Dir + "/dir")
+	assert.Assert(t, os.IsNotExist(err))
+
+	// assert s3
+	for _, f := range files {
+		assert.Assert(t, ensureS3Object(s3client, bucket, f, "content"))
+	}
+}
+
+// cp --transform 's/^raw/processed/' dir/ s3://bucket/
+func TestCopyDirToS3WithTransform(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "this is the first test file"),
+		fs.WithDir(
+			"raw",
+			fs.WithFile("file2.txt", "this is the second test file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--transform", "s/^raw/processed/", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
+		1: equals(`cp %v/raw/file2.txt %vprocessed/file2.txt`, srcpath, dstpath),
+	}, sortInput(true))
+
+	// assert s3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file1.txt", "this is the first test file"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "processed/file2.txt", "this is the second test file"))
+}
+
+// cp --transform 's/\.txt$/.log/' s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithTransform(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	cmd := s5cmd("cp", "--transform", `s/\.txt$/.log/`, "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/a/file1.txt dir/a/file1.log`, bucket),
+		1: equals(`cp s3://%v/file2.txt dir/file2.log`, bucket),
+	}, sortInput(true))
+
+	expected := fs.Expected(t, fs.WithDir(
+		"dir",
+		fs.WithDir("a", fs.WithFile("file1.log", "content")),
+		fs.WithFile("file2.log", "content"),
+	))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --transform 's|^|../|' s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithTransformOutsideDestination(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	cmd := s5cmd("cp", "--transform", `s|^file1|../file1|`, "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/file2.txt dir/file2.txt`, bucket),
+	})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp s3://%v/file1.txt dir/": transformed name "../file1.txt" is outside of the destination directory`, bucket),
+	})
+
+	// nothing is written outside of the destination directory.
+	expected := fs.Expected(t, fs.WithDir("dir", fs.WithFile("file2.txt", "content")))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --transform 's/(/x/' dir/ s3://bucket/
+func TestCopyWithInvalidTransform(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--transform", "s/(/x/", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid transform expression`),
+	})
+}
+
+// cp --key-template '{dir}{name}-{date:2006}{ext}' dir/ s3://bucket/
+func TestCopyDirToS3WithKeyTemplate(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "this is the first test file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("file2.txt", "this is the second test file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--key-template", "{dir}{name}-{date:2006}{ext}", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	year := time.Now().UTC().Format("2006")
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/a/file2.txt %va/file2-%v.txt`, srcpath, dstpath, year),
+		1: equals(`cp %v/file1.txt %vfile1-%v.txt`, srcpath, dstpath, year),
+	}, sortInput(true))
+
+	// assert s3
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file1-"+year+".txt", "this is the first test file"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "a/file2-"+year+".txt", "this is the second test file"))
+}
+
+// cp --key-template '{dir}{filename}' dir/ s3://bucket/
+func TestCopyWithInvalidKeyTemplate(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--key-template", "{dir}{filename}", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid key template "{dir}{filename}": unknown token {filename}`),
+	})
+}
+
+// cp --key-template '{name}-{date}{ext}' file.txt s3://bucket/object.txt
+func TestCopyWithKeyTemplateToObjectKey(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
+	defer workdir.Remove()
+
+	dst := fmt.Sprintf("s3://%v/object.txt", bucket)
+
+	cmd := s5cmd("cp", "--key-template", "{name}-{date}{ext}", "file.txt", dst)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt %v": --key-template can only be used with a bucket or a prefix destination, e.g. s3://bucket/prefix/`, dst),
+	})
+
+	// nothing is uploaded.
+	err := ensureS3Object(s3client, bucket, "object.txt", "content")
+	assert.Assert(t, err != nil)
+}
+
+// cp --include-regex '^\d{4}/data-.*\.parquet$' --exclude-regex '-tmp' s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithRegexFilters(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "2020/data-1.parquet", "content")
+	putFile(t, s3client, bucket, "2020/data-2-tmp.parquet", "content")
+	putFile(t, s3client, bucket, "2020/data-3.csv", "content")
+	putFile(t, s3client, bucket, "latest/data-4.parquet", "content")
+
+	cmd := s5cmd(
+		"cp",
+		"--include-regex", `^\d{4}/data-.*\.parquet$`,
+		"--exclude-regex", `-tmp`,
+		"s3://"+bucket+"/*",
+		"dir/",
+	)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/2020/data-1.parquet dir/2020/data-1.parquet`, bucket),
+	})
+
+	expected := fs.Expected(t, fs.WithDir(
+		"dir",
+		fs.WithDir("2020", fs.WithFile("data-1.parquet", "content")),
+	))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --include-regex '(' s3://bucket/* dir/
+func TestCopyWithInvalidIncludeRegex(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--include-regex", "(", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`invalid --include-regex "("`),
+	})
+}
+
+// cp --include-from include.txt --exclude-from exclude.txt --exclude-regex '^b/' dir/ s3://bucket/
+func TestCopyDirToS3WithPatternFiles(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(t, t.Name(),
+		fs.WithFile("include.txt", "# data files\n\\.parquet$\n\n\\.csv$\n"),
+		fs.WithFile("exclude.txt", "# temporary files\n-tmp\\.\n"),
+		fs.WithDir("dir",
+			fs.WithFile("data-1.parquet", "content"),
+			fs.WithFile("data-2.csv", "content"),
+			fs.WithFile("data-3-tmp.parquet", "content"),
+			fs.WithFile("readme.txt", "content"),
+			fs.WithDir("b", fs.WithFile("data-4.parquet", "content")),
+		),
+	)
+	defer workdir.Remove()
+
+	// objects must match any of the includes and none of the excludes, given
+	// either with the flags or in the files.
+	cmd := s5cmd(
+		"cp",
+		"--include-from", "include.txt",
+		"--exclude-from", "exclude.txt",
+		"--exclude-regex", `^b/`,
+		"dir/",
+		"s3://"+bucket+"/",
+	)
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp dir/data-1.parquet s3://%v/data-1.parquet`, bucket),
+		1: equals(`cp dir/data-2.csv s3://%v/data-2.csv`, bucket),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "data-1.parquet", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "data-2.csv", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "data-3-tmp.parquet", "content") != nil)
+	assert.Assert(t, ensureS3Object(s3client, bucket, "b/data-4.parquet", "content") != nil)
+}
+
+// cp --exclude-from patterns.txt s3://bucket/* dir/
+func TestCopyWithInvalidPatternFile(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("patterns.txt", "\\.tmp$\n[a-\n"))
+	defer workdir.Remove()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid pattern",
+			args:     []string{"cp", "--exclude-from", "patterns.txt", "s3://" + bucket + "/*", "dir/"},
+			expected: `invalid --exclude-from "patterns.txt": line 2: error parsing regexp`,
+		},
+		{
+			name:     "missing file",
+			args:     []string{"cp", "--include-from", "missing.txt", "s3://" + bucket + "/*", "dir/"},
+			expected: `invalid --include-from "missing.txt"`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --flatten s3://bucket/* dir/
+func TestFlattenCopyMultipleS3ObjectsToLocalWithCollision(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file.txt", "content a")
+	putFile(t, s3client, bucket, "b/file.txt", "content b")
+
+	cmd := s5cmd("cp", "--flatten", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`objects "s3://%v/a/file.txt" and "s3://%v/b/file.txt" would be downloaded to the same file "file.txt"`, bucket, bucket),
+	})
+
+	// nothing is downloaded if the destinations collide.
+	expected := fs.Expected(t)
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --flatten --on-collision <strategy> s3://bucket/* dir/
+func TestFlattenCopyMultipleS3ObjectsToLocalWithOnCollision(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		strategy string
+		expected []fs.PathOp
+	}{
+		{
+			name:     "rename",
+			strategy: "rename",
+			expected: []fs.PathOp{
+				fs.WithFile("file.txt", "content a"),
+				fs.WithFile("file-1.txt", "content b"),
+			},
+		},
+		{
+			name:     "skip",
+			strategy: "skip",
+			expected: []fs.PathOp{
+				fs.WithFile("file.txt", "content a"),
+			},
+		},
+		{
+			name:     "overwrite",
+			strategy: "overwrite",
+			expected: []fs.PathOp{
+				fs.WithFile("file.txt", "content b"),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+
+			putFile(t, s3client, bucket, "a/file.txt", "content a")
+			putFile(t, s3client, bucket, "b/file.txt", "content b")
+
+			cmd := s5cmd("cp", "--flatten", "--on-collision", tc.strategy, "s3://"+bucket+"/*", "dir/")
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Success)
+
+			expected := fs.Expected(t, fs.WithDir("dir", tc.expected...))
+			assert.Assert(t, fs.Equal(cmd.Dir, expected))
+		})
+	}
+}
+
+// cp s3://bucket/a/* s3://bucket/b/file.txt dir/
+func TestCopyMultipleS3SourcesToLocal(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file1.txt", "content 1")
+	putFile(t, s3client, bucket, "a/nested/file2.txt", "content 2")
+	putFile(t, s3client, bucket, "b/file3.txt", "content 3")
+	putFile(t, s3client, bucket, "c/file4.txt", "content 4")
+
+	cmd := s5cmd("cp", "s3://"+bucket+"/a/*", "s3://"+bucket+"/b/file3.txt", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/a/file1.txt dir/file1.txt`, bucket),
+		1: equals(`cp s3://%v/a/nested/file2.txt dir/nested/file2.txt`, bucket),
+		2: equals(`cp s3://%v/b/file3.txt dir/file3.txt`, bucket),
+	}, sortInput(true))
+
+	expected := fs.Expected(t, fs.WithDir("dir",
+		fs.WithFile("file1.txt", "content 1"),
+		fs.WithDir("nested", fs.WithFile("file2.txt", "content 2")),
+		fs.WithFile("file3.txt", "content 3"),
+	))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp s3://bucket/a/* s3://bucket/b/* dir/
+func TestCopyMultipleS3SourcesToLocalWithCollision(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file.txt", "content a")
+	putFile(t, s3client, bucket, "b/file.txt", "content b")
+
+	cmd := s5cmd("cp", "s3://"+bucket+"/a/*", "s3://"+bucket+"/b/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`objects "s3://%v/a/file.txt" and "s3://%v/b/file.txt" would be downloaded to the same file "file.txt"`, bucket, bucket),
+	})
+
+	// nothing is downloaded if the destinations collide.
+	expected := fs.Expected(t)
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp s3://bucket/a/* s3://bucket/a/file* dir/
+func TestCopyMultipleS3SourcesToLocalWithOverlappingSources(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file.txt", "content")
+
+	cmd := s5cmd("cp", "s3://"+bucket+"/a/*", "s3://"+bucket+"/a/file*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects matched by more than one source are copied once.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/a/file.txt dir/file.txt`, bucket),
+	})
+
+	expected := fs.Expected(t, fs.WithDir("dir", fs.WithFile("file.txt", "content")))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp <multiple sources> <invalid target>
+func TestCopyMultipleSourcesWithInvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "remote object target",
+			args:     []string{"cp", "s3://" + bucket + "/a/*", "s3://" + bucket + "/b/*", "s3://" + bucket + "/object"},
+			expected: fmt.Sprintf(`target "s3://%v/object" must be a bucket or a prefix`, bucket),
+		},
+		{
+			name:     "local file target",
+			args:     []string{"cp", "s3://" + bucket + "/a/*", "s3://" + bucket + "/b/*", "file.txt"},
+			expected: `target "file.txt" must be a directory`,
+		},
+		{
+			name:     "standard input",
+			args:     []string{"cp", "-", "s3://" + bucket + "/b/*", "s3://" + bucket + "/"},
+			expected: `reading from standard input can not be used with multiple sources`,
+		},
+		{
+			name:     "local and remote sources",
+			args:     []string{"cp", "file.txt", "s3://" + bucket + "/b/*", "s3://" + bucket + "/"},
+			expected: `arguments cannot have both local and remote sources`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --on-collision invalid s3://bucket/* dir/
+func TestCopyWithInvalidOnCollision(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--on-collision", "invalid", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--on-collision must be one of error, rename, skip, overwrite`),
+	})
+}
+
+// cp --max-depth 1 s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "a/file2.txt", "content")
+	putFile(t, s3client, bucket, "a/b/file3.txt", "content")
+
+	cmd := s5cmd("cp", "--max-depth", "1", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/a/file2.txt dir/a/file2.txt`, bucket),
+		1: equals(`cp s3://%v/file1.txt dir/file1.txt`, bucket),
+	}, sortInput(true))
+
+	expected := fs.Expected(t, fs.WithDir(
+		"dir",
+		fs.WithDir("a", fs.WithFile("file2.txt", "content")),
+		fs.WithFile("file1.txt", "content"),
+	))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --max-depth 0 dir/ s3://bucket/
+func TestCopyDirToS3WithMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "this is the first test file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("file2.txt", "this is the second test file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--max-depth", "0", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file1.txt", "this is the first test file"))
+	err := ensureS3Object(s3client, bucket, "a/file2.txt", "this is the second test file")
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// cp --max-depth -1 dir/ s3://bucket/
+func TestCopyWithNegativeMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "--max-depth", "-1", "dir/", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp dir/ s3://%v/": --max-depth can not be negative`, bucket),
+	})
+}
+
+// cp --manifest manifest.csv dir/ s3://bucket/
+func TestCopyDirToS3WithManifest(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "this is the first test file"),
+		fs.WithDir(
+			"a",
+			fs.WithFile("file2.txt", "this is the second test file"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--manifest", "manifest.csv", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	manifest, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "manifest.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(manifest), map[int]compareFunc{
+		0: prefix("%v/a/file2.txt,%va/file2.txt,28,,", srcpath, dstpath),
+		1: prefix("%v/file1.txt,%vfile1.txt,27,,", srcpath, dstpath),
+		2: equals("source,destination,size,etag,timestamp"),
+	}, sortInput(true))
+}
+
+// cp --manifest manifest.csv --error-manifest errors.csv s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithManifest(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "file1.txt", "content")
+
+	cmd := s5cmd("cp", "--manifest", "manifest.csv", "--error-manifest", "errors.csv", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	manifest, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "manifest.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(manifest), map[int]compareFunc{
+		0: equals("source,destination,size,etag,timestamp"),
+		1: match(fmt.Sprintf(`^s3://%v/file1.txt,dir/file1.txt,7,[0-9a-f]{32},\d{4}-\d{2}-\d{2}T`, bucket)),
+	})
+
+	errors, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "errors.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(errors), map[int]compareFunc{
+		0: equals("source,destination,error,timestamp"),
+	})
+}
+
+// cp --error-manifest errors.csv dir/ s3://nonexistent-bucket/
+func TestCopyDirToS3WithErrorManifest(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file1.txt", "content"))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Path())
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--manifest", "manifest.csv", "--error-manifest", "errors.csv", workdir.Path()+"/", dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 3})
+
+	manifest, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "manifest.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(manifest), map[int]compareFunc{
+		0: equals("source,destination,size,etag,timestamp"),
+	})
+
+	errors, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "errors.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(errors), map[int]compareFunc{
+		0: equals("source,destination,error,timestamp"),
+		1: prefix(`%v/file1.txt,%vfile1.txt,"NoSuchBucket: The specified bucket does not exist status code: 404`, srcpath, dstpath),
+	})
+}
+
+// cp --resume-from manifest.csv s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithResumeFrom(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
+
+	// file1.txt is transferred by the previous run.
+	cmd := s5cmd("cp", "--resume-from", "manifest.csv", "s3://"+bucket+"/*", "dir/")
+
+	previous := fmt.Sprintf("source,destination,size,etag,timestamp\ns3://%v/file1.txt,dir/file1.txt,7,,2020-01-01T00:00:00Z\n", bucket)
+	err := ioutil.WriteFile(filepath.Join(cmd.Dir, "manifest.csv"), []byte(previous), 0644)
+	assert.NilError(t, err)
+
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp s3://%v/file2.txt dir/file2.txt`, bucket),
+	})
+
+	manifest, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "manifest.csv"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(manifest), map[int]compareFunc{
+		0: equals("source,destination,size,etag,timestamp"),
+		1: equals("s3://%v/file1.txt,dir/file1.txt,7,,2020-01-01T00:00:00Z", bucket),
+		2: prefix("s3://%v/file2.txt,dir/file2.txt,7,", bucket),
+	})
+
+	expected := fs.Expected(t, fs.WithFile("manifest.csv", string(manifest)), fs.WithDir(
+		"dir",
+		fs.WithFile("file2.txt", "content"),
+	))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+}
+
+// cp --if-match etag s3://bucket/* dir/
+func TestCopyWithETagPreconditionAndWildcard(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--if-match", "etag", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp s3://%v/* dir/": --if-match and --if-none-match can only be used with a single remote source object`, bucket),
+	})
+}
+
+// cp --content-md5 file s3://bucket/
+func TestCopySingleFileToS3WithContentMD5(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile.txt"
+		content  = "this is a test file"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--content-md5", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`cp %v %v%v`, srcpath, dstpath, filename),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// cp --content-md5 file s3://bucket/ (corrupted in transit)
+func TestCopySingleFileToS3WithContentMD5Corrupted(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	// the bodies of the uploads are corrupted in transit, so the server
+	// rejects them with BadDigest.
+	corrupt := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut && r.Header.Get("Content-MD5") != "" {
+				body, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				r.Body = ioutil.NopCloser(bytes.NewReader(bytes.ToUpper(body)))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	s3client, s5cmd, cleanup := setup(t, withMiddleware(corrupt))
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile.txt"
+		content  = "this is a test file"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--content-md5", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
+
+	// BadDigest is in the InvalidArgument category.
+	result.Assert(t, icmd.Expected{ExitCode: 7})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: prefix(`ERROR "cp %v %v%v": BadDigest: `, srcpath, dstpath, filename),
+	})
+
+	err := ensureS3Object(s3client, bucket, filename, content)
+	assert.Assert(t, err != nil)
+}
+
+// cp --estimate dir/ s3://bucket/
+func TestCopyDirToS3Estimate(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	folderLayout := []fs.PathOp{
+		fs.WithFile("file1.txt", "content"),
+		fs.WithDir(
+			"c",
+			fs.WithFile("file2.txt", "more content"),
+		),
+	}
+
+	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--estimate", workdir.Path()+"/", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp would affect 2 objects totaling 19 bytes`),
+	})
+
+	// assert no change in s3
+	for _, obj := range []string{"c/file2.txt", "file1.txt"} {
+		err := ensureS3Object(s3client, bucket, obj, "content")
+		assertError(t, err, errS3NoSuchKey)
+	}
+}
+
+// --json cp --estimate s3://bucket/* dir/
+func TestCopyS3ToDirEstimateJSON(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "c/file2.txt", "content")
+	putFile(t, s3client, bucket, "file1.txt", "content")
+
+	cmd := s5cmd("--json", "cp", "--estimate", "s3://"+bucket+"/*", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: json(`{"operation":"cp","count":2,"size":598}`),
+	}, jsonCheck(true))
+
+	// not even outermost directory should be created
+	_, err := os.Stat(cmd.Dir + "/dir")
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+// cp - s3://bucket/object
+func TestCopyStdinToS3(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const content = "this is a test file streamed from stdin"
+
+	cmd := s5cmd("cp", "-", "s3://"+bucket+"/prefix/object.txt")
+	result := icmd.RunCmd(cmd, icmd.WithStdin(strings.NewReader(content)))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp - s3://%v/prefix/object.txt`, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/object.txt", content))
+}
+
+// cp - s3://bucket/prefix/
+func TestCopyStdinToS3WithInvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		op       string
+		dst      string
+		expected string
+	}{
+		{
+			name:     "bucket",
+			op:       "cp",
+			dst:      "s3://bucket",
+			expected: `ERROR "cp - s3://bucket": target "s3://bucket" must be an object key when reading from standard input`,
+		},
+		{
+			name:     "prefix",
+			op:       "cp",
+			dst:      "s3://bucket/prefix/",
+			expected: `ERROR "cp - s3://bucket/prefix/": target "s3://bucket/prefix/" must be an object key when reading from standard input`,
+		},
+		{
+			name:     "local file",
+			op:       "cp",
+			dst:      "file.txt",
+			expected: `ERROR "cp - file.txt": target "file.txt" must be a remote object when reading from standard input`,
+		},
+		{
+			name:     "move",
+			op:       "mv",
+			dst:      "s3://bucket/object",
+			expected: `ERROR "mv - s3://bucket/object": reading from standard input is only supported by cp command`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(tc.op, "-", tc.dst)
+			result := icmd.RunCmd(cmd, icmd.WithStdin(strings.NewReader("content")))
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// cp s3://bucket/object -
+func TestCopyS3ToStdout(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const content = "this is a test file streamed to stdout"
+	putFile(t, s3client, bucket, "prefix/object.txt", content)
+
+	cmd := s5cmd("cp", "s3://"+bucket+"/prefix/object.txt", "-")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assert.Equal(t, content, result.Stdout())
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`cp s3://%v/prefix/object.txt -`, bucket),
+	})
+}
+
+// cp s3://bucket/missing -
+func TestCopyMissingS3ObjectToStdout(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("cp", "s3://"+bucket+"/missing.txt", "-")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 3})
+
+	assert.Equal(t, "", result.Stdout())
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`ERROR "cp s3://%v/missing.txt -"`, bucket),
+	})
+}
+
+// cp s3://bucket/* -
+func TestCopyToStdoutWithInvalidSource(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		op       string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "wildcard",
+			op:       "cp",
+			args:     []string{"s3://bucket/*"},
+			expected: `ERROR "cp s3://bucket/* -": source "s3://bucket/*" must be a single remote object when writing to standard output`,
+		},
+		{
+			name:     "prefix",
+			op:       "cp",
+			args:     []string{"s3://bucket/prefix/"},
+			expected: `ERROR "cp s3://bucket/prefix/ -": source "s3://bucket/prefix/" must be a single remote object when writing to standard output`,
+		},
+		{
+			name:     "local file",
+			op:       "cp",
+			args:     []string{"file.txt"},
+			expected: `ERROR "cp file.txt -": source "file.txt" must be a single remote object when writing to standard output`,
+		},
+		{
+			name:     "multiple sources",
+			op:       "cp",
+			args:     []string{"s3://bucket/a.txt", "s3://bucket/b.txt"},
+			expected: `ERROR "cp s3://bucket/a.txt s3://bucket/b.txt -": writing to standard output can not be used with multiple sources`,
+		},
+		{
+			name:     "move",
+			op:       "mv",
+			args:     []string{"s3://bucket/object"},
+			expected: `ERROR "mv s3://bucket/object -": writing to standard output is only supported by cp command`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			args := append([]string{tc.op}, tc.args...)
+			cmd := s5cmd(append(args, "-")...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// cp --priority high s3://bucket/* dir/
+func TestCopyMultipleS3ObjectsToLocalWithPriority(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
 
 	createBucket(t, s3client, bucket)
-	// upload a modified version of the file. also uploaded file is newer than
-	// the file on local fs.
-	putFile(t, s3client, bucket, filename, expectedContent)
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "content")
 
-	cmd := s5cmd("cp", "-n", "-u", "s3://"+bucket+"/"+filename, ".")
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("cp", "--priority", "high", "s3://"+bucket+"/*", ".")
 	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
 
-	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
-	// size differs.
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp s3://%v/%v %v`, bucket, filename, filename),
-	})
+		0: equals(`cp s3://%v/file1.txt file1.txt`, bucket),
+		1: equals(`cp s3://%v/file2.txt file2.txt`, bucket),
+	}, sortInput(true))
 
-	expected := fs.Expected(t, fs.WithFile(filename, expectedContent))
+	expected := fs.Expected(t,
+		fs.WithFile("file1.txt", "content"),
+		fs.WithFile("file2.txt", "content"),
+	)
 	assert.Assert(t, fs.Equal(workdir.Path(), expected))
 }
 
-// cp -n -u s3://bucket/object dir/ (source is older)
-func TestCopyS3ToLocalWithSameFilenameDontOverrideIfS3ObjectIsOlder(t *testing.T) {
+// cp --priority urgent s3://bucket/* dir/
+func TestCopyWithInvalidPriority(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
 
-	s3client, s5cmd, cleanup := setup(t)
+	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename = "testfile1.txt"
-		content  = "this is the content"
-	)
+	cmd := s5cmd("cp", "--priority", "urgent", "s3://"+bucket+"/*", ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--priority must be one of high, normal or low`),
+	})
+}
+
+// cp --compress file s3://bucket/
+func TestCopySingleFileToS3WithCompress(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
 
 	createBucket(t, s3client, bucket)
-	// upload a modified version of the file.
-	putFile(t, s3client, bucket, filename, content+"\n")
 
-	// file on the fs is newer than the file on s3. expect an 'dont override'
-	// behaviour.
-	now := time.Now().UTC()
-	timestamp := fs.WithTimestamps(
-		now.Add(time.Minute), // access time
-		now.Add(time.Minute), // mod time
-	)
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content, timestamp))
+	const filename = "testfile.csv"
+	content := strings.Repeat("id,name,value\n", 1024)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
 	defer workdir.Remove()
 
-	cmd := s5cmd("-log=debug", "cp", "-n", "-u", "s3://"+bucket+"/"+filename, ".")
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	srcpath := filepath.ToSlash(workdir.Join(filename))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--compress", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
 
-	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
-	// size differs.
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`DEBUG "cp s3://%v/%v %v": object is newer or same age`, bucket, filename, filename),
+		0: suffix(`cp %v %v%v`, srcpath, dstpath, filename),
 	})
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
+	// assert the object is stored compressed
+	output, err := s3client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(filename),
+	})
+	assert.NilError(t, err)
+	defer output.Body.Close()
 
-	expected := fs.Expected(t, fs.WithFile(filename, content))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	zr, err := gzip.NewReader(output.Body)
+	assert.NilError(t, err)
+
+	got, err := ioutil.ReadAll(zr)
+	assert.NilError(t, err)
+	assert.Equal(t, content, string(got))
 }
 
-// cp -u -s s3://bucket/prefix/* dir/
-func TestCopyS3ToLocal_Issue70(t *testing.T) {
+// cp --compress --content-md5 file s3://bucket/
+func TestCopySingleFileToS3WithCompressAndContentMD5(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--compress", "--content-md5", "file.txt", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt s3://bucket/": --content-md5 can not be used with --compress`),
+	})
+}
+
+// cp --bucket-owner-full-control --acl public-read file s3://bucket/
+func TestCopySingleFileToS3WithConflictingACLFlags(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--bucket-owner-full-control", "--acl", "public-read", "file.txt", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt s3://bucket/": --bucket-owner-full-control can not be used with --acl public-read`),
+	})
+}
+
+// cp --acl-public --acl private file s3://bucket/
+func TestCopySingleFileToS3WithACLPublicAndACL(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--acl-public", "--acl", "private", "file.txt", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt s3://bucket/": --acl-public can not be used with --acl private`),
+	})
+}
+
+// cp --resume-uploads file s3://bucket/
+func TestCopySingleFileToS3WithResumeUploads(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -2106,54 +6182,97 @@ func TestCopyS3ToLocal_Issue70(t *testing.T) {
 
 	createBucket(t, s3client, bucket)
 
-	filesToContent := map[string]string{
-		"config/.local/folder1/file1.txt": "this is a test file 1",
-		"config/.local/folder2/file2.txt": "this is a test file 2",
-	}
-
-	for filename, content := range filesToContent {
-		putFile(t, s3client, bucket, filename, content)
-	}
+	const partSize = 5 * 1024 * 1024
+	content := strings.Repeat("s5cmd", (2*partSize+100)/5)
 
-	workdir := fs.NewDir(t, t.Name())
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("large.txt", content))
 	defer workdir.Remove()
 
-	srcpath := fmt.Sprintf("s3://%v/config/.local/*", bucket)
-	dstpath := filepath.Join(workdir.Path(), ".local")
+	// upload the first part of the file, as if the earlier run was
+	// interrupted.
+	upload, err := s3client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String("large.txt"),
+	})
+	assert.NilError(t, err)
+
+	_, err = s3client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String("large.txt"),
+		UploadId:   upload.UploadId,
+		PartNumber: aws.Int64(1),
+		Body:       strings.NewReader(content[:partSize]),
+	})
+	assert.NilError(t, err)
 
-	dstpath = filepath.ToSlash(dstpath)
-	cmd := s5cmd("cp", "-u", "-s", srcpath, dstpath)
+	srcpath := filepath.ToSlash(workdir.Join("large.txt"))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
 
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	cmd := s5cmd("cp", "--resume-uploads", "--part-size", "5", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp s3://%v/config/.local/folder1/file1.txt %v/folder1/file1.txt`, bucket, dstpath),
-		1: equals(`cp s3://%v/config/.local/folder2/file2.txt %v/folder2/file2.txt`, bucket, dstpath),
-	}, sortInput(true))
+		0: equals(`cp %v %vlarge.txt`, srcpath, dstpath),
+	})
 
-	// assert local filesystem
-	expectedFiles := []fs.PathOp{
-		fs.WithDir(
-			".local",
-			fs.WithMode(0755),
-			fs.WithDir("folder1", fs.WithMode(0755), fs.WithFile("file1.txt", "this is a test file 1")),
-			fs.WithDir("folder2", fs.WithMode(0755), fs.WithFile("file2.txt", "this is a test file 2")),
-		),
+	assert.Assert(t, ensureS3Object(s3client, bucket, "large.txt", content))
+}
+
+// cp --resume-uploads s3://bucket/object dir/
+func TestCopyWithInvalidResumeUploads(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "download",
+			args:     []string{"--resume-uploads", "s3://" + bucket + "/file.txt", "."},
+			expected: `--resume-uploads can only be used for uploading files`,
+		},
+		{
+			name:     "compress",
+			args:     []string{"--resume-uploads", "--compress", "file.txt", "s3://" + bucket + "/"},
+			expected: `--resume-uploads can not be used with --compress`,
+		},
+		{
+			name:     "acl",
+			args:     []string{"--resume-uploads", "--acl", "public-read", "file.txt", "s3://" + bucket + "/"},
+			expected: `--resume-uploads can not be used with --acl, metadata of the upload which is resumed can't be checked`,
+		},
+		{
+			name:     "sse",
+			args:     []string{"--resume-uploads", "--sse", "aws:kms", "file.txt", "s3://" + bucket + "/"},
+			expected: `--resume-uploads can not be used with --sse, metadata of the upload which is resumed can't be checked`,
+		},
 	}
 
-	expectedResult := fs.Expected(t, expectedFiles...)
-	assert.Assert(t, fs.Equal(workdir.Path(), expectedResult))
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
 
-	// assert s3 objects
-	for filename, content := range filesToContent {
-		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
 	}
 }
 
-// cp file s3://bucket (bucket/file exists)
-func TestCopyLocalFileToS3WithTheSameFilename(t *testing.T) {
+// cp --no-overwrite-newer s3://bucket/* dir/
+func TestCopyS3ToLocalWithNoOverwriteNewer(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -2161,39 +6280,44 @@ func TestCopyLocalFileToS3WithTheSameFilename(t *testing.T) {
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename   = "testfile1.txt"
-		content    = "this is the content"
-		newContent = content + "\n"
-	)
-
 	createBucket(t, s3client, bucket)
-	putFile(t, s3client, bucket, filename, content)
+	putFile(t, s3client, bucket, "edited.txt", "remote content")
+	putFile(t, s3client, bucket, "stale.txt", "remote content")
 
-	// the file to be uploaded is modified
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
+	// edited.txt is modified locally after the object is uploaded, stale.txt
+	// is older than the object.
+	now := time.Now().UTC()
+	newer := fs.WithTimestamps(now.Add(time.Minute), now.Add(time.Minute))
+	older := fs.WithTimestamps(now.Add(-time.Minute), now.Add(-time.Minute))
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("edited.txt", "local edits", newer),
+		fs.WithFile("stale.txt", "local content", older),
+	)
 	defer workdir.Remove()
 
-	dst := "s3://" + bucket
-	cmd := s5cmd("cp", filename, dst)
+	cmd := s5cmd("-log=debug", "cp", "--no-overwrite-newer", "s3://"+bucket+"/*", ".")
 	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v/%v`, filename, dst, filename),
-	})
+		0: equals(`DEBUG "cp s3://%v/edited.txt edited.txt": object is newer or same age`, bucket),
+		1: equals(`cp s3://%v/stale.txt stale.txt`, bucket),
+	}, sortInput(true))
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, newContent))
+	expected := fs.Expected(
+		t,
+		fs.WithFile("edited.txt", "local edits"),
+		fs.WithFile("stale.txt", "remote content"),
+	)
 	assert.Assert(t, fs.Equal(workdir.Path(), expected))
-
-	// expect s3 object to be updated with new content
-	assert.Assert(t, ensureS3Object(s3client, bucket, filename, newContent))
 }
 
-// -log=debug cp -n file s3://bucket (bucket/file exists)
-func TestCopyLocalFileToS3WithSameFilenameWithNoClobber(t *testing.T) {
+// cp --no-overwrite-newer --modify-window 1h s3://bucket/* dir/
+func TestCopyS3ToLocalWithNoOverwriteNewerAndModifyWindow(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -2201,120 +6325,195 @@ func TestCopyLocalFileToS3WithSameFilenameWithNoClobber(t *testing.T) {
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename   = "testfile1.txt"
-		content    = "this is the content"
-		newContent = content + "\n"
-	)
-
 	createBucket(t, s3client, bucket)
-	putFile(t, s3client, bucket, filename, content)
+	putFile(t, s3client, bucket, "within.txt", "remote content")
+	putFile(t, s3client, bucket, "edited.txt", "remote content")
 
-	// the file to be uploaded is modified
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
+	// within.txt is newer than the object only within the window.
+	now := time.Now().UTC()
+	within := fs.WithTimestamps(now.Add(time.Minute), now.Add(time.Minute))
+	newer := fs.WithTimestamps(now.Add(2*time.Hour), now.Add(2*time.Hour))
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("within.txt", "local content", within),
+		fs.WithFile("edited.txt", "local edits", newer),
+	)
 	defer workdir.Remove()
 
-	cmd := s5cmd("-log=debug", "cp", "-n", filename, "s3://"+bucket)
+	cmd := s5cmd("-log=debug", "cp", "--no-overwrite-newer", "--modify-window", "1h", "s3://"+bucket+"/*", ".")
 	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
 
-	result.Assert(t, icmd.Success)
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`DEBUG "cp s3://%v/edited.txt edited.txt": object is newer or same age`, bucket),
+		1: equals(`cp s3://%v/within.txt within.txt`, bucket),
+	}, sortInput(true))
+
+	expected := fs.Expected(
+		t,
+		fs.WithFile("edited.txt", "local edits"),
+		fs.WithFile("within.txt", "remote content"),
+	)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+}
+
+// cp --modify-window 1s s3://bucket/* dir/
+func TestCopyWithInvalidModifyWindow(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "without_modification_time_comparison",
+			args:     []string{"--modify-window", "1s"},
+			expected: "--modify-window can only be used with --if-source-newer or --no-overwrite-newer",
+		},
+		{
+			name:     "negative",
+			args:     []string{"-u", "--modify-window", "-1s"},
+			expected: "--modify-window can not be negative",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`DEBUG "cp %v s3://%v/%v": object already exists`, filename, bucket, filename),
-	})
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
+			args := append([]string{"cp"}, tc.args...)
+			cmd := s5cmd(append(args, "s3://"+bucket+"/*", ".")...)
+			result := icmd.RunCmd(cmd)
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, newContent))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+			result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	// expect s3 object is not overridden
-	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
 }
 
-// cp -n file s3://bucket
-func TestCopyLocalFileToS3WithNoClobber(t *testing.T) {
+// cp --no-overwrite-newer file s3://bucket/
+func TestCopyWithNoOverwriteNewerNotDownload(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
 
-	s3client, s5cmd, cleanup := setup(t)
+	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename   = "testfile1.txt"
-		content    = "this is the content"
-		newContent = content + "\n"
-	)
+	cmd := s5cmd("cp", "--no-overwrite-newer", "file.txt", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
 
-	createBucket(t, s3client, bucket)
+	result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	// the file to be uploaded is modified
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, newContent))
-	defer workdir.Remove()
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--no-overwrite-newer can only be used for downloads`),
+	})
+}
 
-	dst := "s3://" + bucket
-	cmd := s5cmd("cp", "-n", filename, dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+// cp --add-extension dir/ s3://bucket/
+func TestCopyWithInvalidAddExtension(t *testing.T) {
+	t.Parallel()
 
-	result.Assert(t, icmd.Success)
+	bucket := s3BucketFromTestName(t)
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v/%v`, filename, dst, filename),
-	})
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "upload",
+			args:     []string{"--add-extension", "dir/", "s3://" + bucket + "/"},
+			expected: `--add-extension can only be used for downloads`,
+		},
+		{
+			name:     "only missing",
+			args:     []string{"--add-extension", "--only-missing", "s3://" + bucket + "/*", "."},
+			expected: `--add-extension can not be used with --only-missing`,
+		},
+	}
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, newContent))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
 
-	// expect s3 object is not overridden
-	assert.Assert(t, ensureS3Object(s3client, bucket, filename, newContent))
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
 }
 
-// cp -n -s file s3://bucket (bucket/file exists)
-func TestCopyLocalFileToS3WithSameFilenameOverrideIfSizeDiffers(t *testing.T) {
+// cp --grant-read grantee file s3://bucket/
+func TestCopyWithInvalidGrants(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
 
-	s3client, s5cmd, cleanup := setup(t)
+	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename        = "testfile1.txt"
-		content         = "this is the content"
-		expectedContent = content + "\n"
-	)
-
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent))
-	defer workdir.Remove()
-
-	createBucket(t, s3client, bucket)
-	// upload a modified version of the file
-	putFile(t, s3client, bucket, filename, content)
-
-	dst := "s3://" + bucket
-	cmd := s5cmd("cp", "-n", "-s", filename, dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	dst := "s3://" + bucket + "/"
 
-	// '-n' prevents overriding the file, but '-s' overrides '-n' if the file
-	// size differs.
-	result.Assert(t, icmd.Success)
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid grantee",
+			args:     []string{"--grant-read", "user=79a59df900b949e5", "file.txt", dst},
+			expected: `invalid --grant-read "user=79a59df900b949e5": grantee type must be one of id, emailAddress, uri`,
+		},
+		{
+			name:     "canned acl",
+			args:     []string{"--grant-read", "id=79a59df900b949e5", "--acl", "public-read", "file.txt", dst},
+			expected: `grant options can not be used with --acl or --bucket-owner-full-control`,
+		},
+		{
+			name:     "download",
+			args:     []string{"--grant-full-control", "emailAddress=owner@example.com", dst + "file.txt", "."},
+			expected: `grant options can only be used for uploads and copying S3 objects`,
+		},
+	}
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v/%v`, filename, dst, filename),
-	})
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
+			result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	assert.NilError(t, ensureS3Object(s3client, bucket, filename, expectedContent))
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
 }
 
-// cp -n -u file s3://bucket (bucket/file exists, source is newer)
-func TestCopyLocalFileToS3WithSameFilenameOverrideIfSourceIsNewer(t *testing.T) {
+// cp --grant-read id=... file s3://bucket/
+func TestCopySingleFileToS3WithGrants(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -2322,164 +6521,195 @@ func TestCopyLocalFileToS3WithSameFilenameOverrideIfSourceIsNewer(t *testing.T)
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename        = "testfile1.txt"
-		content         = "this is the content"
-		expectedContent = content + "\n"
-	)
-
 	createBucket(t, s3client, bucket)
-	// upload a modified version of the file. also uploaded file is newer than
-	// the file on local fs.
-	putFile(t, s3client, bucket, filename, content)
 
-	now := time.Now().UTC()
-	timestamp := fs.WithTimestamps(
-		now.Add(time.Minute), // access time
-		now.Add(time.Minute), // mod time
-	)
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent, timestamp))
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
 	defer workdir.Remove()
 
-	dst := "s3://" + bucket
-	cmd := s5cmd("cp", "-n", "-u", filename, dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	srcpath := filepath.ToSlash(workdir.Join("file.txt"))
+	dstpath := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--grant-read", "id=79a59df900b949e5,uri=http://acs.amazonaws.com/groups/global/AllUsers", srcpath, dstpath)
+	result := icmd.RunCmd(cmd)
 
-	// '-n' prevents overriding the file, but '-u' overrides '-n' if the file
-	// modtime differs.
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v/%v`, filename, dst, filename),
+		0: equals(`cp %v %vfile.txt`, srcpath, dstpath),
 	})
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
-
-	assert.NilError(t, ensureS3Object(s3client, bucket, filename, expectedContent))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file.txt", "content"))
 }
 
-// cp -n -u file s3://bucket (bucket/file exists, source is older)
-func TestCopyLocalFileToS3WithSameFilenameDontOverrideIfS3ObjectIsOlder(t *testing.T) {
+// cp --dest-endpoint <endpoint> s3://bucket/* s3://other-bucket/
+func TestCopyS3ToS3WithDestEndpoint(t *testing.T) {
 	t.Parallel()
 
-	bucket := s3BucketFromTestName(t)
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
 
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename        = "testfile1.txt"
-		content         = "this is the content"
-		expectedContent = content + "\n"
-	)
+	// objects are copied to a different S3 service.
+	dstclient, _, dstcleanup := setup(t)
+	defer dstcleanup()
 
-	createBucket(t, s3client, bucket)
-	// upload a modified version of the file. also uploaded file is newer than
-	// the file on local fs.
-	putFile(t, s3client, bucket, filename, content)
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, dstclient, dstbucket)
 
-	now := time.Now().UTC()
-	timestamp := fs.WithTimestamps(
-		now.Add(-time.Minute), // access time
-		now.Add(-time.Minute), // mod time
-	)
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, expectedContent, timestamp))
-	defer workdir.Remove()
+	putFile(t, s3client, srcbucket, "file1.txt", "first file")
+	putFile(t, s3client, srcbucket, "a/file2.txt", "second file")
 
-	cmd := s5cmd("-log=debug", "cp", "-n", "-u", filename, "s3://"+bucket)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	src := fmt.Sprintf("s3://%v/*", srcbucket)
+	dst := fmt.Sprintf("s3://%v/", dstbucket)
+
+	cmd := s5cmd("cp", "--dest-endpoint", dstclient.Endpoint, src, dst)
+	result := icmd.RunCmd(cmd)
 
-	// '-n' prevents overriding the file, but '-u' overrides '-n' if the file
-	// modtime differs.
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`DEBUG "cp %v s3://%v/%v": object is newer or same age`, filename, bucket, filename),
-	})
+		0: equals(`cp s3://%v/a/file2.txt s3://%v/a/file2.txt`, srcbucket, dstbucket),
+		1: equals(`cp s3://%v/file1.txt s3://%v/file1.txt`, srcbucket, dstbucket),
+	}, sortInput(true))
 
-	assertLines(t, result.Stderr(), map[int]compareFunc{})
+	assert.Assert(t, ensureS3Object(dstclient, dstbucket, "file1.txt", "first file"))
+	assert.Assert(t, ensureS3Object(dstclient, dstbucket, "a/file2.txt", "second file"))
 
-	assert.NilError(t, ensureS3Object(s3client, bucket, filename, content))
+	// source objects are kept.
+	assert.Assert(t, ensureS3Object(s3client, srcbucket, "file1.txt", "first file"))
 }
 
-// cp file s3://bucket/object
-func TestCopyLocalFileToS3WithCustomName(t *testing.T) {
+// mv --source-endpoint <endpoint> s3://bucket/object s3://other-bucket/object
+func TestMoveS3ToS3WithSourceEndpoint(t *testing.T) {
 	t.Parallel()
 
-	bucket := s3BucketFromTestName(t)
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+
+	srcclient, _, srccleanup := setup(t)
+	defer srccleanup()
 
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename = "testfile1.txt"
-		content  = "this is the content"
-	)
-
-	createBucket(t, s3client, bucket)
+	createBucket(t, srcclient, srcbucket)
+	createBucket(t, s3client, dstbucket)
 
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
-	defer workdir.Remove()
+	putFile(t, srcclient, srcbucket, "file.txt", "content")
 
-	dstpath := fmt.Sprintf("s3://%v/%v", bucket, filename)
+	src := fmt.Sprintf("s3://%v/file.txt", srcbucket)
+	dst := fmt.Sprintf("s3://%v/file.txt", dstbucket)
 
-	cmd := s5cmd("cp", filename, dstpath)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	cmd := s5cmd("mv", "--source-endpoint", srcclient.Endpoint, src, dst)
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v`, filename, dstpath),
+		0: equals(`mv %v %v`, src, dst),
 	})
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, content))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "file.txt", "content"))
 
-	// assert s3 object
-	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	err := ensureS3Object(srcclient, srcbucket, "file.txt", "content")
+	assertError(t, err, errS3NoSuchKey)
 }
 
-// cp file s3://bucket/prefix/
-func TestCopyLocalFileToS3WithPrefix(t *testing.T) {
+func TestCopyWithInvalidEndpointOptions(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "source endpoint with local source",
+			args:     []string{"cp", "--source-endpoint", "http://localhost:9000", "file.txt", "s3://bucket/"},
+			expected: "source endpoint options can only be used with remote sources",
+		},
+		{
+			name:     "dest profile with local target",
+			args:     []string{"cp", "--dest-profile", "other", "s3://bucket/file.txt", "."},
+			expected: "dest endpoint options can only be used with remote targets",
+		},
+		{
+			name:     "copy acl across endpoints",
+			args:     []string{"cp", "--copy-acl", "--dest-region", "eu-west-1", "s3://bucket/file.txt", "s3://other-bucket/"},
+			expected: "--copy-acl can not be used with different source and dest endpoints",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
+}
+
+func TestCopyWithInvalidContentTypeMap(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
 
-	s3client, s5cmd, cleanup := setup(t)
+	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename = "testfile1.txt"
-		content  = "this is the content"
+	workdir := fs.NewDir(t, t.Name(),
+		fs.WithFile("types.json", `{".wasm": "application/wasm"}`),
+		fs.WithFile("invalid.json", `{".wasm": "wasm"}`),
 	)
-
-	createBucket(t, s3client, bucket)
-
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
 	defer workdir.Remove()
 
-	dstpath := fmt.Sprintf("s3://%v/s5cmdtest/", bucket)
-
-	cmd := s5cmd("cp", filename, dstpath)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	dst := "s3://" + bucket + "/"
 
-	result.Assert(t, icmd.Success)
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "invalid content type",
+			args:     []string{"--content-type-map", workdir.Join("invalid.json"), "file.txt", dst},
+			expected: `"wasm" is not a valid content type for "wasm"`,
+		},
+		{
+			name:     "download",
+			args:     []string{"--content-type-map", workdir.Join("types.json"), dst + "app.wasm", "."},
+			expected: `--content-type-map can only be used for uploading files`,
+		},
+	}
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v%v`, filename, dstpath, filename),
-	})
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := s5cmd(append([]string{"cp"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, content))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+			result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	// assert s3 object
-	assert.Assert(t, ensureS3Object(s3client, bucket, fmt.Sprintf("s5cmdtest/%v", filename), content))
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: contains(tc.expected),
+			})
+		})
+	}
 }
 
-// cp file s3://bucket
-func TestMultipleLocalFileToS3Bucket(t *testing.T) {
+// cp --from-inventory s3://bucket/inventory/manifest.json s3://bucket/a/* s3://bucket/copy/
+func TestCopyS3ObjectsFromInventory(t *testing.T) {
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
@@ -2487,226 +6717,149 @@ func TestMultipleLocalFileToS3Bucket(t *testing.T) {
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const (
-		filename = "testfile1.txt"
-		content  = "this is the content"
-	)
-
 	createBucket(t, s3client, bucket)
 
-	workdir := fs.NewDir(t, t.Name(), fs.WithFile(filename, content))
-	defer workdir.Remove()
+	putFile(t, s3client, bucket, "a/file1.txt", "this is a test file 1")
+	putFile(t, s3client, bucket, "a/file2.txt", "this is a test file 2")
 
-	dstpath := fmt.Sprintf("s3://%v", bucket)
+	// the report is out of date, it doesn't have the second file.
+	putInventory(t, s3client, bucket, "a/file1.txt,21")
 
-	cmd := s5cmd("cp", filename, dstpath)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	src := fmt.Sprintf("s3://%v/a/*", bucket)
+	dst := fmt.Sprintf("s3://%v/copy/", bucket)
+
+	cmd := s5cmd("cp", "--from-inventory", "s3://"+bucket+"/inventory/manifest.json", src, dst)
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v %v/%v`, filename, dstpath, filename),
+		0: equals(`cp s3://%v/a/file1.txt s3://%v/copy/file1.txt`, bucket, bucket),
 	})
 
-	// assert local filesystem
-	expected := fs.Expected(t, fs.WithFile(filename, content))
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "copy/file1.txt", "this is a test file 1"))
 
-	// assert s3 object
-	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	err := ensureS3Object(s3client, bucket, "copy/file2.txt", "this is a test file 2")
+	assertError(t, err, errS3NoSuchKey)
 }
 
-// cp * s3://bucket/prefix/
-func TestCopyMultipleLocalNestedFilesToS3(t *testing.T) {
+// cp --from-inventory s3://bucket/inventory/manifest.json dir/* s3://bucket/
+func TestCopyFromInventoryWithLocalSource(t *testing.T) {
 	t.Parallel()
 
+	bucket := s3BucketFromTestName(t)
+
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const bucket = "bucket"
 	createBucket(t, s3client, bucket)
 
-	// nested folder layout
-	//
-	// ├─a
-	// │ ├─readme.md
-	// │ └─file1.txt
-	// └─b
-	//   └─c
-	//     └─file2.txt
-	//
-	// after `s5cmd cp * s3://bucket/prefix/`, expect:
-	//
-	// prefix
-	//  ├─a
-	//  │ ├─readme.md
-	//  │ └─file1.txt
-	//  └─b
-	//    └─c
-	//      └─file2.txt
-
-	folderLayout := []fs.PathOp{
-		fs.WithDir(
-			"a",
-			fs.WithFile("file1.txt", "file1"),
-			fs.WithFile("readme.md", "readme"),
-		),
-		fs.WithDir(
-			"b",
-			fs.WithDir(
-				"c",
-				fs.WithFile("file2.txt", "file2"),
-			),
-		),
-	}
-
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file1.txt", "content"))
 	defer workdir.Remove()
 
-	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
-
-	cmd := s5cmd("cp", "*", dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
-
-	result.Assert(t, icmd.Success)
+	src := fmt.Sprintf("%v/*", workdir.Path())
+	src = filepath.ToSlash(src)
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals("cp a/file1.txt %va/file1.txt", dst),
-		1: equals("cp a/readme.md %va/readme.md", dst),
-		2: equals("cp b/c/file2.txt %vb/c/file2.txt", dst),
-	}, sortInput(true))
+	cmd := s5cmd("cp", "--from-inventory", "s3://"+bucket+"/inventory/manifest.json", src, "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
 
-	// assert local filesystem
-	expected := fs.Expected(t, folderLayout...)
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	// assert s3 objects
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/readme.md", "readme"))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/file1.txt", "file1"))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/b/c/file2.txt", "file2"))
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp %v s3://%v/": --from-inventory can only be used with remote sources`, src, bucket),
+	})
 }
 
-// cp --no-follow-symlinks my_link s3://bucket/prefix/
-func TestCopyLinkToASingleFileWithFollowSymlinkDisabled(t *testing.T) {
+// cp --threads-per-file 1 file s3://bucket/
+func TestCopySingleFileToS3WithThreadsPerFile(t *testing.T) {
 	t.Parallel()
 
+	bucket := s3BucketFromTestName(t)
+
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const bucket = "bucket"
 	createBucket(t, s3client, bucket)
 
-	fileContent := "CAFEBABE"
-	folderLayout := []fs.PathOp{
-		fs.WithDir(
-			"a",
-			fs.WithFile("f1.txt", fileContent),
-		),
-		fs.WithDir("b"),
-		fs.WithSymlink("b/my_link", "a/f1.txt"),
-	}
-
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
+	workdir := fs.NewDir(t, t.Name(), fs.WithFile("file.txt", "content"))
 	defer workdir.Remove()
 
-	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+	srcpath := filepath.ToSlash(workdir.Join("file.txt"))
 
-	cmd := s5cmd("cp", "--no-follow-symlinks", "b/my_link", dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	cmd := s5cmd("cp", "--threads-per-file", "1", srcpath, "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{})
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`cp %v s3://%v/file.txt`, srcpath, bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file.txt", "content"))
 }
 
-// cp * s3://bucket/prefix/
-func TestCopyWithFollowSymlink(t *testing.T) {
+// cp --threads-per-file 0 file s3://bucket/
+func TestCopyWithInvalidThreadsPerFile(t *testing.T) {
 	t.Parallel()
 
-	s3client, s5cmd, cleanup := setup(t)
+	_, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const bucket = "bucket"
-	createBucket(t, s3client, bucket)
-
-	fileContent := "CAFEBABE"
-	folderLayout := []fs.PathOp{
-		fs.WithDir(
-			"a",
-			fs.WithFile("f1.txt", fileContent),
-		),
-		fs.WithDir("b"),
-		fs.WithDir("c"),
-		fs.WithSymlink("b/link1", "a/f1.txt"),
-		fs.WithSymlink("c/link2", "b/link1"),
-	}
-
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
-	defer workdir.Remove()
-
-	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
-
-	cmd := s5cmd("cp", "*", dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
-
-	result.Assert(t, icmd.Success)
+	cmd := s5cmd("cp", "--threads-per-file", "0", "file.txt", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals("cp a/f1.txt %va/f1.txt", dst),
-		1: equals("cp b/link1 %vb/link1", dst),
-		2: equals("cp c/link2 %vc/link2", dst),
-	}, sortInput(true))
+	result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	// assert s3 objects
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/f1.txt", fileContent))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/b/link1", fileContent))
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/c/link2", fileContent))
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt s3://bucket/": --concurrency must be a positive value`),
+	})
 }
 
-// cp --no-follow-symlinks * s3://bucket/prefix/
-func TestCopyWithNoFollowSymlink(t *testing.T) {
+// cp --on-success 'sh -c ...' s3://bucket/*.txt dir/
+func TestCopyMultipleS3ObjectsToLocalWithOnSuccess(t *testing.T) {
 	t.Parallel()
 
+	if runtime.GOOS == "windows" {
+		t.Skip("hook command uses sh")
+	}
+
+	bucket := s3BucketFromTestName(t)
+
 	s3client, s5cmd, cleanup := setup(t)
 	defer cleanup()
 
-	const bucket = "bucket"
 	createBucket(t, s3client, bucket)
 
-	fileContent := "CAFEBABE"
-	folderLayout := []fs.PathOp{
-		fs.WithDir(
-			"a",
-			fs.WithFile("f1.txt", fileContent),
-		),
-		fs.WithDir("b"),
-		fs.WithDir("c"),
-		fs.WithSymlink("b/link1", "a/f1.txt"),
-		fs.WithSymlink("c/link2", "b/link1"),
-	}
-
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
-	defer workdir.Remove()
-
-	dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+	putFile(t, s3client, bucket, "file1.txt", "content")
+	putFile(t, s3client, bucket, "file2.txt", "longer content")
 
-	cmd := s5cmd("cp", "--no-follow-symlinks", "*", dst)
-	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+	cmd := s5cmd("--numworkers", "1", "cp", "--on-success", `sh -c 'echo "$0 $1 $2" >> hooks.txt' {key} {path} {size}`, "s3://"+bucket+"/*.txt", "dir/")
+	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals("cp a/f1.txt %va/f1.txt", dst),
+		0: equals(`cp s3://%v/file1.txt dir/file1.txt`, bucket),
+		1: equals(`cp s3://%v/file2.txt dir/file2.txt`, bucket),
 	}, sortInput(true))
 
-	// assert s3 objects
-	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/f1.txt", fileContent))
+	hooks, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "hooks.txt"))
+	assert.NilError(t, err)
+
+	assertLines(t, string(hooks), map[int]compareFunc{
+		0: equals(`file1.txt dir/file1.txt 7`),
+		1: equals(`file2.txt dir/file2.txt 14`),
+	}, sortInput(true))
 }
 
-// --dry-run cp dir/ s3://bucket/
-func TestCopyDirToS3DryRun(t *testing.T) {
+// cp --on-error 'sh -c ...' s3://bucket/missing.txt .
+func TestCopyS3ObjectToLocalWithOnError(t *testing.T) {
 	t.Parallel()
 
+	if runtime.GOOS == "windows" {
+		t.Skip("hook command uses sh")
+	}
+
 	bucket := s3BucketFromTestName(t)
 
 	s3client, s5cmd, cleanup := setup(t)
@@ -2714,76 +6867,120 @@ func TestCopyDirToS3DryRun(t *testing.T) {
 
 	createBucket(t, s3client, bucket)
 
-	folderLayout := []fs.PathOp{
-		fs.WithFile("file1.txt", "content"),
-		fs.WithDir(
-			"c",
-			fs.WithFile("file2.txt", "content"),
-		),
-	}
+	cmd := s5cmd("cp", "--on-error", `sh -c 'echo "$0: $1" > hooks.txt' {src} {error}`, "s3://"+bucket+"/missing.txt", ".")
+	result := icmd.RunCmd(cmd)
 
-	workdir := fs.NewDir(t, t.Name(), folderLayout...)
-	defer workdir.Remove()
+	result.Assert(t, icmd.Expected{ExitCode: 3})
 
-	srcpath := filepath.ToSlash(workdir.Path())
-	dstpath := fmt.Sprintf("s3://%v/", bucket)
+	hooks, err := ioutil.ReadFile(filepath.Join(cmd.Dir, "hooks.txt"))
+	assert.NilError(t, err)
 
-	cmd := s5cmd("--dry-run", "cp", workdir.Path()+"/", dstpath)
+	assertLines(t, string(hooks), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^s3://%v/missing.txt: .*NoSuchKey`, bucket)),
+	})
+}
+
+// cp --on-success 'notify "{key}' file s3://bucket/
+func TestCopyWithInvalidOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("cp", "--on-success", `notify "{key}`, "file.txt", "s3://bucket/")
 	result := icmd.RunCmd(cmd)
 
-	result.Assert(t, icmd.Success)
+	result.Assert(t, icmd.Expected{ExitCode: 1})
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals(`cp %v/c/file2.txt %vc/file2.txt`, srcpath, dstpath),
-		1: equals(`cp %v/file1.txt %vfile1.txt`, srcpath, dstpath),
-	}, sortInput(true))
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp file.txt s3://bucket/": invalid --on-success command: unclosed quote in "notify \"{key}"`),
+	})
+}
 
-	// assert no change in s3
-	objs := []string{"c/file2.txt", "file1.txt"}
-	for _, obj := range objs {
-		err := ensureS3Object(s3client, bucket, obj, "content")
-		assertError(t, err, errS3NoSuchKey)
+// stallingResponseWriter writes the first half of the response body and
+// blocks until the request is canceled, to interrupt a download midway.
+type stallingResponseWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	started func()
+}
+
+func (w stallingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p[:len(p)/2])
+	if err != nil {
+		return n, err
 	}
+	w.ResponseWriter.(http.Flusher).Flush()
+	w.started()
 
-	// assert local filesystem
-	expected := fs.Expected(t, folderLayout...)
-	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+	<-w.ctx.Done()
+	return n, w.ctx.Err()
 }
 
-// --dry-run cp s3://bucket/* dir/
-func TestCopyS3ToDirDryRun(t *testing.T) {
+// cp s3://bucket/object . (interrupted)
+func TestCopySingleS3ObjectToLocalInterrupted(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("interrupt signal can not be sent on windows")
+	}
+
 	t.Parallel()
 
 	bucket := s3BucketFromTestName(t)
+	objectPath := fmt.Sprintf("/%v/file.txt", bucket)
+
+	started := make(chan struct{})
+	var startOnce sync.Once
+	stall := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == objectPath {
+				w = stallingResponseWriter{
+					ResponseWriter: w,
+					ctx:            r.Context(),
+					started:        func() { startOnce.Do(func() { close(started) }) },
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
 
-	s3client, s5cmd, cleanup := setup(t)
+	s3client, s5cmd, cleanup := setup(t, withMiddleware(stall))
 	defer cleanup()
 
 	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "file.txt", strings.Repeat("content", 1024))
 
-	files := [...]string{"c/file2.txt", "file1.txt"}
+	cmd := s5cmd("cp", "s3://"+bucket+"/file.txt", ".")
+	result := icmd.StartCmd(cmd)
+	if result.Error != nil {
+		t.Fatal(result.Error)
+	}
 
-	putFile(t, s3client, bucket, files[0], "content")
-	putFile(t, s3client, bucket, files[1], "content")
+	select {
+	case <-started:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the download to start")
+	}
 
-	srcpath := fmt.Sprintf("s3://%s", bucket)
+	const drainMessage = "Interrupted, waiting for in-flight operations to finish. Press Ctrl-C again to force quit.\n"
 
-	cmd := s5cmd("--dry-run", "cp", srcpath+"/*", "dir/")
-	result := icmd.RunCmd(cmd)
+	// first interrupt waits for the in-flight download, second one aborts it.
+	if err := result.Cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 100 && result.Stderr() != drainMessage; i++ {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := result.Cmd.Process.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
 
-	result.Assert(t, icmd.Success)
+	result = icmd.WaitOnCmd(10*time.Second, result)
+	result.Assert(t, icmd.Expected{ExitCode: 130})
 
-	assertLines(t, result.Stdout(), map[int]compareFunc{
-		0: equals("cp %v/c/file2.txt dir/%s", srcpath, files[0]),
-		1: equals("cp %v/file1.txt dir/%s", srcpath, files[1]),
-	}, sortInput(true))
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+	assert.Equal(t, drainMessage, result.Stderr())
 
-	// not even outermost directory should be created
-	_, err := os.Stat(cmd.Dir + "/dir")
+	// the partially downloaded file is removed.
+	_, err := os.Stat(filepath.Join(cmd.Dir, "file.txt"))
 	assert.Assert(t, os.IsNotExist(err))
-
-	// assert s3
-	for _, f := range files {
-		assert.Assert(t, ensureS3Object(s3client, bucket, f, "content"))
-	}
 }
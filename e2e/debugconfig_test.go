@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/icmd"
+)
+
+// debug-config --region eu-west-1
+func TestDebugConfig(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("debug-config", "--region", "eu-west-1")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// credentials are given with the environment variables, which are
+	// printed by the name of their provider only.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("region: eu-west-1 (flag)"),
+		1: match(`^endpoint: http://127\.0\.0\.1:\d+$`),
+		2: prefix("profile: "),
+		3: prefix("shared-config: "),
+		4: equals("credentials: EnvConfigCredentials"),
+		5: equals("path-style: true"),
+		6: equals("accelerate: false"),
+		7: prefix("max-retries: "),
+	})
+}
+
+// --json --no-sign-request debug-config
+func TestDebugConfigJSONWithNoSignRequest(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("--json", "--no-sign-request", "--retry-count", "3", "debug-config", "--region", "eu-west-1")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^{"region":"eu-west-1","region_source":"flag","endpoint":"http://127\.0\.0\.1:\d+",.*"path_style":true,"accelerate":false,"max_retries":3,"credentials":"anonymous"}$`),
+	})
+}
+
+// debug-config --region eu-west-1 s3://bucket
+func TestDebugConfigWithBucket(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	// regions are only detected for AWS S3, the given region is kept for
+	// the other endpoints.
+	cmd := s5cmd("debug-config", "--region", "eu-west-1", "s3://bucket")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("region: eu-west-1 (flag)"),
+		1: match(`^endpoint: http://127\.0\.0\.1:\d+$`),
+		2: prefix("profile: "),
+		3: prefix("shared-config: "),
+		4: equals("credentials: EnvConfigCredentials"),
+		5: equals("path-style: true"),
+		6: equals("accelerate: false"),
+		7: prefix("max-retries: "),
+	})
+}
+
+func TestDebugConfigWithInvalidArguments(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "object",
+			args:     []string{"s3://bucket/key"},
+			expected: `ERROR "debug-config s3://bucket/key": argument must be a bucket, e.g. s3://bucket`,
+		},
+		{
+			name:     "multiple buckets",
+			args:     []string{"s3://bucket", "s3://other-bucket"},
+			expected: `ERROR "debug-config s3://bucket s3://other-bucket": expected at most one bucket argument`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"debug-config"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 
@@ -183,3 +184,140 @@ func TestDiskUsageMissingObject(t *testing.T) {
 		0: suffix(`0 bytes in 0 objects: s3://%v/non-existent-file`, bucket),
 	})
 }
+
+// du s3://bucket/a/* s3://bucket/b/* s3://bucket/a/file*
+func TestDiskUsageMultipleSources(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "a/file1.txt", "content")
+	putFile(t, s3client, bucket, "a/file2.txt", "content")
+	putFile(t, s3client, bucket, "b/file3.txt", "content")
+	putFile(t, s3client, bucket, "c/file4.txt", "content")
+
+	cmd := s5cmd("du", "s3://"+bucket+"/a/*", "s3://"+bucket+"/b/*", "s3://"+bucket+"/a/file1*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects matched by more than one source are counted once in the total.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^\d+ bytes in 2 objects: s3://%v/a/\*$`, bucket)),
+		1: match(fmt.Sprintf(`^\d+ bytes in 1 objects: s3://%v/b/\*$`, bucket)),
+		2: match(fmt.Sprintf(`^\d+ bytes in 1 objects: s3://%v/a/file1\*$`, bucket)),
+		3: match(`^\d+ bytes in 3 objects: total$`),
+	})
+}
+
+func TestDiskUsageWildcardWithCountOnly(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "this is a file content")
+	putFile(t, s3client, bucket, "foo/testfile2.txt", "this is also a file content")
+	putFile(t, s3client, bucket, "bar/testfile3.gz", "this is also a file content somehow")
+
+	cmd := s5cmd("du", "--count-only", "s3://"+bucket+"/*.txt")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`2 objects: s3://%v/*.txt`, bucket),
+	})
+}
+
+func TestDiskUsageWithCountOnlyJSON(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "this is a file content")
+	putFile(t, s3client, bucket, "testfile2.txt", "this is also a file content")
+
+	cmd := s5cmd("--json", "du", "--count-only", "s3://"+bucket+"/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: json(`
+			{
+				"source": "s3://%v/*",
+				"count":2
+			}
+		`, bucket),
+	}, jsonCheck(true))
+}
+
+func TestDiskUsageWithCountOnlyAndHumanize(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("du", "--count-only", "--humanize", "s3://bucket/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "du s3://bucket/*": --count-only can not be used with --humanize`),
+	})
+}
+
+// du --from-inventory s3://bucket/inventory/manifest.json s3://bucket/*
+func TestDiskUsageFromInventory(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putInventory(t, s3client, bucket, "a/file1.txt,100", "a/nested/file2.txt,200", "b/file3.txt,300")
+
+	cmd := s5cmd("du", "--from-inventory", "s3://"+bucket+"/inventory/manifest.json", "s3://"+bucket+"/a/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(`300 bytes in 2 objects: s3://%v/a/*`, bucket),
+	})
+}
+
+// du --from-inventory dir/manifest.json s3://bucket/*
+func TestDiskUsageFromInventoryWithLocalManifest(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("du", "--from-inventory", "manifest.json", "s3://"+bucket+"/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "du s3://%v/*": --from-inventory must be the S3 URL of an inventory manifest, e.g. s3://bucket/inventory/manifest.json`, bucket),
+	})
+}
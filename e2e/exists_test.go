@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+
+	const bucket = "bucket"
+
+	testcases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		expected map[int]compareFunc
+	}{
+		{
+			name:     "existing object",
+			args:     []string{"exists", "s3://bucket/dir/file.txt"},
+			exitCode: 0,
+		},
+		{
+			name:     "missing object",
+			args:     []string{"exists", "s3://bucket/dir/missing.txt"},
+			exitCode: 1,
+		},
+		{
+			name:     "matching wildcard",
+			args:     []string{"exists", "s3://bucket/dir/*.txt"},
+			exitCode: 0,
+		},
+		{
+			name:     "not matching wildcard",
+			args:     []string{"exists", "s3://bucket/dir/*.gz"},
+			exitCode: 1,
+		},
+		{
+			name:     "existing object with verbose flag",
+			args:     []string{"exists", "-v", "s3://bucket/dir/file.txt"},
+			exitCode: 0,
+			expected: map[int]compareFunc{
+				0: equals("s3://bucket/dir/file.txt exists"),
+			},
+		},
+		{
+			name:     "missing object with verbose flag",
+			args:     []string{"exists", "-v", "s3://bucket/dir/missing.txt"},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: equals("s3://bucket/dir/missing.txt does not exist"),
+			},
+		},
+		{
+			name:     "matching wildcard with verbose flag",
+			args:     []string{"exists", "-v", "s3://bucket/dir/*.txt"},
+			exitCode: 0,
+			expected: map[int]compareFunc{
+				0: equals("s3://bucket/dir/*.txt exists: s3://bucket/dir/file.txt"),
+			},
+		},
+		{
+			name:     "matching wildcard with verbose and json flags",
+			args:     []string{"--json", "exists", "-v", "s3://bucket/dir/*.txt"},
+			exitCode: 0,
+			expected: map[int]compareFunc{
+				0: json(`
+					{
+						"source": "s3://bucket/dir/*.txt",
+						"exists": true,
+						"object": "s3://bucket/dir/file.txt"
+					}
+				`),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "dir/file.txt", "content")
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: tc.exitCode})
+
+			// nothing is printed unless the verbose flag is given.
+			if tc.expected == nil {
+				assert.Equal(t, result.Stdout(), "")
+				return
+			}
+
+			assertLines(t, result.Stdout(), tc.expected, jsonCheck(tc.args[0] == "--json"))
+		})
+	}
+}
+
+func TestExistsWithBucketArgument(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("exists", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	// validation errors are distinguished from missing objects.
+	result.Assert(t, icmd.Expected{ExitCode: 2})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains("remote source must be an object or contain a wildcard"),
+	})
+}
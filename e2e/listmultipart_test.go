@@ -0,0 +1,119 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+// createMultipartUpload initiates a multipart upload of given key and uploads
+// a part of given content, as if an upload is interrupted.
+func createMultipartUpload(t *testing.T, s3client *s3.S3, bucket, key, content string) string {
+	t.Helper()
+
+	upload, err := s3client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	assert.NilError(t, err)
+
+	_, err = s3client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(bucket),
+		Key:        aws.String(key),
+		UploadId:   upload.UploadId,
+		PartNumber: aws.Int64(1),
+		Body:       strings.NewReader(content),
+	})
+	assert.NilError(t, err)
+
+	return aws.StringValue(upload.UploadId)
+}
+
+// list-multipart s3://bucket/prefix/
+func TestListMultipartUploads(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	uploadID := createMultipartUpload(t, s3client, bucket, "prefix/large.iso", "this is the first part")
+	createMultipartUpload(t, s3client, bucket, "other/large.iso", "this is the first part")
+
+	cmd := s5cmd("list-multipart", "s3://"+bucket+"/prefix/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2} 22 ` + uploadID + ` s3://` + bucket + `/prefix/large.iso$`),
+	})
+}
+
+// --json list-multipart s3://bucket/
+func TestListMultipartUploadsJSON(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	uploadID := createMultipartUpload(t, s3client, bucket, "large.iso", "this is the first part")
+
+	cmd := s5cmd("--json", "list-multipart", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^{"key":"s3://` + bucket + `/large.iso","upload_id":"` + uploadID + `","initiated":"[^"]+","parts":1,"size":22}$`),
+	})
+}
+
+// list-multipart --older-than 1h s3://bucket/
+func TestListMultipartUploadsOlderThan(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	createMultipartUpload(t, s3client, bucket, "large.iso", "this is the first part")
+
+	cmd := s5cmd("list-multipart", "--older-than", "1h", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{})
+}
+
+// list-multipart dir/
+func TestListMultipartUploadsLocalSource(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("list-multipart", "dir/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "list-multipart dir/": source must be a remote bucket, prefix or wildcard`),
+	})
+}
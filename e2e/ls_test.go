@@ -1,9 +1,12 @@
 package e2e
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"gotest.tools/v3/fs"
 	"gotest.tools/v3/icmd"
 )
 
@@ -63,6 +66,28 @@ func TestListBucketsJSON(t *testing.T) {
 	}, jsonCheck(true))
 }
 
+// ls --json
+func TestListBucketsWithJSONRecords(t *testing.T) {
+	t.Parallel()
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	bucketPrefix := s3BucketFromTestName(t)
+	createBucket(t, s3client, bucketPrefix+"-1")
+	createBucket(t, s3client, bucketPrefix+"-2")
+
+	cmd := s5cmd("ls", "--json")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^{"name":"%v-1","creation_date":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z"}$`, bucketPrefix)),
+		1: match(fmt.Sprintf(`^{"name":"%v-2","creation_date":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z"}$`, bucketPrefix)),
+	}, jsonCheck(true))
+}
+
 // ls bucket/object
 func TestListSingleS3Object(t *testing.T) {
 	t.Parallel()
@@ -88,6 +113,66 @@ func TestListSingleS3Object(t *testing.T) {
 	})
 }
 
+// ls --time-format rfc3339 --utc bucket/object
+func TestListSingleS3ObjectWithTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "this is a file content")
+
+	cmd := s5cmd("ls", "--time-format", "rfc3339", "--utc", "s3://"+bucket+"/testfile1.txt")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z +317 testfile1.txt$`),
+	})
+}
+
+// ls --time-format layout --utc
+func TestListBucketsWithTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("ls", "--time-format", "2006-01-02 15:04 MST", "--utc")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^\d{4}-\d{2}-\d{2} \d{2}:\d{2} UTC +s3://` + bucket + `$`),
+	})
+}
+
+// ls --time-format invalid
+func TestListWithInvalidTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("ls", "--time-format", "invalid", "s3://bucket/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "ls s3://bucket/": invalid --time-format "invalid": must be a Go time layout, e.g. "2006/01/02 15:04:05", or rfc3339`),
+	})
+}
+
 // -json ls bucket/object
 func TestListSingleS3ObjectJSON(t *testing.T) {
 	t.Parallel()
@@ -139,6 +224,34 @@ func TestListSingleWildcardS3Object(t *testing.T) {
 	}, alignment(true))
 }
 
+// ls bucket/prefix/**/*.gz
+func TestListS3ObjectsWithDoubleStar(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "logs/a.gz", "content")
+	putFile(t, s3client, bucket, "logs/x/b.gz", "content")
+	putFile(t, s3client, bucket, "logs/x/y/c.gz", "content")
+	putFile(t, s3client, bucket, "logs/x/y/c.txt", "content")
+	putFile(t, s3client, bucket, "other/d.gz", "content")
+
+	cmd := s5cmd("ls", "s3://"+bucket+"/logs/**/*.gz")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("a.gz"),
+		1: suffix("x/b.gz"),
+		2: suffix("x/y/c.gz"),
+	}, alignment(true))
+}
+
 // ls -s bucket/object
 func TestListS3ObjectsWithDashS(t *testing.T) {
 	t.Parallel()
@@ -159,6 +272,200 @@ func TestListS3ObjectsWithDashS(t *testing.T) {
 	// TODO: test if full form of storage class is displayed (it can be done when and if gofakes3 supports storage classes)
 }
 
+// ls -R bucket
+func TestListS3ObjectsRecursive(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+	putFile(t, s3client, bucket, "a/testfile2.txt", "content")
+	putFile(t, s3client, bucket, "a/b/testfile3.txt", "content")
+
+	cmd := s5cmd("ls", "-R", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("a/b/testfile3.txt"),
+		1: suffix("a/testfile2.txt"),
+		2: suffix("testfile1.txt"),
+	}, alignment(true))
+}
+
+// --list-concurrency 4 ls bucket/*/data/*
+func TestListS3ObjectsWithListConcurrency(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+	putFile(t, s3client, bucket, "a/data/testfile2.txt", "content")
+	putFile(t, s3client, bucket, "a/meta/testfile3.txt", "content")
+	putFile(t, s3client, bucket, "b/data/testfile4.txt", "content")
+	putFile(t, s3client, bucket, "c/d/data/testfile5.txt", "content")
+
+	cmd := s5cmd("--list-concurrency", "4", "ls", "s3://"+bucket+"/*/data/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects are not listed in order.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix("a/data/testfile2.txt"),
+		1: suffix("b/data/testfile4.txt"),
+		2: suffix("c/d/data/testfile5.txt"),
+	}, sortInput(true))
+}
+
+// ls --recursive bucket/prefix/
+func TestListS3ObjectsRecursiveWithPrefix(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+	putFile(t, s3client, bucket, "a/testfile2.txt", "content")
+	putFile(t, s3client, bucket, "a/b/testfile3.txt", "content")
+	putFile(t, s3client, bucket, "ab/testfile4.txt", "content")
+
+	cmd := s5cmd("ls", "--recursive", "s3://"+bucket+"/a/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(" b/testfile3.txt"),
+		1: suffix(" testfile2.txt"),
+	}, alignment(true))
+}
+
+// ls -R --max-depth 1 bucket
+func TestListS3ObjectsRecursiveWithMaxDepth(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+	putFile(t, s3client, bucket, "a/testfile2.txt", "content")
+	putFile(t, s3client, bucket, "a/b/testfile3.txt", "content")
+
+	cmd := s5cmd("ls", "-R", "--max-depth", "1", "s3://"+bucket)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(" a/testfile2.txt"),
+		1: suffix(" testfile1.txt"),
+	}, alignment(true))
+}
+
+// ls --json bucket/
+func TestListS3ObjectsWithJSONRecords(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "a/testfile1.txt", "content")
+	putFile(t, s3client, bucket, "testfile2.txt", "content")
+
+	cmd := s5cmd("ls", "--json", "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`{"key":"a/","size":0,"last_modified":"","storage_class":"","etag":"","is_prefix":true}`),
+		1: match(`^{"key":"testfile2.txt","size":\d+,"last_modified":"\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}Z","storage_class":"\w*","etag":"[0-9a-f]{32}","is_prefix":false}$`),
+	}, jsonCheck(true))
+}
+
+// ls --progress bucket/*
+func TestListS3ObjectsWithProgress(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+
+	cmd := s5cmd("ls", "--progress", "s3://"+bucket+"/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(" testfile1.txt"),
+	})
+
+	// listing ends before the first progress message
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+}
+
+// --no-sign-request ls bucket
+func TestListS3ObjectsWithNoSignRequest(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+	putFile(t, s3client, bucket, "testfile1.txt", "content")
+
+	// no credentials are available
+	withoutCredentials := func(cmd icmd.Cmd) icmd.Cmd {
+		cmd.Env = append(
+			cmd.Env,
+			"AWS_ACCESS_KEY_ID=",
+			"AWS_SECRET_ACCESS_KEY=",
+			"AWS_SHARED_CREDENTIALS_FILE="+filepath.Join(cmd.Dir, "credentials"),
+			"AWS_CONFIG_FILE="+filepath.Join(cmd.Dir, "config"),
+		)
+		return cmd
+	}
+
+	cmd := withoutCredentials(s5cmd("ls", "s3://"+bucket))
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	cmd = withoutCredentials(s5cmd("--no-sign-request", "ls", "s3://"+bucket))
+	result = icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: suffix(" testfile1.txt"),
+	})
+}
+
 // ls bucket/*/object*.ext
 func TestListMultipleWildcardS3Object(t *testing.T) {
 	t.Parallel()
@@ -310,7 +617,7 @@ func TestListNonexistingS3ObjectInGivenPrefix(t *testing.T) {
 	cmd := s5cmd("ls", "s3://"+bucket+pattern)
 	result := icmd.RunCmd(cmd)
 
-	result.Assert(t, icmd.Expected{ExitCode: 1})
+	result.Assert(t, icmd.Expected{ExitCode: 3})
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{})
 
@@ -333,7 +640,7 @@ func TestListNonexistingS3Object(t *testing.T) {
 	cmd := s5cmd("ls", "s3://"+bucket+"/nosuchobject")
 	result := icmd.RunCmd(cmd)
 
-	result.Assert(t, icmd.Expected{ExitCode: 1})
+	result.Assert(t, icmd.Expected{ExitCode: 3})
 
 	assertLines(t, result.Stdout(), map[int]compareFunc{})
 
@@ -342,6 +649,27 @@ func TestListNonexistingS3Object(t *testing.T) {
 	}, strictLineCheck(false))
 }
 
+// --json ls bucket/object (nonexistent)
+func TestListNonexistingS3ObjectWithJSON(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("--json", "ls", "s3://"+bucket+"/nosuchobject")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 3})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`{"operation":"ls","command":"ls s3://%v/nosuchobject","error":"no object found","category":"NotFound"}`, bucket),
+	}, strictLineCheck(false))
+}
+
 // ls -e bucket
 func TestListS3ObjectsWithDashE(t *testing.T) {
 	t.Parallel()
@@ -391,3 +719,145 @@ func TestListS3ObjectsWithDashH(t *testing.T) {
 		1: match(`^ 264.0K testfile2.txt$`),
 	}, trimMatch(dateRe), alignment(true))
 }
+
+// ls s3://bucket/a/* s3://bucket/b/
+func TestListMultipleSources(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "a/file1.txt", "content")
+	putFile(t, s3client, bucket, "b/file2.txt", "content")
+	putFile(t, s3client, bucket, "b/nested/file3.txt", "content")
+	putFile(t, s3client, bucket, "c/file4.txt", "content")
+
+	cmd := s5cmd("ls", "s3://"+bucket+"/a/*", "s3://"+bucket+"/b/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects are printed with their full URLs if multiple sources are given.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(fmt.Sprintf(`^ \d+ s3://%v/a/file1.txt$`, bucket)),
+		1: match(fmt.Sprintf(`^ DIR s3://%v/b/nested/$`, bucket)),
+		2: match(fmt.Sprintf(`^ \d+ s3://%v/b/file2.txt$`, bucket)),
+	}, trimMatch(dateRe))
+}
+
+// ls --utc --from-inventory s3://bucket/inventory/manifest.json s3://bucket/a/
+func TestListS3ObjectsFromInventory(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	// objects are listed from the inventory report, they don't exist in the
+	// bucket.
+	putInventory(t, s3client, bucket, "a/file1.txt,100", "a/file2.txt,200", "a/nested/file3.txt,300", "b/file4.txt,400")
+
+	cmd := s5cmd("ls", "--utc", "--from-inventory", "s3://"+bucket+"/inventory/manifest.json", "s3://"+bucket+"/a/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: match(`^2020/01/02 03:04:05 +100 file1.txt$`),
+		1: match(`^2020/01/02 03:04:05 +200 file2.txt$`),
+		2: match(`^ +DIR nested/$`),
+	})
+}
+
+// ls --from-inventory s3://bucket/inventory/manifest.json
+func TestListBucketsFromInventory(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	cmd := s5cmd("ls", "--from-inventory", "s3://"+bucket+"/inventory/manifest.json")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "ls ": --from-inventory can not be used for listing buckets`),
+	})
+}
+
+// ls --empty-prefixes s3://bucket/
+func TestListS3EmptyPrefixesWithNonEmptyPrefixes(t *testing.T) {
+	t.Parallel()
+
+	// the test server strips the trailing '/' of the keys, so directory
+	// placeholders can't be created. Prefixes which have objects under them
+	// and the objects themselves are not listed.
+	testcases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "non-recursive",
+			args: []string{"ls", "--empty-prefixes"},
+		},
+		{
+			name: "recursive",
+			args: []string{"ls", "--empty-prefixes", "--recursive"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+
+			putFile(t, s3client, bucket, "a/file.txt", "content")
+			putFile(t, s3client, bucket, "b/c/file.txt", "content")
+			putFile(t, s3client, bucket, "file.txt", "content")
+
+			cmd := s5cmd(append(tc.args, "s3://"+bucket+"/")...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{})
+		})
+	}
+}
+
+// ls --empty-prefixes dir/
+func TestListLocalWithEmptyPrefixes(t *testing.T) {
+	t.Parallel()
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	workdir := fs.NewDir(t, t.Name())
+	defer workdir.Remove()
+
+	cmd := s5cmd("ls", "--empty-prefixes", workdir.Path()+"/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: contains(`--empty-prefixes can only be used with remote sources`),
+	})
+}
@@ -0,0 +1,114 @@
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+// --dry-run make-public s3://bucket/images/*.jpg
+func TestMakePublicDryRun(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "images/a.jpg", "content")
+	putFile(t, s3client, bucket, "images/b.jpg", "content")
+	putFile(t, s3client, bucket, "images/readme.md", "content")
+
+	cmd := s5cmd("--dry-run", "make-public", "s3://"+bucket+"/images/*.jpg")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`make-public s3://%v/images/a.jpg`, bucket),
+		1: equals(`make-public s3://%v/images/b.jpg`, bucket),
+		2: equals(`make-public set public-read acl of 2 objects, 0 failed`),
+	}, sortInput(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "images/a.jpg", "content"))
+}
+
+// --dry-run make-private --recursive s3://bucket/images/
+func TestMakePrivateRecursiveDryRun(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "images/a.jpg", "content")
+	putFile(t, s3client, bucket, "images/nested/b.jpg", "content")
+	putFile(t, s3client, bucket, "readme.md", "content")
+
+	cmd := s5cmd("--dry-run", "make-private", "--recursive", "s3://"+bucket+"/images/")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`make-private s3://%v/images/a.jpg`, bucket),
+		1: equals(`make-private s3://%v/images/nested/b.jpg`, bucket),
+		2: equals(`make-private set private acl of 2 objects, 0 failed`),
+	}, sortInput(true))
+}
+
+func TestMakePublicValidation(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no arguments",
+			args:     []string{"make-public"},
+			expected: `ERROR "make-public ": expected at least 1 object`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"make-public", "image.jpg"},
+			expected: `ERROR "make-public image.jpg": source must be a remote object`,
+		},
+		{
+			name:     "prefix without recursive",
+			args:     []string{"make-public", "s3://bucket/images/"},
+			expected: `ERROR "make-public s3://bucket/images/": "s3://bucket/images/" is a bucket or a prefix, use --recursive to change all the objects under it`,
+		},
+		{
+			name:     "all objects under prefix without recursive",
+			args:     []string{"make-private", "s3://bucket/images/*"},
+			expected: `ERROR "make-private s3://bucket/images/*": "s3://bucket/images/*" matches all the objects under its sub-prefixes, use --recursive to change them`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
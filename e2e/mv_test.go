@@ -3,8 +3,11 @@ package e2e
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/fs"
 	"gotest.tools/v3/icmd"
@@ -312,6 +315,79 @@ func TestMoveMultipleS3ObjectsToS3(t *testing.T) {
 	}
 }
 
+// mv --keep-storage-class s3://bucket/object s3://bucket/dst/
+func TestMoveSingleS3ObjectToS3WithKeepStorageClass(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is a file content"
+	)
+
+	_, err := s3client.PutObject(&s3.PutObjectInput{
+		Body:         strings.NewReader(content),
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(filename),
+		StorageClass: aws.String("STANDARD_IA"),
+	})
+	assert.NilError(t, err)
+
+	src := fmt.Sprintf("s3://%v/%v", bucket, filename)
+	dst := fmt.Sprintf("s3://%v/dst/", bucket)
+
+	cmd := s5cmd("-json", "mv", "--keep-storage-class", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: json(`
+			{
+				"operation": "mv",
+				"success": true,
+				"source": "%v",
+				"destination": "%v%v",
+				"object": {
+					"key": "%v%v",
+					"type": "file",
+					"storage_class": "STANDARD_IA"
+				}
+			}
+		`, src, dst, filename, dst, filename),
+	}, jsonCheck(true))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "dst/"+filename, content, ensureStorageClass("STANDARD_IA")))
+}
+
+// mv --keep-storage-class --storage-class STANDARD_IA s3://bucket/* s3://bucket/dst/
+func TestMoveWithKeepStorageClassAndStorageClass(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	src := fmt.Sprintf("s3://%v/*", bucket)
+	dst := fmt.Sprintf("s3://%v/dst/", bucket)
+
+	cmd := s5cmd("mv", "--keep-storage-class", "--storage-class", "STANDARD_IA", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "mv %v %v": --keep-storage-class can not be used with --storage-class`, src, dst),
+	})
+}
+
 // --dry-run mv s3://bucket/* s3://bucket2/prefix/
 func TestMoveMultipleS3ObjectsToS3DryRun(t *testing.T) {
 	t.Parallel()
@@ -360,3 +436,254 @@ func TestMoveMultipleS3ObjectsToS3DryRun(t *testing.T) {
 		assertError(t, err, errS3NoSuchKey)
 	}
 }
+
+// mv --verify-before-delete s3://bucket/object dir/
+func TestMoveSingleS3ObjectToLocalWithVerifyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is a file content"
+	)
+
+	putFile(t, s3client, bucket, filename, content)
+
+	cmd := s5cmd("mv", "--verify-before-delete", "s3://"+bucket+"/"+filename, ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`mv s3://%v/%v %v`, bucket, filename, filename),
+	})
+
+	expected := fs.Expected(t, fs.WithFile(filename, content, fs.WithMode(0644)))
+	assert.Assert(t, fs.Equal(cmd.Dir, expected))
+
+	err := ensureS3Object(s3client, bucket, filename, content)
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// mv --verify-before-delete file s3://bucket/
+func TestMoveSingleFileToS3WithVerifyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const (
+		filename = "testfile1.txt"
+		content  = "this is a file content"
+	)
+
+	workdir := fs.NewDir(t, bucket, fs.WithFile(filename, content))
+	defer workdir.Remove()
+
+	cmd := s5cmd("mv", "--verify-before-delete", filename, "s3://"+bucket+"/")
+	result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`mv %v s3://%v/%v`, filename, bucket, filename),
+	})
+
+	// the file is moved, the directory is empty.
+	expected := fs.Expected(t)
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+}
+
+// mv --verify-before-delete s3://bucket/* s3://bucket/dst/
+func TestMoveMultipleS3ObjectsToS3WithVerifyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t, withS3Backend("mem"))
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	filesToContent := map[string]string{
+		"testfile1.txt": "this is a test file 1",
+		"readme.md":     "this is a readme file",
+	}
+
+	for filename, content := range filesToContent {
+		putFile(t, s3client, bucket, filename, content)
+	}
+
+	src := fmt.Sprintf("s3://%v/*", bucket)
+	dst := fmt.Sprintf("s3://%v/dst/", bucket)
+
+	cmd := s5cmd("mv", "--verify-before-delete", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("mv s3://%v/readme.md %vreadme.md", bucket, dst),
+		1: equals("mv s3://%v/testfile1.txt %vtestfile1.txt", bucket, dst),
+	}, sortInput(true))
+
+	for filename, content := range filesToContent {
+		err := ensureS3Object(s3client, bucket, filename, content)
+		assertError(t, err, errS3NoSuchKey)
+
+		assert.Assert(t, ensureS3Object(s3client, bucket, "dst/"+filename, content))
+	}
+}
+
+// cp --verify-before-delete s3://bucket/object dir/
+func TestCopyWithVerifyBeforeDelete(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	src := fmt.Sprintf("s3://%v/object", bucket)
+
+	cmd := s5cmd("cp", "--verify-before-delete", src, ".")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp %v .": --verify-before-delete can only be used with mv`, src),
+	})
+}
+
+// mv --remove-empty-dirs dir/ s3://bucket/
+func TestMoveDirToS3WithRemoveEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	workdir := fs.NewDir(
+		t,
+		t.Name(),
+		fs.WithFile("file.txt", "root file"),
+		fs.WithDir("a", fs.WithDir("b", fs.WithFile("nested.txt", "nested file"))),
+		fs.WithDir("empty"),
+	)
+	defer workdir.Remove()
+
+	src := filepath.ToSlash(workdir.Path())
+	dst := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("mv", "--remove-empty-dirs", src+"/", dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`mv %v/a/b/nested.txt %va/b/nested.txt`, src, dst),
+		1: equals(`mv %v/file.txt %vfile.txt`, src, dst),
+	}, sortInput(true))
+
+	// the directories emptied by the move are removed, the one which was
+	// already empty is kept.
+	expected := fs.Expected(t, fs.WithDir("empty"))
+	assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "file.txt", "root file"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "a/b/nested.txt", "nested file"))
+}
+
+// cp --remove-empty-dirs dir/ s3://bucket/
+func TestCopyWithRemoveEmptyDirs(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	_, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	dst := fmt.Sprintf("s3://%v/", bucket)
+
+	cmd := s5cmd("cp", "--remove-empty-dirs", "dir/", dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "cp dir/ %v": --remove-empty-dirs can only be used with mv`, dst),
+	})
+}
+
+// mv * s3://bucket/prefix/
+func TestMoveDoesNotFollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name string
+		args []string
+	}{
+		{name: "default"},
+		{name: "no_follow_symlinks", args: []string{"--no-follow-symlinks"}},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+
+			const content = "CAFEBABE"
+			workdir := fs.NewDir(t, t.Name(),
+				fs.WithDir("a", fs.WithFile("f1.txt", content)),
+				fs.WithDir("b"),
+				fs.WithSymlink("b/link1", "a/f1.txt"),
+			)
+			defer workdir.Remove()
+
+			dst := fmt.Sprintf("s3://%v/prefix/", bucket)
+
+			args := append([]string{"mv"}, tc.args...)
+			args = append(args, "*", dst)
+			cmd := s5cmd(args...)
+			result := icmd.RunCmd(cmd, withWorkingDir(workdir))
+
+			result.Assert(t, icmd.Success)
+
+			assertLines(t, result.Stdout(), map[int]compareFunc{
+				0: equals("mv a/f1.txt %va/f1.txt", dst),
+			})
+
+			// the link is kept, it points to the moved file.
+			expected := fs.Expected(t,
+				fs.WithDir("a"),
+				fs.WithDir("b", fs.WithSymlink("link1", workdir.Join("a", "f1.txt"))),
+			)
+			assert.Assert(t, fs.Equal(workdir.Path(), expected))
+
+			assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/a/f1.txt", content))
+		})
+	}
+}
@@ -83,7 +83,7 @@ func TestRemoveSingleS3ObjectJSON(t *testing.T) {
 	assertError(t, err, errS3NoSuchKey)
 }
 
-// rm s3://bucket/*
+// rm -R s3://bucket/*
 func TestRemoveMultipleS3Objects(t *testing.T) {
 	t.Parallel()
 
@@ -105,7 +105,7 @@ func TestRemoveMultipleS3Objects(t *testing.T) {
 		putFile(t, s3client, bucket, filename, content)
 	}
 
-	cmd := s5cmd("rm", "s3://"+bucket+"/*")
+	cmd := s5cmd("rm", "-R", "s3://"+bucket+"/*")
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
@@ -126,7 +126,7 @@ func TestRemoveMultipleS3Objects(t *testing.T) {
 	}
 }
 
-// --json rm s3://bucket/*
+// --json rm -R s3://bucket/*
 func TestRemoveMultipleS3ObjectsJSON(t *testing.T) {
 	t.Parallel()
 
@@ -148,7 +148,7 @@ func TestRemoveMultipleS3ObjectsJSON(t *testing.T) {
 		putFile(t, s3client, bucket, filename, content)
 	}
 
-	cmd := s5cmd("-json", "rm", "s3://"+bucket+"/*")
+	cmd := s5cmd("-json", "rm", "-R", "s3://"+bucket+"/*")
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
@@ -221,7 +221,7 @@ func TestRemoveTenThousandS3Objects(t *testing.T) {
 		putFile(t, s3client, bucket, filename, content)
 	}
 
-	cmd := s5cmd("rm", "s3://"+bucket+"/*")
+	cmd := s5cmd("rm", "-R", "s3://"+bucket+"/*")
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
@@ -302,6 +302,108 @@ func TestRemoveS3Prefix(t *testing.T) {
 	})
 }
 
+// rm s3://bucket/prefix/*
+func TestRemoveAllObjectsUnderPrefixWithoutRecursive(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "prefix/file.txt", "content")
+	putFile(t, s3client, bucket, "prefix/nested/file.txt", "content")
+
+	src := fmt.Sprintf("s3://%v/prefix/*", bucket)
+
+	cmd := s5cmd("rm", src)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Expected{ExitCode: 1})
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{
+		0: equals(`ERROR "rm %v": %q matches the objects under its sub-prefixes too, use --recursive to remove them`, src, src),
+	})
+
+	// assert s3 objects are not removed
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/file.txt", "content"))
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/nested/file.txt", "content"))
+}
+
+// rm s3://bucket/p*
+// rm s3://bucket/*.gz
+func TestRemoveMatchingObjectsWithoutRecursive(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name    string
+		pattern string
+	}{
+		{name: "prefix wildcard", pattern: "p*"},
+		{name: "extension wildcard", pattern: "*.gz"},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			bucket := s3BucketFromTestName(t)
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+
+			putFile(t, s3client, bucket, "prefix/file.gz", "content")
+			putFile(t, s3client, bucket, "prefix/nested/file.gz", "content")
+
+			src := fmt.Sprintf("s3://%v/%v", bucket, tc.pattern)
+
+			cmd := s5cmd("rm", src)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(`ERROR "rm %v": %q matches the objects under its sub-prefixes too, use --recursive to remove them`, src, src),
+			})
+
+			// wildcards match the objects at any depth, they are kept.
+			assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/file.gz", "content"))
+			assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/nested/file.gz", "content"))
+		})
+	}
+}
+
+// rm -R s3://bucket/prefix/*.txt
+func TestRemoveMatchingObjectsWithRecursive(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "prefix/file.txt", "content")
+	putFile(t, s3client, bucket, "prefix/file.gz", "content")
+
+	cmd := s5cmd("rm", "-R", "s3://"+bucket+"/prefix/*.txt")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("rm s3://%v/prefix/file.txt", bucket),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "prefix/file.gz", "content"))
+}
+
 // rm file
 func TestRemoveSingleLocalFile(t *testing.T) {
 	t.Parallel()
@@ -501,7 +603,7 @@ func TestVariadicRemoveS3Objects(t *testing.T) {
 	}
 }
 
-// rm s3://bucket/prefix/* s3://bucket/object
+// rm -R s3://bucket/prefix/* s3://bucket/object
 func TestVariadicRemoveS3ObjectsWithWildcard(t *testing.T) {
 	t.Parallel()
 
@@ -526,6 +628,7 @@ func TestVariadicRemoveS3ObjectsWithWildcard(t *testing.T) {
 
 	cmd := s5cmd(
 		"rm",
+		"-R",
 		"s3://"+bucket+"/testdir1/*",
 		"s3://"+bucket+"/testdir2/*",
 		"s3://"+bucket+"/file4.txt",
@@ -593,7 +696,7 @@ func TestRemoveMultipleMixedObjects(t *testing.T) {
 	assert.Assert(t, ensureS3Object(s3client, bucket, objectname, content))
 }
 
-// --dry-run rm s3://bucket/*
+// --dry-run rm -R s3://bucket/*
 func TestRemoveMultipleS3ObjectsDryRun(t *testing.T) {
 	t.Parallel()
 
@@ -615,7 +718,7 @@ func TestRemoveMultipleS3ObjectsDryRun(t *testing.T) {
 		putFile(t, s3client, bucket, filename, content)
 	}
 
-	cmd := s5cmd("--dry-run", "rm", "s3://"+bucket+"/*")
+	cmd := s5cmd("--dry-run", "rm", "-R", "s3://"+bucket+"/*")
 	result := icmd.RunCmd(cmd)
 
 	result.Assert(t, icmd.Success)
@@ -634,3 +737,40 @@ func TestRemoveMultipleS3ObjectsDryRun(t *testing.T) {
 		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
 	}
 }
+
+// rm --estimate s3://bucket/*
+func TestRemoveMultipleS3ObjectsEstimate(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	filesToContent := map[string]string{
+		"testfile1.txt": "this is a test file 1",
+		"a/readme.md":   "this is a readme file",
+	}
+
+	for filename, content := range filesToContent {
+		putFile(t, s3client, bucket, filename, content)
+	}
+
+	cmd := s5cmd("rm", "--estimate", "s3://"+bucket+"/*")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stderr(), map[int]compareFunc{})
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`rm would affect 2 objects totaling 630 bytes`),
+	})
+
+	// assert s3 objects were not removed
+	for filename, content := range filesToContent {
+		assert.Assert(t, ensureS3Object(s3client, bucket, filename, content))
+	}
+}
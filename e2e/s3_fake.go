@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -11,7 +12,10 @@ import (
 	"gotest.tools/v3/fs"
 )
 
-func s3ServerEndpoint(t *testing.T, testdir *fs.Dir, loglvl, backend string) (string, func()) {
+// s3ServerEndpoint starts a fake S3 server and returns its URL. The requests
+// are passed through the given middleware, if any, to simulate failures of
+// the server.
+func s3ServerEndpoint(t *testing.T, testdir *fs.Dir, loglvl, backend string, middleware func(http.Handler) http.Handler) (string, func()) {
 	var s3backend gofakes3.Backend
 	switch backend {
 	case "mem":
@@ -34,7 +38,12 @@ func s3ServerEndpoint(t *testing.T, testdir *fs.Dir, loglvl, backend string) (st
 	)
 
 	faker := gofakes3.New(s3backend, withLogger)
-	s3srv := httptest.NewServer(faker.Server())
+
+	handler := faker.Server()
+	if middleware != nil {
+		handler = middleware(handler)
+	}
+	s3srv := httptest.NewServer(handler)
 
 	cleanup := func() {
 		s3srv.Close()
@@ -0,0 +1,139 @@
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+// set-meta --cache-control max-age=60 s3://bucket/*.html
+func TestSetMetaMultipleS3Objects(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	const content = "<html></html>"
+
+	for _, key := range []string{"index.html", "a/about.html"} {
+		_, err := s3client.PutObject(&s3.PutObjectInput{
+			Body:         strings.NewReader(content),
+			Bucket:       aws.String(bucket),
+			Key:          aws.String(key),
+			StorageClass: aws.String("STANDARD_IA"),
+			Metadata:     map[string]*string{"Owner": aws.String("s5cmd")},
+		})
+		assert.NilError(t, err)
+	}
+	putFile(t, s3client, bucket, "readme.md", "this is a readme file")
+
+	cmd := s5cmd("set-meta", "--cache-control", "max-age=60", "s3://"+bucket+"/*.html")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`set-meta s3://%v/a/about.html`, bucket),
+		1: equals(`set-meta s3://%v/index.html`, bucket),
+	}, sortInput(true))
+
+	// assert data, storage class and user-defined metadata are preserved
+	for _, key := range []string{"index.html", "a/about.html"} {
+		assert.Assert(t, ensureS3Object(s3client, bucket, key, content, ensureStorageClass("STANDARD_IA")))
+
+		output, err := s3client.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		assert.NilError(t, err)
+		assert.Equal(t, "s5cmd", aws.StringValue(output.Metadata["Owner"]))
+	}
+
+	assert.Assert(t, ensureS3Object(s3client, bucket, "readme.md", "this is a readme file"))
+}
+
+// set-meta --if-differ --cache-control max-age=60 s3://bucket/*.html
+func TestSetMetaIfDiffer(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	putFile(t, s3client, bucket, "index.html", "<html></html>")
+	putFile(t, s3client, bucket, "about.html", "<html></html>")
+
+	cmd := s5cmd("set-meta", "--if-differ", "--cache-control", "max-age=60", "s3://"+bucket+"/*.html")
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	// objects are printed in the order they're updated, followed by the
+	// number of updated and skipped objects.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`set-meta s3://%v/about.html`, bucket),
+		1: equals(`set-meta s3://%v/index.html`, bucket),
+		2: equals(`set-meta updated 2 objects, skipped 0 objects which are up to date`),
+	}, sortInput(true))
+}
+
+func TestSetMetaValidation(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "no metadata given",
+			args:     []string{"s3://bucket/object"},
+			expected: `ERROR "set-meta s3://bucket/object": at least one of --content-type or --cache-control must be given`,
+		},
+		{
+			name:     "local source",
+			args:     []string{"--content-type", "text/html", "index.html"},
+			expected: `ERROR "set-meta index.html": source must be a remote object`,
+		},
+		{
+			name:     "prefix source",
+			args:     []string{"--content-type", "text/html", "s3://bucket/prefix/"},
+			expected: `ERROR "set-meta s3://bucket/prefix/": source argument must contain wildcard character`,
+		},
+		{
+			name:     "if differ with acl",
+			args:     []string{"--if-differ", "--acl", "public-read", "--content-type", "text/html", "s3://bucket/*.html"},
+			expected: `ERROR "set-meta s3://bucket/*.html": --if-differ can not be used with --acl`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"set-meta"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package e2e
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+// sync s3://bucket/ s3://target-bucket/prefix/
+func TestSyncS3PrefixToS3Prefix(t *testing.T) {
+	t.Parallel()
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+
+	// listing ETags are MD5 digests of the content only with the in-memory
+	// backend.
+	s3client, s5cmd, cleanup := setup(t, withS3Backend("mem"))
+	defer cleanup()
+
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, s3client, srcbucket, "new.txt", "new object")
+	putFile(t, s3client, srcbucket, "changed.txt", "changed content")
+	putFile(t, s3client, srcbucket, "same.txt", "same content")
+	putFile(t, s3client, srcbucket, "a/nested.txt", "nested object")
+
+	putFile(t, s3client, dstbucket, "prefix/changed.txt", "old content")
+	putFile(t, s3client, dstbucket, "prefix/same.txt", "same content")
+	putFile(t, s3client, dstbucket, "prefix/stale.txt", "stale object")
+
+	src := "s3://" + srcbucket + "/"
+	dst := "s3://" + dstbucket + "/prefix/"
+
+	cmd := s5cmd("sync", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`sync %va/nested.txt %va/nested.txt`, src, dst),
+		1: equals(`sync %vchanged.txt %vchanged.txt`, src, dst),
+		2: equals(`sync %vnew.txt %vnew.txt`, src, dst),
+	}, sortInput(true))
+
+	// assert s3
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "prefix/new.txt", "new object"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "prefix/changed.txt", "changed content"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "prefix/same.txt", "same content"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "prefix/a/nested.txt", "nested object"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "prefix/stale.txt", "stale object"))
+}
+
+// sync --delete s3://bucket/ s3://target-bucket/
+func TestSyncS3BucketToS3BucketWithDelete(t *testing.T) {
+	t.Parallel()
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+
+	// listing ETags are MD5 digests of the content only with the in-memory
+	// backend.
+	s3client, s5cmd, cleanup := setup(t, withS3Backend("mem"))
+	defer cleanup()
+
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, s3client, srcbucket, "new.txt", "new object")
+	putFile(t, s3client, srcbucket, "same.txt", "same content")
+
+	putFile(t, s3client, dstbucket, "same.txt", "same content")
+	putFile(t, s3client, dstbucket, "stale.txt", "stale object")
+
+	src := "s3://" + srcbucket + "/"
+	dst := "s3://" + dstbucket + "/"
+
+	cmd := s5cmd("sync", "--delete", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`sync %vnew.txt %vnew.txt`, src, dst),
+		1: equals(`rm %vstale.txt`, dst),
+	})
+
+	// assert s3
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "new.txt", "new object"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "same.txt", "same content"))
+
+	err := ensureS3Object(s3client, dstbucket, "stale.txt", "stale object")
+	assertError(t, err, errS3NoSuchKey)
+}
+
+// sync --force s3://bucket/ s3://target-bucket/
+func TestSyncS3BucketToS3BucketWithForce(t *testing.T) {
+	t.Parallel()
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+
+	s3client, s5cmd, cleanup := setup(t, withS3Backend("mem"))
+	defer cleanup()
+
+	createBucket(t, s3client, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, s3client, srcbucket, "same.txt", "same content")
+	putFile(t, s3client, dstbucket, "same.txt", "same content")
+
+	src := "s3://" + srcbucket + "/"
+	dst := "s3://" + dstbucket + "/"
+
+	cmd := s5cmd("sync", "--force", src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`sync %vsame.txt %vsame.txt`, src, dst),
+	})
+}
+
+func TestSyncValidation(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name:     "local source",
+			args:     []string{"dir/", "s3://bucket/"},
+			expected: `ERROR "sync dir/ s3://bucket/": "dir/" must be a remote bucket or prefix`,
+		},
+		{
+			name:     "wildcard source",
+			args:     []string{"s3://bucket/*", "s3://target-bucket/"},
+			expected: `ERROR "sync s3://bucket/* s3://target-bucket/": "s3://bucket/*" can not contain glob characters`,
+		},
+		{
+			name:     "object destination",
+			args:     []string{"s3://bucket/", "s3://target-bucket/object"},
+			expected: `ERROR "sync s3://bucket/ s3://target-bucket/object": "s3://target-bucket/object" must be a bucket or a prefix`,
+		},
+		{
+			name:     "negative modify window",
+			args:     []string{"--modify-window", "-1s", "s3://bucket/", "s3://target-bucket/"},
+			expected: `ERROR "sync s3://bucket/ s3://target-bucket/": --modify-window can not be negative`,
+		},
+		{
+			name:     "unknown priority",
+			args:     []string{"--priority", "urgent", "s3://bucket/", "s3://target-bucket/"},
+			expected: `ERROR "sync s3://bucket/ s3://target-bucket/": --priority must be one of high, normal or low`,
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			cmd := s5cmd(append([]string{"sync"}, tc.args...)...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: 1})
+
+			assertLines(t, result.Stderr(), map[int]compareFunc{
+				0: equals(tc.expected),
+			})
+		})
+	}
+}
+
+// sync --source-endpoint <endpoint> s3://bucket/ s3://target-bucket/
+func TestSyncS3BucketToS3BucketWithSourceEndpoint(t *testing.T) {
+	t.Parallel()
+
+	srcbucket := s3BucketFromTestName(t)
+	dstbucket := "copy-" + srcbucket
+
+	srcclient, _, srccleanup := setup(t, withS3Backend("mem"))
+	defer srccleanup()
+
+	s3client, s5cmd, cleanup := setup(t, withS3Backend("mem"))
+	defer cleanup()
+
+	createBucket(t, srcclient, srcbucket)
+	createBucket(t, s3client, dstbucket)
+
+	putFile(t, srcclient, srcbucket, "new.txt", "new object")
+	putFile(t, srcclient, srcbucket, "same.txt", "same content")
+	putFile(t, s3client, dstbucket, "same.txt", "same content")
+
+	src := "s3://" + srcbucket + "/"
+	dst := "s3://" + dstbucket + "/"
+
+	cmd := s5cmd("sync", "--source-endpoint", srcclient.Endpoint, src, dst)
+	result := icmd.RunCmd(cmd)
+
+	result.Assert(t, icmd.Success)
+
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals(`sync %vnew.txt %vnew.txt`, src, dst),
+	})
+
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "new.txt", "new object"))
+	assert.Assert(t, ensureS3Object(s3client, dstbucket, "same.txt", "same content"))
+}
@@ -4,6 +4,7 @@ package e2e
 
 import (
 	"bytes"
+	"compress/gzip"
 	jsonpkg "encoding/json"
 	"errors"
 	"flag"
@@ -11,6 +12,7 @@ import (
 	"io"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -52,7 +54,8 @@ func init() {
 }
 
 type setupOpts struct {
-	s3backend string
+	s3backend  string
+	middleware func(http.Handler) http.Handler
 }
 
 type option func(*setupOpts)
@@ -63,6 +66,14 @@ func withS3Backend(backend string) option {
 	}
 }
 
+// withMiddleware passes the requests of s5cmd through the given middleware
+// before the fake S3 server, to simulate failures of the server.
+func withMiddleware(middleware func(http.Handler) http.Handler) option {
+	return func(opts *setupOpts) {
+		opts.middleware = middleware
+	}
+}
+
 func setup(t *testing.T, options ...option) (*s3.S3, func(...string) icmd.Cmd, func()) {
 	t.Helper()
 
@@ -98,7 +109,7 @@ func setup(t *testing.T, options ...option) (*s3.S3, func(...string) icmd.Cmd, f
 		awsLogLevel = aws.LogDebug
 	}
 
-	endpoint, dbcleanup := s3ServerEndpoint(t, testdir, s3LogLevel, opts.s3backend)
+	endpoint, dbcleanup := s3ServerEndpoint(t, testdir, s3LogLevel, opts.s3backend, opts.middleware)
 
 	s3Config := aws.NewConfig().
 		WithEndpoint(endpoint).
@@ -283,6 +294,33 @@ func putFile(t *testing.T, client *s3.S3, bucket string, filename string, conten
 	}
 }
 
+// putInventory uploads an S3 Inventory report of the given bucket, whose
+// gzipped CSV data file has Bucket, Key, Size and LastModifiedDate columns.
+// Each row is given as "key,size".
+func putInventory(t *testing.T, client *s3.S3, bucket string, rows ...string) {
+	t.Helper()
+
+	var data bytes.Buffer
+	gw := gzip.NewWriter(&data)
+	for _, row := range rows {
+		fields := strings.SplitN(row, ",", 2)
+		fmt.Fprintf(gw, "%q,%q,%q,%q\n", bucket, fields[0], fields[1], "2020-01-02T03:04:05.000Z")
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	putFile(t, client, bucket, "inventory/data/report.csv.gz", data.String())
+
+	manifest := fmt.Sprintf(`{
+  "sourceBucket": %q,
+  "destinationBucket": "arn:aws:s3:::%v",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, Size, LastModifiedDate",
+  "files": [{"key": "inventory/data/report.csv.gz"}]
+}`, bucket, bucket)
+	putFile(t, client, bucket, "inventory/manifest.json", manifest)
+}
+
 func replaceMatchWithSpace(input string, match ...string) string {
 	for _, m := range match {
 		if m == "" {
@@ -297,7 +335,9 @@ func replaceMatchWithSpace(input string, match ...string) string {
 
 func s3BucketFromTestName(t *testing.T) string {
 	t.Helper()
-	bucket := strcase.ToKebab(t.Name())
+	// names of the subtests are separated from their parents with a slash,
+	// which is not allowed in bucket names.
+	bucket := strcase.ToKebab(strings.ReplaceAll(t.Name(), "/", "-"))
 
 	if len(bucket) > 63 {
 		bucket = fmt.Sprintf("%v-%v", bucket[:55], randomString(7))
@@ -1,6 +1,7 @@
 package e2e
 
 import (
+	"runtime"
 	"testing"
 
 	"gotest.tools/v3/icmd"
@@ -18,4 +19,13 @@ func TestVersion(t *testing.T) {
 	// make sure that -version flag works as expected:
 	// https://github.com/peak/s5cmd/issues/70#issuecomment-592218542
 	result.Assert(t, icmd.Success)
+
+	// version information is not set with ldflags in tests.
+	assertLines(t, result.Stdout(), map[int]compareFunc{
+		0: equals("version: v0.0.0"),
+		1: equals("git commit: dev"),
+		2: equals("build date: unknown"),
+		3: equals("go version: %v", runtime.Version()),
+		4: prefix("aws-sdk-go: v1."),
+	}, alignment(true))
 }
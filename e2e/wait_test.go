@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/icmd"
+)
+
+func TestWait(t *testing.T) {
+	t.Parallel()
+
+	const bucket = "bucket"
+
+	testcases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		expected map[int]compareFunc
+	}{
+		{
+			name:     "existing object",
+			args:     []string{"wait", "s3://bucket/dir/file.txt"},
+			exitCode: 0,
+		},
+		{
+			name:     "missing object",
+			args:     []string{"wait", "--timeout", "1s", "--interval", "100ms", "s3://bucket/dir/missing.txt"},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains("timed out after 1s waiting for s3://bucket/dir/missing.txt to exist"),
+			},
+		},
+		{
+			name:     "matching wildcard",
+			args:     []string{"wait", "s3://bucket/dir/*.txt"},
+			exitCode: 0,
+		},
+		{
+			name:     "missing object until not exists",
+			args:     []string{"wait", "--until", "not-exists", "s3://bucket/dir/missing.txt"},
+			exitCode: 0,
+		},
+		{
+			name:     "existing object until not exists",
+			args:     []string{"wait", "--until", "not-exists", "--timeout", "1s", "--interval", "100ms", "s3://bucket/dir/file.txt"},
+			exitCode: 1,
+			expected: map[int]compareFunc{
+				0: contains("timed out after 1s waiting for s3://bucket/dir/file.txt to not exist"),
+			},
+		},
+		{
+			name:     "invalid condition",
+			args:     []string{"wait", "--until", "deleted", "s3://bucket/dir/file.txt"},
+			exitCode: 2,
+			expected: map[int]compareFunc{
+				0: contains("--until must be one of exists, not-exists"),
+			},
+		},
+		{
+			name:     "invalid interval",
+			args:     []string{"wait", "--interval", "0s", "s3://bucket/dir/file.txt"},
+			exitCode: 2,
+			expected: map[int]compareFunc{
+				0: contains("--interval must be positive"),
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			s3client, s5cmd, cleanup := setup(t)
+			defer cleanup()
+
+			createBucket(t, s3client, bucket)
+			putFile(t, s3client, bucket, "dir/file.txt", "content")
+
+			cmd := s5cmd(tc.args...)
+			result := icmd.RunCmd(cmd)
+
+			result.Assert(t, icmd.Expected{ExitCode: tc.exitCode})
+
+			if tc.expected == nil {
+				assert.Equal(t, result.Stdout(), "")
+				assert.Equal(t, result.Stderr(), "")
+				return
+			}
+
+			assertLines(t, result.Stderr(), tc.expected)
+		})
+	}
+}
+
+func TestWaitUntilObjectIsUploaded(t *testing.T) {
+	t.Parallel()
+
+	bucket := s3BucketFromTestName(t)
+
+	s3client, s5cmd, cleanup := setup(t)
+	defer cleanup()
+
+	createBucket(t, s3client, bucket)
+
+	cmd := s5cmd("wait", "--timeout", "10s", "--interval", "100ms", "s3://"+bucket+"/_SUCCESS")
+	result := icmd.StartCmd(cmd)
+
+	time.Sleep(500 * time.Millisecond)
+	putFile(t, s3client, bucket, "_SUCCESS", "done")
+
+	result = icmd.WaitOnCmd(10*time.Second, result)
+	result.Assert(t, icmd.Success)
+}
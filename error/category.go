@@ -0,0 +1,198 @@
+package error
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+// Category is the kind of an error, which lets the callers react to the
+// errors without parsing their messages.
+type Category string
+
+const (
+	// CategoryUnknown is the category of the errors which are not classified.
+	CategoryUnknown Category = ""
+	// CategoryNotFound indicates the bucket, object or file doesn't exist.
+	CategoryNotFound Category = "NotFound"
+	// CategoryAccessDenied indicates the credentials are not allowed to
+	// perform the operation, or they are invalid.
+	CategoryAccessDenied Category = "AccessDenied"
+	// CategoryThrottled indicates the request is rejected because of the
+	// request rate, and it can be retried later.
+	CategoryThrottled Category = "Throttled"
+	// CategoryNetworkTimeout indicates the request timed out or couldn't be
+	// sent because of a network failure.
+	CategoryNetworkTimeout Category = "NetworkTimeout"
+	// CategoryInvalidArgument indicates the request is malformed or has
+	// invalid parameters.
+	CategoryInvalidArgument Category = "InvalidArgument"
+	// CategoryConflict indicates the request conflicts with the current state
+	// of the resource, e.g. a failed precondition or a non-empty bucket.
+	CategoryConflict Category = "Conflict"
+	// CategoryInterrupted indicates the command is interrupted before
+	// processing all of its objects.
+	CategoryInterrupted Category = "Interrupted"
+)
+
+// categoryExitCodes are the exit statuses of the program for each category.
+// 1 is used for unknown errors, and 2 is reserved for the commands which
+// report their results with the exit status.
+var categoryExitCodes = map[Category]int{
+	CategoryNotFound:        3,
+	CategoryAccessDenied:    4,
+	CategoryThrottled:       5,
+	CategoryNetworkTimeout:  6,
+	CategoryInvalidArgument: 7,
+	CategoryConflict:        8,
+	CategoryInterrupted:     130,
+}
+
+// ExitCode returns the exit status for the category, or 1 if the category is
+// unknown.
+func (c Category) ExitCode() int {
+	if code, ok := categoryExitCodes[c]; ok {
+		return code
+	}
+	return 1
+}
+
+// errorCodeCategories maps the error codes of S3 API to categories.
+var errorCodeCategories = map[string]Category{
+	"NoSuchKey":    CategoryNotFound,
+	"NoSuchBucket": CategoryNotFound,
+	"NoSuchUpload": CategoryNotFound,
+	"NotFound":     CategoryNotFound,
+
+	"AccessDenied":          CategoryAccessDenied,
+	"Forbidden":             CategoryAccessDenied,
+	"AccountProblem":        CategoryAccessDenied,
+	"AllAccessDisabled":     CategoryAccessDenied,
+	"InvalidAccessKeyId":    CategoryAccessDenied,
+	"SignatureDoesNotMatch": CategoryAccessDenied,
+	"ExpiredToken":          CategoryAccessDenied,
+	"InvalidToken":          CategoryAccessDenied,
+
+	"SlowDown":             CategoryThrottled,
+	"Throttling":           CategoryThrottled,
+	"ThrottlingException":  CategoryThrottled,
+	"RequestLimitExceeded": CategoryThrottled,
+	"TooManyRequests":      CategoryThrottled,
+	"ServiceUnavailable":   CategoryThrottled,
+
+	"RequestTimeout":               CategoryNetworkTimeout,
+	request.ErrCodeRequestError:    CategoryNetworkTimeout,
+	request.ErrCodeResponseTimeout: CategoryNetworkTimeout,
+	request.ErrCodeRead:            CategoryNetworkTimeout,
+
+	"RequestTimeTooSkewed": CategoryInvalidArgument,
+	"InvalidArgument":      CategoryInvalidArgument,
+	"InvalidRequest":       CategoryInvalidArgument,
+	"InvalidBucketName":    CategoryInvalidArgument,
+	"InvalidStorageClass":  CategoryInvalidArgument,
+	"InvalidPart":          CategoryInvalidArgument,
+	"InvalidDigest":        CategoryInvalidArgument,
+	"BadDigest":            CategoryInvalidArgument,
+	"KeyTooLongError":      CategoryInvalidArgument,
+	"EntityTooLarge":       CategoryInvalidArgument,
+	"EntityTooSmall":       CategoryInvalidArgument,
+	"MalformedXML":         CategoryInvalidArgument,
+
+	"BucketAlreadyExists":            CategoryConflict,
+	"BucketAlreadyOwnedByYou":        CategoryConflict,
+	"BucketNotEmpty":                 CategoryConflict,
+	"OperationAborted":               CategoryConflict,
+	"PreconditionFailed":             CategoryConflict,
+	"InvalidObjectState":             CategoryConflict,
+	"ObjectLockConfigurationMissing": CategoryConflict,
+}
+
+// statusCodeCategories maps the HTTP status codes of the failed requests to
+// categories, for the error codes which are not known.
+var statusCodeCategories = map[int]Category{
+	http.StatusNotFound:              CategoryNotFound,
+	http.StatusForbidden:             CategoryAccessDenied,
+	http.StatusUnauthorized:          CategoryAccessDenied,
+	http.StatusTooManyRequests:       CategoryThrottled,
+	http.StatusServiceUnavailable:    CategoryThrottled,
+	http.StatusRequestTimeout:        CategoryNetworkTimeout,
+	http.StatusGatewayTimeout:        CategoryNetworkTimeout,
+	http.StatusBadRequest:            CategoryInvalidArgument,
+	http.StatusConflict:              CategoryConflict,
+	http.StatusPreconditionFailed:    CategoryConflict,
+	http.StatusRequestEntityTooLarge: CategoryInvalidArgument,
+}
+
+// Classify returns the category of the given error. Errors are classified by
+// the error codes of S3 API, the HTTP status codes of the failed requests and
+// the well-known errors of the storage and the filesystem. Aggregated errors
+// are classified only if all of them are in the same category.
+func Classify(err error) Category {
+	if err == nil {
+		return CategoryUnknown
+	}
+
+	if merr, ok := err.(*multierror.Error); ok {
+		category := CategoryUnknown
+		for i, err := range merr.Errors {
+			c := Classify(err)
+			if i > 0 && c != category {
+				return CategoryUnknown
+			}
+			category = c
+		}
+		return category
+	}
+
+	switch {
+	case errors.Is(err, ErrInterrupted):
+		return CategoryInterrupted
+	case errors.Is(err, storage.ErrGivenObjectNotFound),
+		errors.Is(err, storage.ErrNoObjectFound),
+		errors.Is(err, os.ErrNotExist):
+		return CategoryNotFound
+	case errors.Is(err, os.ErrPermission):
+		return CategoryAccessDenied
+	case errors.Is(err, storage.ErrPreconditionFailed),
+		errors.Is(err, ErrObjectExists),
+		errors.Is(err, ErrObjectIsNewer),
+		errors.Is(err, ErrObjectSizesMatch),
+		errors.Is(err, ErrObjectTooLarge):
+		return CategoryConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return CategoryNetworkTimeout
+	}
+
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) {
+		if c, ok := errorCodeCategories[reqErr.Code()]; ok {
+			return c
+		}
+		return statusCodeCategories[reqErr.StatusCode()]
+	}
+
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if c, ok := errorCodeCategories[awsErr.Code()]; ok {
+			return c
+		}
+		// errors of multipart uploads and failed connections wrap the
+		// original error.
+		return Classify(awsErr.OrigErr())
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return CategoryNetworkTimeout
+	}
+
+	return CategoryUnknown
+}
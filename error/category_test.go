@@ -0,0 +1,161 @@
+package error
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/peak/s5cmd/storage"
+)
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+
+	requestFailure := func(code string, status int) error {
+		return awserr.NewRequestFailure(awserr.New(code, "message", nil), status, "request-id")
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want Category
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: CategoryUnknown,
+		},
+		{
+			name: "unknown",
+			err:  fmt.Errorf("something went wrong"),
+			want: CategoryUnknown,
+		},
+		{
+			name: "no_such_key",
+			err:  requestFailure("NoSuchKey", http.StatusNotFound),
+			want: CategoryNotFound,
+		},
+		{
+			name: "access_denied",
+			err:  requestFailure("AccessDenied", http.StatusForbidden),
+			want: CategoryAccessDenied,
+		},
+		{
+			name: "access_denied_key_of_batch_delete",
+			err:  &Error{Op: "rm", Err: awserr.New("AccessDenied", "Access Denied", nil)},
+			want: CategoryAccessDenied,
+		},
+		{
+			name: "slow_down",
+			err:  requestFailure("SlowDown", http.StatusServiceUnavailable),
+			want: CategoryThrottled,
+		},
+		{
+			name: "unknown_code_with_known_status",
+			err:  requestFailure("SomethingElse", http.StatusConflict),
+			want: CategoryConflict,
+		},
+		{
+			name: "request_error",
+			err:  awserr.New(request.ErrCodeRequestError, "send request failed", fmt.Errorf("connection reset")),
+			want: CategoryNetworkTimeout,
+		},
+		{
+			name: "wrapped_original_error",
+			err:  awserr.New("MultipartUpload", "upload failed", requestFailure("InvalidArgument", http.StatusBadRequest)),
+			want: CategoryInvalidArgument,
+		},
+		{
+			name: "wrapped_in_error",
+			err:  &Error{Op: "cp", Err: requestFailure("NoSuchBucket", http.StatusNotFound)},
+			want: CategoryNotFound,
+		},
+		{
+			name: "given_object_not_found",
+			err:  storage.ErrGivenObjectNotFound,
+			want: CategoryNotFound,
+		},
+		{
+			name: "precondition_failed",
+			err:  storage.ErrPreconditionFailed,
+			want: CategoryConflict,
+		},
+		{
+			name: "object_exists",
+			err:  &Error{Op: "cp", Err: ErrObjectExists},
+			want: CategoryConflict,
+		},
+		{
+			name: "object_too_large",
+			err:  &Error{Op: "cp", Err: ErrObjectTooLarge},
+			want: CategoryConflict,
+		},
+		{
+			name: "missing_file",
+			err:  &os.PathError{Op: "open", Path: "file.txt", Err: os.ErrNotExist},
+			want: CategoryNotFound,
+		},
+		{
+			name: "deadline_exceeded",
+			err:  context.DeadlineExceeded,
+			want: CategoryNetworkTimeout,
+		},
+		{
+			name: "interrupted",
+			err:  multierror.Append(nil, ErrInterrupted),
+			want: CategoryInterrupted,
+		},
+		{
+			name: "aggregated_same_category",
+			err: multierror.Append(
+				requestFailure("NoSuchKey", http.StatusNotFound),
+				storage.ErrGivenObjectNotFound,
+			),
+			want: CategoryNotFound,
+		},
+		{
+			name: "aggregated_different_categories",
+			err: multierror.Append(
+				requestFailure("NoSuchKey", http.StatusNotFound),
+				requestFailure("AccessDenied", http.StatusForbidden),
+			),
+			want: CategoryUnknown,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Classify(tc.err); got != tc.want {
+				t.Errorf("got = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCategoryExitCode(t *testing.T) {
+	t.Parallel()
+
+	if got := CategoryUnknown.ExitCode(); got != 1 {
+		t.Errorf("exit code of unknown category = %v, want 1", got)
+	}
+
+	seen := map[int]Category{}
+	for category := range categoryExitCodes {
+		code := category.ExitCode()
+		if code <= 2 {
+			t.Errorf("exit code of %q = %v, must not collide with 0, 1 and 2", category, code)
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("exit code %v is used for both %q and %q", code, category, other)
+		}
+		seen[code] = category
+	}
+}
@@ -76,13 +76,21 @@ var (
 
 	// ErrObjectSizesMatch indicates the sizes of objects match.
 	ErrObjectSizesMatch = fmt.Errorf("object size matches")
+
+	// ErrObjectTooLarge indicates the object is larger than the maximum
+	// object size.
+	ErrObjectTooLarge = fmt.Errorf("object is larger than --max-object-size")
+
+	// ErrInterrupted indicates the command is stopped by an interrupt before
+	// processing all of its objects.
+	ErrInterrupted = fmt.Errorf("interrupted, remaining objects are not processed")
 )
 
 // IsWarning checks if given error is either ErrObjectExists,
-// ErrObjectIsNewer or ErrObjectSizesMatch.
+// ErrObjectIsNewer, ErrObjectSizesMatch or ErrObjectTooLarge.
 func IsWarning(err error) bool {
 	switch err {
-	case ErrObjectExists, ErrObjectIsNewer, ErrObjectSizesMatch:
+	case ErrObjectExists, ErrObjectIsNewer, ErrObjectSizesMatch, ErrObjectTooLarge:
 		return true
 	}
 
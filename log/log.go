@@ -32,6 +32,17 @@ func Info(msg Message) {
 	global.printf(levelInfo, msg, os.Stdout)
 }
 
+// InfoToStderr prints message in info mode to standard error, when the
+// standard output is used for data.
+func InfoToStderr(msg Message) {
+	global.printf(levelInfo, msg, os.Stderr)
+}
+
+// Warning prints message in warning mode.
+func Warning(msg Message) {
+	global.printf(levelWarning, msg, os.Stderr)
+}
+
 // Error prints message in error mode.
 func Error(msg Message) {
 	global.printf(levelError, msg, os.Stderr)
@@ -96,6 +107,7 @@ type logLevel int
 const (
 	levelDebug logLevel = iota
 	levelInfo
+	levelWarning
 	levelError
 )
 
@@ -104,6 +116,8 @@ func (l logLevel) String() string {
 	switch l {
 	case levelInfo:
 		return ""
+	case levelWarning:
+		return "WARNING "
 	case levelError:
 		return "ERROR "
 	case levelDebug:
@@ -121,6 +135,8 @@ func levelFromString(s string) logLevel {
 		return levelDebug
 	case "info":
 		return levelInfo
+	case "warning":
+		return levelWarning
 	case "error":
 		return levelError
 	default:
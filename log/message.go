@@ -21,10 +21,23 @@ type InfoMessage struct {
 	Source      *url.URL        `json:"source"`
 	Destination *url.URL        `json:"destination,omitempty"`
 	Object      *storage.Object `json:"object,omitempty"`
+
+	// URL is the URL of the uploaded object, which is only set if asked for.
+	URL string `json:"url,omitempty"`
+
+	// Retries is the number of times the object is transferred again since
+	// it didn't match the source.
+	Retries int `json:"retries,omitempty"`
 }
 
 // String is the string representation of InfoMessage.
 func (i InfoMessage) String() string {
+	if i.URL != "" {
+		return fmt.Sprintf("%v %v %v %v", i.Operation, i.Source, i.Destination, i.URL)
+	}
+	if i.Destination != nil && i.Retries > 0 {
+		return fmt.Sprintf("%v %v %v (%d retries)", i.Operation, i.Source, i.Destination, i.Retries)
+	}
 	if i.Destination != nil {
 		return fmt.Sprintf("%v %v %v", i.Operation, i.Source, i.Destination)
 	}
@@ -42,6 +55,9 @@ type ErrorMessage struct {
 	Operation string `json:"operation,omitempty"`
 	Command   string `json:"command,omitempty"`
 	Err       string `json:"error"`
+	// Category is the kind of the error, i.e. NotFound or AccessDenied. It's
+	// only included in JSON output.
+	Category string `json:"category,omitempty"`
 }
 
 // String is the string representation of ErrorMessage.
@@ -57,6 +73,27 @@ func (e ErrorMessage) JSON() string {
 	return strutil.JSON(e)
 }
 
+// WarningMessage is a failure which doesn't fail the operation, e.g. an
+// object is uploaded but its URL can't be printed.
+type WarningMessage struct {
+	Operation string `json:"operation,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Warning   string `json:"warning"`
+}
+
+// String is the string representation of WarningMessage.
+func (w WarningMessage) String() string {
+	if w.Command == "" {
+		return fmt.Sprint(w.Warning)
+	}
+	return fmt.Sprintf("%q: %v", w.Command, w.Warning)
+}
+
+// JSON is the JSON representation of WarningMessage.
+func (w WarningMessage) JSON() string {
+	return strutil.JSON(w)
+}
+
 // DebugMessage is a generic message structure for unsuccessful operations.
 type DebugMessage struct {
 	Operation string `json:"operation,omitempty"`
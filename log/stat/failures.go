@@ -0,0 +1,85 @@
+package stat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/peak/s5cmd/strutil"
+)
+
+// failures holds the last error of each failed object.
+var failures struct {
+	sync.Mutex
+	enabled bool
+	errs    map[string]string
+}
+
+// InitFailures initializes collecting the errors of the failed objects.
+func InitFailures() {
+	failures.Lock()
+	defer failures.Unlock()
+
+	failures.enabled = true
+	failures.errs = map[string]string{}
+}
+
+// AddFailure records the error of the given object. Only the last error of
+// an object is kept, e.g. if it's retried by a following command.
+func AddFailure(object, err string) {
+	failures.Lock()
+	defer failures.Unlock()
+
+	if !failures.enabled {
+		return
+	}
+	failures.errs[object] = err
+}
+
+// Failure is the last error of a failed object.
+type Failure struct {
+	Object string `json:"object"`
+	Error  string `json:"error"`
+}
+
+// FailureSummary implements log.Message interface.
+type FailureSummary []Failure
+
+func (s FailureSummary) String() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%d objects failed:", len(s))
+	for _, f := range s {
+		fmt.Fprintf(&builder, "\n\t%s: %s", f.Object, f.Error)
+	}
+	return builder.String()
+}
+
+func (s FailureSummary) JSON() string {
+	var builder strings.Builder
+
+	for i, f := range s {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString(strutil.JSON(f))
+	}
+	return builder.String()
+}
+
+// Failures returns the failures collected so far, sorted by the objects.
+func Failures() FailureSummary {
+	failures.Lock()
+	defer failures.Unlock()
+
+	var result FailureSummary
+	for object, err := range failures.errs {
+		result = append(result, Failure{Object: object, Error: err})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Object < result[j].Object
+	})
+	return result
+}
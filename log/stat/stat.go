@@ -31,6 +31,7 @@ func InitStat() {
 			mapStrInt64: map[string]int64{},
 		}
 	}
+	initTransfers()
 }
 
 // syncMapStrInt64 is a statically typed and synchronized map.
@@ -0,0 +1,115 @@
+package stat
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/peak/s5cmd/strutil"
+)
+
+const megabyte = 1024 * 1024
+
+// transfers holds the number of transferred objects and bytes.
+var transfers struct {
+	sync.Mutex
+	start      time.Time
+	objects    int64
+	uploaded   int64
+	downloaded int64
+	copied     int64
+}
+
+// initTransfers starts measuring the elapsed time of the transfers.
+func initTransfers() {
+	transfers.Lock()
+	defer transfers.Unlock()
+
+	transfers.start = time.Now()
+	transfers.objects = 0
+	transfers.uploaded = 0
+	transfers.downloaded = 0
+	transfers.copied = 0
+}
+
+// AddUpload records an uploaded object of given size.
+func AddUpload(size int64) {
+	addTransfer(&transfers.uploaded, size)
+}
+
+// AddDownload records a downloaded object of given size.
+func AddDownload(size int64) {
+	addTransfer(&transfers.downloaded, size)
+}
+
+// AddCopy records an object of given size copied on the server side.
+func AddCopy(size int64) {
+	addTransfer(&transfers.copied, size)
+}
+
+func addTransfer(counter *int64, size int64) {
+	if !enabled {
+		return
+	}
+
+	transfers.Lock()
+	defer transfers.Unlock()
+
+	transfers.objects++
+	*counter += size
+}
+
+// TransferStats implements log.Message interface. Throughput is calculated
+// from the uploaded and the downloaded bytes, since copied objects are not
+// transferred over the network.
+type TransferStats struct {
+	Objects            int64   `json:"objects"`
+	UploadedBytes      int64   `json:"uploaded_bytes"`
+	DownloadedBytes    int64   `json:"downloaded_bytes"`
+	CopiedBytes        int64   `json:"copied_bytes"`
+	ElapsedSeconds     float64 `json:"elapsed_seconds"`
+	ObjectsPerSecond   float64 `json:"objects_per_second"`
+	MegabytesPerSecond float64 `json:"megabytes_per_second"`
+}
+
+func (t TransferStats) String() string {
+	return fmt.Sprintf(
+		"Transferred %d objects (%s uploaded, %s downloaded, %s copied) in %.1fs, %.1f objects/s, %.2f MB/s",
+		t.Objects,
+		strutil.HumanizeBytes(t.UploadedBytes),
+		strutil.HumanizeBytes(t.DownloadedBytes),
+		strutil.HumanizeBytes(t.CopiedBytes),
+		t.ElapsedSeconds,
+		t.ObjectsPerSecond,
+		t.MegabytesPerSecond,
+	)
+}
+
+func (t TransferStats) JSON() string {
+	return strutil.JSON(t)
+}
+
+// Transfers returns the transfers recorded so far, with the throughput since
+// the statistics are started to be collected.
+func Transfers() TransferStats {
+	if !enabled {
+		return TransferStats{}
+	}
+
+	transfers.Lock()
+	defer transfers.Unlock()
+
+	t := TransferStats{
+		Objects:         transfers.objects,
+		UploadedBytes:   transfers.uploaded,
+		DownloadedBytes: transfers.downloaded,
+		CopiedBytes:     transfers.copied,
+		ElapsedSeconds:  time.Since(transfers.start).Seconds(),
+	}
+
+	if t.ElapsedSeconds > 0 {
+		t.ObjectsPerSecond = float64(t.Objects) / t.ElapsedSeconds
+		t.MegabytesPerSecond = float64(t.UploadedBytes+t.DownloadedBytes) / megabyte / t.ElapsedSeconds
+	}
+	return t
+}
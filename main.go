@@ -2,25 +2,36 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/peak/s5cmd/command"
+	"github.com/peak/s5cmd/parallel"
 )
 
+const drainMessage = "Interrupted, waiting for in-flight operations to finish. Press Ctrl-C again to force quit."
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	go func() {
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
-		<-ch
+		defer signal.Stop(ch)
+
+		// first interrupt stops dispatching new operations and lets the
+		// in-flight ones complete. Second interrupt, or SIGTERM, aborts them.
+		if sig := <-ch; sig == os.Interrupt {
+			parallel.Interrupt()
+			fmt.Fprintln(os.Stderr, drainMessage)
+			<-ch
+		}
 		cancel()
-		signal.Stop(ch)
 	}()
 
 	if err := command.Main(ctx, os.Args); err != nil {
-		os.Exit(1)
+		os.Exit(command.ExitCode(err))
 	}
 }
@@ -1,19 +1,68 @@
 package parallel
 
-import "github.com/peak/s5cmd/parallel/fdlimit"
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/peak/s5cmd/parallel/fdlimit"
+)
 
 var global *Manager
 
+var (
+	drainCh   = make(chan struct{})
+	drainOnce sync.Once
+
+	// interrupted is set to 1 if draining is started by an interrupt signal
+	// rather than by an error.
+	interrupted int32
+)
+
 // Init tries to increase the soft limit of open files and
-// creates new global ParallelManager.
-func Init(workercount int) {
+// creates new global ParallelManager. maxInflight limits the number of tasks
+// which are running or waiting for a worker, it's not limited if it's 0.
+func Init(workercount, maxInflight int) {
 	_ = fdlimit.Raise()
 	global = New(workercount)
+	global.SetMaxInflight(maxInflight)
 }
 
-// Close waits all jobs to finish and
-// closes the semaphore of global ParallelManager.
+// Close waits all jobs of global ParallelManager to finish.
 func Close() { global.Close() }
 
 // Run runs global ParallelManager.
 func Run(task Task, waiter *Waiter) { global.Run(task, waiter) }
+
+// RunWithPriority runs global ParallelManager with the given priority.
+func RunWithPriority(task Task, waiter *Waiter, priority Priority) {
+	global.RunWithPriority(task, waiter, priority)
+}
+
+// Drain stops all managers from running new tasks. Tasks which are already
+// running are not affected and can be waited as usual.
+func Drain() { drainOnce.Do(func() { close(drainCh) }) }
+
+// Interrupt drains the managers on an interrupt signal. Unlike Drain, it lets
+// the commands report that their remaining objects are not processed.
+func Interrupt() {
+	atomic.StoreInt32(&interrupted, 1)
+	Drain()
+}
+
+// IsInterrupted reports whether Interrupt is called.
+func IsInterrupted() bool { return atomic.LoadInt32(&interrupted) == 1 }
+
+// IsDraining reports whether Drain is called. Callers dispatching tasks in a
+// loop should stop producing new ones once it returns true.
+func IsDraining() bool {
+	select {
+	case <-drainCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// Draining returns a channel which is closed when Drain is called. It allows
+// the callers which are waiting for an event to stop waiting on interrupt.
+func Draining() <-chan struct{} { return drainCh }
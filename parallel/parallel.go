@@ -1,8 +1,10 @@
 package parallel
 
 import (
+	"fmt"
 	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -12,10 +14,50 @@ const (
 // Task is a function type for parallel manager.
 type Task func() error
 
+// Priority is the scheduling class of a task. When all workers are busy, the
+// waiting tasks of a higher class are run first. Tasks of the same class are
+// run in the order they are given.
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+
+	numPriorities = int(PriorityHigh) + 1
+)
+
+// ParsePriority returns the priority of the given name, which is one of high,
+// normal or low.
+func ParsePriority(s string) (Priority, error) {
+	switch s {
+	case "high":
+		return PriorityHigh, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "low":
+		return PriorityLow, nil
+	default:
+		return PriorityNormal, fmt.Errorf("unknown priority %q, must be one of high, normal or low", s)
+	}
+}
+
 // Manager is a structure for running tasks in parallel.
 type Manager struct {
-	wg        *sync.WaitGroup
-	semaphore chan bool
+	wg          *sync.WaitGroup
+	workercount int
+	draining    <-chan struct{}
+
+	// running is the number of acquired workers. waiting are the tasks
+	// which wait for a worker, by their priorities. A released worker is
+	// handed over to the first waiting task of the highest priority.
+	mu      sync.Mutex
+	running int
+	waiting [numPriorities][]chan struct{}
+
+	// inflight limits the number of tasks which are running or waiting for
+	// a worker. It's nil if the number is not limited.
+	inflight chan struct{}
 }
 
 // New creates a new parallel.Manager.
@@ -29,29 +71,125 @@ func New(workercount int) *Manager {
 	}
 
 	return &Manager{
-		wg:        &sync.WaitGroup{},
-		semaphore: make(chan bool, workercount),
+		wg:          &sync.WaitGroup{},
+		workercount: workercount,
+		draining:    drainCh,
 	}
 }
 
-// acquire limits concurrency by trying to acquire the semaphore.
-func (p *Manager) acquire() {
-	p.semaphore <- true
-	p.wg.Add(1)
+// SetMaxInflight limits the number of tasks which are running or waiting for
+// a worker to n. Once the limit is reached, Run blocks until a task is
+// finished, even if there are idle workers. The number is not limited if n is
+// 0.
+func (p *Manager) SetMaxInflight(n int) {
+	if n <= 0 {
+		p.inflight = nil
+		return
+	}
+	p.inflight = make(chan struct{}, n)
 }
 
-// release releases the acquired semaphore to signal that a task is finished.
+// acquireInflight waits until the number of inflight tasks is below the
+// limit. It returns false if the manager starts draining in the meantime.
+func (p *Manager) acquireInflight() bool {
+	if p.inflight == nil {
+		return true
+	}
+
+	select {
+	case p.inflight <- struct{}{}:
+		return true
+	case <-p.draining:
+		return false
+	}
+}
+
+// releaseInflight signals that an inflight task is finished.
+func (p *Manager) releaseInflight() {
+	if p.inflight != nil {
+		<-p.inflight
+	}
+}
+
+// acquire limits concurrency by waiting for a worker. Tasks wait in the queue
+// of their priority if all workers are busy.
+func (p *Manager) acquire(priority Priority) {
+	p.mu.Lock()
+	if p.running < p.workercount {
+		p.running++
+		p.wg.Add(1)
+		p.mu.Unlock()
+		return
+	}
+
+	ready := make(chan struct{})
+	p.waiting[priority] = append(p.waiting[priority], ready)
+	p.mu.Unlock()
+
+	// the worker is handed over by release.
+	<-ready
+}
+
+// release hands the worker over to the next waiting task, or frees it if no
+// task is waiting, to signal that a task is finished.
 func (p *Manager) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for priority := numPriorities - 1; priority >= 0; priority-- {
+		queue := p.waiting[priority]
+		if len(queue) == 0 {
+			continue
+		}
+
+		p.waiting[priority] = queue[1:]
+		// the next task is counted before this one is done, so that Close
+		// doesn't return in between.
+		p.wg.Add(1)
+		p.wg.Done()
+		close(queue[0])
+		return
+	}
+
+	p.running--
 	p.wg.Done()
-	<-p.semaphore
 }
 
-// Run runs the given task while limiting the concurrency.
+// Run runs the given task with normal priority while limiting the
+// concurrency. It blocks until a worker is available, and until the number of
+// inflight tasks is below the limit set by SetMaxInflight. This applies
+// backpressure to the caller: object listings are streamed through unbuffered
+// channels, so listing pauses until the tasks are finished.
+//
+// The task is not run if the manager is draining, which is reported by the
+// waiter.
 func (p *Manager) Run(fn Task, waiter *Waiter) {
+	p.RunWithPriority(fn, waiter, PriorityNormal)
+}
+
+// RunWithPriority runs the given task like Run does. If all workers are busy,
+// it's run before the waiting tasks of lower priorities.
+func (p *Manager) RunWithPriority(fn Task, waiter *Waiter, priority Priority) {
+	if p.isDraining() || !p.acquireInflight() {
+		waiter.skip()
+		return
+	}
+
 	waiter.wg.Add(1)
-	p.acquire()
+	p.acquire(priority)
+
+	// draining might have started while waiting for a worker.
+	if p.isDraining() {
+		p.release()
+		p.releaseInflight()
+		waiter.skip()
+		waiter.wg.Done()
+		return
+	}
+
 	go func() {
 		defer waiter.wg.Done()
+		defer p.releaseInflight()
 		defer p.release()
 
 		if err := fn(); err != nil {
@@ -60,10 +198,19 @@ func (p *Manager) Run(fn Task, waiter *Waiter) {
 	}()
 }
 
+// isDraining reports whether the manager stopped accepting new tasks.
+func (p *Manager) isDraining() bool {
+	select {
+	case <-p.draining:
+		return true
+	default:
+		return false
+	}
+}
+
 // Close waits all tasks to finish.
 func (p *Manager) Close() {
 	p.wg.Wait()
-	close(p.semaphore)
 }
 
 // Waiter is a structure for waiting and reading
@@ -71,6 +218,9 @@ func (p *Manager) Close() {
 type Waiter struct {
 	wg    sync.WaitGroup
 	errch chan error
+
+	// skipped is set if any task is not run because the manager is draining.
+	skipped int32
 }
 
 // NewWaiter creates a new parallel.Waiter.
@@ -87,6 +237,17 @@ func (w *Waiter) Wait() {
 	close(w.errch)
 }
 
+// skip records that a task is not run.
+func (w *Waiter) skip() {
+	atomic.StoreInt32(&w.skipped, 1)
+}
+
+// Interrupted reports whether any of the tasks is not run because the
+// manager is draining.
+func (w *Waiter) Interrupted() bool {
+	return atomic.LoadInt32(&w.skipped) == 1
+}
+
 // Err returns read-only error channel.
 func (w *Waiter) Err() <-chan error {
 	return w.errch
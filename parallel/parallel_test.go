@@ -0,0 +1,291 @@
+package parallel
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunBlocksWhenAllWorkersAreBusy(t *testing.T) {
+	const workercount = 2
+
+	manager := New(workercount)
+	waiter := NewWaiter()
+
+	done := make(chan struct{})
+	task := func() error {
+		<-done
+		return nil
+	}
+
+	for i := 0; i < workercount; i++ {
+		manager.Run(task, waiter)
+	}
+
+	queued := make(chan struct{})
+	go func() {
+		manager.Run(task, waiter)
+		close(queued)
+	}()
+
+	select {
+	case <-queued:
+		t.Fatal("expected Run to block until a worker is available")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(done)
+
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return after workers are released")
+	}
+
+	waiter.Wait()
+	manager.Close()
+}
+
+func TestRunBlocksWhenMaxInflightIsReached(t *testing.T) {
+	const maxInflight = 2
+
+	// workers are idle, only the inflight tasks are limited.
+	manager := New(4)
+	manager.SetMaxInflight(maxInflight)
+	waiter := NewWaiter()
+
+	done := make(chan struct{})
+	task := func() error {
+		<-done
+		return nil
+	}
+
+	for i := 0; i < maxInflight; i++ {
+		manager.Run(task, waiter)
+	}
+
+	queued := make(chan struct{})
+	go func() {
+		manager.Run(task, waiter)
+		close(queued)
+	}()
+
+	select {
+	case <-queued:
+		t.Fatal("expected Run to block until an inflight task is finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(done)
+
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return after inflight tasks are finished")
+	}
+
+	waiter.Wait()
+	manager.Close()
+}
+
+func TestRunStopsWaitingForInflightTasksWhileDraining(t *testing.T) {
+	draining := make(chan struct{})
+
+	manager := New(2)
+	manager.draining = draining
+	manager.SetMaxInflight(1)
+	waiter := NewWaiter()
+
+	go func() {
+		for range waiter.Err() {
+		}
+	}()
+
+	done := make(chan struct{})
+	manager.Run(func() error {
+		<-done
+		return nil
+	}, waiter)
+
+	var skippedRan bool
+	queued := make(chan struct{})
+	go func() {
+		manager.Run(func() error {
+			skippedRan = true
+			return nil
+		}, waiter)
+		close(queued)
+	}()
+
+	close(draining)
+
+	select {
+	case <-queued:
+	case <-time.After(time.Second):
+		t.Fatal("expected Run to return once draining starts")
+	}
+
+	close(done)
+	waiter.Wait()
+	manager.Close()
+
+	if skippedRan {
+		t.Fatal("expected task not to run while draining")
+	}
+	if !waiter.Interrupted() {
+		t.Fatal("expected waiter to report the skipped task")
+	}
+}
+
+func TestRunSkipsTasksWhileDraining(t *testing.T) {
+	draining := make(chan struct{})
+
+	manager := New(2)
+	manager.draining = draining
+	waiter := NewWaiter()
+
+	go func() {
+		for range waiter.Err() {
+		}
+	}()
+
+	done := make(chan struct{})
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	manager.Run(func() error {
+		close(started)
+		<-done
+		close(finished)
+		return nil
+	}, waiter)
+
+	<-started
+	if waiter.Interrupted() {
+		t.Fatal("expected waiter not to be interrupted before draining")
+	}
+	close(draining)
+
+	var skippedRan bool
+	manager.Run(func() error {
+		skippedRan = true
+		return nil
+	}, waiter)
+
+	// in-flight task is not affected by draining
+	close(done)
+	waiter.Wait()
+	manager.Close()
+
+	select {
+	case <-finished:
+	default:
+		t.Fatal("expected in-flight task to finish")
+	}
+
+	if skippedRan {
+		t.Fatal("expected task not to run while draining")
+	}
+	if !waiter.Interrupted() {
+		t.Fatal("expected waiter to report the skipped task")
+	}
+}
+
+func TestReleaseHandsOverToHigherPrioritiesFirst(t *testing.T) {
+	const workercount = 2
+
+	manager := New(workercount)
+	for i := 0; i < workercount; i++ {
+		manager.acquire(PriorityNormal)
+	}
+
+	// all workers are busy, the tasks wait for the workers to be released.
+	type task struct {
+		name  string
+		ready chan struct{}
+	}
+	var (
+		mu    sync.Mutex
+		order []string
+		tasks []task
+	)
+	queue := func(name string, priority Priority) {
+		ready := make(chan struct{})
+		manager.mu.Lock()
+		manager.waiting[priority] = append(manager.waiting[priority], ready)
+		manager.mu.Unlock()
+		tasks = append(tasks, task{name: name, ready: ready})
+	}
+
+	queue("low", PriorityLow)
+	queue("normal-1", PriorityNormal)
+	queue("high-1", PriorityHigh)
+	queue("normal-2", PriorityNormal)
+	queue("high-2", PriorityHigh)
+
+	var wg sync.WaitGroup
+	for _, tk := range tasks {
+		wg.Add(1)
+		go func(tk task) {
+			defer wg.Done()
+			<-tk.ready
+			mu.Lock()
+			order = append(order, tk.name)
+			mu.Unlock()
+		}(tk)
+	}
+
+	// each release hands the worker over to a single waiting task.
+	for i := range tasks {
+		manager.release()
+
+		deadline := time.Now().Add(time.Second)
+		for {
+			mu.Lock()
+			n := len(order)
+			mu.Unlock()
+			if n == i+1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %d tasks to get a worker, got %d", i+1, n)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wg.Wait()
+
+	// the handed over workers and the busy ones are released.
+	for i := 0; i < workercount; i++ {
+		manager.release()
+	}
+	manager.Close()
+
+	expected := []string{"high-1", "high-2", "normal-1", "normal-2", "low"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected tasks to get workers in %v order, got %v", expected, order)
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expected Priority
+		wantErr  bool
+	}{
+		{name: "high", expected: PriorityHigh},
+		{name: "normal", expected: PriorityNormal},
+		{name: "low", expected: PriorityLow},
+		{name: "urgent", expected: PriorityNormal, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		got, err := ParsePriority(tc.name)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%v: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.expected {
+			t.Fatalf("%v: expected %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
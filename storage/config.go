@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// Config is the configuration of the S3 clients created with the given
+// options, resolved from the options, the environment variables and the
+// shared config files. It doesn't contain any secrets.
+type Config struct {
+	Region        string
+	RegionSource  string
+	Endpoint      string
+	Profile       string
+	ProfileSource string
+	SharedConfig  bool
+	PathStyle     bool
+	Accelerate    bool
+	MaxRetries    int
+
+	// Credentials is the name of the provider the credentials are retrieved
+	// from, e.g. EnvConfigCredentials. CredentialsErr is set if no provider
+	// has the credentials.
+	Credentials    string
+	CredentialsErr error
+}
+
+// ResolveConfig creates a session with the given options as the commands do,
+// and returns its configuration. If a bucket url is given, the region of the
+// bucket is detected as the clients of the commands do, which requires a
+// GetBucketLocation request. No other S3 requests are made, but retrieving
+// the credentials may send requests to the credential providers, e.g. the
+// EC2 instance metadata service.
+func ResolveConfig(ctx context.Context, bucket *url.URL, opts Options) (*Config, error) {
+	sess, err := cachedSession(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var detectedFrom string
+	if region := detectRegion(ctx, bucket, opts, sess); region != "" {
+		opts.Region = region
+		sess, err = cachedSession(opts)
+		if err != nil {
+			return nil, err
+		}
+		detectedFrom = fmt.Sprintf("location of bucket %q", bucket.Bucket)
+	}
+
+	sharedConfig := sharedConfigState() == session.SharedConfigEnable
+
+	cfg := &Config{
+		Region:       aws.StringValue(sess.Config.Region),
+		Endpoint:     s3.New(sess).Endpoint,
+		SharedConfig: sharedConfig,
+		PathStyle:    aws.BoolValue(sess.Config.S3ForcePathStyle),
+		Accelerate:   aws.BoolValue(sess.Config.S3UseAccelerate),
+		MaxRetries:   opts.MaxRetries,
+	}
+
+	cfg.Profile, cfg.ProfileSource = resolveProfile(opts, sharedConfig)
+
+	if detectedFrom != "" {
+		cfg.RegionSource = detectedFrom
+	} else {
+		cfg.RegionSource, err = regionSource(opts, sharedConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.NoSignRequest {
+		cfg.Credentials = "anonymous"
+		return cfg, nil
+	}
+
+	creds, err := sess.Config.Credentials.Get()
+	if err != nil {
+		cfg.CredentialsErr = err
+		return cfg, nil
+	}
+	cfg.Credentials = creds.ProviderName
+
+	return cfg, nil
+}
+
+// resolveProfile returns the name of the profile which the shared config
+// files are read with, and where it's given. The option takes precedence over
+// the environment variables, as it does in the SDK.
+func resolveProfile(opts Options, sharedConfig bool) (string, string) {
+	if opts.Profile != "" {
+		return opts.Profile, "flag"
+	}
+
+	keys := []string{"AWS_PROFILE"}
+	if sharedConfig {
+		keys = append(keys, "AWS_DEFAULT_PROFILE")
+	}
+	for _, key := range keys {
+		if profile := os.Getenv(key); profile != "" {
+			return profile, key + " environment variable"
+		}
+	}
+
+	return session.DefaultSharedConfigProfile, "default"
+}
+
+// regionSource returns where the region of the session is given. The option
+// takes precedence over the environment variables, which take precedence over
+// the shared config files. us-east-1 is used if none of them has a region.
+func regionSource(opts Options, sharedConfig bool) (string, error) {
+	if opts.Region != "" {
+		return "flag", nil
+	}
+
+	keys := []string{"AWS_REGION"}
+	if sharedConfig {
+		keys = append(keys, "AWS_DEFAULT_REGION")
+	}
+	for _, key := range keys {
+		if os.Getenv(key) != "" {
+			return key + " environment variable", nil
+		}
+	}
+
+	// a session without the options only has the region of the shared
+	// config files.
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Profile:           opts.Profile,
+		SharedConfigState: sharedConfigState(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if aws.StringValue(sess.Config.Region) != "" {
+		profile, _ := resolveProfile(opts, sharedConfig)
+		return fmt.Sprintf("profile %q", profile), nil
+	}
+	return "default", nil
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+func TestResolveConfig(t *testing.T) {
+	os.Setenv("AWS_REGION", "us-west-2")
+	defer os.Unsetenv("AWS_REGION")
+	os.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	// the region of the bucket is cached as if it's looked up before.
+	done := make(chan struct{})
+	close(done)
+	bucketRegions.Lock()
+	bucketRegions.lookups[regionKey{
+		endpoint: "https://s3.us-west-2.amazonaws.com",
+		bucket:   "resolve-config-bucket",
+	}] = &regionLookup{done: done, region: "eu-central-1"}
+	bucketRegions.Unlock()
+
+	testcases := []struct {
+		name     string
+		bucket   string
+		opts     Options
+		expected Config
+	}{
+		{
+			name: "environment",
+			opts: Options{Endpoint: "http://127.0.0.1:9100", MaxRetries: 3},
+			expected: Config{
+				Region:        "us-west-2",
+				RegionSource:  "AWS_REGION environment variable",
+				Endpoint:      "http://127.0.0.1:9100",
+				Profile:       "default",
+				ProfileSource: "default",
+				SharedConfig:  true,
+				PathStyle:     true,
+				MaxRetries:    3,
+				Credentials:   "EnvConfigCredentials",
+			},
+		},
+		{
+			name: "region flag",
+			opts: Options{Region: "eu-west-1"},
+			expected: Config{
+				Region:        "eu-west-1",
+				RegionSource:  "flag",
+				Endpoint:      "https://s3.eu-west-1.amazonaws.com",
+				Profile:       "default",
+				ProfileSource: "default",
+				SharedConfig:  true,
+				Credentials:   "EnvConfigCredentials",
+			},
+		},
+		{
+			name: "no sign request",
+			opts: Options{Endpoint: "http://127.0.0.1:9101", NoSignRequest: true},
+			expected: Config{
+				Region:        "us-west-2",
+				RegionSource:  "AWS_REGION environment variable",
+				Endpoint:      "http://127.0.0.1:9101",
+				Profile:       "default",
+				ProfileSource: "default",
+				SharedConfig:  true,
+				PathStyle:     true,
+				Credentials:   "anonymous",
+			},
+		},
+		{
+			name:   "detected region",
+			bucket: "s3://resolve-config-bucket",
+			expected: Config{
+				Region:        "eu-central-1",
+				RegionSource:  `location of bucket "resolve-config-bucket"`,
+				Endpoint:      "https://s3.eu-central-1.amazonaws.com",
+				Profile:       "default",
+				ProfileSource: "default",
+				SharedConfig:  true,
+				Credentials:   "EnvConfigCredentials",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var bucket *url.URL
+			if tc.bucket != "" {
+				u, err := url.New(tc.bucket)
+				if err != nil {
+					t.Fatal(err)
+				}
+				bucket = u
+			}
+
+			cfg, err := ResolveConfig(context.Background(), bucket, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *cfg != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, *cfg)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/karrick/godirwalk"
 	"github.com/termie/go-shutil"
@@ -66,6 +68,10 @@ func (f *Filesystem) listSingleObject(ctx context.Context, src *url.URL) <-chan
 }
 
 func (f *Filesystem) expandGlob(ctx context.Context, src *url.URL, followSymlinks bool) <-chan *Object {
+	if hasDoubleStar(src.Absolute()) {
+		return f.expandDoubleStarGlob(ctx, src, followSymlinks)
+	}
+
 	ch := make(chan *Object)
 
 	go func() {
@@ -103,6 +109,120 @@ func (f *Filesystem) expandGlob(ctx context.Context, src *url.URL, followSymlink
 	return ch
 }
 
+// doubleStar is the wildcard which matches any number of directories, which
+// filepath.Glob doesn't support.
+const doubleStar = "**"
+
+// hasDoubleStar reports whether any element of the pattern is '**'.
+func hasDoubleStar(pattern string) bool {
+	for _, elem := range strings.Split(filepath.ToSlash(pattern), "/") {
+		if elem == doubleStar {
+			return true
+		}
+	}
+	return false
+}
+
+// expandDoubleStarGlob walks the directory up to the first wildcard of the
+// pattern, and sends the files which match the pattern. Relative paths of the
+// files are relative to the walked directory.
+func (f *Filesystem) expandDoubleStarGlob(ctx context.Context, src *url.URL, followSymlinks bool) <-chan *Object {
+	ch := make(chan *Object)
+
+	go func() {
+		defer close(ch)
+
+		pattern := path.Clean(filepath.ToSlash(src.Absolute()))
+		for _, elem := range strings.Split(pattern, "/") {
+			if _, err := path.Match(elem, ""); err != nil {
+				sendError(ctx, fmt.Errorf("invalid pattern %q: %v", src, err), ch)
+				return
+			}
+		}
+
+		root := globRoot(pattern)
+		rooturl, err := url.New(root)
+		if err != nil {
+			sendError(ctx, err, ch)
+			return
+		}
+
+		var matched bool
+		walkDir(ctx, f, rooturl, followSymlinks, func(obj *Object) {
+			if obj.Err != nil {
+				sendObject(ctx, obj, ch)
+				return
+			}
+
+			name := path.Clean(filepath.ToSlash(obj.URL.Absolute()))
+			if !matchDoubleStar(pattern, name) {
+				return
+			}
+
+			obj.URL.SetRelative(root + "/")
+			matched = true
+			sendObject(ctx, obj, ch)
+		})
+
+		if !matched {
+			sendError(ctx, fmt.Errorf("no match found for %q", src), ch)
+		}
+	}()
+	return ch
+}
+
+// globRoot returns the directory of the pattern up to its first wildcard.
+func globRoot(pattern string) string {
+	var elems []string
+	for _, elem := range strings.Split(pattern, "/") {
+		if strings.ContainsAny(elem, "*?[") {
+			break
+		}
+		elems = append(elems, elem)
+	}
+
+	root := strings.Join(elems, "/")
+	switch {
+	case root == "" && strings.HasPrefix(pattern, "/"):
+		return "/"
+	case root == "":
+		return "."
+	default:
+		return root
+	}
+}
+
+// matchDoubleStar reports whether the slash-separated name matches the
+// pattern, where '**' matches zero or more directories and the other
+// elements are matched with path.Match.
+func matchDoubleStar(pattern, name string) bool {
+	return matchElems(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == doubleStar {
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
 func walkDir(ctx context.Context, fs *Filesystem, src *url.URL, followSymlinks bool, fn func(o *Object)) {
 	//skip if symlink is pointing to a dir and --no-follow-symlink
 	if !ShouldProcessUrl(src, followSymlinks) {
@@ -234,3 +354,25 @@ func sendError(ctx context.Context, err error, ch chan *Object) {
 	obj := &Object{Err: err}
 	sendObject(ctx, obj, ch)
 }
+
+// AvailableSpace returns the number of bytes available to the user on the
+// filesystem of the given path. The closest existing parent directory is used
+// if the path doesn't exist yet, e.g. the target directory of a download.
+func (f *Filesystem) AvailableSpace(path string) (uint64, error) {
+	for {
+		_, err := os.Stat(path)
+		if err == nil {
+			break
+		}
+		if !os.IsNotExist(err) {
+			return 0, err
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	return availableSpace(path)
+}
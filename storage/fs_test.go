@@ -1,6 +1,17 @@
 package storage
 
-import "testing"
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/peak/s5cmd/storage/url"
+)
 
 func TestFilesystemImplementsStorageInterface(t *testing.T) {
 	var i interface{} = new(Filesystem)
@@ -8,3 +19,110 @@ func TestFilesystemImplementsStorageInterface(t *testing.T) {
 		t.Errorf("expected %t to implement Storage interface", i)
 	}
 }
+
+func TestMatchDoubleStar(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "data/**/*.csv", name: "data/a.csv", want: true},
+		{pattern: "data/**/*.csv", name: "data/a/b/c.csv", want: true},
+		{pattern: "data/**/*.csv", name: "data/a/b/c.txt", want: false},
+		{pattern: "data/**/*.csv", name: "other/a.csv", want: false},
+		{pattern: "data/**", name: "data/a/b.txt", want: true},
+		{pattern: "**/b/*.txt", name: "a/b/c.txt", want: true},
+		{pattern: "**/b/*.txt", name: "b/c.txt", want: true},
+		{pattern: "**/b/*.txt", name: "a/b/c/d.txt", want: false},
+		{pattern: "data/*/**/x?.csv", name: "data/a/x1.csv", want: true},
+		{pattern: "data/*/**/x?.csv", name: "data/x1.csv", want: false},
+	}
+
+	for _, tc := range tests {
+		got := matchDoubleStar(tc.pattern, tc.name)
+		assert.Equal(t, got, tc.want, "pattern %q name %q", tc.pattern, tc.name)
+	}
+}
+
+func TestGlobRoot(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"data/**/*.csv":      "data",
+		"data/a/*/**/*.csv":  "data/a",
+		"/tmp/data/**":       "/tmp/data",
+		"/**/*.csv":          "/",
+		"**/*.csv":           ".",
+		"data/a?/**/b/*.csv": "data",
+	}
+
+	for pattern, want := range tests {
+		assert.Equal(t, globRoot(pattern), want, pattern)
+	}
+}
+
+func TestFilesystemListDoubleStar(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-doublestar-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"a.csv", "a.txt", "x/b.csv", "x/y/c.csv", "x/y/d.txt"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(p), os.ModePerm))
+		assert.NilError(t, ioutil.WriteFile(p, []byte("content"), 0644))
+	}
+
+	src, err := url.New(filepath.ToSlash(dir) + "/**/*.csv")
+	assert.NilError(t, err)
+
+	var got []string
+	for obj := range new(Filesystem).List(context.Background(), src, true) {
+		assert.NilError(t, obj.Err)
+		got = append(got, obj.URL.Relative())
+	}
+	sort.Strings(got)
+
+	assert.DeepEqual(t, got, []string{"a.csv", "x/b.csv", "x/y/c.csv"})
+}
+
+func TestFilesystemListDoubleStarNoMatch(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-doublestar-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	src, err := url.New(filepath.ToSlash(dir) + "/**/*.csv")
+	assert.NilError(t, err)
+
+	var objects []*Object
+	for obj := range new(Filesystem).List(context.Background(), src, true) {
+		objects = append(objects, obj)
+	}
+
+	assert.Equal(t, len(objects), 1)
+	assert.ErrorContains(t, objects[0].Err, "no match found")
+}
+
+func TestFilesystemAvailableSpace(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "s5cmd-space-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(dir)
+
+	fs := &Filesystem{}
+
+	available, err := fs.AvailableSpace(dir)
+	assert.NilError(t, err)
+	assert.Assert(t, available > 0)
+
+	// missing directories are on the filesystem of their closest parent.
+	missing, err := fs.AvailableSpace(filepath.Join(dir, "a", "b") + string(filepath.Separator))
+	assert.NilError(t, err)
+	assert.Assert(t, missing > 0)
+}
@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	urlpkg "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// inventoryManifest is the manifest.json of an S3 Inventory report, which
+// lists the data files of the report along with their schema.
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// inventorySchema holds the indexes of the columns of the data files. Columns
+// which are not in the report are -1.
+type inventorySchema struct {
+	key            int
+	size           int
+	lastModified   int
+	etag           int
+	storageClass   int
+	isLatest       int
+	isDeleteMarker int
+}
+
+func newInventorySchema(fileSchema string) (inventorySchema, error) {
+	columns := map[string]int{}
+	for i, column := range strings.Split(fileSchema, ",") {
+		columns[strings.TrimSpace(column)] = i
+	}
+
+	index := func(column string) int {
+		if i, ok := columns[column]; ok {
+			return i
+		}
+		return -1
+	}
+
+	schema := inventorySchema{
+		key:            index("Key"),
+		size:           index("Size"),
+		lastModified:   index("LastModifiedDate"),
+		etag:           index("ETag"),
+		storageClass:   index("StorageClass"),
+		isLatest:       index("IsLatest"),
+		isDeleteMarker: index("IsDeleteMarker"),
+	}
+	if schema.key < 0 {
+		return inventorySchema{}, fmt.Errorf("inventory report has no Key column: %q", fileSchema)
+	}
+	return schema, nil
+}
+
+// column returns the value of the given column of the record, or an empty
+// string if the column is not in the report.
+func (s inventorySchema) column(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// ListInventory is a non-blocking alternative to List, which reads the objects
+// from the S3 Inventory report of the given manifest instead of listing the
+// bucket. It's much faster for the buckets with billions of objects, though the
+// report may be out of date. Only the reports in CSV format are supported.
+// Keys are grouped by the delimiter of the url the same way as listing does.
+func (s *S3) ListInventory(ctx context.Context, manifestURL, url *url.URL) <-chan *Object {
+	objCh := make(chan *Object)
+
+	go func() {
+		defer close(objCh)
+
+		objectFound, err := s.readInventory(ctx, manifestURL, url, objCh)
+		if err != nil {
+			objCh <- &Object{Err: err}
+			return
+		}
+
+		if !objectFound {
+			objCh <- &Object{Err: ErrNoObjectFound}
+		}
+	}()
+
+	return objCh
+}
+
+func (s *S3) readInventory(ctx context.Context, manifestURL, src *url.URL, objCh chan<- *Object) (bool, error) {
+	manifest, err := s.readInventoryManifest(ctx, manifestURL)
+	if err != nil {
+		return false, err
+	}
+
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return false, fmt.Errorf("only CSV inventory reports are supported, got %v", manifest.FileFormat)
+	}
+
+	if manifest.SourceBucket != src.Bucket {
+		return false, fmt.Errorf("inventory report is of bucket %q, not %q", manifest.SourceBucket, src.Bucket)
+	}
+
+	schema, err := newInventorySchema(manifest.FileSchema)
+	if err != nil {
+		return false, err
+	}
+
+	// data files are in the destination bucket of the report, which is given
+	// as an ARN.
+	bucket := manifest.DestinationBucket
+	if i := strings.LastIndex(bucket, ":"); i >= 0 {
+		bucket = bucket[i+1:]
+	}
+
+	var objectFound bool
+	prefixes := map[string]struct{}{}
+	for _, file := range manifest.Files {
+		fileURL := &url.URL{Type: manifestURL.Type, Scheme: manifestURL.Scheme, Bucket: bucket, Path: file.Key}
+
+		found, err := s.readInventoryFile(ctx, fileURL, src, schema, prefixes, objCh)
+		if err != nil {
+			return false, err
+		}
+		objectFound = objectFound || found
+	}
+	return objectFound, nil
+}
+
+func (s *S3) readInventoryManifest(ctx context.Context, manifestURL *url.URL) (inventoryManifest, error) {
+	body, err := s.Read(ctx, manifestURL, NewMetadata())
+	if err != nil {
+		return inventoryManifest{}, fmt.Errorf("read inventory manifest %v: %v", manifestURL, err)
+	}
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return inventoryManifest{}, fmt.Errorf("read inventory manifest %v: %v", manifestURL, err)
+	}
+
+	var manifest inventoryManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return inventoryManifest{}, fmt.Errorf("invalid inventory manifest %v: %v", manifestURL, err)
+	}
+	return manifest, nil
+}
+
+// readInventoryFile sends the objects in the given data file of the report,
+// which match the url, to objCh. Common prefixes are sent once as directories
+// if the url has a delimiter.
+func (s *S3) readInventoryFile(
+	ctx context.Context,
+	fileURL *url.URL,
+	url *url.URL,
+	schema inventorySchema,
+	prefixes map[string]struct{},
+	objCh chan<- *Object,
+) (bool, error) {
+	body, err := s.Read(ctx, fileURL, NewMetadata())
+	if err != nil {
+		return false, fmt.Errorf("read inventory file %v: %v", fileURL, err)
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if strings.HasSuffix(fileURL.Path, ".gz") {
+		gr, err := gzip.NewReader(body)
+		if err != nil {
+			return false, fmt.Errorf("read inventory file %v: %v", fileURL, err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	var objectFound bool
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return objectFound, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("read inventory file %v: %v", fileURL, err)
+		}
+
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+
+		// reports of versioned buckets have all versions of the objects.
+		if schema.column(record, schema.isDeleteMarker) == "true" || schema.column(record, schema.isLatest) == "false" {
+			continue
+		}
+
+		// keys are URL encoded in CSV reports.
+		key, err := urlpkg.QueryUnescape(schema.column(record, schema.key))
+		if err != nil {
+			return false, fmt.Errorf("invalid key %q in inventory file %v: %v", schema.column(record, schema.key), fileURL, err)
+		}
+
+		if !strings.HasPrefix(key, url.Prefix) {
+			continue
+		}
+
+		if url.Delimiter != "" {
+			rest := strings.TrimPrefix(key, url.Prefix)
+			if i := strings.Index(rest, url.Delimiter); i >= 0 {
+				prefix := url.Prefix + rest[:i+len(url.Delimiter)]
+				if _, ok := prefixes[prefix]; ok || !url.Match(prefix) {
+					continue
+				}
+				prefixes[prefix] = struct{}{}
+
+				newurl := url.Clone()
+				newurl.Path = prefix
+				objCh <- &Object{
+					URL:  newurl,
+					Type: ObjectType{os.ModeDir},
+				}
+				objectFound = true
+				continue
+			}
+		}
+
+		if !url.Match(key) {
+			continue
+		}
+
+		var objtype os.FileMode
+		if strings.HasSuffix(key, "/") {
+			objtype = os.ModeDir
+		}
+
+		newurl := url.Clone()
+		newurl.Path = key
+
+		obj := &Object{
+			URL:          newurl,
+			Etag:         strings.Trim(schema.column(record, schema.etag), `"`),
+			Type:         ObjectType{objtype},
+			StorageClass: StorageClass(schema.column(record, schema.storageClass)),
+		}
+
+		if size := schema.column(record, schema.size); size != "" {
+			obj.Size, err = strconv.ParseInt(size, 10, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid size %q in inventory file %v: %v", size, fileURL, err)
+			}
+		}
+
+		if mod := schema.column(record, schema.lastModified); mod != "" {
+			modTime, err := time.Parse(time.RFC3339, mod)
+			if err != nil {
+				return false, fmt.Errorf("invalid last modified date %q in inventory file %v: %v", mod, fileURL, err)
+			}
+			modTime = modTime.UTC()
+			obj.ModTime = &modTime
+		}
+
+		objCh <- obj
+		objectFound = true
+	}
+}
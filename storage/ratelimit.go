@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// requestLimiter limits the rate of the requests sent to each bucket. S3
+// responds with 'SlowDown' errors when the request rate of a prefix exceeds
+// its limits, regardless of the bandwidth used.
+//
+// Requests to a bucket are spaced evenly, so that no more than the given
+// number of requests are sent in a second. Each retry of a request is counted
+// as a separate request.
+type requestLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func newRequestLimiter(requestsPerSecond int) *requestLimiter {
+	return &requestLimiter{
+		interval: time.Second / time.Duration(requestsPerSecond),
+		next:     map[string]time.Time{},
+	}
+}
+
+// wait blocks until a request can be sent to the given bucket, or the context
+// is cancelled.
+func (l *requestLimiter) wait(ctx context.Context, bucket string) error {
+	l.mu.Lock()
+	now := time.Now()
+	next := l.next[bucket]
+	if next.Before(now) {
+		next = now
+	}
+	l.next[bucket] = next.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := next.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handler is a request handler which waits for the limiter before each
+// attempt of the request. It must run before the request is signed, since
+// signatures are only valid for a limited time.
+func (l *requestLimiter) handler(r *request.Request) {
+	if err := l.wait(r.Context(), requestBucket(r)); err != nil {
+		r.Error = err
+	}
+}
+
+// requestBucket returns the name of the bucket the request is sent to. Empty
+// string is returned for the requests which are not bucket specific, such as
+// ListBuckets.
+func requestBucket(r *request.Request) string {
+	values, err := awsutil.ValuesAtPath(r.Params, "Bucket")
+	if err != nil || len(values) == 0 {
+		return ""
+	}
+
+	if bucket, ok := values[0].(*string); ok {
+		return aws.StringValue(bucket)
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/peak/s5cmd/storage/url"
+)
+
+// bucketRegions caches the regions of the buckets for the lifetime of the
+// process. Commands create a client for each source and destination, and
+// batch files can run thousands of commands against the same bucket, so the
+// region of a bucket is looked up only once.
+var bucketRegions = struct {
+	sync.Mutex
+	lookups map[regionKey]*regionLookup
+}{lookups: map[regionKey]*regionLookup{}}
+
+// regionKey identifies a bucket of an endpoint. The profile is a part of the
+// key since the location of a bucket may be looked up with the credentials of
+// one profile but not with the other.
+type regionKey struct {
+	endpoint string
+	profile  string
+	bucket   string
+}
+
+// regionLookup is the lookup of the region of a bucket. done is closed once
+// region is set, or once the lookup is canceled.
+type regionLookup struct {
+	done     chan struct{}
+	region   string
+	canceled bool
+}
+
+// detectRegion returns the region of the bucket of the given url if it's
+// different from the region of the session, or an empty string otherwise.
+// Regions are only detected for AWS S3 endpoints when the region is not given
+// with the options, since the other S3 compatible services don't have regions
+// or don't support looking them up.
+func detectRegion(ctx context.Context, url *url.URL, opts Options, sess *session.Session) string {
+	if url == nil || url.Bucket == "" || opts.Region != "" || opts.Endpoint != "" {
+		return ""
+	}
+
+	api := s3.New(sess)
+	key := regionKey{
+		endpoint: api.Endpoint,
+		profile:  opts.Profile,
+		bucket:   url.Bucket,
+	}
+
+	region := aws.StringValue(sess.Config.Region)
+	if detected := bucketRegion(ctx, api, key, region); detected != region {
+		return detected
+	}
+	return ""
+}
+
+// bucketRegion returns the region of the bucket of the given key, looking it
+// up with GetBucketLocation at the first call for the key. The fallback region
+// is returned if the location can't be looked up, e.g. the caller is not
+// allowed to get the location of the bucket, and it's not looked up again.
+// Concurrent callers of the same key wait for the first lookup instead of
+// looking it up again, the other keys are looked up in parallel. If the first
+// lookup is canceled, the waiting callers look the region up again with their
+// own contexts.
+func bucketRegion(ctx context.Context, api s3iface.S3API, key regionKey, fallback string) string {
+	for {
+		bucketRegions.Lock()
+		lookup, ok := bucketRegions.lookups[key]
+		if !ok {
+			lookup = &regionLookup{done: make(chan struct{})}
+			bucketRegions.lookups[key] = lookup
+		}
+		bucketRegions.Unlock()
+
+		if !ok {
+			return lookupBucketRegion(ctx, api, key, lookup, fallback)
+		}
+
+		select {
+		case <-lookup.done:
+			if !lookup.canceled {
+				return lookup.region
+			}
+		case <-ctx.Done():
+			return fallback
+		}
+	}
+}
+
+// lookupBucketRegion looks up the region of the bucket of the given key and
+// sets it to the given lookup.
+func lookupBucketRegion(ctx context.Context, api s3iface.S3API, key regionKey, lookup *regionLookup, fallback string) string {
+	lookup.region = fallback
+	output, err := api.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: aws.String(key.bucket),
+	})
+	if err == nil {
+		lookup.region = s3.NormalizeBucketLocation(aws.StringValue(output.LocationConstraint))
+	}
+
+	// lookups which are canceled are not cached, the next caller looks the
+	// region up again.
+	if err != nil && ctx.Err() != nil {
+		lookup.canceled = true
+		bucketRegions.Lock()
+		delete(bucketRegions.lookups, key)
+		bucketRegions.Unlock()
+	}
+	close(lookup.done)
+
+	return lookup.region
+}
@@ -2,10 +2,15 @@ package storage
 
 import (
 	"context"
+	"crypto/md5"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	urlpkg "net/url"
 	"os"
@@ -16,7 +21,9 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
 	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -35,6 +42,12 @@ const (
 	// request.
 	deleteObjectsMax = 1000
 
+	// deleteRetryBaseDelay is the delay before resubmitting the keys which
+	// failed with transient errors on the first attempt. It's doubled on
+	// each attempt, up to deleteRetryMaxDelay.
+	deleteRetryBaseDelay = 100 * time.Millisecond
+	deleteRetryMaxDelay  = 10 * time.Second
+
 	// Amazon Accelerated Transfer endpoint
 	transferAccelEndpoint = "s3-accelerate.amazonaws.com"
 
@@ -42,20 +55,36 @@ const (
 	gcsEndpoint = "storage.googleapis.com"
 )
 
-// Re-used AWS sessions dramatically improve performance.
-var cachedSession func() *session.Session
+// Re-used AWS sessions dramatically improve performance. Sessions are kept by
+// the options they are created with, since the clients of the source and the
+// destination may be configured with different endpoints.
+var sessions = struct {
+	sync.Mutex
+	byOptions map[Options]*session.Session
+}{byOptions: map[Options]*session.Session{}}
 
 // Init creates a new global S3 session.
 func Init(opts Options) error {
-	sess, err := newSession(opts)
-	if err != nil {
-		return err
+	_, err := cachedSession(opts)
+	return err
+}
+
+// cachedSession returns the session created with the given options, creating
+// it if there is none.
+func cachedSession(opts Options) (*session.Session, error) {
+	sessions.Lock()
+	defer sessions.Unlock()
+
+	if sess, ok := sessions.byOptions[opts]; ok {
+		return sess, nil
 	}
 
-	cachedSession = func() *session.Session {
-		return sess
+	sess, err := newSession(opts)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	sessions.byOptions[opts] = sess
+	return sess, nil
 }
 
 // S3 is a storage type which interacts with S3API, DownloaderAPI and
@@ -66,7 +95,9 @@ type S3 struct {
 	uploader    s3manageriface.UploaderAPI
 	endpointURL urlpkg.URL
 
-	dryRun bool
+	dryRun          bool
+	maxRetries      int
+	listConcurrency int
 }
 
 func parseEndpoint(endpoint string) (urlpkg.URL, error) {
@@ -96,11 +127,13 @@ func newS3Storage(opts Options, sessProvider func() *session.Session) (*S3, erro
 	awsSession := sessProvider()
 
 	return &S3{
-		api:         s3.New(awsSession),
-		downloader:  s3manager.NewDownloader(awsSession),
-		uploader:    s3manager.NewUploader(awsSession),
-		endpointURL: endpointURL,
-		dryRun:      opts.DryRun,
+		api:             s3.New(awsSession),
+		downloader:      s3manager.NewDownloader(awsSession),
+		uploader:        s3manager.NewUploader(awsSession),
+		endpointURL:     endpointURL,
+		dryRun:          opts.DryRun,
+		maxRetries:      opts.MaxRetries,
+		listConcurrency: opts.ListConcurrency,
 	}, nil
 }
 
@@ -119,11 +152,28 @@ func (s *S3) Stat(ctx context.Context, url *url.URL) (*Object, error) {
 
 	etag := aws.StringValue(output.ETag)
 	mod := aws.TimeValue(output.LastModified)
+	metadata := NewMetadata().
+		SetContentType(aws.StringValue(output.ContentType)).
+		SetContentEncoding(aws.StringValue(output.ContentEncoding)).
+		SetCacheControl(aws.StringValue(output.CacheControl)).
+		SetContentDisposition(aws.StringValue(output.ContentDisposition)).
+		SetContentLanguage(aws.StringValue(output.ContentLanguage)).
+		SetStorageClass(aws.StringValue(output.StorageClass)).
+		SetSSE(aws.StringValue(output.ServerSideEncryption)).
+		SetSSEKeyID(aws.StringValue(output.SSEKMSKeyId)).
+		SetWebsiteRedirectLocation(aws.StringValue(output.WebsiteRedirectLocation))
+
+	for k, v := range output.Metadata {
+		metadata.SetUserDefined(k, decodeMetadataValue(aws.StringValue(v)))
+	}
+
 	return &Object{
-		URL:     url,
-		Etag:    strings.Trim(etag, `"`),
-		ModTime: &mod,
-		Size:    aws.Int64Value(output.ContentLength),
+		URL:          url,
+		Etag:         strings.Trim(etag, `"`),
+		ModTime:      &mod,
+		Size:         aws.Int64Value(output.ContentLength),
+		StorageClass: StorageClass(aws.StringValue(output.StorageClass)),
+		Metadata:     metadata,
 	}, nil
 }
 
@@ -138,92 +188,178 @@ func (s *S3) List(ctx context.Context, url *url.URL, _ bool) <-chan *Object {
 }
 
 func (s *S3) listObjectsV2(ctx context.Context, url *url.URL) <-chan *Object {
+	objCh := make(chan *Object)
+
+	go func() {
+		defer close(objCh)
+
+		var (
+			objectFound bool
+			err         error
+		)
+		if s.listConcurrency > 1 && url.HasGlob() {
+			objectFound, err = s.listObjectsV2Concurrently(ctx, url, objCh)
+		} else {
+			_, objectFound, err = s.listObjectsV2Pages(ctx, url, url.Prefix, url.Delimiter, objCh)
+		}
+
+		if err != nil {
+			objCh <- &Object{Err: err}
+			return
+		}
+
+		if !objectFound {
+			objCh <- &Object{Err: ErrNoObjectFound}
+		}
+	}()
+
+	return objCh
+}
+
+// listObjectsV2Pages lists the keys under given prefix, and sends the ones
+// which match the url to objCh. Common prefixes are sent as directories if the
+// url has a delimiter. Otherwise they are only returned, which is the case
+// when a wildcard listing is split by the delimiter.
+func (s *S3) listObjectsV2Pages(
+	ctx context.Context,
+	url *url.URL,
+	prefix string,
+	delimiter string,
+	objCh chan<- *Object,
+) ([]string, bool, error) {
 	listInput := s3.ListObjectsV2Input{
 		Bucket: aws.String(url.Bucket),
-		Prefix: aws.String(url.Prefix),
+		Prefix: aws.String(prefix),
 	}
 
-	if url.Delimiter != "" {
-		listInput.SetDelimiter(url.Delimiter)
+	if delimiter != "" {
+		listInput.SetDelimiter(delimiter)
 	}
 
-	objCh := make(chan *Object)
+	var (
+		prefixes    []string
+		objectFound bool
+		now         time.Time
+	)
 
-	go func() {
-		defer close(objCh)
-		objectFound := false
+	err := s.api.ListObjectsV2PagesWithContext(ctx, &listInput, func(p *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, c := range p.CommonPrefixes {
+			prefix := aws.StringValue(c.Prefix)
+			if url.Delimiter == "" {
+				prefixes = append(prefixes, prefix)
+				continue
+			}
 
-		var now time.Time
+			if !url.Match(prefix) {
+				continue
+			}
 
-		err := s.api.ListObjectsV2PagesWithContext(ctx, &listInput, func(p *s3.ListObjectsV2Output, lastPage bool) bool {
-			for _, c := range p.CommonPrefixes {
-				prefix := aws.StringValue(c.Prefix)
-				if !url.Match(prefix) {
-					continue
-				}
+			newurl := url.Clone()
+			newurl.Path = prefix
+			objCh <- &Object{
+				URL:  newurl,
+				Type: ObjectType{os.ModeDir},
+			}
 
-				newurl := url.Clone()
-				newurl.Path = prefix
-				objCh <- &Object{
-					URL:  newurl,
-					Type: ObjectType{os.ModeDir},
-				}
+			objectFound = true
+		}
+		// track the instant object iteration began,
+		// so it can be used to bypass objects created after this instant
+		if now.IsZero() {
+			now = time.Now().UTC()
+		}
+
+		for _, c := range p.Contents {
+			key := aws.StringValue(c.Key)
+			if !url.Match(key) {
+				continue
+			}
+
+			var objtype os.FileMode
+			if strings.HasSuffix(key, "/") {
+				objtype = os.ModeDir
+			}
+
+			newurl := url.Clone()
+			newurl.Path = aws.StringValue(c.Key)
+			etag := aws.StringValue(c.ETag)
+			mod := aws.TimeValue(c.LastModified).UTC()
 
+			if mod.After(now) {
 				objectFound = true
+				continue
 			}
-			// track the instant object iteration began,
-			// so it can be used to bypass objects created after this instant
-			if now.IsZero() {
-				now = time.Now().UTC()
+
+			objCh <- &Object{
+				URL:          newurl,
+				Etag:         strings.Trim(etag, `"`),
+				ModTime:      &mod,
+				Type:         ObjectType{objtype},
+				Size:         aws.Int64Value(c.Size),
+				StorageClass: StorageClass(aws.StringValue(c.StorageClass)),
 			}
 
-			for _, c := range p.Contents {
-				key := aws.StringValue(c.Key)
-				if !url.Match(key) {
-					continue
-				}
+			objectFound = true
+		}
 
-				var objtype os.FileMode
-				if strings.HasSuffix(key, "/") {
-					objtype = os.ModeDir
-				}
+		return !lastPage
+	})
 
-				newurl := url.Clone()
-				newurl.Path = aws.StringValue(c.Key)
-				etag := aws.StringValue(c.ETag)
-				mod := aws.TimeValue(c.LastModified).UTC()
+	return prefixes, objectFound, err
+}
 
-				if mod.After(now) {
-					objectFound = true
-					continue
-				}
+// listObjectsV2Concurrently lists the keys of a wildcard url by splitting the
+// listing by the delimiter. The keys right under the prefix of the url are
+// listed first, then each of the common prefixes is listed concurrently with
+// at most listConcurrency requests at a time. Wildcards match the delimiter,
+// so the keys under each common prefix are listed without a delimiter.
+// Objects are not sent in lexicographical order.
+func (s *S3) listObjectsV2Concurrently(ctx context.Context, url *url.URL, objCh chan<- *Object) (bool, error) {
+	// url.Match sets the relative path of the url, each listing matches the
+	// keys with its own copy.
+	prefixes, objectFound, err := s.listObjectsV2Pages(ctx, url.Clone(), url.Prefix, "/", objCh)
+	if err != nil {
+		return false, err
+	}
 
-				objCh <- &Object{
-					URL:          newurl,
-					Etag:         strings.Trim(etag, `"`),
-					ModTime:      &mod,
-					Type:         ObjectType{objtype},
-					Size:         aws.Int64Value(c.Size),
-					StorageClass: StorageClass(aws.StringValue(c.StorageClass)),
-				}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-				objectFound = true
-			}
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	sem := make(chan struct{}, s.listConcurrency)
 
-			return !lastPage
-		})
+	for _, prefix := range prefixes {
+		prefix := prefix
 
-		if err != nil {
-			objCh <- &Object{Err: err}
-			return
-		}
+		wg.Add(1)
+		sem <- struct{}{}
 
-		if !objectFound {
-			objCh <- &Object{Err: ErrNoObjectFound}
-		}
-	}()
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
 
-	return objCh
+			_, found, err := s.listObjectsV2Pages(ctx, url.Clone(), prefix, "", objCh)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			objectFound = objectFound || found
+			// the other listings are canceled once one of them fails, only
+			// the first error is reported.
+			if err != nil && firstErr == nil {
+				firstErr = err
+				cancel()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return objectFound, firstErr
 }
 
 // listObjects is used for cloud services that does not support S3
@@ -326,6 +462,9 @@ func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata) err
 
 	// SDK expects CopySource like "bucket[/key]"
 	copySource := strings.TrimPrefix(from.String(), "s3://")
+	if versionID := metadata.SourceVersionID(); versionID != "" {
+		copySource += "?versionId=" + urlpkg.QueryEscape(versionID)
+	}
 
 	input := &s3.CopyObjectInput{
 		Bucket:     aws.String(to.Bucket),
@@ -352,18 +491,242 @@ func (s *S3) Copy(ctx context.Context, from, to *url.URL, metadata Metadata) err
 		input.ACL = aws.String(acl)
 	}
 
-	_, err := s.api.CopyObject(input)
+	input.GrantRead = nilIfEmpty(metadata.GrantRead())
+	input.GrantReadACP = nilIfEmpty(metadata.GrantReadACP())
+	input.GrantWriteACP = nilIfEmpty(metadata.GrantWriteACP())
+	input.GrantFullControl = nilIfEmpty(metadata.GrantFullControl())
+
+	// metadata of the object is only replaced with the given values if the
+	// directive is 'REPLACE', otherwise it's copied from the source object.
+	directive := metadata.MetadataDirective()
+	if directive != "" {
+		input.MetadataDirective = aws.String(directive)
+	}
+
+	if directive == s3.MetadataDirectiveReplace {
+		input.ContentType = nilIfEmpty(metadata.ContentType())
+		input.ContentEncoding = nilIfEmpty(metadata.ContentEncoding())
+		input.CacheControl = nilIfEmpty(metadata.CacheControl())
+		input.ContentDisposition = nilIfEmpty(metadata.ContentDisposition())
+		input.ContentLanguage = nilIfEmpty(metadata.ContentLanguage())
+
+		userDefined := metadata.UserDefined()
+		if len(userDefined) > 0 {
+			input.Metadata = userMetadataInput(userDefined)
+		}
+	}
+
+	input.CopySourceIfMatch = nilIfEmpty(metadata.IfMatch())
+	input.CopySourceIfNoneMatch = nilIfEmpty(metadata.IfNoneMatch())
+
+	_, err := s.api.CopyObjectWithContext(ctx, input)
+	return preconditionError(err)
+}
+
+// Concat concatenates the given source objects into the destination object
+// with a multipart upload, without downloading them. Each source object is
+// copied as a part, in the given order. All parts except the last one must be
+// at least 5 MiB. The upload is aborted if any of the parts fails.
+func (s *S3) Concat(ctx context.Context, sources []*url.URL, to *url.URL, metadata Metadata) error {
+	if s.dryRun {
+		return nil
+	}
+
+	input := &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(to.Bucket),
+		Key:    aws.String(to.Path),
+	}
+
+	contentType := metadata.ContentType()
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	input.ContentType = aws.String(contentType)
+	input.StorageClass = nilIfEmpty(metadata.StorageClass())
+	input.ACL = nilIfEmpty(metadata.ACL())
+
+	sseEncryption := metadata.SSE()
+	if sseEncryption != "" {
+		input.ServerSideEncryption = aws.String(sseEncryption)
+		input.SSEKMSKeyId = nilIfEmpty(metadata.SSEKeyID())
+	}
+
+	upload, err := s.api.CreateMultipartUploadWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	parts := make([]*s3.CompletedPart, 0, len(sources))
+	for i, src := range sources {
+		partNumber := aws.Int64(int64(i + 1))
+
+		// SDK expects CopySource like "bucket[/key]"
+		copySource := strings.TrimPrefix(src.String(), "s3://")
+
+		output, err := s.api.UploadPartCopyWithContext(ctx, &s3.UploadPartCopyInput{
+			Bucket:     aws.String(to.Bucket),
+			Key:        aws.String(to.Path),
+			UploadId:   upload.UploadId,
+			PartNumber: partNumber,
+			CopySource: aws.String(copySource),
+		})
+		if err != nil {
+			s.abortMultipartUpload(to, upload.UploadId)
+			return err
+		}
+
+		parts = append(parts, &s3.CompletedPart{
+			ETag:       output.CopyPartResult.ETag,
+			PartNumber: partNumber,
+		})
+	}
+
+	_, err = s.api.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(to.Bucket),
+		Key:             aws.String(to.Path),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		s.abortMultipartUpload(to, upload.UploadId)
+		return err
+	}
+	return nil
+}
+
+// abortMultipartUpload aborts the given multipart upload, so that the copied
+// parts are not billed. It's called after the upload fails, possibly because
+// the context is canceled, so the context of the upload is not used. Its
+// error is ignored in favor of the one which failed the upload.
+func (s *S3) abortMultipartUpload(to *url.URL, uploadID *string) {
+	_, _ = s.api.AbortMultipartUploadWithContext(context.Background(), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(to.Bucket),
+		Key:      aws.String(to.Path),
+		UploadId: uploadID,
+	})
+}
+
+// MultipartUpload is an in-progress multipart upload of an object.
+type MultipartUpload struct {
+	URL       *url.URL
+	UploadID  string
+	Initiated time.Time
+}
+
+// ListMultipartUploads returns the in-progress multipart uploads of the
+// objects which match the given URL.
+func (s *S3) ListMultipartUploads(ctx context.Context, u *url.URL) ([]MultipartUpload, error) {
+	var uploads []MultipartUpload
+	err := s.api.ListMultipartUploadsPagesWithContext(ctx, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(u.Bucket),
+		Prefix: aws.String(u.Prefix),
+	}, func(p *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, upload := range p.Uploads {
+			key := aws.StringValue(upload.Key)
+			if !u.Match(key) {
+				continue
+			}
+
+			uploadurl := u.Clone()
+			uploadurl.Path = key
+			uploads = append(uploads, MultipartUpload{
+				URL:       uploadurl,
+				UploadID:  aws.StringValue(upload.UploadId),
+				Initiated: aws.TimeValue(upload.Initiated),
+			})
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// MultipartUploadSize returns the number and the total size of the parts
+// uploaded so far for the given multipart upload.
+func (s *S3) MultipartUploadSize(ctx context.Context, upload MultipartUpload) (int64, int64, error) {
+	var parts, size int64
+	err := s.api.ListPartsPagesWithContext(ctx, &s3.ListPartsInput{
+		Bucket:   aws.String(upload.URL.Bucket),
+		Key:      aws.String(upload.URL.Path),
+		UploadId: aws.String(upload.UploadID),
+	}, func(p *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range p.Parts {
+			parts++
+			size += aws.Int64Value(part.Size)
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return parts, size, nil
+}
+
+// CopyACL replaces the access control list of the destination object with
+// the one of the source object. Grants are not copied by CopyObject, the
+// destination object only gets the grants of the given canned ACL.
+func (s *S3) CopyACL(ctx context.Context, from, to *url.URL) error {
+	if s.dryRun {
+		return nil
+	}
+
+	acl, err := s.api.GetObjectAclWithContext(ctx, &s3.GetObjectAclInput{
+		Bucket: aws.String(from.Bucket),
+		Key:    aws.String(from.Path),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.api.PutObjectAclWithContext(ctx, &s3.PutObjectAclInput{
+		Bucket: aws.String(to.Bucket),
+		Key:    aws.String(to.Path),
+		AccessControlPolicy: &s3.AccessControlPolicy{
+			Grants: acl.Grants,
+			Owner:  acl.Owner,
+		},
+	})
 	return err
 }
 
+// SetACL replaces the access control list of the object with the given canned
+// ACL, e.g. public-read or private.
+func (s *S3) SetACL(ctx context.Context, url *url.URL, acl string) error {
+	if s.dryRun {
+		return nil
+	}
+
+	_, err := s.api.PutObjectAclWithContext(ctx, &s3.PutObjectAclInput{
+		Bucket: aws.String(url.Bucket),
+		Key:    aws.String(url.Path),
+		ACL:    aws.String(acl),
+	})
+	return err
+}
+
+// nilIfEmpty returns a pointer to the given string, or nil if it's empty.
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return aws.String(s)
+}
+
 // Read fetches the remote object and returns its contents as an io.ReadCloser.
-func (s *S3) Read(ctx context.Context, src *url.URL) (io.ReadCloser, error) {
+// ETag preconditions in metadata are checked, and only the byte range in
+// metadata is read, if given.
+func (s *S3) Read(ctx context.Context, src *url.URL, metadata Metadata) (io.ReadCloser, error) {
 	resp, err := s.api.GetObjectWithContext(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(src.Bucket),
-		Key:    aws.String(src.Path),
+		Bucket:      aws.String(src.Bucket),
+		Key:         aws.String(src.Path),
+		IfMatch:     nilIfEmpty(metadata.IfMatch()),
+		IfNoneMatch: nilIfEmpty(metadata.IfNoneMatch()),
+		Range:       nilIfEmpty(metadata.Range()),
 	})
 	if err != nil {
-		return nil, err
+		return nil, preconditionError(err)
 	}
 	return resp.Body, nil
 }
@@ -371,10 +734,13 @@ func (s *S3) Read(ctx context.Context, src *url.URL) (io.ReadCloser, error) {
 // Get is a multipart download operation which downloads S3 objects into any
 // destination that implements io.WriterAt interface.
 // Makes a single 'GetObject' call if 'concurrency' is 1 and ignores 'partSize'.
+// ETag preconditions in metadata are checked for each part, so the download
+// fails if the object changes in the middle of it.
 func (s *S3) Get(
 	ctx context.Context,
 	from *url.URL,
 	to io.WriterAt,
+	metadata Metadata,
 	concurrency int,
 	partSize int64,
 ) (int64, error) {
@@ -382,13 +748,32 @@ func (s *S3) Get(
 		return 0, nil
 	}
 
-	return s.downloader.DownloadWithContext(ctx, to, &s3.GetObjectInput{
-		Bucket: aws.String(from.Bucket),
-		Key:    aws.String(from.Path),
+	n, err := s.downloader.DownloadWithContext(ctx, to, &s3.GetObjectInput{
+		Bucket:      aws.String(from.Bucket),
+		Key:         aws.String(from.Path),
+		IfMatch:     nilIfEmpty(metadata.IfMatch()),
+		IfNoneMatch: nilIfEmpty(metadata.IfNoneMatch()),
 	}, func(u *s3manager.Downloader) {
 		u.PartSize = partSize
 		u.Concurrency = concurrency
 	})
+	return n, preconditionError(err)
+}
+
+// preconditionError returns ErrPreconditionFailed if the request failed
+// because of an ETag precondition. S3 responds with '412 Precondition Failed',
+// or with '304 Not Modified' for the If-None-Match condition of GET requests.
+func preconditionError(err error) error {
+	var reqErr awserr.RequestFailure
+	if !errors.As(err, &reqErr) {
+		return err
+	}
+
+	switch reqErr.StatusCode() {
+	case http.StatusPreconditionFailed, http.StatusNotModified:
+		return ErrPreconditionFailed
+	}
+	return err
 }
 
 // Put is a multipart upload operation to upload resources, which implements
@@ -405,6 +790,289 @@ func (s *S3) Put(
 		return nil
 	}
 
+	input, err := newUploadInput(reader, to, metadata)
+	if err != nil {
+		return err
+	}
+
+	// S3 rejects parts smaller than 5 MiB, except the last one.
+	if partSize < s3manager.MinUploadPartSize {
+		partSize = s3manager.MinUploadPartSize
+	}
+
+	_, err = s.uploader.UploadWithContext(ctx, input, func(u *s3manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = concurrency
+	})
+
+	if err != nil && isObjectLockError(err) {
+		return fmt.Errorf("object lock can only be used with the buckets which have object lock enabled: %v", err)
+	}
+
+	return err
+}
+
+// PutSinglePart uploads the content of reader to S3 destination with a single
+// PutObject request, regardless of its size. It's used for the S3 compatible
+// services which don't handle multipart uploads well. S3 rejects objects
+// larger than 5 GiB uploaded in a single part.
+func (s *S3) PutSinglePart(
+	ctx context.Context,
+	reader io.ReadSeeker,
+	to *url.URL,
+	metadata Metadata,
+) error {
+	if s.dryRun {
+		return nil
+	}
+
+	input, err := newUploadInput(reader, to, metadata)
+	if err != nil {
+		return err
+	}
+
+	// fields of the upload input are the same as the ones of PutObject
+	// request, like s3manager does for the single part uploads.
+	params := &s3.PutObjectInput{}
+	awsutil.Copy(params, input)
+	params.Body = reader
+
+	_, err = s.api.PutObjectWithContext(ctx, params)
+	if err != nil && isObjectLockError(err) {
+		return fmt.Errorf("object lock can only be used with the buckets which have object lock enabled: %v", err)
+	}
+
+	return err
+}
+
+// maxUploadParts is the maximum number of parts of a multipart upload.
+const maxUploadParts = 10000
+
+// PutResumable uploads the file of given size to S3 destination with a
+// multipart upload which can be resumed. If an upload of an interrupted run
+// is in progress for the same key, it's resumed: the parts which are already
+// uploaded are verified by comparing their ETags with the MD5 digests of the
+// file parts, and only the missing or the different ones are uploaded. The
+// upload is not aborted if it fails, so that it can be resumed later.
+//
+// Only the uploads of the same storage class are resumed, since it's the only
+// metadata of the uploads in progress which is listed by S3. Other metadata,
+// such as the content type or the ACL, is the one given to the upload which is
+// resumed.
+func (s *S3) PutResumable(
+	ctx context.Context,
+	file io.ReaderAt,
+	size int64,
+	to *url.URL,
+	metadata Metadata,
+	concurrency int,
+	partSize int64,
+) error {
+	if s.dryRun {
+		return nil
+	}
+
+	// S3 rejects parts smaller than 5 MiB, except the last one.
+	if partSize < s3manager.MinUploadPartSize {
+		partSize = s3manager.MinUploadPartSize
+	}
+
+	// part size is not adjusted like s3manager does, the parts must be the
+	// same on each run to be resumed.
+	numParts := (size + partSize - 1) / partSize
+	if numParts > maxUploadParts {
+		return fmt.Errorf("file needs %v parts to be uploaded, more than %v, increase --part-size", numParts, maxUploadParts)
+	}
+
+	uploadID, uploaded, err := s.findMultipartUpload(ctx, to, metadata.StorageClass())
+	if err != nil {
+		return err
+	}
+
+	if uploadID == nil {
+		input, err := newUploadInput(nil, to, metadata)
+		if err != nil {
+			return err
+		}
+
+		params := &s3.CreateMultipartUploadInput{}
+		awsutil.Copy(params, input)
+
+		upload, err := s.api.CreateMultipartUploadWithContext(ctx, params)
+		if err != nil {
+			if isObjectLockError(err) {
+				return fmt.Errorf("object lock can only be used with the buckets which have object lock enabled: %v", err)
+			}
+			return err
+		}
+		uploadID = upload.UploadId
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		first error
+		sem   = make(chan struct{}, concurrency)
+		parts = make([]*s3.CompletedPart, numParts)
+	)
+
+	for i := int64(0); i < numParts; i++ {
+		offset := i * partSize
+		length := partSize
+		if offset+length > size {
+			length = size - offset
+		}
+		partNumber := i + 1
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			part := io.NewSectionReader(file, offset, length)
+			etag, err := s.uploadPart(ctx, to, uploadID, partNumber, part, uploaded[partNumber])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if first == nil {
+					first = err
+				}
+				return
+			}
+			parts[i] = &s3.CompletedPart{
+				ETag:       etag,
+				PartNumber: aws.Int64(partNumber),
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return first
+	}
+
+	_, err = s.api.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(to.Bucket),
+		Key:             aws.String(to.Path),
+		UploadId:        uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
+// uploadPart uploads the given part of a multipart upload, unless the part
+// which is already uploaded has the same size and content. It returns the
+// ETag of the part.
+func (s *S3) uploadPart(
+	ctx context.Context,
+	to *url.URL,
+	uploadID *string,
+	partNumber int64,
+	part *io.SectionReader,
+	uploaded *s3.Part,
+) (*string, error) {
+	if uploaded != nil && aws.Int64Value(uploaded.Size) == part.Size() {
+		hash := md5.New()
+		if _, err := io.Copy(hash, part); err != nil {
+			return nil, err
+		}
+
+		// ETags of the parts encrypted with SSE-KMS are not MD5 digests,
+		// such parts are uploaded again.
+		etag := strings.Trim(aws.StringValue(uploaded.ETag), `"`)
+		if etag == hex.EncodeToString(hash.Sum(nil)) {
+			return uploaded.ETag, nil
+		}
+
+		if _, err := part.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := s.api.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:     aws.String(to.Bucket),
+		Key:        aws.String(to.Path),
+		UploadId:   uploadID,
+		PartNumber: aws.Int64(partNumber),
+		Body:       part,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.ETag, nil
+}
+
+// findMultipartUpload returns the ID and the parts of the latest multipart
+// upload in progress for the given key with given storage class. ID is nil if
+// there is no such upload.
+func (s *S3) findMultipartUpload(ctx context.Context, to *url.URL, storageClass string) (*string, map[int64]*s3.Part, error) {
+	var latest *s3.MultipartUpload
+	err := s.api.ListMultipartUploadsPagesWithContext(ctx, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(to.Bucket),
+		Prefix: aws.String(to.Path),
+	}, func(p *s3.ListMultipartUploadsOutput, lastPage bool) bool {
+		for _, upload := range p.Uploads {
+			if aws.StringValue(upload.Key) != to.Path {
+				continue
+			}
+			if !sameStorageClass(aws.StringValue(upload.StorageClass), storageClass) {
+				continue
+			}
+			if latest == nil || aws.TimeValue(upload.Initiated).After(aws.TimeValue(latest.Initiated)) {
+				latest = upload
+			}
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if latest == nil {
+		return nil, nil, nil
+	}
+
+	parts := map[int64]*s3.Part{}
+	err = s.api.ListPartsPagesWithContext(ctx, &s3.ListPartsInput{
+		Bucket:   aws.String(to.Bucket),
+		Key:      aws.String(to.Path),
+		UploadId: latest.UploadId,
+	}, func(p *s3.ListPartsOutput, lastPage bool) bool {
+		for _, part := range p.Parts {
+			parts[aws.Int64Value(part.PartNumber)] = part
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return latest.UploadId, parts, nil
+}
+
+// sameStorageClass reports whether the given storage classes are the same.
+// An empty class is the default STANDARD class.
+func sameStorageClass(a, b string) bool {
+	if a == "" {
+		a = s3.StorageClassStandard
+	}
+	if b == "" {
+		b = s3.StorageClassStandard
+	}
+	return a == b
+}
+
+// newUploadInput creates the upload request of the content of reader with
+// given metadata.
+func newUploadInput(reader io.Reader, to *url.URL, metadata Metadata) (*s3manager.UploadInput, error) {
 	contentType := metadata.ContentType()
 	if contentType == "" {
 		contentType = "application/octet-stream"
@@ -414,10 +1082,19 @@ func (s *S3) Put(
 		Bucket:      aws.String(to.Bucket),
 		Key:         aws.String(to.Path),
 		Body:        reader,
-		ContentType: aws.String("text/csv"),
-		ContentEncoding: aws.String("gzip"),
+		ContentType: aws.String(contentType),
 	}
 
+	contentEncoding := metadata.ContentEncoding()
+	if contentEncoding != "" {
+		input.ContentEncoding = aws.String(contentEncoding)
+	}
+
+	input.CacheControl = nilIfEmpty(metadata.CacheControl())
+	input.ContentDisposition = nilIfEmpty(metadata.ContentDisposition())
+	input.ContentLanguage = nilIfEmpty(metadata.ContentLanguage())
+	input.WebsiteRedirectLocation = nilIfEmpty(metadata.WebsiteRedirectLocation())
+
 	storageClass := metadata.StorageClass()
 	if storageClass != "" {
 		input.StorageClass = aws.String(storageClass)
@@ -427,6 +1104,18 @@ func (s *S3) Put(
 		input.ACL = aws.String(acl)
 	}
 
+	input.GrantRead = nilIfEmpty(metadata.GrantRead())
+	input.GrantReadACP = nilIfEmpty(metadata.GrantReadACP())
+	input.GrantWriteACP = nilIfEmpty(metadata.GrantWriteACP())
+	input.GrantFullControl = nilIfEmpty(metadata.GrantFullControl())
+
+	// Content-MD5 header is only meaningful for single-part uploads. S3
+	// rejects the object with a 'BadDigest' error if the body is corrupted.
+	contentMD5 := metadata.ContentMD5()
+	if contentMD5 != "" {
+		input.ContentMD5 = aws.String(contentMD5)
+	}
+
 	sseEncryption := metadata.SSE()
 	if sseEncryption != "" {
 		input.ServerSideEncryption = aws.String(sseEncryption)
@@ -436,12 +1125,58 @@ func (s *S3) Put(
 		}
 	}
 
-	_, err := s.uploader.UploadWithContext(ctx, input, func(u *s3manager.Uploader) {
-		u.PartSize = partSize
-		u.Concurrency = concurrency
-	})
+	if mode := metadata.ObjectLockMode(); mode != "" {
+		input.ObjectLockMode = aws.String(mode)
+	}
 
-	return err
+	if date := metadata.ObjectLockRetainUntilDate(); date != "" {
+		retainUntil, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid object lock retain until date %q: %v", date, err)
+		}
+		input.ObjectLockRetainUntilDate = aws.Time(retainUntil)
+	}
+
+	if status := metadata.ObjectLockLegalHoldStatus(); status != "" {
+		input.ObjectLockLegalHoldStatus = aws.String(status)
+	}
+
+	userDefined := metadata.UserDefined()
+	if len(userDefined) > 0 {
+		input.Metadata = userMetadataInput(userDefined)
+	}
+
+	return input, nil
+}
+
+// userMetadataInput returns the user-defined metadata of a request, with the
+// non-ASCII values encoded.
+func userMetadataInput(userDefined map[string]string) map[string]*string {
+	input := make(map[string]*string, len(userDefined))
+	for k, v := range userDefined {
+		input[k] = aws.String(EncodeMetadataValue(v))
+	}
+	return input
+}
+
+// isObjectLockError reports whether the upload is rejected because the bucket
+// doesn't have object lock enabled.
+func isObjectLockError(err error) bool {
+	return errHasCode(err, "InvalidRequest") && strings.Contains(err.Error(), "Object Lock")
+}
+
+// ObjectURL returns the URL of the given object. The URL is virtual-hosted or
+// path-style depending on the endpoint, the same way the requests are sent. It
+// is not signed, thus only accessible if the object is public.
+func (s *S3) ObjectURL(url *url.URL) (string, error) {
+	req, _ := s.api.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(url.Bucket),
+		Key:    aws.String(url.Path),
+	})
+	if err := req.Build(); err != nil {
+		return "", err
+	}
+	return req.HTTPRequest.URL.String(), nil
 }
 
 // chunk is an object identifier container which is used on MultiDelete
@@ -521,29 +1256,77 @@ func (s *S3) doDelete(ctx context.Context, chunk chunk, resultch chan *Object) {
 	}
 
 	bucket := chunk.Bucket
-	o, err := s.api.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
-		Bucket: aws.String(bucket),
-		Delete: &s3.Delete{Objects: chunk.Keys},
-	})
-	if err != nil {
-		resultch <- &Object{Err: err}
-		return
+	keys := chunk.Keys
+	for attempt := 0; len(keys) > 0; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(deleteRetryDelay(attempt)):
+			case <-ctx.Done():
+				resultch <- &Object{Err: ctx.Err()}
+				return
+			}
+		}
+
+		o, err := s.api.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{Objects: keys},
+		})
+		// output is nil if the request itself fails, the error is reported
+		// once for the whole chunk instead of the keys.
+		if err != nil {
+			resultch <- &Object{Err: err}
+			return
+		}
+
+		for _, d := range o.Deleted {
+			key := fmt.Sprintf("s3://%v/%v", bucket, aws.StringValue(d.Key))
+			url, _ := url.New(key)
+			resultch <- &Object{URL: url}
+		}
+
+		// keys which failed with transient errors are resubmitted, the
+		// others are reported.
+		keys = nil
+		for _, e := range o.Errors {
+			if attempt < s.maxRetries && isRetryableDeleteError(aws.StringValue(e.Code)) {
+				keys = append(keys, &s3.ObjectIdentifier{Key: e.Key, VersionId: e.VersionId})
+				continue
+			}
+
+			key := fmt.Sprintf("s3://%v/%v", bucket, aws.StringValue(e.Key))
+			url, _ := url.New(key)
+			// error codes of the keys are kept, so that the errors are
+			// classified like the ones of the requests.
+			resultch <- &Object{
+				URL: url,
+				Err: awserr.New(aws.StringValue(e.Code), aws.StringValue(e.Message), nil),
+			}
+		}
 	}
+}
 
-	for _, d := range o.Deleted {
-		key := fmt.Sprintf("s3://%v/%v", bucket, aws.StringValue(d.Key))
-		url, _ := url.New(key)
-		resultch <- &Object{URL: url}
+// isRetryableDeleteError reports whether a key which failed to be deleted with
+// given error code is worth resubmitting. DeleteObjects responds with
+// '200 OK' even if some of the keys fail, so the SDK doesn't retry them.
+func isRetryableDeleteError(code string) bool {
+	switch code {
+	case "SlowDown", "InternalError", "ServiceUnavailable", "RequestTimeout":
+		return true
 	}
+	return false
+}
 
-	for _, e := range o.Errors {
-		key := fmt.Sprintf("s3://%v/%v", bucket, aws.StringValue(e.Key))
-		url, _ := url.New(key)
-		resultch <- &Object{
-			URL: url,
-			Err: fmt.Errorf(aws.StringValue(e.Message)),
-		}
+// deleteRetryDelay returns the delay before given attempt to delete the
+// failed keys.
+func deleteRetryDelay(attempt int) time.Duration {
+	delay := deleteRetryBaseDelay
+	for i := 1; i < attempt && delay < deleteRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > deleteRetryMaxDelay {
+		delay = deleteRetryMaxDelay
 	}
+	return delay
 }
 
 // MultiDelete is a asynchronous removal operation for multiple objects.
@@ -628,7 +1411,7 @@ func newSession(opts Options) (*session.Session, error) {
 	// otherwise use the path-style approach.
 	isVirtualHostStyle := isVirtualHostStyle(endpointURL)
 
-	useAccelerate := supportsTransferAcceleration(endpointURL)
+	useAccelerate := opts.UseAccelerate || supportsTransferAcceleration(endpointURL)
 	// AWS SDK handles transfer acceleration automatically. Setting the
 	// Endpoint to a transfer acceleration endpoint would cause bucket
 	// operations fail.
@@ -636,9 +1419,9 @@ func newSession(opts Options) (*session.Session, error) {
 		endpointURL = sentinelURL
 	}
 
-	var httpClient *http.Client
-	if opts.NoVerifySSL {
-		httpClient = insecureHTTPClient
+	httpClient, err := newHTTPClient(opts)
+	if err != nil {
+		return nil, err
 	}
 
 	awsCfg = awsCfg.
@@ -651,24 +1434,18 @@ func newSession(opts Options) (*session.Session, error) {
 		awsCfg.WithRegion(opts.Region)
 	}
 
-	awsCfg.Retryer = newCustomRetryer(opts.MaxRetries)
-
-	useSharedConfig := session.SharedConfigEnable
-	{
-		// Reverse of what the SDK does: if AWS_SDK_LOAD_CONFIG is 0 (or a
-		// falsy value) disable shared configs
-		loadCfg := os.Getenv("AWS_SDK_LOAD_CONFIG")
-		if loadCfg != "" {
-			if enable, _ := strconv.ParseBool(loadCfg); !enable {
-				useSharedConfig = session.SharedConfigDisable
-			}
-		}
+	if opts.NoSignRequest {
+		// do not sign requests when making calls, i.e. public buckets
+		awsCfg = awsCfg.WithCredentials(credentials.AnonymousCredentials)
 	}
 
+	awsCfg.Retryer = newCustomRetryer(opts.MaxRetries)
+
 	sess, err := session.NewSessionWithOptions(
 		session.Options{
 			Config:            *awsCfg,
-			SharedConfigState: useSharedConfig,
+			Profile:           opts.Profile,
+			SharedConfigState: sharedConfigState(),
 		},
 	)
 	if err != nil {
@@ -679,9 +1456,27 @@ func newSession(opts Options) (*session.Session, error) {
 		sess.Config.Region = aws.String(endpoints.UsEast1RegionID)
 	}
 
+	if opts.MaxRequestsPerSecond > 0 {
+		limiter := newRequestLimiter(opts.MaxRequestsPerSecond)
+		sess.Handlers.Sign.PushFront(limiter.handler)
+	}
+
 	return sess, nil
 }
 
+// sharedConfigState returns whether the shared config files are loaded. It's
+// the reverse of what the SDK does: shared configs are enabled unless
+// AWS_SDK_LOAD_CONFIG is 0 (or a falsy value).
+func sharedConfigState() session.SharedConfigState {
+	loadCfg := os.Getenv("AWS_SDK_LOAD_CONFIG")
+	if loadCfg != "" {
+		if enable, _ := strconv.ParseBool(loadCfg); !enable {
+			return session.SharedConfigDisable
+		}
+	}
+	return session.SharedConfigEnable
+}
+
 // customRetryer wraps the SDK's built in DefaultRetryer adding additional
 // error codes. Such as, retry for S3 InternalError code.
 type customRetryer struct {
@@ -710,10 +1505,107 @@ func (c *customRetryer) ShouldRetry(req *request.Request) bool {
 	return c.DefaultRetryer.ShouldRetry(req)
 }
 
-var insecureHTTPClient = &http.Client{
-	Transport: &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	},
+// newHTTPClient creates the HTTP client to be used by the AWS session. API,
+// downloader and uploader clients are created from the same session, so they
+// share the returned client and its transport. If no HTTP option is given,
+// nil is returned to let the SDK use its default client.
+func newHTTPClient(opts Options) (*http.Client, error) {
+	if !opts.NoVerifySSL && opts.InsecureHosts == "" && opts.HTTPTimeout == 0 &&
+		opts.ProxyURL == "" && opts.CABundle == "" && !opts.NoFollowRedirects {
+		return nil, nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: opts.NoVerifySSL}
+
+	if opts.ProxyURL != "" {
+		proxyURL, err := urlpkg.Parse(opts.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse proxy url %q: %v", opts.ProxyURL, err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if opts.CABundle != "" {
+		pem, err := ioutil.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %v", err)
+		}
+
+		// custom certificates are trusted in addition to the system ones.
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificate found in CA bundle %q", opts.CABundle)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	httpClient := &http.Client{
+		Transport: transport,
+		Timeout:   opts.HTTPTimeout,
+	}
+
+	if opts.InsecureHosts != "" && !opts.NoVerifySSL {
+		httpClient.Transport = newHostTransport(transport, opts.InsecureHosts)
+	}
+
+	if opts.NoFollowRedirects {
+		httpClient.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return httpClient, nil
+}
+
+// hostTransport sends the requests to the insecure hosts through a transport
+// which doesn't verify the TLS certificates, and the other requests through
+// the one which does.
+type hostTransport struct {
+	insecureHosts map[string]bool
+	secure        http.RoundTripper
+	insecure      http.RoundTripper
+}
+
+// newHostTransport creates a hostTransport for the given comma separated
+// hosts, based on the given transport.
+func newHostTransport(transport *http.Transport, hosts string) *hostTransport {
+	insecureHosts := map[string]bool{}
+	for _, host := range strings.Split(hosts, ",") {
+		if host = normalizeHost(host); host != "" {
+			insecureHosts[host] = true
+		}
+	}
+
+	insecure := transport.Clone()
+	insecure.TLSClientConfig.InsecureSkipVerify = true
+
+	return &hostTransport{
+		insecureHosts: insecureHosts,
+		secure:        transport,
+		insecure:      insecure,
+	}
+}
+
+// RoundTrip implements http.RoundTripper interface.
+func (t *hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.insecureHosts[normalizeHost(req.URL.Hostname())] {
+		return t.insecure.RoundTrip(req)
+	}
+	return t.secure.RoundTrip(req)
+}
+
+// normalizeHost returns the lowercase host name without the port.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.Trim(host, "[]")
 }
 
 func supportsTransferAcceleration(endpoint urlpkg.URL) bool {
@@ -724,6 +1616,24 @@ func isGoogleEndpoint(endpoint urlpkg.URL) bool {
 	return endpoint.Hostname() == gcsEndpoint
 }
 
+// IsAmazonEndpoint reports whether the requests are sent to AWS S3, i.e. no
+// custom endpoint is given or it's an endpoint of AWS.
+func IsAmazonEndpoint(opts Options) bool {
+	if opts.UseAccelerate {
+		return true
+	}
+
+	endpoint, err := parseEndpoint(opts.Endpoint)
+	if err != nil {
+		return false
+	}
+
+	host := endpoint.Hostname()
+	return endpoint == sentinelURL ||
+		strings.HasSuffix(host, ".amazonaws.com") ||
+		strings.HasSuffix(host, ".amazonaws.com.cn")
+}
+
 // isVirtualHostStyle reports whether the given endpoint supports S3 virtual
 // host style bucket name resolving. If a custom S3 API compatible endpoint is
 // given, resolve the bucketname from the URL path.
@@ -3,21 +3,30 @@ package storage
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	urlpkg "net/url"
 	"os"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/awstesting/unit"
 	"github.com/aws/aws-sdk-go/service/s3"
@@ -86,6 +95,26 @@ func TestNewSessionPathStyle(t *testing.T) {
 	}
 }
 
+func TestIsAmazonEndpoint(t *testing.T) {
+	testcases := []struct {
+		name     string
+		opts     Options
+		expected bool
+	}{
+		{name: "default", opts: Options{}, expected: true},
+		{name: "regional", opts: Options{Endpoint: "https://s3.eu-west-1.amazonaws.com"}, expected: true},
+		{name: "china", opts: Options{Endpoint: "s3.cn-north-1.amazonaws.com.cn"}, expected: true},
+		{name: "accelerate", opts: Options{UseAccelerate: true}, expected: true},
+		{name: "minio", opts: Options{Endpoint: "http://127.0.0.1:9000"}, expected: false},
+		{name: "google", opts: Options{Endpoint: "https://storage.googleapis.com"}, expected: false},
+		{name: "lookalike", opts: Options{Endpoint: "https://notamazonaws.com"}, expected: false},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, IsAmazonEndpoint(tc.opts), tc.expected, tc.name)
+	}
+}
+
 func TestNewSessionWithRegionSetViaEnv(t *testing.T) {
 	opts := Options{
 		Region: "",
@@ -107,6 +136,451 @@ func TestNewSessionWithRegionSetViaEnv(t *testing.T) {
 	}
 }
 
+func TestNewSessionUseAccelerate(t *testing.T) {
+	sess, err := newSession(Options{UseAccelerate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !aws.BoolValue(sess.Config.S3UseAccelerate) {
+		t.Fatalf("expected transfer acceleration to be enabled")
+	}
+	if aws.BoolValue(sess.Config.S3ForcePathStyle) {
+		t.Fatalf("expected virtual host style for transfer acceleration")
+	}
+}
+
+func TestNewSessionNoSignRequest(t *testing.T) {
+	sess, err := newSession(Options{NoSignRequest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sess.Config.Credentials != credentials.AnonymousCredentials {
+		t.Fatalf("expected anonymous credentials, got %v", sess.Config.Credentials)
+	}
+}
+
+func TestCachedSession(t *testing.T) {
+	opts := Options{Endpoint: "http://127.0.0.1:9000"}
+
+	sess, err := cachedSession(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	again, err := cachedSession(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != sess {
+		t.Fatalf("expected the session to be re-used for the same options")
+	}
+
+	// clients of a different endpoint have their own session.
+	other, err := cachedSession(Options{Endpoint: "http://127.0.0.1:9001", Profile: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == sess {
+		t.Fatalf("expected a new session for different options")
+	}
+	if endpoint := aws.StringValue(other.Config.Endpoint); endpoint != "http://127.0.0.1:9001" {
+		t.Fatalf("expected endpoint %q, got %q", "http://127.0.0.1:9001", endpoint)
+	}
+}
+
+func TestBucketRegion(t *testing.T) {
+	t.Parallel()
+
+	regions := map[string]string{
+		"region-bucket-eu": "eu-west-1",
+		"region-bucket-us": "", // us-east-1 has an empty location constraint
+	}
+
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	mockApi := s3.New(unit.Session)
+	mockApi.Handlers.Send.Clear()
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		if err := r.Context().Err(); err != nil {
+			r.Error = err
+			return
+		}
+
+		bucket := aws.StringValue(r.Params.(*s3.GetBucketLocationInput).Bucket)
+
+		mu.Lock()
+		calls[bucket]++
+		mu.Unlock()
+
+		region, ok := regions[bucket]
+		if !ok {
+			r.Error = awserr.New("AccessDenied", "Access Denied", nil)
+			return
+		}
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(fmt.Sprintf("<LocationConstraint>%v</LocationConstraint>", region))),
+		}
+	})
+
+	expected := map[string]string{
+		"region-bucket-eu":     "eu-west-1",
+		"region-bucket-us":     "us-east-1",
+		"region-bucket-denied": "fallback-region",
+	}
+
+	// canceled lookups are not cached.
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceledKey := regionKey{endpoint: t.Name(), profile: "first", bucket: "region-bucket-eu"}
+	if got := bucketRegion(canceled, mockApi, canceledKey, "fallback-region"); got != "fallback-region" {
+		t.Errorf("expected fallback region for canceled lookup, got %q", got)
+	}
+
+	// buckets are looked up once per profile.
+	profiles := []string{"first", "second"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, profile := range profiles {
+			for bucket, region := range expected {
+				wg.Add(1)
+				go func(profile, bucket, region string) {
+					defer wg.Done()
+					key := regionKey{endpoint: t.Name(), profile: profile, bucket: bucket}
+					got := bucketRegion(context.Background(), mockApi, key, "fallback-region")
+					if got != region {
+						t.Errorf("expected region %q of bucket %q, got %q", region, bucket, got)
+					}
+				}(profile, bucket, region)
+			}
+		}
+	}
+	wg.Wait()
+
+	for bucket := range expected {
+		if calls[bucket] != len(profiles) {
+			t.Errorf("expected GetBucketLocation to be called %d times for bucket %q, got %d", len(profiles), bucket, calls[bucket])
+		}
+	}
+}
+
+func TestBucketRegionWaitersRetryCanceledLookup(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{})
+
+	var mu sync.Mutex
+	calls := 0
+
+	mockApi := s3.New(unit.Session)
+	mockApi.Handlers.Send.Clear()
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		mu.Unlock()
+
+		// the first lookup hangs until it's canceled.
+		if first {
+			close(started)
+			<-r.Context().Done()
+			r.Error = awserr.New(request.CanceledErrorCode, "request context canceled", r.Context().Err())
+			return
+		}
+
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("<LocationConstraint>eu-west-1</LocationConstraint>")),
+		}
+	})
+
+	key := regionKey{endpoint: t.Name(), bucket: "bucket"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan string)
+	go func() {
+		canceled <- bucketRegion(ctx, mockApi, key, "fallback-region")
+	}()
+	<-started
+
+	const waiters = 10
+
+	var wg sync.WaitGroup
+	for i := 0; i < waiters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got := bucketRegion(context.Background(), mockApi, key, "fallback-region")
+			if got != "eu-west-1" {
+				t.Errorf("expected region %q, got %q", "eu-west-1", got)
+			}
+		}()
+	}
+
+	// let the waiters wait for the first lookup before canceling it.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if got := <-canceled; got != "fallback-region" {
+		t.Errorf("expected fallback region for canceled lookup, got %q", got)
+	}
+	wg.Wait()
+
+	if calls != 2 {
+		t.Errorf("expected GetBucketLocation to be called %d times, got %d", 2, calls)
+	}
+}
+
+func TestDetectRegionIsSkippedForGivenRegionAndEndpoint(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []Options{
+		{Region: "eu-west-1"},
+		{Endpoint: "http://127.0.0.1:9000"},
+	} {
+		if region := detectRegion(context.Background(), u, opts, unit.Session); region != "" {
+			t.Errorf("expected no region to be detected for %+v, got %q", opts, region)
+		}
+	}
+}
+
+func TestRequestLimiter(t *testing.T) {
+	t.Parallel()
+
+	limiter := newRequestLimiter(1)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// first request to a bucket is not delayed.
+	if err := limiter.wait(cancelled, "bucket"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// second request has to wait for a second.
+	if err := limiter.wait(cancelled, "bucket"); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	// buckets are limited separately.
+	if err := limiter.wait(cancelled, "another-bucket"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewSessionMaxRequestsPerSecond(t *testing.T) {
+	t.Parallel()
+
+	const requestsPerSecond = 20
+
+	sess, err := newSession(Options{
+		MaxRequestsPerSecond: requestsPerSecond,
+		NoSignRequest:        true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockApi := s3.New(sess)
+	mockApi.Handlers.Send.Clear()
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+	})
+
+	const requests = 3
+
+	start := time.Now()
+	for i := 0; i < requests; i++ {
+		_, err := mockApi.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String("bucket"),
+			Key:    aws.String("key"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	minElapsed := (requests - 1) * time.Second / requestsPerSecond
+	if elapsed := time.Since(start); elapsed < minElapsed {
+		t.Errorf("expected %v requests to take at least %v, took %v", requests, minElapsed, elapsed)
+	}
+}
+
+func TestNewSessionHTTPClient(t *testing.T) {
+	opts := Options{
+		HTTPTimeout:       30 * time.Second,
+		ProxyURL:          "http://proxy.example.com:3128",
+		NoFollowRedirects: true,
+	}
+
+	sess, err := newSession(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpClient := sess.Config.HTTPClient
+	if httpClient.Timeout != opts.HTTPTimeout {
+		t.Errorf("expected timeout %v, got %v", opts.HTTPTimeout, httpClient.Timeout)
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", httpClient.Transport)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "https://bucket.s3.amazonaws.com/key", nil)
+	proxyURL, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if proxyURL.String() != opts.ProxyURL {
+		t.Errorf("expected proxy %v, got %v", opts.ProxyURL, proxyURL)
+	}
+
+	if err := httpClient.CheckRedirect(req, nil); err != http.ErrUseLastResponse {
+		t.Errorf("expected redirects not to be followed, got %v", err)
+	}
+}
+
+func TestNewSessionWithoutHTTPOptionsUsesDefaultClient(t *testing.T) {
+	httpClient, err := newHTTPClient(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if httpClient != nil {
+		t.Errorf("expected SDK default HTTP client to be used, got %v", httpClient)
+	}
+}
+
+func TestNewHTTPClientCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	bundle, err := ioutil.TempFile("", "s5cmd-ca-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bundle.Name())
+
+	err = pem.Encode(bundle, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.Close()
+
+	// server certificate is not trusted without the bundle
+	httpClient, err := newHTTPClient(Options{HTTPTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := httpClient.Get(server.URL); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+
+	httpClient, err = newHTTPClient(Options{CABundle: bundle.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected certificate to be trusted, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestNewHTTPClientInsecureHosts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// certificate of the server is only trusted for the listed hosts.
+	httpClient, err := newHTTPClient(Options{InsecureHosts: "minio.local, 127.0.0.1:9000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := httpClient.Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected certificate verification to be skipped, got %v", err)
+	}
+	resp.Body.Close()
+
+	httpClient, err = newHTTPClient(Options{InsecureHosts: "minio.local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := httpClient.Get(server.URL); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+}
+
+func TestNewHTTPClientInvalidOptions(t *testing.T) {
+	bundle, err := ioutil.TempFile("", "s5cmd-ca-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(bundle.Name())
+
+	bundle.WriteString("not a certificate")
+	bundle.Close()
+
+	testcases := []struct {
+		name string
+		opts Options
+	}{
+		{
+			name: "missing_ca_bundle",
+			opts: Options{CABundle: "/nonexistent/ca-bundle.pem"},
+		},
+		{
+			name: "invalid_ca_bundle",
+			opts: Options{CABundle: bundle.Name()},
+		},
+		{
+			name: "invalid_proxy_url",
+			opts: Options{ProxyURL: "http://proxy.example.com:port"},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := newHTTPClient(tc.opts); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
 func TestS3ListSuccess(t *testing.T) {
 	url, err := url.New("s3://bucket/key")
 	if err != nil {
@@ -284,23 +758,296 @@ func TestS3ListContextCancelled(t *testing.T) {
 	}
 }
 
-func TestS3Retry(t *testing.T) {
-	testcases := []struct {
-		name string
-		err  error
-	}{
-		// Internal error
-		{
-			name: "InternalError",
-			err:  awserr.New("InternalError", "internal error", nil),
-		},
+// newSyntheticListAPI returns an S3 API which lists given keys, grouping them
+// by the delimiter of the requests. Like S3, at most 1000 keys are returned in
+// a page. Each request takes given latency. Listings of the prefixes in
+// failPrefixes fail.
+func newSyntheticListAPI(keys []string, latency time.Duration, failPrefixes ...string) *s3.S3 {
+	sort.Strings(keys)
 
-		// Request errors
-		{
-			name: "RequestError",
-			err:  awserr.New(request.ErrCodeRequestError, "request error", nil),
-		},
-		{
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Send.Clear()
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		time.Sleep(latency)
+
+		input := r.Params.(*s3.ListObjectsV2Input)
+		prefix, delimiter := aws.StringValue(input.Prefix), aws.StringValue(input.Delimiter)
+		for _, p := range failPrefixes {
+			if p == prefix {
+				r.Error = fmt.Errorf("listing %v failed", prefix)
+				return
+			}
+		}
+
+		const pageSize = 1000
+
+		// the continuation token is the index of the first key of the page,
+		// common prefixes are returned in the first page.
+		start, _ := strconv.Atoi(aws.StringValue(input.ContinuationToken))
+
+		output := r.Data.(*s3.ListObjectsV2Output)
+
+		i := sort.SearchStrings(keys, prefix)
+		if start > i {
+			i = start
+		}
+		for i < len(keys) && strings.HasPrefix(keys[i], prefix) {
+			key := keys[i]
+
+			rest := strings.TrimPrefix(key, prefix)
+			if j := strings.Index(rest, delimiter); delimiter != "" && j >= 0 {
+				commonPrefix := prefix + rest[:j+len(delimiter)]
+				if start == 0 {
+					output.CommonPrefixes = append(output.CommonPrefixes, &s3.CommonPrefix{Prefix: aws.String(commonPrefix)})
+				}
+
+				// skip the rest of the keys under the common prefix.
+				i += sort.Search(len(keys)-i, func(k int) bool {
+					return !strings.HasPrefix(keys[i+k], commonPrefix)
+				})
+				continue
+			}
+
+			if len(output.Contents) == pageSize {
+				output.NextContinuationToken = aws.String(strconv.Itoa(i))
+				break
+			}
+
+			output.Contents = append(output.Contents, &s3.Object{
+				Key:          aws.String(key),
+				LastModified: aws.Time(time.Unix(0, 0)),
+			})
+			i++
+		}
+	})
+
+	return mockApi
+}
+
+func TestS3ListConcurrently(t *testing.T) {
+	keys := []string{
+		"2023/top.txt",
+		"2023/01/data/a.csv",
+		"2023/01/data/b.csv",
+		"2023/01/meta/a.csv",
+		"2023/02/data/a.csv",
+		"2023/02/data/nested/c.csv",
+		"2023/03/other.csv",
+		"2024/01/data/a.csv",
+	}
+
+	testcases := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "wildcard in the middle",
+			src:  "s3://bucket/2023/*/data/*",
+			want: []string{
+				"01/data/a.csv",
+				"01/data/b.csv",
+				"02/data/a.csv",
+				"02/data/nested/c.csv",
+			},
+		},
+		{
+			name: "wildcard at the end",
+			src:  "s3://bucket/2023/*",
+			want: []string{
+				"01/data/a.csv",
+				"01/data/b.csv",
+				"01/meta/a.csv",
+				"02/data/a.csv",
+				"02/data/nested/c.csv",
+				"03/other.csv",
+				"top.txt",
+			},
+		},
+		{
+			name: "wildcard in the prefix",
+			src:  "s3://bucket/202*/01/data/a.csv",
+			want: []string{
+				"2023/01/data/a.csv",
+				"2024/01/data/a.csv",
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			for _, concurrency := range []int{1, 4} {
+				u, err := url.New(tc.src)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				mockS3 := &S3{
+					api:             newSyntheticListAPI(keys, 0),
+					listConcurrency: concurrency,
+				}
+
+				var got []string
+				for obj := range mockS3.List(context.Background(), u, true) {
+					if obj.Err != nil {
+						t.Fatalf("unexpected error: %v", obj.Err)
+					}
+					got = append(got, obj.URL.Relative())
+				}
+				sort.Strings(got)
+
+				if diff := cmp.Diff(tc.want, got); diff != "" {
+					t.Errorf("concurrency %v: (-want +got):\n%v", concurrency, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestS3ListConcurrentlyError(t *testing.T) {
+	keys := []string{
+		"2023/01/data/a.csv",
+		"2023/02/data/a.csv",
+		"2023/03/data/a.csv",
+	}
+
+	u, err := url.New("s3://bucket/2023/*/data/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockS3 := &S3{
+		api:             newSyntheticListAPI(keys, 0, "2023/02/"),
+		listConcurrency: 2,
+	}
+
+	var errs []error
+	for obj := range mockS3.List(context.Background(), u, true) {
+		if obj.Err != nil {
+			errs = append(errs, obj.Err)
+		}
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Error(), "listing 2023/02/ failed") {
+		t.Errorf("unexpected error: %v", errs[0])
+	}
+}
+
+func TestS3ListConcurrentlyNoItemFound(t *testing.T) {
+	u, err := url.New("s3://bucket/2023/*/data/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockS3 := &S3{
+		api:             newSyntheticListAPI([]string{"2023/01/meta/a.csv"}, 0),
+		listConcurrency: 2,
+	}
+
+	for obj := range mockS3.List(context.Background(), u, true) {
+		if obj.Err != ErrNoObjectFound {
+			t.Errorf("error got = %v, want %v", obj.Err, ErrNoObjectFound)
+		}
+	}
+}
+
+// BenchmarkS3ListConcurrently lists a synthetic bucket with hundreds of
+// prefixes, each with a page of keys. Each request takes 10ms. Listing
+// concurrently pays off when the prefixes have many keys, since sequential
+// listings get 1000 keys in a request regardless of the prefixes.
+func BenchmarkS3ListConcurrently(b *testing.B) {
+	const (
+		numPrefixes      = 200
+		numKeysPerPrefix = 1000
+	)
+
+	keys := make([]string, 0, numPrefixes*numKeysPerPrefix)
+	for i := 0; i < numPrefixes; i++ {
+		for j := 0; j < numKeysPerPrefix; j++ {
+			keys = append(keys, fmt.Sprintf("2023/%04d/data/%04d.csv", i, j))
+		}
+	}
+	api := newSyntheticListAPI(keys, 10*time.Millisecond)
+
+	for _, concurrency := range []int{1, 16, 64} {
+		concurrency := concurrency
+		b.Run(fmt.Sprintf("concurrency=%v", concurrency), func(b *testing.B) {
+			mockS3 := &S3{
+				api:             api,
+				listConcurrency: concurrency,
+			}
+
+			for i := 0; i < b.N; i++ {
+				u, err := url.New("s3://bucket/2023/*/data/*")
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				for obj := range mockS3.List(context.Background(), u, true) {
+					if obj.Err != nil {
+						b.Fatal(obj.Err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestS3CopyContextCancelled(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+
+	err = mockS3.Copy(ctx, u, u, NewMetadata())
+
+	reqErr, ok := err.(awserr.Error)
+	if !ok {
+		t.Fatalf("could not convert error: %v", err)
+	}
+
+	if reqErr.Code() != request.CanceledErrorCode {
+		t.Errorf("error got = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestS3Retry(t *testing.T) {
+	testcases := []struct {
+		name string
+		err  error
+	}{
+		// Internal error
+		{
+			name: "InternalError",
+			err:  awserr.New("InternalError", "internal error", nil),
+		},
+
+		// Request errors
+		{
+			name: "RequestError",
+			err:  awserr.New(request.ErrCodeRequestError, "request error", nil),
+		},
+		{
 			name: "UseOfClosedNetworkConnection",
 			err:  awserr.New(request.ErrCodeRequestError, "use of closed network connection", nil),
 		},
@@ -540,6 +1287,182 @@ func TestS3CopyEncryptionRequest(t *testing.T) {
 	}
 }
 
+func TestS3CopyReplaceMetadata(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		params := r.Params
+		assert.Equal(t, val(params, "MetadataDirective"), "REPLACE")
+		assert.Equal(t, val(params, "ContentType"), "text/html")
+		assert.Equal(t, val(params, "CacheControl"), "max-age=60")
+		assert.Equal(t, val(params, "StorageClass"), "STANDARD_IA")
+		assert.Equal(t, val(params, "ContentEncoding"), nil)
+		assert.DeepEqual(t, params.(*s3.CopyObjectInput).Metadata, map[string]*string{
+			"Owner": aws.String("s5cmd"),
+		})
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	metadata := NewMetadata().
+		SetMetadataDirective("REPLACE").
+		SetContentType("text/html").
+		SetCacheControl("max-age=60").
+		SetStorageClass("STANDARD_IA").
+		SetUserDefined("Owner", "s5cmd")
+
+	err = mockS3.Copy(context.Background(), u, u, metadata)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+}
+
+func TestS3CopySourceVersion(t *testing.T) {
+	from, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	to, err := url.New("s3://bucket/restored")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		params := r.Params
+		assert.Equal(t, val(params, "CopySource"), "bucket/key?versionId=3%2FL4kqtJlcpXroDTDmJ%2Brm")
+		assert.Equal(t, val(params, "Key"), "restored")
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	metadata := NewMetadata().SetSourceVersionID("3/L4kqtJlcpXroDTDmJ+rm")
+
+	err = mockS3.Copy(context.Background(), from, to, metadata)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+}
+
+func TestS3ETagPreconditions(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	testcases := []struct {
+		name        string
+		ifMatch     string
+		ifNoneMatch string
+		statusCode  int
+	}{
+		{
+			name:       "if_match_does_not_hold",
+			ifMatch:    "etag",
+			statusCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:        "if_none_match_does_not_hold",
+			ifNoneMatch: "etag",
+			statusCode:  http.StatusNotModified,
+		},
+	}
+
+	operations := map[string]func(s *S3, metadata Metadata) error{
+		"read": func(s *S3, metadata Metadata) error {
+			_, err := s.Read(context.Background(), u, metadata)
+			return err
+		},
+		"get": func(s *S3, metadata Metadata) error {
+			_, err := s.Get(context.Background(), u, aws.NewWriteAtBuffer(nil), metadata, 1, s3manager.DefaultDownloadPartSize)
+			return err
+		},
+		"copy": func(s *S3, metadata Metadata) error {
+			return s.Copy(context.Background(), u, u, metadata)
+		},
+	}
+
+	for _, tc := range testcases {
+		for opname, op := range operations {
+			tc, op := tc, op
+			t.Run(tc.name+"_"+opname, func(t *testing.T) {
+				mockApi := s3.New(unit.Session)
+
+				mockApi.Handlers.Unmarshal.Clear()
+				mockApi.Handlers.UnmarshalMeta.Clear()
+				mockApi.Handlers.UnmarshalError.Clear()
+				mockApi.Handlers.ValidateResponse.Clear()
+				mockApi.Handlers.Send.Clear()
+
+				mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+					r.HTTPResponse = &http.Response{
+						StatusCode: tc.statusCode,
+						Body:       ioutil.NopCloser(strings.NewReader("")),
+					}
+
+					switch params := r.Params.(type) {
+					case *s3.GetObjectInput:
+						assert.Equal(t, aws.StringValue(params.IfMatch), tc.ifMatch)
+						assert.Equal(t, aws.StringValue(params.IfNoneMatch), tc.ifNoneMatch)
+					case *s3.CopyObjectInput:
+						assert.Equal(t, aws.StringValue(params.CopySourceIfMatch), tc.ifMatch)
+						assert.Equal(t, aws.StringValue(params.CopySourceIfNoneMatch), tc.ifNoneMatch)
+					default:
+						t.Fatalf("unexpected request %T", params)
+					}
+
+					r.Error = awserr.NewRequestFailure(awserr.New("PreconditionFailed", "", nil), tc.statusCode, "")
+				})
+
+				mockS3 := &S3{
+					api:        mockApi,
+					downloader: s3manager.NewDownloaderWithClient(mockApi),
+				}
+
+				metadata := NewMetadata().
+					SetIfMatch(tc.ifMatch).
+					SetIfNoneMatch(tc.ifNoneMatch)
+
+				err := op(mockS3, metadata)
+				if err != ErrPreconditionFailed {
+					t.Errorf("expected %v, got %v", ErrPreconditionFailed, err)
+				}
+			})
+		}
+	}
+}
+
 func TestS3PutEncryptionRequest(t *testing.T) {
 	testcases := []struct {
 		name     string
@@ -633,24 +1556,1048 @@ func TestS3PutEncryptionRequest(t *testing.T) {
 	}
 }
 
-func TestS3listObjectsV2(t *testing.T) {
-	const (
-		numObjectsToReturn = 10100
-		numObjectsToIgnore = 1127
-
-		pre = "s3://bucket/key"
-	)
+func TestS3PutContentMD5Request(t *testing.T) {
+	testcases := []struct {
+		name       string
+		contentMD5 string
 
-	u, err := url.New(pre)
+		expectedContentMD5 string
+	}{
+		{
+			name: "no content-md5, by default",
+		},
+		{
+			name:               "content-md5 with a value",
+			contentMD5:         "1B2M2Y8AsgTpgAmY7PhCfg==",
+			expectedContentMD5: "1B2M2Y8AsgTpgAmY7PhCfg==",
+		},
+	}
+	u, err := url.New("s3://bucket/key")
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockApi := s3.New(unit.Session)
+
+			mockApi.Handlers.Unmarshal.Clear()
+			mockApi.Handlers.UnmarshalMeta.Clear()
+			mockApi.Handlers.UnmarshalError.Clear()
+			mockApi.Handlers.Send.Clear()
+
+			mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+
+				contentMD5 := val(r.Params, "ContentMD5")
+				if contentMD5 == nil && tc.expectedContentMD5 == "" {
+					return
+				}
+				assert.Equal(t, contentMD5, tc.expectedContentMD5)
+			})
+
+			mockS3 := &S3{
+				uploader: s3manager.NewUploaderWithClient(mockApi),
+			}
+
+			metadata := NewMetadata().SetContentMD5(tc.contentMD5)
+
+			err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+			if err != nil {
+				t.Errorf("Expected %v, but received %q", nil, err)
+			}
+		})
+	}
+}
+
+func TestS3ListDirectoryPlaceholders(t *testing.T) {
+	u, err := url.New("s3://bucket/*")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		r.Data = &s3.ListObjectsV2Output{
+			Contents: []*s3.Object{
+				{Key: aws.String("a/"), Size: aws.Int64(0), LastModified: aws.Time(time.Now())},
+				{Key: aws.String("a/file"), Size: aws.Int64(5), LastModified: aws.Time(time.Now())},
+				{Key: aws.String("a/b/"), Size: aws.Int64(0), LastModified: aws.Time(time.Now())},
+			},
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	// zero-byte keys with a trailing slash are folder placeholders. They must
+	// be reported as directories so that they are not downloaded as files.
+	expected := map[string]bool{
+		"a/":     true,
+		"a/file": false,
+		"a/b/":   true,
+	}
+
+	for obj := range mockS3.listObjectsV2(context.Background(), u) {
+		isDir, ok := expected[obj.URL.Path]
+		if !ok {
+			t.Errorf("%v should not have been returned", obj)
+			continue
+		}
+		assert.Equal(t, obj.Type.IsDir(), isDir, obj.URL.Path)
+		delete(expected, obj.URL.Path)
+	}
+	assert.Equal(t, len(expected), 0)
+}
+
+func TestS3PutContentHeaders(t *testing.T) {
+	testcases := []struct {
+		name            string
+		contentType     string
+		contentEncoding string
+
+		expectedContentType     string
+		expectedContentEncoding string
+	}{
+		{
+			name:                "default content-type, no content-encoding",
+			expectedContentType: "application/octet-stream",
+		},
+		{
+			name:                    "given content-type and content-encoding",
+			contentType:             "text/csv",
+			contentEncoding:         "gzip",
+			expectedContentType:     "text/csv",
+			expectedContentEncoding: "gzip",
+		},
+	}
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			mockApi := s3.New(unit.Session)
+
+			mockApi.Handlers.Unmarshal.Clear()
+			mockApi.Handlers.UnmarshalMeta.Clear()
+			mockApi.Handlers.UnmarshalError.Clear()
+			mockApi.Handlers.Send.Clear()
+
+			mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+				r.HTTPResponse = &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       ioutil.NopCloser(strings.NewReader("")),
+				}
+
+				assert.Equal(t, val(r.Params, "ContentType"), tc.expectedContentType)
+
+				contentEncoding := val(r.Params, "ContentEncoding")
+				if contentEncoding == nil && tc.expectedContentEncoding == "" {
+					return
+				}
+				assert.Equal(t, contentEncoding, tc.expectedContentEncoding)
+			})
+
+			mockS3 := &S3{
+				uploader: s3manager.NewUploaderWithClient(mockApi),
+			}
+
+			metadata := NewMetadata().
+				SetContentType(tc.contentType).
+				SetContentEncoding(tc.contentEncoding)
+
+			err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+			if err != nil {
+				t.Errorf("Expected %v, but received %q", nil, err)
+			}
+		})
+	}
+}
+
+func TestS3PutWebsiteRedirectLocation(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		assert.Equal(t, val(r.Params, "WebsiteRedirectLocation"), "https://example.com/")
+	})
+
+	mockS3 := &S3{
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	metadata := NewMetadata().SetWebsiteRedirectLocation("https://example.com/")
+
+	err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+}
+
+func TestS3PutObjectLock(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		assert.Equal(t, val(r.Params, "ObjectLockMode"), "COMPLIANCE")
+		assert.Equal(t, val(r.Params, "ObjectLockRetainUntilDate"), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, val(r.Params, "ObjectLockLegalHoldStatus"), "ON")
+	})
+
+	mockS3 := &S3{
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	metadata := NewMetadata().
+		SetObjectLockMode("COMPLIANCE").
+		SetObjectLockRetainUntilDate("2030-01-01T00:00:00Z").
+		SetObjectLockLegalHoldStatus("ON")
+
+	err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+}
+
+func TestS3Grants(t *testing.T) {
+	from, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	to, err := url.New("s3://bucket/copy")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		assert.Equal(t, val(r.Params, "GrantRead"), `id="79a59df900b949e5"`)
+		assert.Equal(t, val(r.Params, "GrantReadACP"), `uri="http://acs.amazonaws.com/groups/global/AllUsers"`)
+		assert.Equal(t, val(r.Params, "GrantWriteACP"), nil)
+		assert.Equal(t, val(r.Params, "GrantFullControl"), `emailAddress="owner@example.com"`)
+	})
+
+	mockS3 := &S3{
+		api:      mockApi,
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	metadata := NewMetadata().
+		SetGrantRead(`id="79a59df900b949e5"`).
+		SetGrantReadACP(`uri="http://acs.amazonaws.com/groups/global/AllUsers"`).
+		SetGrantFullControl(`emailAddress="owner@example.com"`)
+
+	err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), to, metadata, 1, 5242880)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+
+	err = mockS3.Copy(context.Background(), from, to, metadata)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+}
+
+func TestS3UserMetadataEncoding(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	const encoded = "=?utf-8?q?caf=C3=A9_cr=C3=A8me?="
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		switch r.Operation.Name {
+		case "PutObject":
+			assert.DeepEqual(t, r.Params.(*s3.PutObjectInput).Metadata, map[string]*string{
+				"title": aws.String(encoded),
+				"owner": aws.String("data-team"),
+			})
+		case "HeadObject":
+			*r.Data.(*s3.HeadObjectOutput) = s3.HeadObjectOutput{
+				Metadata: map[string]*string{
+					"Title": aws.String(encoded),
+					"Owner": aws.String("data-team"),
+					// values which look like encoded-words, but are not
+					// valid ones, are kept as is.
+					"Note": aws.String("=?utf-8?x?invalid?="),
+				},
+			}
+		default:
+			t.Errorf("unexpected operation %v", r.Operation.Name)
+		}
+	})
+
+	mockS3 := &S3{
+		api:      mockApi,
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	metadata := NewMetadata().
+		SetUserDefined("title", "café crème").
+		SetUserDefined("owner", "data-team")
+
+	err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+
+	obj, err := mockS3.Stat(context.Background(), u)
+	if err != nil {
+		t.Fatalf("Expected %v, but received %q", nil, err)
+	}
+
+	assert.DeepEqual(t, obj.Metadata.UserDefined(), map[string]string{
+		"Title": "café crème",
+		"Owner": "data-team",
+		"Note":  "=?utf-8?x?invalid?=",
+	})
+}
+
+func TestS3PutObjectLockNotEnabled(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+		r.Error = awserr.New("InvalidRequest", "Bucket is missing Object Lock Configuration", nil)
+	})
+
+	mockS3 := &S3{
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	metadata := NewMetadata().
+		SetObjectLockMode("GOVERNANCE").
+		SetObjectLockRetainUntilDate("2030-01-01T00:00:00Z")
+
+	err = mockS3.Put(context.Background(), bytes.NewReader([]byte("")), u, metadata, 1, 5242880)
+	if err == nil || !strings.Contains(err.Error(), "buckets which have object lock enabled") {
+		t.Errorf("expected object lock error, got %v", err)
+	}
+}
+
+func TestS3PutSinglePart(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var operations []string
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		operations = append(operations, r.Operation.Name)
+
+		assert.Equal(t, val(r.Params, "Bucket"), "bucket")
+		assert.Equal(t, val(r.Params, "Key"), "key")
+		assert.Equal(t, val(r.Params, "ContentType"), "text/plain")
+		assert.Equal(t, val(r.Params, "StorageClass"), "STANDARD_IA")
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	metadata := NewMetadata().
+		SetContentType("text/plain").
+		SetStorageClass("STANDARD_IA")
+
+	// the content is larger than the minimum part size, it'd be uploaded in
+	// multiple parts by the uploader.
+	content := make([]byte, s3manager.MinUploadPartSize+1)
+	err = mockS3.PutSinglePart(context.Background(), bytes.NewReader(content), u, metadata)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.DeepEqual(t, operations, []string{"PutObject"})
+}
+
+func TestS3PutSinglePartContextCancelled(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.ValidateResponse.Clear()
+
+	err = mockS3.PutSinglePart(ctx, bytes.NewReader([]byte("content")), u, NewMetadata())
+
+	reqErr, ok := err.(awserr.Error)
+	if !ok {
+		t.Fatalf("could not convert error: %v", err)
+	}
+
+	if reqErr.Code() != request.CanceledErrorCode {
+		t.Errorf("error got = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestS3Concat(t *testing.T) {
+	to, err := url.New("s3://bucket/combined")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var sources []*url.URL
+	for _, src := range []string{"s3://bucket/part-1", "s3://other/part-2"} {
+		u, err := url.New(src)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sources = append(sources, u)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var (
+		operations  []string
+		copySources []string
+	)
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		operations = append(operations, r.Operation.Name)
+
+		switch params := r.Params.(type) {
+		case *s3.CreateMultipartUploadInput:
+			assert.Equal(t, aws.StringValue(params.ContentType), "text/csv")
+			r.Data.(*s3.CreateMultipartUploadOutput).UploadId = aws.String("upload-id")
+		case *s3.UploadPartCopyInput:
+			assert.Equal(t, aws.StringValue(params.UploadId), "upload-id")
+			copySources = append(copySources, aws.StringValue(params.CopySource))
+			etag := fmt.Sprintf("etag-%v", aws.Int64Value(params.PartNumber))
+			r.Data.(*s3.UploadPartCopyOutput).CopyPartResult = &s3.CopyPartResult{ETag: aws.String(etag)}
+		case *s3.CompleteMultipartUploadInput:
+			parts := params.MultipartUpload.Parts
+			assert.Equal(t, len(parts), 2)
+			for i, part := range parts {
+				assert.Equal(t, aws.Int64Value(part.PartNumber), int64(i+1))
+				assert.Equal(t, aws.StringValue(part.ETag), fmt.Sprintf("etag-%v", i+1))
+			}
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	err = mockS3.Concat(context.Background(), sources, to, NewMetadata().SetContentType("text/csv"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.DeepEqual(t, operations, []string{
+		"CreateMultipartUpload",
+		"UploadPartCopy",
+		"UploadPartCopy",
+		"CompleteMultipartUpload",
+	})
+	assert.DeepEqual(t, copySources, []string{"bucket/part-1", "other/part-2"})
+}
+
+func TestS3ConcatAbortsUploadOnError(t *testing.T) {
+	to, err := url.New("s3://bucket/combined")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	src, err := url.New("s3://bucket/part-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var operations []string
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		operations = append(operations, r.Operation.Name)
+
+		switch r.Params.(type) {
+		case *s3.CreateMultipartUploadInput:
+			r.Data.(*s3.CreateMultipartUploadOutput).UploadId = aws.String("upload-id")
+		case *s3.UploadPartCopyInput:
+			r.HTTPResponse.StatusCode = http.StatusNotFound
+			r.Error = awserr.New("NoSuchKey", "The specified key does not exist.", nil)
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	err = mockS3.Concat(context.Background(), []*url.URL{src}, to, NewMetadata())
+
+	aerr, ok := err.(awserr.Error)
+	if !ok || aerr.Code() != "NoSuchKey" {
+		t.Errorf("expected NoSuchKey error, got %v", err)
+	}
+
+	assert.DeepEqual(t, operations, []string{
+		"CreateMultipartUpload",
+		"UploadPartCopy",
+		"AbortMultipartUpload",
+	})
+}
+
+func TestS3CopyACL(t *testing.T) {
+	from, err := url.New("s3://source/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	to, err := url.New("s3://target/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	owner := &s3.Owner{ID: aws.String("owner-id")}
+	grants := []*s3.Grant{
+		{
+			Grantee: &s3.Grantee{
+				ID:   aws.String("grantee-id"),
+				Type: aws.String(s3.TypeCanonicalUser),
+			},
+			Permission: aws.String(s3.PermissionRead),
+		},
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var operations []string
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		operations = append(operations, r.Operation.Name)
+
+		switch input := r.Params.(type) {
+		case *s3.GetObjectAclInput:
+			assert.Equal(t, aws.StringValue(input.Bucket), "source")
+			output := r.Data.(*s3.GetObjectAclOutput)
+			output.Owner = owner
+			output.Grants = grants
+		case *s3.PutObjectAclInput:
+			assert.Equal(t, aws.StringValue(input.Bucket), "target")
+			assert.DeepEqual(t, input.AccessControlPolicy.Owner, owner)
+			assert.DeepEqual(t, input.AccessControlPolicy.Grants, grants)
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	if err := mockS3.CopyACL(context.Background(), from, to); err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+	assert.DeepEqual(t, operations, []string{"GetObjectAcl", "PutObjectAcl"})
+}
+
+func TestS3SetACL(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var operations []string
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		operations = append(operations, r.Operation.Name)
+
+		input := r.Params.(*s3.PutObjectAclInput)
+		assert.Equal(t, aws.StringValue(input.Bucket), "bucket")
+		assert.Equal(t, aws.StringValue(input.Key), "key")
+		assert.Equal(t, aws.StringValue(input.ACL), "public-read")
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	if err := mockS3.SetACL(context.Background(), u, "public-read"); err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+	assert.DeepEqual(t, operations, []string{"PutObjectAcl"})
+}
+
+func TestS3MultiDeleteRetriesTransientErrors(t *testing.T) {
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var submitted [][]string
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		input := r.Params.(*s3.DeleteObjectsInput)
+		output := r.Data.(*s3.DeleteObjectsOutput)
+
+		var keys []string
+		for _, obj := range input.Delete.Objects {
+			keys = append(keys, aws.StringValue(obj.Key))
+		}
+		submitted = append(submitted, keys)
+
+		for _, key := range keys {
+			switch {
+			// throttled on the first attempt only.
+			case key == "throttled" && len(submitted) == 1:
+				output.Errors = append(output.Errors, &s3.Error{
+					Key:     aws.String(key),
+					Code:    aws.String("SlowDown"),
+					Message: aws.String("Please reduce your request rate."),
+				})
+			case key == "denied":
+				output.Errors = append(output.Errors, &s3.Error{
+					Key:     aws.String(key),
+					Code:    aws.String("AccessDenied"),
+					Message: aws.String("Access Denied"),
+				})
+			default:
+				output.Deleted = append(output.Deleted, &s3.DeletedObject{Key: aws.String(key)})
+			}
+		}
+	})
+
+	mockS3 := &S3{
+		api:        mockApi,
+		maxRetries: 3,
+	}
+
+	urlch := make(chan *url.URL, 3)
+	for _, key := range []string{"ok", "throttled", "denied"} {
+		u, err := url.New("s3://bucket/" + key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		urlch <- u
+	}
+	close(urlch)
+
+	deleted := map[string]bool{}
+	failed := map[string]string{}
+	for obj := range mockS3.MultiDelete(context.Background(), urlch) {
+		if obj.Err != nil {
+			// error codes of the keys are kept to classify the errors.
+			var awsErr awserr.Error
+			if !errors.As(obj.Err, &awsErr) {
+				t.Fatalf("expected an AWS error, got %v", obj.Err)
+			}
+			failed[obj.URL.Path] = awsErr.Code()
+			continue
+		}
+		deleted[obj.URL.Path] = true
+	}
+
+	assert.DeepEqual(t, deleted, map[string]bool{"ok": true, "throttled": true})
+	assert.DeepEqual(t, failed, map[string]string{"denied": "AccessDenied"})
+	assert.DeepEqual(t, submitted, [][]string{{"ok", "throttled", "denied"}, {"throttled"}})
+}
+
+func TestS3MultiDeleteGivesUpAfterMaxRetries(t *testing.T) {
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var attempts int
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		attempts++
+		output := r.Data.(*s3.DeleteObjectsOutput)
+		output.Errors = []*s3.Error{
+			{
+				Key:     aws.String("key"),
+				Code:    aws.String("InternalError"),
+				Message: aws.String("We encountered an internal error."),
+			},
+		}
+	})
+
+	mockS3 := &S3{
+		api:        mockApi,
+		maxRetries: 2,
+	}
+
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockS3.Delete(context.Background(), u)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	assert.Equal(t, attempts, 3)
+}
+
+func TestS3MultiDeleteRequestError(t *testing.T) {
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	requestErr := awserr.New("AccessDenied", "Access Denied", nil)
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.Error = requestErr
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// single delete
+	if err := mockS3.Delete(context.Background(), u); err != requestErr {
+		t.Errorf("expected %v, got %v", requestErr, err)
+	}
+
+	// batch delete
+	urlch := make(chan *url.URL, 1)
+	urlch <- u
+	close(urlch)
+
+	var results []*Object
+	for obj := range mockS3.MultiDelete(context.Background(), urlch) {
+		results = append(results, obj)
+	}
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %v", len(results))
+	}
+	if results[0].Err != requestErr {
+		t.Errorf("expected %v, got %v", requestErr, results[0].Err)
+	}
+}
+
+func TestS3StatMetadata(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		output := r.Data.(*s3.HeadObjectOutput)
+		output.ContentType = aws.String("text/csv")
+		output.ContentEncoding = aws.String("gzip")
+		output.ContentLength = aws.Int64(42)
+		output.WebsiteRedirectLocation = aws.String("/new-page.html")
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	obj, err := mockS3.Stat(context.Background(), u)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assert.Equal(t, obj.Size, int64(42))
+	assert.Equal(t, obj.Metadata.ContentType(), "text/csv")
+	assert.Equal(t, obj.Metadata.ContentEncoding(), "gzip")
+	assert.Equal(t, obj.Metadata.WebsiteRedirectLocation(), "/new-page.html")
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	testcases := []struct {
+		name     string
+		endpoint string
+		expected string
+	}{
+		{
+			name:     "virtual_host_style_when_missing_endpoint",
+			expected: "https://bucket.s3.amazonaws.com/dir/my%20file.txt",
+		},
+		{
+			name:     "virtual_host_style_for_google_cloud_storage",
+			endpoint: "https://" + gcsEndpoint,
+			expected: "https://bucket.storage.googleapis.com/dir/my%20file.txt",
+		},
+		{
+			name:     "path_style_for_custom_endpoint",
+			endpoint: "https://example.com",
+			expected: "https://example.com/bucket/dir/my%20file.txt",
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.New("s3://bucket/dir/my file.txt")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			opts := Options{Endpoint: tc.endpoint, Region: "us-east-1"}
+			sess, err := newSession(opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			client := &S3{
+				api: s3.New(sess),
+			}
+
+			got, err := client.ObjectURL(u)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			assert.Equal(t, got, tc.expected)
+		})
+	}
+}
+
+func TestS3PutMinimumPartSize(t *testing.T) {
+	const (
+		mb       = 1024 * 1024
+		fileSize = 11 * mb
+	)
+
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var (
+		mu        sync.Mutex
+		partSizes []int64
+	)
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		switch r.Operation.Name {
+		case "CreateMultipartUpload":
+			r.Data.(*s3.CreateMultipartUploadOutput).UploadId = aws.String("upload-id")
+		case "UploadPart":
+			body := val(r.Params, "Body").(io.ReadSeeker)
+			size, _ := body.Seek(0, io.SeekEnd)
+
+			mu.Lock()
+			partSizes = append(partSizes, size)
+			mu.Unlock()
+
+			r.Data.(*s3.UploadPartOutput).ETag = aws.String("etag")
+		}
+	})
+
+	mockS3 := &S3{
+		uploader: s3manager.NewUploaderWithClient(mockApi),
+	}
+
+	body := bytes.NewReader(make([]byte, fileSize))
+
+	// 1 MiB parts are below the minimum part size S3 accepts.
+	err = mockS3.Put(context.Background(), body, u, NewMetadata(), 1, 1*mb)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Slice(partSizes, func(i, j int) bool { return partSizes[i] > partSizes[j] })
+	assert.DeepEqual(t, partSizes, []int64{
+		s3manager.MinUploadPartSize,
+		s3manager.MinUploadPartSize,
+		fileSize - 2*s3manager.MinUploadPartSize,
+	})
+}
+
+func TestS3listObjectsV2(t *testing.T) {
+	const (
+		numObjectsToReturn = 10100
+		numObjectsToIgnore = 1127
+
+		pre = "s3://bucket/key"
+	)
+
+	u, err := url.New(pre)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	mapReturnObjNameToModtime := map[string]time.Time{}
+	mapIgnoreObjNameToModtime := map[string]time.Time{}
+
+	s3objs := make([]*s3.Object, 0, numObjectsToIgnore+numObjectsToReturn)
 
-	mapReturnObjNameToModtime := map[string]time.Time{}
-	mapIgnoreObjNameToModtime := map[string]time.Time{}
-
-	s3objs := make([]*s3.Object, 0, numObjectsToIgnore+numObjectsToReturn)
-
 	for i := 0; i < numObjectsToReturn; i++ {
 		fname := fmt.Sprintf("%s/%d", pre, i)
 		now := time.Now()
@@ -714,3 +2661,161 @@ func TestS3listObjectsV2(t *testing.T) {
 	}
 	assert.Equal(t, len(mapReturnObjNameToModtime), 0)
 }
+
+func TestS3PutResumable(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	const partSize = s3manager.MinUploadPartSize
+	content := bytes.Repeat([]byte("s5cmd"), int(2*partSize+100)/5)
+	firstPart := md5.Sum(content[:partSize])
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var (
+		mu       sync.Mutex
+		uploaded []int64
+		parts    []*s3.CompletedPart
+	)
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		switch r.Operation.Name {
+		case "ListMultipartUploads":
+			*r.Data.(*s3.ListMultipartUploadsOutput) = s3.ListMultipartUploadsOutput{
+				Uploads: []*s3.MultipartUpload{
+					{Key: aws.String("key"), UploadId: aws.String("old"), Initiated: aws.Time(time.Unix(100, 0))},
+					{Key: aws.String("key"), UploadId: aws.String("latest"), Initiated: aws.Time(time.Unix(200, 0)), StorageClass: aws.String("STANDARD")},
+					{Key: aws.String("key"), UploadId: aws.String("glacier"), Initiated: aws.Time(time.Unix(250, 0)), StorageClass: aws.String("GLACIER")},
+					{Key: aws.String("key2"), UploadId: aws.String("other"), Initiated: aws.Time(time.Unix(300, 0))},
+				},
+			}
+		case "ListParts":
+			assert.Equal(t, val(r.Params, "UploadId"), "latest")
+			*r.Data.(*s3.ListPartsOutput) = s3.ListPartsOutput{
+				Parts: []*s3.Part{
+					{PartNumber: aws.Int64(1), Size: aws.Int64(partSize), ETag: aws.String(fmt.Sprintf("%q", hex.EncodeToString(firstPart[:])))},
+					{PartNumber: aws.Int64(2), Size: aws.Int64(partSize), ETag: aws.String(`"corrupted"`)},
+				},
+			}
+		case "UploadPart":
+			assert.Equal(t, val(r.Params, "UploadId"), "latest")
+			partNumber := val(r.Params, "PartNumber").(int64)
+			mu.Lock()
+			uploaded = append(uploaded, partNumber)
+			mu.Unlock()
+			*r.Data.(*s3.UploadPartOutput) = s3.UploadPartOutput{
+				ETag: aws.String(fmt.Sprintf(`"etag-%v"`, partNumber)),
+			}
+		case "CompleteMultipartUpload":
+			assert.Equal(t, val(r.Params, "UploadId"), "latest")
+			parts = r.Params.(*s3.CompleteMultipartUploadInput).MultipartUpload.Parts
+		default:
+			t.Errorf("unexpected operation %v", r.Operation.Name)
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	err = mockS3.PutResumable(context.Background(), bytes.NewReader(content), int64(len(content)), u, NewMetadata(), 2, partSize)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+
+	sort.Slice(uploaded, func(i, j int) bool { return uploaded[i] < uploaded[j] })
+	assert.DeepEqual(t, uploaded, []int64{2, 3})
+
+	var etags []string
+	for i, part := range parts {
+		assert.Equal(t, aws.Int64Value(part.PartNumber), int64(i+1))
+		etags = append(etags, aws.StringValue(part.ETag))
+	}
+	assert.DeepEqual(t, etags, []string{
+		fmt.Sprintf("%q", hex.EncodeToString(firstPart[:])),
+		`"etag-2"`,
+		`"etag-3"`,
+	})
+}
+
+func TestS3PutResumableStartsNewUploadForDifferentStorageClass(t *testing.T) {
+	u, err := url.New("s3://bucket/key")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	const partSize = s3manager.MinUploadPartSize
+	content := bytes.Repeat([]byte("s5cmd"), int(partSize+100)/5)
+
+	mockApi := s3.New(unit.Session)
+
+	mockApi.Handlers.Unmarshal.Clear()
+	mockApi.Handlers.UnmarshalMeta.Clear()
+	mockApi.Handlers.UnmarshalError.Clear()
+	mockApi.Handlers.Send.Clear()
+
+	var (
+		mu         sync.Mutex
+		operations []string
+	)
+
+	mockApi.Handlers.Send.PushBack(func(r *request.Request) {
+		r.HTTPResponse = &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+
+		mu.Lock()
+		operations = append(operations, r.Operation.Name)
+		mu.Unlock()
+
+		switch r.Operation.Name {
+		case "ListMultipartUploads":
+			*r.Data.(*s3.ListMultipartUploadsOutput) = s3.ListMultipartUploadsOutput{
+				Uploads: []*s3.MultipartUpload{
+					{Key: aws.String("key"), UploadId: aws.String("standard"), Initiated: aws.Time(time.Unix(100, 0)), StorageClass: aws.String("STANDARD")},
+				},
+			}
+		case "CreateMultipartUpload":
+			assert.Equal(t, val(r.Params, "StorageClass"), "GLACIER")
+			*r.Data.(*s3.CreateMultipartUploadOutput) = s3.CreateMultipartUploadOutput{
+				UploadId: aws.String("new"),
+			}
+		case "UploadPart", "CompleteMultipartUpload":
+			assert.Equal(t, val(r.Params, "UploadId"), "new")
+		default:
+			t.Errorf("unexpected operation %v", r.Operation.Name)
+		}
+	})
+
+	mockS3 := &S3{
+		api: mockApi,
+	}
+
+	// the upload in progress is of another storage class, it's not resumed.
+	metadata := NewMetadata().SetStorageClass("GLACIER")
+	err = mockS3.PutResumable(context.Background(), bytes.NewReader(content), int64(len(content)), u, metadata, 1, partSize)
+	if err != nil {
+		t.Errorf("Expected %v, but received %q", nil, err)
+	}
+
+	assert.DeepEqual(t, operations, []string{
+		"ListMultipartUploads",
+		"CreateMultipartUpload",
+		"UploadPart",
+		"UploadPart",
+		"CompleteMultipartUpload",
+	})
+}
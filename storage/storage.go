@@ -5,9 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/session"
+
 	"github.com/peak/s5cmd/storage/url"
 	"github.com/peak/s5cmd/strutil"
 )
@@ -18,6 +22,10 @@ var (
 
 	// ErrNoObjectFound indicates there are no objects found from a given directory.
 	ErrNoObjectFound = fmt.Errorf("no object found")
+
+	// ErrPreconditionFailed indicates the ETag of the object doesn't satisfy
+	// the given If-Match or If-None-Match precondition.
+	ErrPreconditionFailed = fmt.Errorf("precondition failed: ETag of the object doesn't satisfy the given condition")
 )
 
 // Storage is an interface for storage operations that is common
@@ -46,13 +54,28 @@ func NewLocalClient(opts Options) *Filesystem {
 	return &Filesystem{dryRun: opts.DryRun}
 }
 
-func NewRemoteClient(_ *url.URL, opts Options) (*S3, error) {
-	return newS3Storage(opts, cachedSession)
+// NewRemoteClient creates a client of the S3 compatible service of the given
+// options. The region of the bucket of the given url is detected if it's not
+// given, so the client sends the requests to the region of the bucket.
+func NewRemoteClient(ctx context.Context, url *url.URL, opts Options) (*S3, error) {
+	sess, err := cachedSession(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if region := detectRegion(ctx, url, opts, sess); region != "" {
+		opts.Region = region
+		sess, err = cachedSession(opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return newS3Storage(opts, func() *session.Session { return sess })
 }
 
-func NewClient(url *url.URL, opts Options) (Storage, error) {
+func NewClient(ctx context.Context, url *url.URL, opts Options) (Storage, error) {
 	if url.IsRemote() {
-		return NewRemoteClient(url, opts)
+		return NewRemoteClient(ctx, url, opts)
 	}
 	return NewLocalClient(opts), nil
 }
@@ -62,8 +85,37 @@ type Options struct {
 	MaxRetries  int
 	Endpoint    string
 	Region      string
+	Profile     string
 	NoVerifySSL bool
 	DryRun      bool
+
+	// NoSignRequest sends anonymous requests, which is required to access
+	// public buckets without credentials.
+	NoSignRequest bool
+
+	// UseAccelerate sends requests to S3 Transfer Acceleration endpoints.
+	// Acceleration must be enabled on the buckets.
+	UseAccelerate bool
+
+	// MaxRequestsPerSecond limits the number of requests sent to each bucket
+	// in a second. Zero means there is no limit.
+	MaxRequestsPerSecond int
+
+	// ListConcurrency is the number of sub-prefixes listed concurrently for
+	// wildcard listings. Listings are sequential if it's less than 2, or if
+	// the endpoint doesn't support ListObjectsV2 API.
+	ListConcurrency int
+
+	// InsecureHosts is a comma separated list of the hosts whose TLS
+	// certificates are not verified. Certificates of the other hosts are
+	// verified, unless NoVerifySSL is set.
+	InsecureHosts string
+
+	// HTTP client options
+	HTTPTimeout       time.Duration
+	ProxyURL          string
+	CABundle          string
+	NoFollowRedirects bool
 }
 
 // Object is a generic type which contains metadata for storage items.
@@ -75,6 +127,7 @@ type Object struct {
 	Size         int64        `json:"size,omitempty"`
 	StorageClass StorageClass `json:"storage_class,omitempty"`
 	Err          error        `json:"error,omitempty"`
+	Metadata     Metadata     `json:"-"`
 }
 
 // String returns the string representation of Object.
@@ -166,6 +219,18 @@ func (s StorageClass) IsGlacier() bool {
 	return s == "GLACIER"
 }
 
+// IsInfrequentAccess reports whether the storage class is one of the
+// infrequent access classes, which bill the objects smaller than 128KB as if
+// they were 128KB.
+func (s StorageClass) IsInfrequentAccess() bool {
+	switch s {
+	case "STANDARD_IA", "ONEZONE_IA", "GLACIER_IR":
+		return true
+	default:
+		return false
+	}
+}
+
 // notImplemented is a structure which is used on the unsupported operations.
 type notImplemented struct {
 	apiType string
@@ -193,6 +258,42 @@ func (m Metadata) SetACL(acl string) Metadata {
 	return m
 }
 
+func (m Metadata) GrantRead() string {
+	return m["GrantRead"]
+}
+
+func (m Metadata) SetGrantRead(grantees string) Metadata {
+	m["GrantRead"] = grantees
+	return m
+}
+
+func (m Metadata) GrantReadACP() string {
+	return m["GrantReadACP"]
+}
+
+func (m Metadata) SetGrantReadACP(grantees string) Metadata {
+	m["GrantReadACP"] = grantees
+	return m
+}
+
+func (m Metadata) GrantWriteACP() string {
+	return m["GrantWriteACP"]
+}
+
+func (m Metadata) SetGrantWriteACP(grantees string) Metadata {
+	m["GrantWriteACP"] = grantees
+	return m
+}
+
+func (m Metadata) GrantFullControl() string {
+	return m["GrantFullControl"]
+}
+
+func (m Metadata) SetGrantFullControl(grantees string) Metadata {
+	m["GrantFullControl"] = grantees
+	return m
+}
+
 func (m Metadata) StorageClass() string {
 	return m["StorageClass"]
 }
@@ -211,6 +312,101 @@ func (m Metadata) SetContentType(contentType string) Metadata {
 	return m
 }
 
+func (m Metadata) ContentEncoding() string {
+	return m["ContentEncoding"]
+}
+
+func (m Metadata) SetContentEncoding(contentEncoding string) Metadata {
+	m["ContentEncoding"] = contentEncoding
+	return m
+}
+
+func (m Metadata) CacheControl() string {
+	return m["CacheControl"]
+}
+
+func (m Metadata) SetCacheControl(cacheControl string) Metadata {
+	m["CacheControl"] = cacheControl
+	return m
+}
+
+func (m Metadata) ContentDisposition() string {
+	return m["ContentDisposition"]
+}
+
+func (m Metadata) SetContentDisposition(contentDisposition string) Metadata {
+	m["ContentDisposition"] = contentDisposition
+	return m
+}
+
+func (m Metadata) ContentLanguage() string {
+	return m["ContentLanguage"]
+}
+
+func (m Metadata) SetContentLanguage(contentLanguage string) Metadata {
+	m["ContentLanguage"] = contentLanguage
+	return m
+}
+
+// WebsiteRedirectLocation is the URL or the path which the requests of the
+// object are redirected to, if its bucket is configured as a website.
+func (m Metadata) WebsiteRedirectLocation() string {
+	return m["WebsiteRedirectLocation"]
+}
+
+func (m Metadata) SetWebsiteRedirectLocation(location string) Metadata {
+	m["WebsiteRedirectLocation"] = location
+	return m
+}
+
+// MetadataDirective specifies whether the metadata is copied from the source
+// object or replaced with the metadata provided in the copy request.
+func (m Metadata) MetadataDirective() string {
+	return m["MetadataDirective"]
+}
+
+func (m Metadata) SetMetadataDirective(directive string) Metadata {
+	m["MetadataDirective"] = directive
+	return m
+}
+
+// userMetadataPrefix is the prefix of the keys which hold user-defined
+// metadata of the objects.
+const userMetadataPrefix = "X-Amz-Meta-"
+
+// UserDefined returns user-defined metadata of the object.
+func (m Metadata) UserDefined() map[string]string {
+	userDefined := map[string]string{}
+	for k, v := range m {
+		if strings.HasPrefix(k, userMetadataPrefix) {
+			userDefined[strings.TrimPrefix(k, userMetadataPrefix)] = v
+		}
+	}
+	return userDefined
+}
+
+func (m Metadata) SetUserDefined(key, value string) Metadata {
+	m[userMetadataPrefix+key] = value
+	return m
+}
+
+// EncodeMetadataValue encodes a user-defined metadata value which has non-ASCII
+// characters as RFC 2047 encoded-words, since S3 only accepts US-ASCII
+// metadata. ASCII values are returned as is.
+func EncodeMetadataValue(value string) string {
+	return mime.QEncoding.Encode("utf-8", value)
+}
+
+// decodeMetadataValue decodes the RFC 2047 encoded-words of a user-defined
+// metadata value. Values which can't be decoded are returned as is.
+func decodeMetadataValue(value string) string {
+	decoded, err := new(mime.WordDecoder).DecodeHeader(value)
+	if err != nil {
+		return value
+	}
+	return decoded
+}
+
 func (m Metadata) SSE() string {
 	return m["EncryptionMethod"]
 }
@@ -228,3 +424,89 @@ func (m Metadata) SetSSEKeyID(kid string) Metadata {
 	m["EncryptionKeyID"] = kid
 	return m
 }
+
+func (m Metadata) ContentMD5() string {
+	return m["ContentMD5"]
+}
+
+func (m Metadata) SetContentMD5(md5 string) Metadata {
+	m["ContentMD5"] = md5
+	return m
+}
+
+// IfMatch is the ETag precondition of read and copy requests. The request
+// fails unless the ETag of the source object matches.
+func (m Metadata) IfMatch() string {
+	return m["IfMatch"]
+}
+
+func (m Metadata) SetIfMatch(etag string) Metadata {
+	m["IfMatch"] = etag
+	return m
+}
+
+// IfNoneMatch is the ETag precondition of read and copy requests. The request
+// fails if the ETag of the source object matches.
+func (m Metadata) IfNoneMatch() string {
+	return m["IfNoneMatch"]
+}
+
+func (m Metadata) SetIfNoneMatch(etag string) Metadata {
+	m["IfNoneMatch"] = etag
+	return m
+}
+
+// Range is the byte range of read requests, e.g. "bytes=0-1023". Only the
+// given part of the object is read.
+func (m Metadata) Range() string {
+	return m["Range"]
+}
+
+func (m Metadata) SetRange(byteRange string) Metadata {
+	m["Range"] = byteRange
+	return m
+}
+
+// SourceVersionID is the version of the source object to be copied, instead
+// of its current version.
+func (m Metadata) SourceVersionID() string {
+	return m["SourceVersionID"]
+}
+
+func (m Metadata) SetSourceVersionID(versionID string) Metadata {
+	m["SourceVersionID"] = versionID
+	return m
+}
+
+// ObjectLockMode is the retention mode of the object, i.e. GOVERNANCE or
+// COMPLIANCE.
+func (m Metadata) ObjectLockMode() string {
+	return m["ObjectLockMode"]
+}
+
+func (m Metadata) SetObjectLockMode(mode string) Metadata {
+	m["ObjectLockMode"] = mode
+	return m
+}
+
+// ObjectLockRetainUntilDate is the date in RFC3339 format until the object
+// is locked.
+func (m Metadata) ObjectLockRetainUntilDate() string {
+	return m["ObjectLockRetainUntilDate"]
+}
+
+func (m Metadata) SetObjectLockRetainUntilDate(date string) Metadata {
+	m["ObjectLockRetainUntilDate"] = date
+	return m
+}
+
+// ObjectLockLegalHoldStatus is the legal hold status of the object, i.e. ON
+// or OFF.
+func (m Metadata) ObjectLockLegalHoldStatus() string {
+	return m["ObjectLockLegalHoldStatus"]
+}
+
+func (m Metadata) SetObjectLockLegalHoldStatus(status string) Metadata {
+	m["ObjectLockLegalHoldStatus"] = status
+	return m
+}
@@ -22,6 +22,9 @@ const (
 
 	// matchAllRe is the regex to match everything
 	matchAllRe string = ".*"
+
+	// doubleStar is the wildcard to match keys at any depth
+	doubleStar string = "**"
 )
 
 type urlType int
@@ -201,6 +204,16 @@ func (u *URL) remoteURL() string {
 //		regex: ^a/b/test./c/.*?\\.tsv$
 //		delimiter: ""
 //
+// "**" matches keys at any depth. Since wildcard keys are listed without a
+// delimiter, everything under the prefix is listed and then filtered.
+//
+// Example:
+//		key: logs/**/*.gz
+//		prefix: logs/
+//		filter: **/*.gz
+//		regex: ^logs/(?:.*/)?.*?\\.gz$
+//		delimiter: ""
+//
 // It prepares delimiter, prefix and regex for regular strings.
 // These are used in S3 listing operations.
 // See: https://docs.aws.amazon.com/AmazonS3/latest/dev/ListingKeysHierarchy.html
@@ -225,9 +238,8 @@ func (u *URL) setPrefixAndFilter() error {
 
 	filterRegex := matchAllRe
 	if u.filter != "" {
-		filterRegex = regexp.QuoteMeta(u.filter)
-		filterRegex = strings.Replace(filterRegex, "\\?", ".", -1)
-		filterRegex = strings.Replace(filterRegex, "\\*", ".*?", -1)
+		atBoundary := u.Prefix == "" || strings.HasSuffix(u.Prefix, s3Separator)
+		filterRegex = globToRegex(u.filter, atBoundary)
 	}
 	filterRegex = regexp.QuoteMeta(u.Prefix) + filterRegex
 	r, err := regexp.Compile("^" + filterRegex + "$")
@@ -238,6 +250,34 @@ func (u *URL) setPrefixAndFilter() error {
 	return nil
 }
 
+// globToRegex converts the filter of a wildcard URL to a regular expression.
+// "?" matches a single character and "*" matches any number of characters,
+// including the separator. "**/" at the beginning of a path element matches
+// zero or more directories, e.g. "logs/**/*.gz" matches both "logs/a.gz" and
+// "logs/x/y/b.gz". atBoundary reports whether the filter starts a path
+// element.
+func globToRegex(filter string, atBoundary bool) string {
+	var builder strings.Builder
+	for i := 0; i < len(filter); i++ {
+		switch {
+		case strings.HasPrefix(filter[i:], doubleStar+s3Separator) && atBoundary:
+			builder.WriteString("(?:.*/)?")
+			i += len(doubleStar)
+		case strings.HasPrefix(filter[i:], doubleStar):
+			builder.WriteString(".*")
+			i += len(doubleStar) - 1
+		case filter[i] == '*':
+			builder.WriteString(".*?")
+		case filter[i] == '?':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(filter[i : i+1]))
+		}
+		atBoundary = filter[i] == '/'
+	}
+	return builder.String()
+}
+
 // Clone creates a copy of the receiver.
 func (u *URL) Clone() *URL {
 	return &URL{
@@ -254,6 +294,46 @@ func (u *URL) Clone() *URL {
 	}
 }
 
+// Recursive returns a copy of the remote URL which matches all the keys under
+// it, without grouping them by the delimiter. Local URLs and URLs with glob
+// characters are returned as is, since local directories are always walked
+// and wildcards already match keys at any depth.
+//
+// Example:
+//		key: a/b/
+//		prefix: a/b/
+//		filter: *
+//		regex: ^a/b/.*?$
+//		delimiter: ""
+//
+func (u *URL) Recursive() (*URL, error) {
+	clone := u.Clone()
+	if !u.IsRemote() || u.HasGlob() {
+		return clone, nil
+	}
+
+	clone.Path += "*"
+	clone.Delimiter = ""
+	if err := clone.setPrefixAndFilter(); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+// Depth returns the number of directory levels between the base of the
+// listing and the object, which is the number of separators in its relative
+// path. Trailing separator of a prefix is not counted.
+//
+// Example:
+//		relative path: a/b/file.txt
+//		depth: 2
+//
+func (u *URL) Depth() int {
+	rel := filepath.ToSlash(u.Relative())
+	rel = strings.TrimSuffix(rel, s3Separator)
+	return strings.Count(rel, s3Separator)
+}
+
 // SetRelative explicitly sets the relative path of u against given base value.
 func (u *URL) SetRelative(base string) {
 	dir := filepath.Dir(base)
@@ -137,6 +137,19 @@ func TestURLSetPrefixAndFilter(t *testing.T) {
 				filterRegex: regexp.MustCompile("^a/b_c/.*?/de/.*?/test$"),
 			},
 		},
+		{
+			name: "double_star_operation",
+			before: &URL{
+				Path: "a/**/b/*.gz",
+			},
+			after: &URL{
+				Path:        "a/**/b/*.gz",
+				Prefix:      "a/",
+				Delimiter:   "",
+				filter:      "**/b/*.gz",
+				filterRegex: regexp.MustCompile("^a/(?:.*/)?b/.*?\\.gz$"),
+			},
+		},
 		{
 			name: "not_wild_operation",
 			before: &URL{
@@ -245,6 +258,28 @@ func TestCheckMatch(t *testing.T) {
 				"prefix/dummy/a":          {},
 			},
 		},
+		{
+			name: "match_if_has_double_star_at_any_depth",
+			url:  "s3://bucket/logs/**/*.gz",
+			keys: map[string]matchResult{
+				"logs/a.gz":       {true, "a.gz"},
+				"logs/x/b.gz":     {true, "x/b.gz"},
+				"logs/x/y/c.gz":   {true, "x/y/c.gz"},
+				"logs/x/y/c.txt":  {},
+				"other/a.gz":      {},
+				"logsx/y/c.gz":    {},
+				"logs/x/y/c.gz/z": {},
+			},
+		},
+		{
+			name: "match_if_has_double_star_in_the_middle_of_element",
+			url:  "s3://bucket/logs/a**.gz",
+			keys: map[string]matchResult{
+				"logs/a.gz":     {true, "a.gz"},
+				"logs/ab/c.gz":  {true, "ab/c.gz"},
+				"logs/b/a/c.gz": {},
+			},
+		},
 		{
 			name: "not_match_if_single_wildcard_does_not_match_with_key",
 			url:  "s3://bucket/*.tsv",
@@ -402,3 +437,82 @@ func TestURLIsBucket(t *testing.T) {
 		}
 	}
 }
+
+func TestURLRecursive(t *testing.T) {
+	tests := []struct {
+		input      string
+		key        string
+		wantMatch  bool
+		wantRel    string
+		wantPrefix string
+	}{
+		{"s3://bucket", "a/b/c.txt", true, "a/b/c.txt", ""},
+		{"s3://bucket/a/", "a/b/c.txt", true, "b/c.txt", "a/"},
+		{"s3://bucket/a/", "ab/c.txt", false, "", "a/"},
+		{"s3://bucket/a/b", "a/b/c.txt", true, "b/c.txt", "a/b"},
+		{"s3://bucket/a/*.txt", "a/b/c.txt", true, "b/c.txt", "a/"},
+	}
+	for _, tc := range tests {
+		u, err := New(tc.input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		recursive, err := u.Recursive()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if recursive.Delimiter != "" {
+			t.Errorf("%v: expected no delimiter, got %q", tc.input, recursive.Delimiter)
+		}
+
+		if recursive.Prefix != tc.wantPrefix {
+			t.Errorf("%v: prefix got = %q, want %q", tc.input, recursive.Prefix, tc.wantPrefix)
+		}
+
+		if got := recursive.Match(tc.key); got != tc.wantMatch {
+			t.Errorf("%v: match %q got = %v, want %v", tc.input, tc.key, got, tc.wantMatch)
+			continue
+		}
+
+		if tc.wantMatch && recursive.Relative() != tc.wantRel {
+			t.Errorf("%v: relative path of %q got = %q, want %q", tc.input, tc.key, recursive.Relative(), tc.wantRel)
+		}
+	}
+
+	// local urls are returned as is
+	u, _ := New("dir/")
+	recursive, _ := u.Recursive()
+	if recursive.Path != "dir/" {
+		t.Errorf("local url should not be changed, got %q", recursive.Path)
+	}
+}
+
+func TestURLDepth(t *testing.T) {
+	tests := []struct {
+		input string
+		key   string
+		want  int
+	}{
+		{"s3://bucket/*", "file.txt", 0},
+		{"s3://bucket/*", "a/b/file.txt", 2},
+		{"s3://bucket/a/*", "a/b/file.txt", 1},
+		{"s3://bucket/a/", "a/b/", 0},
+		{"s3://bucket/a/", "a/file.txt", 0},
+	}
+	for _, tc := range tests {
+		u, err := New(tc.input)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !u.Match(tc.key) {
+			t.Fatalf("%v: expected %q to match", tc.input, tc.key)
+		}
+
+		if got := u.Depth(); got != tc.want {
+			t.Errorf("%v: depth of %q got = %v, want %v", tc.input, tc.key, got, tc.want)
+		}
+	}
+}
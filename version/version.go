@@ -1,6 +1,12 @@
 package version
 
-import "strings"
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+)
 
 var (
 	// Version represents the git tag of a particular release.
@@ -8,14 +14,32 @@ var (
 
 	// GitCommit represents git commit hash of a particular release.
 	GitCommit = "dev"
+
+	// BuildDate represents the time of a particular build in RFC3339 format.
+	BuildDate = "unknown"
 )
 
 // GetHumanVersion returns human readable version information.
 func GetHumanVersion() string {
-	version := Version
-	if !strings.HasPrefix(version, "v") {
-		version = "v" + Version
-	}
+	return tagVersion() + "-" + GitCommit
+}
+
+// GetBuildInfo returns the version of s5cmd, the commit and the date it was
+// built from, and the versions of Go and AWS SDK it was built with. Each one
+// is printed on its own line.
+func GetBuildInfo() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "version:    %v\n", tagVersion())
+	fmt.Fprintf(&buf, "git commit: %v\n", GitCommit)
+	fmt.Fprintf(&buf, "build date: %v\n", BuildDate)
+	fmt.Fprintf(&buf, "go version: %v\n", runtime.Version())
+	fmt.Fprintf(&buf, "aws-sdk-go: v%v", aws.SDKVersion)
+	return buf.String()
+}
 
-	return version + "-" + GitCommit
+func tagVersion() string {
+	if !strings.HasPrefix(Version, "v") {
+		return "v" + Version
+	}
+	return Version
 }